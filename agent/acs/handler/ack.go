@@ -0,0 +1,52 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/aws/amazon-ecs-agent/agent/wsclient"
+	"github.com/cihub/seelog"
+)
+
+// The ack retry settings are shared by all the ACS message handlers
+const (
+	// ackAttempts is the number of times an ack is sent for a message before
+	// giving up
+	ackAttempts          = 3
+	ackBackoffMin        = 250 * time.Millisecond
+	ackBackoffMax        = 2 * time.Second
+	ackBackoffJitter     = 0.2
+	ackBackoffMultiplier = 2
+)
+
+// sendAck sends the ack for a message to ACS. A failed ack is sent again with
+// backoff until the attempts run out or the context is canceled, and the error
+// of the last attempt is returned
+func sendAck(ctx context.Context, acsClient wsclient.ClientServer, ack interface{}, messageID string) error {
+	backoff := retry.NewExponentialBackoff(ackBackoffMin, ackBackoffMax, ackBackoffJitter, ackBackoffMultiplier)
+	err := acsClient.MakeRequest(ack)
+	for attempt := 1; err != nil && attempt < ackAttempts; attempt++ {
+		seelog.Warnf("Error 'ack'ing request with messageID: %s, error: %v", messageID, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Duration()):
+		}
+		err = acsClient.MakeRequest(ack)
+	}
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/acs/client"
@@ -33,6 +34,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
@@ -43,13 +45,12 @@ import (
 )
 
 const (
-	// heartbeatTimeout is the maximum time to wait between heartbeats
-	// without disconnecting
+	// heartbeatTimeout is the default maximum time to wait between heartbeats
+	// without disconnecting. The jitter added to it is as long as the timeout
 	heartbeatTimeout = 1 * time.Minute
-	heartbeatJitter  = 1 * time.Minute
-	// wsRWTimeout is the duration of read and write deadline for the
-	// websocket connection
-	wsRWTimeout = 2*heartbeatTimeout + heartbeatJitter
+	// connectionMaxAgeJitterDivisor is the fraction of the connection max age
+	// added to it at most as jitter
+	connectionMaxAgeJitterDivisor = 10
 
 	inactiveInstanceReconnectDelay = 1 * time.Hour
 
@@ -70,6 +71,9 @@ const (
 // Session defines an interface for handler's long-lived connection with ACS.
 type Session interface {
 	Start() error
+	// Connected returns whether the session is connected to ACS, and the time
+	// at which it connected or disconnected
+	Connected() (bool, time.Time)
 }
 
 // session encapsulates all arguments needed by the handler to connect to ACS
@@ -86,6 +90,7 @@ type session struct {
 	stateManager                    statemanager.StateManager
 	credentialsManager              rolecredentials.Manager
 	taskHandler                     *eventhandler.TaskHandler
+	appliedPayloads                 *AppliedPayloads
 	ctx                             context.Context
 	cancel                          context.CancelFunc
 	backoff                         retry.Backoff
@@ -93,6 +98,10 @@ type session struct {
 	_heartbeatTimeout               time.Duration
 	_heartbeatJitter                time.Duration
 	_inactiveInstanceReconnectDelay time.Duration
+	// connectionLock guards connected and connectionChangedAt
+	connectionLock      sync.RWMutex
+	connected           bool
+	connectionChangedAt time.Time
 }
 
 // sessionResources defines the resource creator interface for starting
@@ -132,7 +141,9 @@ type sessionState interface {
 	getSendCredentialsURLParameter() string
 }
 
-// NewSession creates a new Session object
+// NewSession creates a new Session object. The applied payloads are expected
+// to be saved with the agent state, so that redelivered payloads are detected
+// across agent restarts
 func NewSession(ctx context.Context,
 	config *config.Config,
 	deregisterInstanceEventStream *eventstream.EventStream,
@@ -143,7 +154,8 @@ func NewSession(ctx context.Context,
 	stateManager statemanager.StateManager,
 	taskEngine engine.TaskEngine,
 	credentialsManager rolecredentials.Manager,
-	taskHandler *eventhandler.TaskHandler) Session {
+	taskHandler *eventhandler.TaskHandler,
+	appliedPayloads *AppliedPayloads) Session {
 	resources := newSessionResources(credentialsProvider)
 	backoff := retry.NewExponentialBackoff(connectionBackoffMin, connectionBackoffMax,
 		connectionBackoffJitter, connectionBackoffMultiplier)
@@ -160,16 +172,34 @@ func NewSession(ctx context.Context,
 		taskEngine:                      taskEngine,
 		credentialsManager:              credentialsManager,
 		taskHandler:                     taskHandler,
+		appliedPayloads:                 appliedPayloads,
 		ctx:                             derivedContext,
 		cancel:                          cancel,
 		backoff:                         backoff,
 		resources:                       resources,
-		_heartbeatTimeout:               heartbeatTimeout,
-		_heartbeatJitter:                heartbeatJitter,
+		_heartbeatTimeout:               sessionHeartbeatTimeout(config),
+		_heartbeatJitter:                sessionHeartbeatTimeout(config),
 		_inactiveInstanceReconnectDelay: inactiveInstanceReconnectDelay,
+		connectionChangedAt:             time.Now(),
 	}
 }
 
+// Connected returns whether the session is connected to ACS, and the time at
+// which it connected or disconnected. Until the first connection, the time is
+// the creation time of the session
+func (acsSession *session) Connected() (bool, time.Time) {
+	acsSession.connectionLock.RLock()
+	defer acsSession.connectionLock.RUnlock()
+	return acsSession.connected, acsSession.connectionChangedAt
+}
+
+func (acsSession *session) setConnected(connected bool) {
+	acsSession.connectionLock.Lock()
+	defer acsSession.connectionLock.Unlock()
+	acsSession.connected = connected
+	acsSession.connectionChangedAt = time.Now()
+}
+
 // Start starts the session. It'll forever keep trying to connect to ACS unless
 // the context is cancelled.
 //
@@ -208,6 +238,7 @@ func (acsSession *session) Start() error {
 				// reconnect immediately
 				seelog.Info("ACS Websocket connection closed for a valid reason")
 				acsSession.backoff.Reset()
+				metrics.MetricsEngineGlobal.RecordACSReconnect("closed")
 				sendEmptyMessageOnChannel(connectToACS)
 			} else {
 				// Disconnected unexpectedly from ACS, compute backoff duration to
@@ -219,6 +250,7 @@ func (acsSession *session) Start() error {
 					// If the context was not cancelled and we've waited for the
 					// wait duration without any errors, send the message to the channel
 					// to reconnect to ACS
+					metrics.MetricsEngineGlobal.RecordACSReconnect("error")
 					sendEmptyMessageOnChannel(connectToACS)
 				} else {
 					// Wait was interrupted. We expect the session to close as canceling
@@ -290,7 +322,8 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 		acsSession.stateManager,
 		refreshCredsHandler,
 		acsSession.credentialsManager,
-		acsSession.taskHandler)
+		acsSession.taskHandler,
+		acsSession.appliedPayloads)
 	// Clear the acks channel on return because acks of messageids don't have any value across sessions
 	defer payloadHandler.clearAcks()
 	payloadHandler.start()
@@ -312,10 +345,13 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 	// Start inactivity timer for closing the connection
 	timer := newDisconnectionTimer(client, acsSession.heartbeatTimeout(), acsSession.heartbeatJitter())
 	// Any message from the server resets the disconnect timeout
-	client.SetAnyRequestHandler(anyMessageHandler(timer, client))
+	client.SetAnyRequestHandler(anyMessageHandler(timer, client, acsSession.heartbeatTimeout(), acsSession.heartbeatJitter(),
+		sessionReadWriteTimeout(acsSession.agentConfig)))
 	defer timer.Stop()
 
 	acsSession.resources.connectedToACS()
+	acsSession.setConnected(true)
+	defer acsSession.setConnected(false)
 
 	backoffResetTimer := time.AfterFunc(
 		retry.AddJitter(acsSession.heartbeatTimeout(), acsSession.heartbeatJitter()), func() {
@@ -332,6 +368,15 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 		serveErr <- client.Serve()
 	}()
 
+	// Reconnect once the connection reaches its maximum age, if configured.
+	// The jitter keeps a fleet of instances from reconnecting all at once
+	var connectionMaxAge <-chan time.Time
+	if maxAge := acsSession.agentConfig.ACSConnectionMaxAge; maxAge > 0 {
+		maxAgeTimer := time.NewTimer(retry.AddJitter(maxAge, maxAge/connectionMaxAgeJitterDivisor))
+		defer maxAgeTimer.Stop()
+		connectionMaxAge = maxAgeTimer.C
+	}
+
 	for {
 		select {
 		case <-acsSession.ctx.Done():
@@ -343,6 +388,10 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 			// client.Serve returns an error. This can happen when the
 			// the connection is closed by ACS or the agent
 			return err
+		case <-connectionMaxAge:
+			// Returning without an error reconnects without any backoff
+			seelog.Info("ACS connection reached its maximum age; reconnecting")
+			return nil
 		}
 	}
 }
@@ -376,9 +425,34 @@ func (acsSession *session) heartbeatJitter() time.Duration {
 	return acsSession._heartbeatJitter
 }
 
+// sessionHeartbeatTimeout returns the configured heartbeat timeout, or the
+// default one
+func sessionHeartbeatTimeout(cfg *config.Config) time.Duration {
+	if cfg.ACSHeartbeatTimeout > 0 {
+		return cfg.ACSHeartbeatTimeout
+	}
+	return heartbeatTimeout
+}
+
+// sessionReadWriteTimeout returns the configured read and write timeout, or
+// the one derived from the heartbeat timeout when it is not set
+func sessionReadWriteTimeout(cfg *config.Config) time.Duration {
+	if cfg.ACSReadWriteTimeout > 0 {
+		return cfg.ACSReadWriteTimeout
+	}
+	timeout := sessionHeartbeatTimeout(cfg)
+	return readWriteTimeout(timeout, timeout)
+}
+
+// readWriteTimeout returns the duration of the read and write deadlines of the
+// websocket connection, which exceeds the longest wait between heartbeats
+func readWriteTimeout(heartbeatTimeout time.Duration, heartbeatJitter time.Duration) time.Duration {
+	return 2*heartbeatTimeout + heartbeatJitter
+}
+
 // createACSClient creates the ACS Client using the specified URL
 func (acsResources *acsSessionResources) createACSClient(url string, cfg *config.Config) wsclient.ClientServer {
-	return acsclient.New(url, cfg, acsResources.credentialsProvider, wsRWTimeout)
+	return acsclient.New(url, cfg, acsResources.credentialsProvider, sessionReadWriteTimeout(cfg))
 }
 
 // connectedToACS records a successful connection to ACS
@@ -436,11 +510,12 @@ func newDisconnectionTimer(client wsclient.ClientServer, timeout time.Duration,
 
 // anyMessageHandler handles any server message. Any server message means the
 // connection is active and thus the heartbeat disconnect should not occur
-func anyMessageHandler(timer ttime.Timer, client wsclient.ClientServer) func(interface{}) {
+func anyMessageHandler(timer ttime.Timer, client wsclient.ClientServer,
+	heartbeatTimeout time.Duration, heartbeatJitter time.Duration, rwTimeout time.Duration) func(interface{}) {
 	return func(interface{}) {
 		seelog.Debug("ACS activity occurred")
 		// Reset read deadline as there's activity on the channel
-		if err := client.SetReadDeadline(time.Now().Add(wsRWTimeout)); err != nil {
+		if err := client.SetReadDeadline(time.Now().Add(rwTimeout)); err != nil {
 			seelog.Warnf("Unable to extend read deadline for ACS connection: %v", err)
 		}
 
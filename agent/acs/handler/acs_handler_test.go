@@ -227,6 +227,7 @@ func TestHandlerReconnectsOnConnectErrors(t *testing.T) {
 		}).Return(io.EOF).MinTimes(1),
 	)
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -298,8 +299,9 @@ func TestWaitForDurationReturnsTrueWhenContextNotCancelled(t *testing.T) {
 	defer cancel()
 
 	acsSession := session{
-		ctx:    ctx,
-		cancel: cancel,
+		appliedPayloads: NewAppliedPayloads(),
+		ctx:             ctx,
+		cancel:          cancel,
 	}
 
 	assert.True(t, acsSession.waitForDuration(time.Millisecond),
@@ -315,8 +317,9 @@ func TestWaitForDurationReturnsFalseWhenContextCancelled(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	acsSession := session{
-		ctx:    ctx,
-		cancel: cancel,
+		appliedPayloads: NewAppliedPayloads(),
+		ctx:             ctx,
+		cancel:          cancel,
 	}
 	cancel()
 
@@ -371,6 +374,7 @@ func TestHandlerReconnectsWithoutBackoffOnEOFError(t *testing.T) {
 		mockBackoff.EXPECT().Reset().AnyTimes(),
 	)
 	acsSession := session{
+		appliedPayloads:                 NewAppliedPayloads(),
 		containerInstanceARN:            "myArn",
 		credentialsProvider:             testCreds,
 		agentConfig:                     testConfig,
@@ -434,6 +438,7 @@ func TestHandlerReconnectsWithBackoffOnNonEOFError(t *testing.T) {
 		mockBackoff.EXPECT().Reset().AnyTimes(),
 	)
 	acsSession := session{
+		appliedPayloads:               NewAppliedPayloads(),
 		containerInstanceARN:          "myArn",
 		credentialsProvider:           testCreds,
 		agentConfig:                   testConfig,
@@ -493,6 +498,7 @@ func TestHandlerGeneratesDeregisteredInstanceEvent(t *testing.T) {
 	mockWsClient.EXPECT().Connect().Return(fmt.Errorf("InactiveInstanceException:"))
 	inactiveInstanceReconnectDelay := 200 * time.Millisecond
 	acsSession := session{
+		appliedPayloads:                 NewAppliedPayloads(),
 		containerInstanceARN:            "myArn",
 		credentialsProvider:             testCreds,
 		agentConfig:                     testConfig,
@@ -562,6 +568,7 @@ func TestHandlerReconnectDelayForInactiveInstanceError(t *testing.T) {
 		}).Return(io.EOF),
 	)
 	acsSession := session{
+		appliedPayloads:                 NewAppliedPayloads(),
 		containerInstanceARN:            "myArn",
 		credentialsProvider:             testCreds,
 		agentConfig:                     testConfig,
@@ -620,6 +627,7 @@ func TestHandlerReconnectsOnServeErrors(t *testing.T) {
 	)
 
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -671,6 +679,7 @@ func TestHandlerStopsWhenContextIsCancelled(t *testing.T) {
 		}).Return(errors.New("InactiveInstanceException")),
 	)
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -725,6 +734,7 @@ func TestHandlerReconnectsOnDiscoverPollEndpointError(t *testing.T) {
 		ecsClient.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(acsURL, nil).Times(1),
 	)
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -798,6 +808,7 @@ func TestConnectionIsClosedOnIdle(t *testing.T) {
 		connectionClosed <- true
 	}).Return(nil)
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -852,6 +863,7 @@ func TestHandlerDoesntLeakGoroutines(t *testing.T) {
 	go func() {
 
 		acsSession := session{
+			appliedPayloads:      NewAppliedPayloads(),
 			containerInstanceARN: "myArn",
 			credentialsProvider:  testCreds,
 			agentConfig:          testConfig,
@@ -946,6 +958,7 @@ func TestStartSessionHandlesRefreshCredentialsMessages(t *testing.T) {
 			taskEngine,
 			credentialsManager,
 			taskHandler,
+			NewAppliedPayloads(),
 		)
 		acsSession.Start()
 		// StartSession should never return unless the context is canceled
@@ -1053,6 +1066,7 @@ func TestHandlerReconnectsCorrectlySetsSendCredentialsURLParameter(t *testing.T)
 	)
 
 	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
 		containerInstanceARN: "myArn",
 		credentialsProvider:  testCreds,
 		agentConfig:          testConfig,
@@ -1170,3 +1184,56 @@ func validateSendCredentialsInSession(t *testing.T, state sessionState, expected
 		t.Errorf("Incorrect value set for sendCredentials, expected: %s, got: %s", expected, sendCredentials)
 	}
 }
+
+func TestSessionHeartbeatTimeout(t *testing.T) {
+	assert.Equal(t, heartbeatTimeout, sessionHeartbeatTimeout(&config.Config{}))
+	assert.Equal(t, 30*time.Second, sessionHeartbeatTimeout(&config.Config{ACSHeartbeatTimeout: 30 * time.Second}))
+}
+
+func TestSessionReadWriteTimeout(t *testing.T) {
+	assert.Equal(t, readWriteTimeout(heartbeatTimeout, heartbeatTimeout), sessionReadWriteTimeout(&config.Config{}))
+	assert.Equal(t, 90*time.Second, sessionReadWriteTimeout(&config.Config{ACSHeartbeatTimeout: 30 * time.Second}))
+	assert.Equal(t, 5*time.Minute, sessionReadWriteTimeout(&config.Config{ACSReadWriteTimeout: 5 * time.Minute}))
+}
+
+func TestSessionReconnectsAtConnectionMaxAge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+	stateManager := statemanager.NewNoopStateManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	taskHandler := eventhandler.NewTaskHandler(ctx, stateManager, nil, nil)
+
+	serving := make(chan struct{})
+	defer close(serving)
+	mockWsClient := mock_wsclient.NewMockClientServer(ctrl)
+	mockWsClient.EXPECT().SetAnyRequestHandler(gomock.Any()).AnyTimes()
+	mockWsClient.EXPECT().AddRequestHandler(gomock.Any()).AnyTimes()
+	mockWsClient.EXPECT().Connect().Return(nil)
+	mockWsClient.EXPECT().Serve().Do(func() {
+		<-serving
+	}).Return(io.EOF)
+
+	cfg := *testConfig
+	cfg.ACSConnectionMaxAge = 10 * time.Millisecond
+	acsSession := session{
+		appliedPayloads:      NewAppliedPayloads(),
+		containerInstanceARN: "myArn",
+		credentialsProvider:  testCreds,
+		agentConfig:          &cfg,
+		taskEngine:           taskEngine,
+		ecsClient:            ecsClient,
+		stateManager:         stateManager,
+		taskHandler:          taskHandler,
+		ctx:                  ctx,
+		backoff:              retry.NewExponentialBackoff(connectionBackoffMin, connectionBackoffMax, connectionBackoffJitter, connectionBackoffMultiplier),
+		resources:            &mockSessionResources{},
+		_heartbeatTimeout:    time.Minute,
+		_heartbeatJitter:     time.Minute,
+	}
+	// The session ends without an error once the connection reaches its
+	// maximum age, so that it is re-established without any backoff
+	assert.NoError(t, acsSession.startACSSession(mockWsClient))
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// appliedPayloadsSize is the number of applied payload messages remembered
+// to detect redelivered payloads
+const appliedPayloadsSize = 100
+
+// AppliedPayloads records the payload messages that were applied to the task
+// engine, so that a payload redelivered by ACS is not applied twice. It is
+// saved with the agent state, which lets it detect redelivered payloads
+// across connections and agent restarts
+type AppliedPayloads struct {
+	// messages holds the most recently applied payload messages, oldest
+	// first
+	messages []appliedPayload
+	// lastSeqNum is the highest sequence number of the applied payload
+	// messages
+	lastSeqNum int64
+	lock       sync.RWMutex
+}
+
+// appliedPayload is a payload message that was applied to the task engine
+type appliedPayload struct {
+	MessageID string
+	SeqNum    int64
+}
+
+// appliedPayloadsJSON is the saved form of AppliedPayloads
+type appliedPayloadsJSON struct {
+	Messages   []appliedPayload
+	LastSeqNum int64
+}
+
+// NewAppliedPayloads returns an empty AppliedPayloads object
+func NewAppliedPayloads() *AppliedPayloads {
+	return &AppliedPayloads{}
+}
+
+// contains returns true if the payload message with the given id was applied
+func (payloads *AppliedPayloads) contains(messageID string) bool {
+	payloads.lock.RLock()
+	defer payloads.lock.RUnlock()
+
+	for _, message := range payloads.messages {
+		if message.MessageID == messageID {
+			return true
+		}
+	}
+	return false
+}
+
+// add records an applied payload message, forgetting the oldest one when
+// there are too many
+func (payloads *AppliedPayloads) add(messageID string, seqNum int64) {
+	payloads.lock.Lock()
+	defer payloads.lock.Unlock()
+
+	payloads.messages = append(payloads.messages, appliedPayload{MessageID: messageID, SeqNum: seqNum})
+	if len(payloads.messages) > appliedPayloadsSize {
+		payloads.messages = payloads.messages[len(payloads.messages)-appliedPayloadsSize:]
+	}
+	if seqNum > payloads.lastSeqNum {
+		payloads.lastSeqNum = seqNum
+	}
+}
+
+// getLastSeqNum returns the highest sequence number of the applied payload
+// messages
+func (payloads *AppliedPayloads) getLastSeqNum() int64 {
+	payloads.lock.RLock()
+	defer payloads.lock.RUnlock()
+
+	return payloads.lastSeqNum
+}
+
+// Reset forgets all the applied payload messages
+func (payloads *AppliedPayloads) Reset() {
+	payloads.lock.Lock()
+	defer payloads.lock.Unlock()
+
+	payloads.messages = nil
+	payloads.lastSeqNum = 0
+}
+
+// MarshalJSON marshals the applied payload messages for the state file
+func (payloads *AppliedPayloads) MarshalJSON() ([]byte, error) {
+	payloads.lock.RLock()
+	defer payloads.lock.RUnlock()
+
+	return json.Marshal(appliedPayloadsJSON{
+		Messages:   payloads.messages,
+		LastSeqNum: payloads.lastSeqNum,
+	})
+}
+
+// UnmarshalJSON restores the applied payload messages from the state file
+func (payloads *AppliedPayloads) UnmarshalJSON(data []byte) error {
+	var saved appliedPayloadsJSON
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	payloads.lock.Lock()
+	defer payloads.lock.Unlock()
+
+	payloads.messages = saved.Messages
+	payloads.lastSeqNum = saved.LastSeqNum
+	return nil
+}
@@ -0,0 +1,56 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppliedPayloadsForgetsOldestPayloads(t *testing.T) {
+	payloads := NewAppliedPayloads()
+	for i := 0; i <= appliedPayloadsSize; i++ {
+		payloads.add(strconv.Itoa(i), int64(i))
+	}
+
+	assert.False(t, payloads.contains("0"))
+	assert.True(t, payloads.contains("1"))
+	assert.True(t, payloads.contains(strconv.Itoa(appliedPayloadsSize)))
+	assert.EqualValues(t, appliedPayloadsSize, payloads.getLastSeqNum())
+}
+
+func TestAppliedPayloadsMarshalJSON(t *testing.T) {
+	payloads := NewAppliedPayloads()
+	payloads.add("1", 5)
+	payloads.add("2", 3)
+
+	data, err := json.Marshal(payloads)
+	require.NoError(t, err)
+
+	restored := NewAppliedPayloads()
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.True(t, restored.contains("1"))
+	assert.True(t, restored.contains("2"))
+	assert.EqualValues(t, 5, restored.getLastSeqNum())
+
+	restored.Reset()
+	assert.False(t, restored.contains("1"))
+	assert.EqualValues(t, 0, restored.getLastSeqNum())
+}
@@ -105,12 +105,12 @@ func (handler *attachENIHandler) handleSingleMessage(message *ecsacs.AttachTaskN
 
 	// Send ACK
 	go func(clusterArn *string, containerInstanceArn *string, messageID *string) {
-		if err := handler.acsClient.MakeRequest(&ecsacs.AckRequest{
+		if err := sendAck(handler.ctx, handler.acsClient, &ecsacs.AckRequest{
 			Cluster:           clusterArn,
 			ContainerInstance: containerInstanceArn,
 			MessageId:         messageID,
-		}); err != nil {
-			seelog.Warnf("Failed to ack request with messageId: %s, error: %v", aws.StringValue(messageID), err)
+		}, aws.StringValue(messageID)); err != nil {
+			seelog.Errorf("Unable to ack attach ENI message id %s after %d attempts", aws.StringValue(messageID), ackAttempts)
 		}
 	}(message.ClusterArn, message.ContainerInstanceArn, message.MessageId)
 
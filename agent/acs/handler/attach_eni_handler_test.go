@@ -17,6 +17,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -433,3 +434,44 @@ func TestENIAckWithinTimeout(t *testing.T) {
 
 	assert.Len(t, taskEngineState.(*dockerstate.DockerTaskEngineState).AllENIAttachments(), 1)
 }
+
+// TestENIAckRetried checks that a failed ack for an attach ENI message is sent
+// again
+func TestENIAckRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskEngineState := dockerstate.NewTaskEngineState()
+	manager := mock_statemanager.NewMockStateManager(ctrl)
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	eniAttachHandler := newAttachENIHandler(context.TODO(), clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager)
+	defer eniAttachHandler.stop()
+
+	var ackSent sync.WaitGroup
+	ackSent.Add(1)
+	gomock.InOrder(
+		mockWSClient.EXPECT().MakeRequest(gomock.Any()).Return(errors.New("error")),
+		mockWSClient.EXPECT().MakeRequest(gomock.Any()).Do(func(ackRequest *ecsacs.AckRequest) {
+			assert.Equal(t, eniMessageId, aws.StringValue(ackRequest.MessageId))
+			ackSent.Done()
+		}).Return(nil),
+	)
+	manager.EXPECT().Save().Return(nil)
+
+	err := eniAttachHandler.handleSingleMessage(&ecsacs.AttachTaskNetworkInterfacesMessage{
+		MessageId:            aws.String(eniMessageId),
+		ClusterArn:           aws.String(clusterName),
+		ContainerInstanceArn: aws.String(containerInstanceArn),
+		ElasticNetworkInterfaces: []*ecsacs.ElasticNetworkInterface{
+			{
+				Ec2Id:         aws.String("1"),
+				MacAddress:    aws.String(randomMAC),
+				AttachmentArn: aws.String("attachmentarn"),
+			},
+		},
+		TaskArn:       aws.String(taskArn),
+		WaitTimeoutMs: aws.Int64(waitTimeoutMillis),
+	})
+	assert.NoError(t, err)
+	ackSent.Wait()
+}
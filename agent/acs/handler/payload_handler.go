@@ -49,6 +49,9 @@ type payloadRequestHandler struct {
 	acsClient            wsclient.ClientServer
 	refreshHandler       refreshCredentialsHandler
 	credentialsManager   credentials.Manager
+	// appliedPayloads records the applied payload messages, it is saved with
+	// the agent state
+	appliedPayloads *AppliedPayloads
 }
 
 // newPayloadRequestHandler returns a new payloadRequestHandler object
@@ -62,7 +65,8 @@ func newPayloadRequestHandler(
 	saver statemanager.Saver,
 	refreshHandler refreshCredentialsHandler,
 	credentialsManager credentials.Manager,
-	taskHandler *eventhandler.TaskHandler) payloadRequestHandler {
+	taskHandler *eventhandler.TaskHandler,
+	appliedPayloads *AppliedPayloads) payloadRequestHandler {
 	// Create a cancelable context from the parent context
 	derivedContext, cancel := context.WithCancel(ctx)
 	return payloadRequestHandler{
@@ -79,6 +83,7 @@ func newPayloadRequestHandler(
 		acsClient:            acsClient,
 		refreshHandler:       refreshHandler,
 		credentialsManager:   credentialsManager,
+		appliedPayloads:      appliedPayloads,
 	}
 }
 
@@ -116,16 +121,17 @@ func (payloadHandler *payloadRequestHandler) sendAcks() {
 	}
 }
 
-// ackMessageId sends an AckRequest for a message id
+// ackMessageId sends an AckRequest for a message id. Failed acks are retried
+// with backoff, ACS sends the payload again if it is never acked
 func (payloadHandler *payloadRequestHandler) ackMessageId(messageID string) {
 	seelog.Debugf("Acking payload message id: %s", messageID)
-	err := payloadHandler.acsClient.MakeRequest(&ecsacs.AckRequest{
+	err := sendAck(payloadHandler.ctx, payloadHandler.acsClient, &ecsacs.AckRequest{
 		Cluster:           aws.String(payloadHandler.cluster),
 		ContainerInstance: aws.String(payloadHandler.containerInstanceArn),
 		MessageId:         aws.String(messageID),
-	})
+	}, messageID)
 	if err != nil {
-		seelog.Warnf("Error 'ack'ing request with messageID: %s, error: %v", messageID, err)
+		seelog.Errorf("Unable to ack payload message id %s after %d attempts", messageID, ackAttempts)
 	}
 }
 
@@ -149,8 +155,29 @@ func (payloadHandler *payloadRequestHandler) handleSingleMessage(payload *ecsacs
 		seelog.Criticalf("Received a payload with no message id")
 		return fmt.Errorf("received a payload with no message id")
 	}
-	seelog.Debugf("Received payload message, message id: %s", aws.StringValue(payload.MessageId))
-	credentialsAcks, allTasksHandled := payloadHandler.addPayloadTasks(payload)
+	messageID := aws.StringValue(payload.MessageId)
+	seelog.Debugf("Received payload message, message id: %s", messageID)
+	var credentialsAcks []*ecsacs.IAMRoleCredentialsAckRequest
+	allTasksHandled := true
+	if payloadHandler.appliedPayloads.contains(messageID) {
+		// ACS redelivers payloads that it did not get an ack for. The tasks
+		// are not applied twice, but the payload and its credentials are
+		// acked again
+		seelog.Infof("Received an already applied payload message, message id: %s", messageID)
+		credentialsAcks = payloadHandler.payloadCredentialsAcks(payload)
+	} else {
+		seqNum := aws.Int64Value(payload.SeqNum)
+		if lastSeqNum := payloadHandler.appliedPayloads.getLastSeqNum(); seqNum != 0 && seqNum <= lastSeqNum {
+			// Tasks are applied by arn and their desired status never goes
+			// back, so an older payload cannot undo a newer one
+			seelog.Warnf("Received payload message out of order, message id: %s, seqNum: %d, last seqNum: %d",
+				messageID, seqNum, lastSeqNum)
+		}
+		credentialsAcks, allTasksHandled = payloadHandler.addPayloadTasks(payload)
+		if allTasksHandled {
+			payloadHandler.appliedPayloads.add(messageID, seqNum)
+		}
+	}
 	// save the state of tasks we know about after passing them to the task engine
 	err := payloadHandler.saver.Save()
 	if err != nil {
@@ -294,6 +321,43 @@ func (payloadHandler *payloadRequestHandler) addTasks(payload *ecsacs.PayloadMes
 	return credentialsAcks, allTasksOK
 }
 
+// payloadCredentialsAcks stores the credentials of the tasks in a payload
+// message that was already applied, and returns the ack requests for them
+func (payloadHandler *payloadRequestHandler) payloadCredentialsAcks(payload *ecsacs.PayloadMessage) []*ecsacs.IAMRoleCredentialsAckRequest {
+	var credentialsAcks []*ecsacs.IAMRoleCredentialsAckRequest
+	for _, task := range payload.Tasks {
+		if task == nil {
+			continue
+		}
+		roleTypes := []string{credentials.ApplicationRoleType, credentials.ExecutionRoleType}
+		for i, acsCredentials := range []*ecsacs.IAMRoleCredentials{task.RoleCredentials, task.ExecutionRoleCredentials} {
+			if acsCredentials == nil {
+				continue
+			}
+			roleType := roleTypes[i]
+			taskIAMRoleCredentials := credentials.IAMRoleCredentialsFromACS(acsCredentials, roleType)
+			err := payloadHandler.credentialsManager.SetTaskCredentials(
+				&(credentials.TaskIAMRoleCredentials{
+					ARN:                aws.StringValue(task.Arn),
+					IAMRoleCredentials: taskIAMRoleCredentials,
+				}))
+			if err != nil {
+				seelog.Errorf("Failed to store %s credentials of task %s from redelivered payload: %v",
+					roleType, aws.StringValue(task.Arn), err)
+				continue
+			}
+			ack, err := payloadHandler.ackCredentials(payload.MessageId, taskIAMRoleCredentials.CredentialsID)
+			if err != nil {
+				seelog.Errorf("Failed to acknowledge %s credentials of task %s from redelivered payload: %v",
+					roleType, aws.StringValue(task.Arn), err)
+				continue
+			}
+			credentialsAcks = append(credentialsAcks, ack)
+		}
+	}
+	return credentialsAcks
+}
+
 func (payloadHandler *payloadRequestHandler) ackCredentials(messageID *string, credentialsID string) (*ecsacs.IAMRoleCredentialsAckRequest, error) {
 	creds, ok := payloadHandler.credentialsManager.GetTaskCredentials(credentialsID)
 	if !ok {
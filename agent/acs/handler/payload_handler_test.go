@@ -79,7 +79,8 @@ func setup(t *testing.T) *testHelper {
 		stateManager,
 		refreshCredentialsHandler{},
 		credentialsManager,
-		taskHandler)
+		taskHandler,
+		NewAppliedPayloads())
 
 	return &testHelper{
 		ctrl:               ctrl,
@@ -782,3 +783,113 @@ func TestHandleUnrecognizedTask(t *testing.T) {
 	tester.payloadHandler.handleUnrecognizedTask(ecsacsTask, errors.New("test error"), payloadMessage)
 	wait.Wait()
 }
+
+// TestAckMessageIdRetriesFailedAcks tests that a failed ack is sent again
+func TestAckMessageIdRetriesFailedAcks(t *testing.T) {
+	tester := setup(t)
+	defer tester.ctrl.Finish()
+	defer tester.cancel()
+
+	gomock.InOrder(
+		tester.mockWsClient.EXPECT().MakeRequest(gomock.Any()).Return(errors.New("error")),
+		tester.mockWsClient.EXPECT().MakeRequest(gomock.Any()).Do(func(ackRequest *ecsacs.AckRequest) {
+			assert.Equal(t, payloadMessageId, aws.StringValue(ackRequest.MessageId))
+		}).Return(nil),
+	)
+
+	tester.payloadHandler.ackMessageId(payloadMessageId)
+}
+
+// TestAckMessageIdGivesUp tests that a payload is acked a limited number of times
+func TestAckMessageIdGivesUp(t *testing.T) {
+	tester := setup(t)
+	defer tester.ctrl.Finish()
+	defer tester.cancel()
+
+	tester.mockWsClient.EXPECT().MakeRequest(gomock.Any()).Return(errors.New("error")).Times(ackAttempts)
+
+	tester.payloadHandler.ackMessageId(payloadMessageId)
+}
+
+// TestHandlePayloadMessageRedelivered tests that a payload message which was
+// already applied is acked again without adding its tasks to the engine twice
+func TestHandlePayloadMessageRedelivered(t *testing.T) {
+	tester := setup(t)
+	defer tester.ctrl.Finish()
+
+	tester.mockTaskEngine.EXPECT().AddTask(gomock.Any()).Times(2)
+
+	payloadMessage := func(messageID string, seqNum int64) *ecsacs.PayloadMessage {
+		return &ecsacs.PayloadMessage{
+			Tasks: []*ecsacs.Task{
+				{
+					Arn: aws.String("t1"),
+				},
+			},
+			MessageId: aws.String(messageID),
+			SeqNum:    aws.Int64(seqNum),
+		}
+	}
+	for _, message := range []*ecsacs.PayloadMessage{
+		payloadMessage(payloadMessageId, 2),
+		payloadMessage(payloadMessageId, 2),
+		// Out of order messages are still applied
+		payloadMessage("456", 1),
+	} {
+		err := tester.payloadHandler.handleSingleMessage(message)
+		assert.NoError(t, err)
+		assert.Equal(t, aws.StringValue(message.MessageId), <-tester.payloadHandler.ackRequest)
+	}
+	assert.EqualValues(t, 2, tester.payloadHandler.appliedPayloads.getLastSeqNum())
+}
+
+// TestHandlePayloadMessageRedeliveredAcksCredentials tests that the
+// credentials of a payload message which was already applied, for example
+// before the agent restarted, are acked again with the payload
+func TestHandlePayloadMessageRedeliveredAcksCredentials(t *testing.T) {
+	tester := setup(t)
+	defer tester.ctrl.Finish()
+
+	var payloadAckRequested *ecsacs.AckRequest
+	var taskCredentialsAckRequested *ecsacs.IAMRoleCredentialsAckRequest
+	gomock.InOrder(
+		tester.mockWsClient.EXPECT().MakeRequest(gomock.Any()).Do(func(ackRequest *ecsacs.IAMRoleCredentialsAckRequest) {
+			taskCredentialsAckRequested = ackRequest
+		}),
+		tester.mockWsClient.EXPECT().MakeRequest(gomock.Any()).Do(func(ackRequest *ecsacs.AckRequest) {
+			payloadAckRequested = ackRequest
+			tester.cancel()
+		}),
+	)
+
+	refreshCredsHandler := newRefreshCredentialsHandler(tester.ctx, clusterName, containerInstanceArn, tester.mockWsClient, tester.credentialsManager, tester.mockTaskEngine)
+	defer refreshCredsHandler.clearAcks()
+	refreshCredsHandler.start()
+	tester.payloadHandler.refreshHandler = refreshCredsHandler
+	tester.payloadHandler.appliedPayloads.add(payloadMessageId, 1)
+
+	go tester.payloadHandler.start()
+
+	// The task engine is not expected to be invoked for an applied payload
+	err := tester.payloadHandler.handleSingleMessage(&ecsacs.PayloadMessage{
+		Tasks: []*ecsacs.Task{
+			{
+				Arn: aws.String("t1"),
+				RoleCredentials: &ecsacs.IAMRoleCredentials{
+					AccessKeyId:   aws.String("akid"),
+					Expiration:    aws.String("expiration"),
+					CredentialsId: aws.String("credsid"),
+				},
+			},
+		},
+		MessageId: aws.String(payloadMessageId),
+		SeqNum:    aws.Int64(1),
+	})
+	assert.NoError(t, err)
+
+	<-tester.ctx.Done()
+	assert.Equal(t, payloadMessageId, aws.StringValue(payloadAckRequested.MessageId))
+	assert.Equal(t, "credsid", aws.StringValue(taskCredentialsAckRequested.CredentialsId))
+	_, ok := tester.credentialsManager.GetTaskCredentials("credsid")
+	assert.True(t, ok, "credentials of the redelivered payload are not stored")
+}
@@ -91,13 +91,14 @@ func (refreshHandler *refreshCredentialsHandler) sendAcks() {
 	}
 }
 
-// ackMessageId sends an IAMRoleCredentialsAckRequest to the backend
+// ackMessage sends an IAMRoleCredentialsAckRequest to the backend. Failed acks
+// are retried with backoff
 func (refreshHandler *refreshCredentialsHandler) ackMessage(ack *ecsacs.IAMRoleCredentialsAckRequest) {
-	err := refreshHandler.acsClient.MakeRequest(ack)
+	seelog.Debugf("Acking credentials message: %s", ack.String())
+	err := sendAck(refreshHandler.ctx, refreshHandler.acsClient, ack, aws.StringValue(ack.MessageId))
 	if err != nil {
-		seelog.Warnf("Error 'ack'ing request with messageID: %s, error: %v", aws.StringValue(ack.MessageId), err)
+		seelog.Errorf("Unable to ack credentials message id %s after %d attempts", aws.StringValue(ack.MessageId), ackAttempts)
 	}
-	seelog.Debugf("Acking credentials message: %s", ack.String())
 }
 
 // handleMessages processes refresh credentials messages in the buffer in-order
@@ -15,6 +15,7 @@
 package handler
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -316,3 +317,21 @@ func TestRefreshCredentialsHandler(t *testing.T) {
 		t.Errorf("Mismatch between expected credentials and credentials for task. Expected: %v, got: %v", expectedCredentials, creds)
 	}
 }
+
+// TestRefreshCredentialsAckRetried tests that a failed credentials ack is sent
+// again
+func TestRefreshCredentialsAckRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockWsClient := mock_wsclient.NewMockClientServer(ctrl)
+	gomock.InOrder(
+		mockWsClient.EXPECT().MakeRequest(expectedAck).Return(errors.New("error")),
+		mockWsClient.EXPECT().MakeRequest(expectedAck).Return(nil),
+	)
+
+	handler := newRefreshCredentialsHandler(ctx, clusterName, containerInstanceArn, mockWsClient, nil, nil)
+	handler.ackMessage(expectedAck)
+}
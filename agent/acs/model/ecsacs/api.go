@@ -206,22 +206,34 @@ type Container struct {
 
 	Cpu *int64 `locationName:"cpu" type:"integer"`
 
+	DependsOn []*ContainerDependency `locationName:"dependsOn" type:"list"`
+
 	DockerConfig *DockerConfig `locationName:"dockerConfig" type:"structure"`
 
 	EntryPoint []*string `locationName:"entryPoint" type:"list"`
 
 	Environment map[string]*string `locationName:"environment" type:"map"`
 
+	EnvironmentFiles []*EnvironmentFile `locationName:"environmentFiles" type:"list"`
+
 	Essential *bool `locationName:"essential" type:"boolean"`
 
+	FirelensConfiguration *FirelensConfiguration `locationName:"firelensConfiguration" type:"structure"`
+
 	HealthCheckType *string `locationName:"healthCheckType" type:"string" enum:"HealthCheckType"`
 
 	Image *string `locationName:"image" type:"string"`
 
+	Interactive *bool `locationName:"interactive" type:"boolean"`
+
 	Links []*string `locationName:"links" type:"list"`
 
+	LinuxParameters *LinuxParameters `locationName:"linuxParameters" type:"structure"`
+
 	LogsAuthStrategy *string `locationName:"logsAuthStrategy" type:"string" enum:"AuthStrategy"`
 
+	ManagedAgents []*ManagedAgent `locationName:"managedAgents" type:"list"`
+
 	Memory *int64 `locationName:"memory" type:"integer"`
 
 	MountPoints []*MountPoint `locationName:"mountPoints" type:"list"`
@@ -232,10 +244,16 @@ type Container struct {
 
 	PortMappings []*PortMapping `locationName:"portMappings" type:"list"`
 
+	PseudoTerminal *bool `locationName:"pseudoTerminal" type:"boolean"`
+
 	RegistryAuthentication *RegistryAuthenticationData `locationName:"registryAuthentication" type:"structure"`
 
 	Secrets []*Secret `locationName:"secrets" type:"list"`
 
+	StopTimeout *int64 `locationName:"stopTimeout" type:"integer"`
+
+	SystemControls []*SystemControl `locationName:"systemControls" type:"list"`
+
 	VolumesFrom []*VolumeFrom `locationName:"volumesFrom" type:"list"`
 }
 
@@ -249,6 +267,44 @@ func (s Container) GoString() string {
 	return s.String()
 }
 
+type ContainerDependency struct {
+	_ struct{} `type:"structure"`
+
+	Condition *string `locationName:"condition" type:"string" enum:"ContainerCondition"`
+
+	ContainerName *string `locationName:"containerName" type:"string"`
+}
+
+// String returns the string representation
+func (s ContainerDependency) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ContainerDependency) GoString() string {
+	return s.String()
+}
+
+type Device struct {
+	_ struct{} `type:"structure"`
+
+	ContainerPath *string `locationName:"containerPath" type:"string"`
+
+	HostPath *string `locationName:"hostPath" type:"string"`
+
+	Permissions []*string `locationName:"permissions" type:"list"`
+}
+
+// String returns the string representation
+func (s Device) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Device) GoString() string {
+	return s.String()
+}
+
 type DockerConfig struct {
 	_ struct{} `type:"structure"`
 
@@ -315,6 +371,48 @@ func (s ECRAuthData) GoString() string {
 	return s.String()
 }
 
+type EFSAuthorizationConfig struct {
+	_ struct{} `type:"structure"`
+
+	AccessPointId *string `locationName:"accessPointId" type:"string"`
+
+	Iam *string `locationName:"iam" type:"string" enum:"EFSAuthorizationConfigIAM"`
+}
+
+// String returns the string representation
+func (s EFSAuthorizationConfig) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EFSAuthorizationConfig) GoString() string {
+	return s.String()
+}
+
+type EFSVolumeConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	AuthorizationConfig *EFSAuthorizationConfig `locationName:"authorizationConfig" type:"structure"`
+
+	FileSystemId *string `locationName:"fileSystemId" type:"string"`
+
+	RootDirectory *string `locationName:"rootDirectory" type:"string"`
+
+	TransitEncryption *string `locationName:"transitEncryption" type:"string" enum:"EFSTransitEncryption"`
+
+	TransitEncryptionPort *int64 `locationName:"transitEncryptionPort" type:"integer"`
+}
+
+// String returns the string representation
+func (s EFSVolumeConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EFSVolumeConfiguration) GoString() string {
+	return s.String()
+}
+
 type ElasticNetworkInterface struct {
 	_ struct{} `type:"structure"`
 
@@ -365,6 +463,24 @@ func (s EncodedString) GoString() string {
 	return s.String()
 }
 
+type EnvironmentFile struct {
+	_ struct{} `type:"structure"`
+
+	Type *string `locationName:"type" type:"string" enum:"EnvironmentFileType"`
+
+	Value *string `locationName:"value" type:"string"`
+}
+
+// String returns the string representation
+func (s EnvironmentFile) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EnvironmentFile) GoString() string {
+	return s.String()
+}
+
 type ErrorInput struct {
 	_ struct{} `type:"structure"`
 
@@ -411,6 +527,24 @@ func (s ErrorOutput) GoString() string {
 	return s.String()
 }
 
+type FirelensConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	Options map[string]*string `locationName:"options" type:"map"`
+
+	Type *string `locationName:"type" type:"string" enum:"FirelensConfigurationType"`
+}
+
+// String returns the string representation
+func (s FirelensConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s FirelensConfiguration) GoString() string {
+	return s.String()
+}
+
 type HeartbeatInput struct {
 	_ struct{} `type:"structure"`
 
@@ -623,6 +757,68 @@ func (s InvalidInstanceException) GoString() string {
 	return s.String()
 }
 
+type KernelCapabilities struct {
+	_ struct{} `type:"structure"`
+
+	Add []*string `locationName:"add" type:"list"`
+
+	Drop []*string `locationName:"drop" type:"list"`
+}
+
+// String returns the string representation
+func (s KernelCapabilities) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s KernelCapabilities) GoString() string {
+	return s.String()
+}
+
+type LinuxParameters struct {
+	_ struct{} `type:"structure"`
+
+	Capabilities *KernelCapabilities `locationName:"capabilities" type:"structure"`
+
+	Devices []*Device `locationName:"devices" type:"list"`
+
+	InitProcessEnabled *bool `locationName:"initProcessEnabled" type:"boolean"`
+
+	MaxSwap *int64 `locationName:"maxSwap" type:"integer"`
+
+	SharedMemorySize *int64 `locationName:"sharedMemorySize" type:"integer"`
+
+	Swappiness *int64 `locationName:"swappiness" type:"integer"`
+
+	Tmpfs []*Tmpfs `locationName:"tmpfs" type:"list"`
+}
+
+// String returns the string representation
+func (s LinuxParameters) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s LinuxParameters) GoString() string {
+	return s.String()
+}
+
+type ManagedAgent struct {
+	_ struct{} `type:"structure"`
+
+	Name *string `locationName:"name" type:"string"`
+}
+
+// String returns the string representation
+func (s ManagedAgent) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ManagedAgent) GoString() string {
+	return s.String()
+}
+
 type MountPoint struct {
 	_ struct{} `type:"structure"`
 
@@ -1053,6 +1249,24 @@ func (s StageUpdateOutput) GoString() string {
 	return s.String()
 }
 
+type SystemControl struct {
+	_ struct{} `type:"structure"`
+
+	Namespace *string `locationName:"namespace" type:"string"`
+
+	Value *string `locationName:"value" type:"string"`
+}
+
+// String returns the string representation
+func (s SystemControl) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s SystemControl) GoString() string {
+	return s.String()
+}
+
 type Task struct {
 	_ struct{} `type:"structure"`
 
@@ -1099,6 +1313,26 @@ func (s Task) GoString() string {
 	return s.String()
 }
 
+type Tmpfs struct {
+	_ struct{} `type:"structure"`
+
+	ContainerPath *string `locationName:"containerPath" type:"string"`
+
+	MountOptions []*string `locationName:"mountOptions" type:"list"`
+
+	Size *int64 `locationName:"size" type:"integer"`
+}
+
+// String returns the string representation
+func (s Tmpfs) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Tmpfs) GoString() string {
+	return s.String()
+}
+
 type UpdateFailureInput struct {
 	_ struct{} `type:"structure"`
 
@@ -1178,6 +1412,8 @@ type Volume struct {
 
 	DockerVolumeConfiguration *DockerVolumeConfiguration `locationName:"dockerVolumeConfiguration" type:"structure"`
 
+	EfsVolumeConfiguration *EFSVolumeConfiguration `locationName:"efsVolumeConfiguration" type:"structure"`
+
 	Host *HostVolumeProperties `locationName:"host" type:"structure"`
 
 	Name *string `locationName:"name" type:"string"`
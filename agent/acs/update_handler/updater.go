@@ -179,6 +179,9 @@ func (u *updater) download(info *ecsacs.UpdateInfo) (err error) {
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Unexpected response status: %s", resp.Status)
+	}
 
 	outFileBasename := utils.RandHex() + ".ecs-update.tar"
 	outFilePath := filepath.Join(u.config.UpdateDownloadDir, outFileBasename)
@@ -479,3 +479,31 @@ func TestValidationError(t *testing.T) {
 
 	assert.Equal(t, "update-tar-data", writtenFile.String(), "incorrect data written")
 }
+
+func TestDownloadErrorStatus(t *testing.T) {
+	u, ctrl, _, _, mockacs, mockhttp := mocks(t, nil)
+	defer ctrl.Finish()
+
+	response := mock_http.SuccessResponse("AccessDenied")
+	response.Status = "403 Forbidden"
+	response.StatusCode = 403
+	gomock.InOrder(
+		mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")).Return(response, nil),
+		mockacs.EXPECT().MakeRequest(&nackRequestMatcher{&ecsacs.NackRequest{
+			Cluster:           ptr("cluster").(*string),
+			ContainerInstance: ptr("containerInstance").(*string),
+			MessageId:         ptr("StageMID").(*string),
+		}}),
+	)
+
+	u.stageUpdateHandler()(&ecsacs.StageUpdateMessage{
+		ClusterArn:           ptr("cluster").(*string),
+		ContainerInstanceArn: ptr("containerInstance").(*string),
+		MessageId:            ptr("StageMID").(*string),
+		UpdateInfo: &ecsacs.UpdateInfo{
+			Location:  ptr("https://s3.amazonaws.com/amazon-ecs-agent/update.tar").(*string),
+			Signature: ptr("6caeef375a080e3241781725b357890758d94b15d7ce63f6b2ff1cb5589f2007").(*string),
+		},
+	})
+	assert.Equal(t, updateNone, u.stage, "expected the update to be reset")
+}
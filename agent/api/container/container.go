@@ -14,6 +14,7 @@
 package container
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"sync"
@@ -26,6 +27,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 
 	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
 )
 
 const (
@@ -61,8 +63,32 @@ const (
 	// SecretProviderASM is to show secret provider being ASM
 	SecretProviderASM = "asm"
 
+	// EnvironmentFileTypeS3 is the type of the environment files stored in S3
+	EnvironmentFileTypeS3 = "s3"
+
 	// SecretTypeEnv is to show secret type being ENVIRONMENT_VARIABLE
 	SecretTypeEnv = "ENVIRONMENT_VARIABLE"
+
+	// DependsOnConditionStart is satisfied once the dependency container has
+	// started
+	DependsOnConditionStart = "START"
+	// DependsOnConditionComplete is satisfied once the dependency container has
+	// exited, whatever its exit code
+	DependsOnConditionComplete = "COMPLETE"
+	// DependsOnConditionSuccess is satisfied once the dependency container has
+	// exited with a zero exit code
+	DependsOnConditionSuccess = "SUCCESS"
+	// DependsOnConditionHealthy is satisfied once the docker health check of the
+	// dependency container has passed
+	DependsOnConditionHealthy = "HEALTHY"
+
+	// FirelensConfigTypeFluentd is the FireLens log router type of fluentd
+	FirelensConfigTypeFluentd = "fluentd"
+	// FirelensConfigTypeFluentbit is the FireLens log router type of fluent bit
+	FirelensConfigTypeFluentbit = "fluentbit"
+	// FirelensLogDriver is the log driver of containers whose logs are routed
+	// through the FireLens log router of their task
+	FirelensLogDriver = "awsfirelens"
 )
 
 // DockerConfig represents additional metadata about a container to run. It's
@@ -100,6 +126,10 @@ type Container struct {
 	Image string
 	// ImageID is the local ID of the image used in the container
 	ImageID string
+	// ImageDigestUnsafe is the digest the image resolved to when the task
+	// first pulled it. Every container of the task using the same image is
+	// created from this digest, even if the image's tag is moved later
+	ImageDigestUnsafe string `json:"ImageDigest,omitempty"`
 	// Command is the command to run in the container which is specified in the task definition
 	Command []string
 	// CPU is the cpu limitation of the container which is specified in the task definition
@@ -110,6 +140,9 @@ type Container struct {
 	Memory uint
 	// Links contains a list of containers to link, corresponding to docker option: --link
 	Links []string
+	// LinuxParameters are the Linux specific options of the container, such as
+	// kernel capabilities, devices and tmpfs mounts
+	LinuxParameters *LinuxParameters `json:"linuxParameters,omitempty"`
 	// VolumesFrom contains a list of container's volume to use, corresponding to docker option: --volumes-from
 	VolumesFrom []VolumeFrom `json:"volumesFrom"`
 	// MountPoints contains a list of volume mount paths
@@ -118,12 +151,38 @@ type Container struct {
 	Ports []PortBinding `json:"portMappings"`
 	// Secrets contains a list of secret
 	Secrets []Secret `json:"secrets"`
+	// DependsOn contains the containers of the task this container waits for,
+	// and the conditions they have to meet, before it is created and started
+	DependsOn []DependsOn `json:"dependsOn,omitempty"`
+	// StopTimeout is the number of seconds to wait for the container to exit
+	// on its own after being asked to stop, before it is killed. If it is not
+	// set, the agent's ECS_CONTAINER_STOP_TIMEOUT is used instead
+	StopTimeout uint `json:"stopTimeout,omitempty"`
+	// FirelensConfig marks the container as the FireLens log router of the task
+	// and holds its configuration
+	FirelensConfig *FirelensConfig `json:"firelensConfiguration,omitempty"`
+	// ManagedAgentsUnsafe are the agents run by the ECS agent inside the
+	// container, such as the agent serving ECS Exec sessions, along with
+	// their state
+	ManagedAgentsUnsafe []ManagedAgent `json:"managedAgents,omitempty"`
 	// Essential denotes whether the container is essential or not
 	Essential bool
 	// EntryPoint is entrypoint of the container, corresponding to docker option: --entrypoint
 	EntryPoint *[]string
+	// PseudoTerminal allocates a TTY for the container, corresponding to
+	// docker option: --tty
+	PseudoTerminal bool `json:"pseudoTerminal,omitempty"`
+	// Interactive keeps the stdin of the container open, corresponding to
+	// docker option: --interactive
+	Interactive bool `json:"interactive,omitempty"`
+	// SystemControls are the namespaced kernel parameters set in the
+	// container, corresponding to docker option: --sysctl
+	SystemControls []SystemControl `json:"systemControls,omitempty"`
 	// Environment is the environment variable set in the container
 	Environment map[string]string `json:"environment"`
+	// EnvironmentFiles are the files holding environment variables of the
+	// container, which are added to its environment when it is created
+	EnvironmentFiles []EnvironmentFile `json:"environmentFiles,omitempty"`
 	// Overrides contains the configuration to override of a container
 	Overrides ContainerOverrides `json:"overrides"`
 	// DockerConfig is the configuration used to create the container
@@ -228,6 +287,16 @@ type Container struct {
 	finishedAt time.Time
 
 	labels map[string]string
+
+	// secretEnvironmentUnsafe holds the values of the secrets injected into
+	// the container as environment variables. It is kept apart from
+	// Environment so that the values are never written to the state file
+	secretEnvironmentUnsafe map[string]string
+
+	// envFileEnvironmentUnsafe holds the variables read from the environment
+	// files of the container. It is kept apart from Environment so that the
+	// values are never written to the state file
+	envFileEnvironmentUnsafe map[string]string
 }
 
 // DockerContainer is a mapping between containers-as-docker-knows-them and
@@ -255,6 +324,33 @@ type VolumeFrom struct {
 	ReadOnly        bool   `json:"readOnly"`
 }
 
+// DependsOn is a dependency of a container on the condition of another
+// container of the task.
+type DependsOn struct {
+	ContainerName string `json:"containerName"`
+	Condition     string `json:"condition"`
+}
+
+// FirelensConfig is the configuration of the FireLens log router of a task.
+type FirelensConfig struct {
+	Type    string            `json:"type"`
+	Options map[string]string `json:"options"`
+}
+
+// EnvironmentFile is a file of environment variables of a container. Value is
+// the ARN of the file
+type EnvironmentFile struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// SystemControl is a namespaced kernel parameter, such as
+// "net.core.somaxconn", set in a container
+type SystemControl struct {
+	Namespace string `json:"namespace"`
+	Value     string `json:"value"`
+}
+
 // Secret contains all essential attributes needed for ECS secrets vending as environment variables/tmpfs files
 type Secret struct {
 	Name          string `json:"name"`
@@ -553,6 +649,32 @@ func (c *Container) SetLabels(labels map[string]string) {
 	c.labels = labels
 }
 
+// GetStopTimeout returns the time to wait for the container to exit after
+// being asked to stop, or 0 if the container does not set it
+func (c *Container) GetStopTimeout() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return time.Duration(c.StopTimeout) * time.Second
+}
+
+// SetImageDigest sets the digest of the image the container is created from
+func (c *Container) SetImageDigest(imageDigest string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ImageDigestUnsafe = imageDigest
+}
+
+// GetImageDigest returns the digest of the image the container is created
+// from, or an empty string if it has not been resolved
+func (c *Container) GetImageDigest() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ImageDigestUnsafe
+}
+
 // GetLabels gets the labels for a container
 func (c *Container) GetLabels() map[string]string {
 	c.lock.RLock()
@@ -754,6 +876,21 @@ func (c *Container) InjectV3MetadataEndpoint() {
 		fmt.Sprintf(MetadataURIFormat, c.V3EndpointID)
 }
 
+// GetLogConfig returns the log configuration set in the docker host config of
+// the container
+func (c *Container) GetLogConfig() (dockercontainer.LogConfig, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	hostConfig := &dockercontainer.HostConfig{}
+	if c.DockerConfig.HostConfig != nil {
+		if err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), hostConfig); err != nil {
+			return dockercontainer.LogConfig{}, err
+		}
+	}
+	return hostConfig.LogConfig, nil
+}
+
 // ShouldCreateWithSSMSecret returns true if this container needs to get secret
 // value from SSM Parameter Store
 func (c *Container) ShouldCreateWithSSMSecret() bool {
@@ -807,6 +944,60 @@ func (c *Container) MergeEnvironmentVariables(envVars map[string]string) {
 	}
 }
 
+// ShouldCreateWithEnvFiles returns true if the container has environment
+// files whose variables are added to its environment
+func (c *Container) ShouldCreateWithEnvFiles() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return len(c.EnvironmentFiles) > 0
+}
+
+// SetEnvFileEnvironment sets the variables read from the container's
+// environment files, in the order of the files. A file takes precedence over
+// the files after it
+func (c *Container) SetEnvFileEnvironment(envVarsList []map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	envVars := make(map[string]string)
+	for _, fileEnvVars := range envVarsList {
+		for k, v := range fileEnvVars {
+			if _, ok := envVars[k]; !ok {
+				envVars[k] = v
+			}
+		}
+	}
+	c.envFileEnvironmentUnsafe = envVars
+}
+
+// GetEnvFileEnvironment returns the variables read from the container's
+// environment files
+func (c *Container) GetEnvFileEnvironment() map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.envFileEnvironmentUnsafe
+}
+
+// SetSecretEnvironment sets the environment variables that hold the values of
+// the container's secrets
+func (c *Container) SetSecretEnvironment(envVars map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.secretEnvironmentUnsafe = envVars
+}
+
+// GetSecretEnvironment returns the environment variables that hold the values
+// of the container's secrets
+func (c *Container) GetSecretEnvironment() map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.secretEnvironmentUnsafe
+}
+
 func (c *Container) HasSecretAsEnv() bool {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -327,7 +327,7 @@ func TestMergeEnvironmentVariables(t *testing.T) {
 		},
 
 		{
-			Name: "merge single item to nil container env var map",
+			Name:                   "merge single item to nil container env var map",
 			InContainerEnvironment: nil,
 			InEnvVarMap: map[string]string{
 				"SECRET1": "secret1"},
@@ -357,7 +357,7 @@ func TestMergeEnvironmentVariables(t *testing.T) {
 		},
 
 		{
-			Name: "merge nil to nil container env var map",
+			Name:                   "merge nil to nil container env var map",
 			InContainerEnvironment: nil,
 			InEnvVarMap:            nil,
 			OutEnvVarMap:           map[string]string{},
@@ -377,6 +377,25 @@ func TestMergeEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestSetEnvFileEnvironment(t *testing.T) {
+	container := Container{
+		Environment: map[string]string{"CONFIG1": "config1"},
+	}
+
+	container.SetEnvFileEnvironment([]map[string]string{
+		{"CONFIG1": "file1", "CONFIG2": "file1"},
+		{"CONFIG2": "file2", "CONFIG3": "file2"},
+	})
+	// a file takes precedence over the files after it
+	assert.Equal(t, map[string]string{
+		"CONFIG1": "file1",
+		"CONFIG2": "file1",
+		"CONFIG3": "file2",
+	}, container.GetEnvFileEnvironment())
+	// the values are kept out of the saved environment
+	assert.Equal(t, map[string]string{"CONFIG1": "config1"}, container.Environment)
+}
+
 func TestShouldCreateWithASMSecret(t *testing.T) {
 	cases := []struct {
 		in  Container
@@ -0,0 +1,59 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+// LinuxParameters are the Linux specific options of a container, they are
+// applied to its host config when the container is created
+type LinuxParameters struct {
+	// Capabilities are the kernel capabilities added to and dropped from the
+	// default set docker gives the container
+	Capabilities *KernelCapabilities `json:"capabilities,omitempty"`
+	// Devices are the host devices exposed to the container
+	Devices []Device `json:"devices,omitempty"`
+	// InitProcessEnabled runs an init process as PID 1 of the container, which
+	// forwards signals and reaps zombie processes
+	InitProcessEnabled *bool `json:"initProcessEnabled,omitempty"`
+	// MaxSwap is the swap memory in MiB the container may use on top of its
+	// memory limit. A value of 0 keeps the container from swapping
+	MaxSwap *int64 `json:"maxSwap,omitempty"`
+	// SharedMemorySize is the size of /dev/shm in MiB
+	SharedMemorySize *int64 `json:"sharedMemorySize,omitempty"`
+	// Swappiness tunes how likely the container's pages are to be swapped
+	// out, between 0 and 100
+	Swappiness *int64 `json:"swappiness,omitempty"`
+	// Tmpfs are the tmpfs mounts of the container
+	Tmpfs []Tmpfs `json:"tmpfs,omitempty"`
+}
+
+// KernelCapabilities are the kernel capabilities, such as "SYS_ADMIN", added
+// to and dropped from a container
+type KernelCapabilities struct {
+	Add  []string `json:"add,omitempty"`
+	Drop []string `json:"drop,omitempty"`
+}
+
+// Device is a host device exposed to a container. Permissions are any of
+// "read", "write" and "mknod", all of them are granted when none is given
+type Device struct {
+	HostPath      string   `json:"hostPath"`
+	ContainerPath string   `json:"containerPath,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+}
+
+// Tmpfs is a tmpfs mount of a container. Size is in MiB
+type Tmpfs struct {
+	ContainerPath string   `json:"containerPath"`
+	Size          int64    `json:"size"`
+	MountOptions  []string `json:"mountOptions,omitempty"`
+}
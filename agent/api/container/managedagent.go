@@ -0,0 +1,120 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"time"
+
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+)
+
+const (
+	// ExecuteCommandAgentName is the name of the managed agent serving the
+	// `aws ecs execute-command` sessions of a container
+	ExecuteCommandAgentName = "ExecuteCommandAgent"
+)
+
+// ManagedAgent is an agent the ECS agent runs and supervises inside a
+// container
+type ManagedAgent struct {
+	Name string `json:"name"`
+	ManagedAgentState
+}
+
+// ManagedAgentState is the state of a managed agent in its container
+type ManagedAgentState struct {
+	// ID is the id of the docker exec process the agent runs in
+	ID string `json:"id,omitempty"`
+	// Status is the last known status of the agent, RUNNING or STOPPED
+	Status apicontainerstatus.ContainerStatus `json:"status,omitempty"`
+	// SentStatus is the status of the agent last reported to ECS
+	SentStatus apicontainerstatus.ContainerStatus `json:"sentStatus,omitempty"`
+	// Reason explains why the agent failed to start or stopped
+	Reason string `json:"reason,omitempty"`
+	// LastStartedAt is when the agent was last started in the container
+	LastStartedAt time.Time `json:"lastStartedAt,omitempty"`
+	// InitFailed is set when the agent could not be set up in the container,
+	// in which case it is never started
+	InitFailed bool `json:"initFailed,omitempty"`
+	// Metadata holds the settings of the agent in the container, which have to
+	// stay the same when the agent is restarted
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// GetManagedAgents returns the managed agents of the container
+func (c *Container) GetManagedAgents() []ManagedAgent {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	managedAgents := make([]ManagedAgent, len(c.ManagedAgentsUnsafe))
+	copy(managedAgents, c.ManagedAgentsUnsafe)
+	return managedAgents
+}
+
+// GetManagedAgentByName returns the managed agent of the container with the
+// given name, and whether the container runs it
+func (c *Container) GetManagedAgentByName(name string) (ManagedAgent, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, managedAgent := range c.ManagedAgentsUnsafe {
+		if managedAgent.Name == name {
+			return managedAgent, true
+		}
+	}
+	return ManagedAgent{}, false
+}
+
+// UpdateManagedAgentByName sets the state of the managed agent of the
+// container with the given name. It returns false if the container does not
+// run the agent
+func (c *Container) UpdateManagedAgentByName(name string, state ManagedAgentState) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, managedAgent := range c.ManagedAgentsUnsafe {
+		if managedAgent.Name == name {
+			c.ManagedAgentsUnsafe[i].ManagedAgentState = state
+			return true
+		}
+	}
+	return false
+}
+
+// SetManagedAgentsStopped marks the running managed agents of the container
+// as stopped, once the container itself has stopped
+func (c *Container) SetManagedAgentsStopped() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, managedAgent := range c.ManagedAgentsUnsafe {
+		if managedAgent.Status == apicontainerstatus.ContainerRunning {
+			c.ManagedAgentsUnsafe[i].Status = apicontainerstatus.ContainerStopped
+			c.ManagedAgentsUnsafe[i].Reason = "container stopped"
+		}
+	}
+}
+
+// UpdateManagedAgentSentStatus records the status of the managed agent of the
+// container with the given name last reported to ECS
+func (c *Container) UpdateManagedAgentSentStatus(name string, status apicontainerstatus.ContainerStatus) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, managedAgent := range c.ManagedAgentsUnsafe {
+		if managedAgent.Name == name {
+			c.ManagedAgentsUnsafe[i].SentStatus = status
+		}
+	}
+}
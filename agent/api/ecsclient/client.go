@@ -18,9 +18,11 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/async"
@@ -29,9 +31,12 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/httpclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/cihub/seelog"
 	"github.com/docker/docker/pkg/system"
 )
@@ -42,8 +47,31 @@ const (
 	pollEndpointCacheTTL  = 20 * time.Minute
 	roundtripTimeout      = 5 * time.Second
 	azAttrName            = "ecs.availability-zone"
+
+	createClusterMinBackoff        = 1 * time.Second
+	createClusterMaxBackoff        = 30 * time.Second
+	createClusterBackoffJitter     = 0.2
+	createClusterBackoffMultiplier = 2
+	iidSignatureErrorMessage       = "signature"
+	pullErrorReasonFormat          = "[%s] %s"
+
+	// Exit codes inferred for stopped containers without one, for a container
+	// that stopped because of an error and for one that was otherwise killed
+	inferredErrorExitCode  = 1
+	inferredKilledExitCode = 137
+	exitCodeUnknownReason  = "[ExitCodeUnknown]"
+
+	dockerVersionPrefix = "DockerVersion: "
 )
 
+// iidSignatureResources lists the instance identity document signature formats,
+// in the order they are tried when the backend rejects a signature
+var iidSignatureResources = []string{
+	ec2.InstanceIdentityDocumentSignatureResource,
+	ec2.InstanceIdentityDocumentRSA2048Resource,
+	ec2.InstanceIdentityDocumentPKCS7Resource,
+}
+
 // APIECSClient implements ECSClient
 type APIECSClient struct {
 	credentialProvider      *credentials.Credentials
@@ -52,24 +80,54 @@ type APIECSClient struct {
 	submitStateChangeClient api.ECSSubmitStateSDK
 	ec2metadata             ec2.EC2MetadataClient
 	pollEndpoinCache        async.Cache
+	// newCreateClusterBackoff returns the backoff of a CreateCluster call,
+	// each call starts with a new backoff
+	newCreateClusterBackoff func() retry.Backoff
+	// iidSignatureResource is the signature format that was last accepted
+	// by the backend, it is tried first on subsequent registrations
+	iidSignatureResource string
+
+	// lastKnownPollEndpoints holds the last successful DiscoverPollEndpoint
+	// responses, which are used when discovery is throttled
+	lastKnownPollEndpoints map[string]*ecs.DiscoverPollEndpointOutput
+	// pollEndpointIdentities holds the cluster and credentials each poll
+	// endpoint was discovered with, the endpoint is discovered again when
+	// either changes
+	pollEndpointIdentities map[string]string
+	pollEndpointLock       sync.RWMutex
+	// dockerVersion resolves the version of the docker daemon, which is
+	// reported with the agent version on registration
+	dockerVersion func() (string, error)
 }
 
-// NewECSClient creates a new ECSClient interface object
+// NewECSClient creates a new ECSClient interface object. The docker version
+// resolver may be nil, in which case no docker version is reported
 func NewECSClient(
 	credentialProvider *credentials.Credentials,
 	config *config.Config,
-	ec2MetadataClient ec2.EC2MetadataClient) api.ECSClient {
+	ec2MetadataClient ec2.EC2MetadataClient,
+	dockerVersion func() (string, error)) api.ECSClient {
 
 	var ecsConfig aws.Config
 	ecsConfig.Credentials = credentialProvider
 	ecsConfig.Region = &config.AWSRegion
-	ecsConfig.HTTPClient = httpclient.New(roundtripTimeout, config.AcceptInsecureCert)
-	if config.APIEndpoint != "" {
-		ecsConfig.Endpoint = &config.APIEndpoint
+	// The HTTP client is shared by the standard and the submit state change
+	// clients, so that all the API calls reuse the same pool of connections
+	ecsConfig.HTTPClient = httpclient.NewWithIdleConnPool(roundtripTimeout, config.AcceptInsecureCert,
+		config.ECSClientMaxIdleConns, config.ECSClientIdleConnTimeout)
+	if err := httpclient.SetTLSTrust(ecsConfig.HTTPClient, config.CABundlePath, config.PinnedPublicKeys); err != nil {
+		seelog.Criticalf("Unable to configure the trusted certificates of the ECS client: %v", err)
 	}
-	standardClient := ecs.New(session.New(&ecsConfig))
+	if endpoint := config.ECSEndpoint(); endpoint != "" {
+		ecsConfig.Endpoint = &endpoint
+	}
+	standardClient := newStandardClient(&ecsConfig)
 	submitStateChangeClient := newSubmitStateChangeClient(&ecsConfig)
-	pollEndpoinCache := async.NewLRUCache(pollEndpointCacheSize, pollEndpointCacheTTL)
+	pollEndpointCacheMaxAge := config.PollEndpointCacheMaxAge
+	if pollEndpointCacheMaxAge == 0 {
+		pollEndpointCacheMaxAge = pollEndpointCacheTTL
+	}
+	pollEndpoinCache := async.NewLRUCache(pollEndpointCacheSize, pollEndpointCacheMaxAge)
 	return &APIECSClient{
 		credentialProvider:      credentialProvider,
 		config:                  config,
@@ -77,6 +135,12 @@ func NewECSClient(
 		submitStateChangeClient: submitStateChangeClient,
 		ec2metadata:             ec2MetadataClient,
 		pollEndpoinCache:        pollEndpoinCache,
+		newCreateClusterBackoff: func() retry.Backoff {
+			return retry.NewExponentialBackoff(createClusterMinBackoff, createClusterMaxBackoff,
+				createClusterBackoffJitter, createClusterBackoffMultiplier)
+		},
+		iidSignatureResource: ec2.InstanceIdentityDocumentSignatureResource,
+		dockerVersion:        dockerVersion,
 	}
 }
 
@@ -92,15 +156,76 @@ func (client *APIECSClient) SetSubmitStateChangeSDK(sdk api.ECSSubmitStateSDK) {
 	client.submitStateChangeClient = sdk
 }
 
-// CreateCluster creates a cluster from a given name and returns its arn
+// CreateCluster creates a cluster from a given name and returns its name.
+// Throttled and retriable errors are retried with backoff. If the cluster is
+// being updated concurrently by another instance, the existing cluster is
+// looked up instead.
 func (client *APIECSClient) CreateCluster(clusterName string) (string, error) {
-	resp, err := client.standardClient.CreateCluster(&ecs.CreateClusterInput{ClusterName: &clusterName})
+	backoff := client.newCreateClusterBackoff()
+	var resp *ecs.CreateClusterOutput
+	// createErr is the error of the last attempt, as returned by the SDK
+	var createErr error
+	err := retry.RetryNWithBackoff(backoff, client.config.CreateClusterAttempts, func() error {
+		resp, createErr = client.standardClient.CreateCluster(&ecs.CreateClusterInput{ClusterName: &clusterName})
+		return createClusterRetriableError("creating", clusterName, createErr)
+	})
 	if err != nil {
+		err = createErr
+		if isClusterConflictError(err) {
+			seelog.Infof("Cluster %s is being updated concurrently, looking up the existing cluster: %v", clusterName, err)
+			return client.describeCluster(clusterName, backoff)
+		}
 		seelog.Criticalf("Could not create cluster: %v", err)
 		return "", err
 	}
 	seelog.Infof("Created a cluster named: %s", clusterName)
-	return *resp.Cluster.ClusterName, nil
+	return aws.StringValue(resp.Cluster.ClusterName), nil
+}
+
+// describeCluster returns the name of an existing active cluster
+func (client *APIECSClient) describeCluster(clusterName string, backoff retry.Backoff) (string, error) {
+	backoff.Reset()
+	var resp *ecs.DescribeClustersOutput
+	var describeErr error
+	err := retry.RetryNWithBackoff(backoff, client.config.CreateClusterAttempts, func() error {
+		resp, describeErr = client.standardClient.DescribeClusters(&ecs.DescribeClustersInput{
+			Clusters: []*string{aws.String(clusterName)},
+		})
+		return createClusterRetriableError("describing", clusterName, describeErr)
+	})
+	if err != nil {
+		seelog.Criticalf("Could not describe cluster %s: %v", clusterName, describeErr)
+		return "", describeErr
+	}
+	for _, cluster := range resp.Clusters {
+		if aws.StringValue(cluster.ClusterName) == clusterName && aws.StringValue(cluster.Status) == "ACTIVE" {
+			seelog.Infof("Found existing cluster named: %s", clusterName)
+			return clusterName, nil
+		}
+	}
+	return "", fmt.Errorf("cluster %s was not found after conflicting create", clusterName)
+}
+
+// createClusterRetriableError marks throttling and retriable errors of the
+// calls made to create the default cluster as retriable
+func createClusterRetriableError(action string, clusterName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	retriable := request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
+	if retriable {
+		seelog.Warnf("Retriable error %s cluster %s: %v", action, clusterName, err)
+	}
+	return apierrors.NewRetriableError(apierrors.NewRetriable(retriable), err)
+}
+
+// isClusterConflictError returns true if CreateCluster failed because the
+// cluster is being updated concurrently
+func isClusterConflictError(err error) bool {
+	if awserr, ok := err.(awserr.Error); ok {
+		return awserr.Code() == ecs.ErrCodeUpdateInProgressException
+	}
+	return false
 }
 
 // RegisterContainerInstance calculates the appropriate resources, creates
@@ -165,17 +290,11 @@ func (client *APIECSClient) registerContainerInstance(clusterRef string, contain
 		registerRequest.Tags = tags
 	}
 	registerRequest.PlatformDevices = platformDevices
-	registerRequest = client.setInstanceIdentity(registerRequest)
-
-	resources, err := client.getResources()
-	if err != nil {
-		return "", "", err
-	}
-
-	registerRequest.TotalResources = resources
+	registerRequest.TotalResources = client.getResources()
+	registerRequest.VersionInfo = client.getVersionInfo()
 
 	registerRequest.ClientToken = &registrationToken
-	resp, err := client.standardClient.RegisterContainerInstance(&registerRequest)
+	resp, err := client.sendRegisterContainerInstance(registerRequest)
 	if err != nil {
 		seelog.Errorf("Unable to register as a container instance with ECS: %v", err)
 		return "", "", err
@@ -196,7 +315,58 @@ func (client *APIECSClient) registerContainerInstance(clusterRef string, contain
 	return aws.StringValue(resp.ContainerInstance.ContainerInstanceArn), availabilityzone, err
 }
 
-func (client *APIECSClient) setInstanceIdentity(registerRequest ecs.RegisterContainerInstanceInput) ecs.RegisterContainerInstanceInput {
+// sendRegisterContainerInstance sets the instance identity on the request and
+// registers the container instance. If the backend rejects the signature of the
+// instance identity document, the other signature formats are tried in turn and
+// the accepted one is remembered for later registrations
+func (client *APIECSClient) sendRegisterContainerInstance(
+	registerRequest ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error) {
+	var resp *ecs.RegisterContainerInstanceOutput
+	var err error
+	for _, signatureResource := range client.orderedIIDSignatureResources() {
+		request := client.setInstanceIdentity(registerRequest, signatureResource)
+		err = client.retryAPICall("RegisterContainerInstance", func() error {
+			var callErr error
+			resp, callErr = client.standardClient.RegisterContainerInstance(&request)
+			return callErr
+		})
+		if err == nil {
+			client.iidSignatureResource = signatureResource
+			return resp, nil
+		}
+		if client.config.NoIID || !isIIDSignatureError(err) {
+			return nil, err
+		}
+		seelog.Warnf("Instance identity signature from %s was rejected, trying the next signature format: %v",
+			signatureResource, err)
+	}
+	return nil, err
+}
+
+// orderedIIDSignatureResources returns the signature formats to try, starting
+// with the last accepted one
+func (client *APIECSClient) orderedIIDSignatureResources() []string {
+	resources := []string{client.iidSignatureResource}
+	for _, resource := range iidSignatureResources {
+		if resource != client.iidSignatureResource {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// isIIDSignatureError returns true if the registration was rejected because of
+// the instance identity document signature
+func isIIDSignatureError(err error) bool {
+	if awserr, ok := err.(awserr.Error); ok {
+		return (awserr.Code() == ecs.ErrCodeClientException || awserr.Code() == ecs.ErrCodeInvalidParameterException) &&
+			strings.Contains(strings.ToLower(awserr.Message()), iidSignatureErrorMessage)
+	}
+	return false
+}
+
+func (client *APIECSClient) setInstanceIdentity(registerRequest ecs.RegisterContainerInstanceInput,
+	signatureResource string) ecs.RegisterContainerInstanceInput {
 	instanceIdentityDoc := ""
 	instanceIdentitySignature := ""
 
@@ -216,7 +386,7 @@ func (client *APIECSClient) setInstanceIdentity(registerRequest ecs.RegisterCont
 	registerRequest.InstanceIdentityDocument = &instanceIdentityDoc
 
 	if iidRetrieved {
-		instanceIdentitySignature, err = client.ec2metadata.GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource)
+		instanceIdentitySignature, err = client.ec2metadata.GetDynamicData(signatureResource)
 		if err != nil {
 			seelog.Errorf("Unable to get instance identity signature: %v", err)
 		}
@@ -251,23 +421,19 @@ func findMissingAttributes(expectedAttributes, actualAttributes map[string]strin
 	return missingAttributes, err
 }
 
-func (client *APIECSClient) getResources() ([]*ecs.Resource, error) {
+func (client *APIECSClient) getResources() []*ecs.Resource {
 	// Micro-optimization, the pointer to this is used multiple times below
 	integerStr := "INTEGER"
 
 	cpu, mem := getCpuAndMemory()
-	remainingMem := mem - int64(client.config.ReservedMemory)
-	seelog.Infof("Remaining mem: %d", remainingMem)
-	if remainingMem < 0 {
-		return nil, fmt.Errorf(
-			"api register-container-instance: reserved memory is higher than available memory on the host, total memory: %d, reserved: %d",
-			mem, client.config.ReservedMemory)
-	}
+	remainingCPU := subtractReservedResource("cpu", cpu, int64(client.config.ReservedCPU))
+	remainingMem := subtractReservedResource("memory", mem, int64(client.config.ReservedMemory))
+	seelog.Infof("Remaining cpu: %d, remaining mem: %d", remainingCPU, remainingMem)
 
 	cpuResource := ecs.Resource{
 		Name:         utils.Strptr("CPU"),
 		Type:         &integerStr,
-		IntegerValue: &cpu,
+		IntegerValue: &remainingCPU,
 	}
 	memResource := ecs.Resource{
 		Name:         utils.Strptr("MEMORY"),
@@ -285,10 +451,25 @@ func (client *APIECSClient) getResources() ([]*ecs.Resource, error) {
 		StringSetValue: utils.Uint16SliceToStringSlice(client.config.ReservedPortsUDP),
 	}
 
-	return []*ecs.Resource{&cpuResource, &memResource, &portResource, &udpPortResource}, nil
+	return []*ecs.Resource{&cpuResource, &memResource, &portResource, &udpPortResource}
 }
 
-func getCpuAndMemory() (int64, int64) {
+// subtractReservedResource returns the amount of a resource left for tasks after
+// the reserved amount is taken out of the detected amount. The result is clamped
+// at zero, so that a negative value is never advertised
+func subtractReservedResource(name string, detected int64, reserved int64) int64 {
+	remaining := detected - reserved
+	if remaining < 0 {
+		seelog.Errorf("Reserved %s is higher than available %s on the host, total: %d, reserved: %d; advertising 0",
+			name, name, detected, reserved)
+		return 0
+	}
+	return remaining
+}
+
+// getCpuAndMemory returns the CPU units and the memory in MiB of the host. It
+// is a variable so that tests can advertise fixed amounts
+var getCpuAndMemory = func() (int64, int64) {
 	memInfo, err := system.ReadMemInfo()
 	mem := int64(0)
 	if err == nil {
@@ -321,6 +502,26 @@ func (client *APIECSClient) getAdditionalAttributes() []*ecs.Attribute {
 	}}
 }
 
+// getVersionInfo returns the versions of the agent and of the docker daemon,
+// which are displayed for the container instance
+func (client *APIECSClient) getVersionInfo() *ecs.VersionInfo {
+	versionInfo := &ecs.VersionInfo{
+		AgentVersion: aws.String(version.Version),
+		AgentHash:    aws.String(version.GitHashString()),
+	}
+	if client.dockerVersion == nil {
+		return versionInfo
+	}
+	dockerVersion, err := client.dockerVersion()
+	if err != nil {
+		seelog.Warnf("Unable to get docker version to report on registration: %v", err)
+		return versionInfo
+	}
+	// Use the same format as the docker version reported when connecting to ACS
+	versionInfo.DockerVersion = aws.String(dockerVersionPrefix + dockerVersion)
+	return versionInfo
+}
+
 func (client *APIECSClient) getCustomAttributes() []*ecs.Attribute {
 	var attributes []*ecs.Attribute
 	for attribute, value := range client.config.InstanceAttributes {
@@ -352,12 +553,19 @@ func (client *APIECSClient) SubmitTaskStateChange(change api.TaskStateChange) er
 		})
 		if err != nil {
 			seelog.Warnf("Could not submit an attachment state change: %v", err)
-			return err
+			return apierrors.NewAPIError(err, 0)
 		}
 
 		return nil
 	}
 
+	// Container state changes are keyed on the container name, reject the
+	// change rather than letting containers overwrite each other's reports
+	if err := validateContainerNames(change); err != nil {
+		seelog.Errorf("Not submitting task state change: [%s]: %v", change.String(), err)
+		return err
+	}
+
 	status := change.Status.BackendStatus()
 
 	req := ecs.SubmitTaskStateChangeInput{
@@ -376,27 +584,87 @@ func (client *APIECSClient) SubmitTaskStateChange(change api.TaskStateChange) er
 	}
 
 	req.Containers = containerEvents
+	req.ManagedAgents = client.buildManagedAgentStateChangePayload(change.ManagedAgents)
 
 	_, err := client.submitStateChangeClient.SubmitTaskStateChange(&req)
 	if err != nil {
 		seelog.Warnf("Could not submit task state change: [%s]: %v", change.String(), err)
-		return err
+		return apierrors.NewAPIError(err, 0)
 	}
 
 	return nil
 }
 
+// validateContainerNames returns an error if the container state changes of a
+// task state change report distinct containers with the same name. Several
+// transitions of the same container, such as a batched RUNNING and STOPPED
+// change, are allowed. Changes without a container cannot be told apart, so
+// they are treated as distinct containers
+func validateContainerNames(change api.TaskStateChange) error {
+	containers := make(map[string]*apicontainer.Container, len(change.Containers))
+	for _, containerChange := range change.Containers {
+		container, ok := containers[containerChange.ContainerName]
+		if ok && (container == nil || container != containerChange.Container) {
+			return &apierrors.DuplicateContainerNameError{
+				TaskARN:       change.TaskARN,
+				ContainerName: containerChange.ContainerName,
+			}
+		}
+		containers[containerChange.ContainerName] = containerChange.Container
+	}
+	return nil
+}
+
+// containerStateChangeReason returns the reason to submit for a container state
+// change. The state change APIs have no field for the image pull error, so its
+// category is prepended to the reason, which is surfaced by DescribeTasks
+func containerStateChangeReason(change api.ContainerStateChange) string {
+	if change.PullError == api.PullErrorNone {
+		return change.Reason
+	}
+	return fmt.Sprintf(pullErrorReasonFormat, change.PullError, change.Reason)
+}
+
+// containerExitCodeAndReason returns the exit code and the reason to report
+// for the container. When a stopped container has no exit code, either is
+// amended according to the configured missing exit code behavior
+func (client *APIECSClient) containerExitCodeAndReason(change api.ContainerStateChange) (*int64, string) {
+	reason := containerStateChangeReason(change)
+	if change.ExitCode != nil {
+		return aws.Int64(int64(aws.IntValue(change.ExitCode))), reason
+	}
+	if change.Status != apicontainerstatus.ContainerStopped {
+		return nil, reason
+	}
+
+	switch client.config.MissingExitCodeBehavior {
+	case config.MissingExitCodeInferBehavior:
+		if reason != "" {
+			return aws.Int64(inferredErrorExitCode), reason
+		}
+		return aws.Int64(inferredKilledExitCode), reason
+	case config.MissingExitCodeFlagBehavior:
+		if reason != "" {
+			return nil, exitCodeUnknownReason + " " + reason
+		}
+		return nil, exitCodeUnknownReason
+	default:
+		return nil, reason
+	}
+}
+
 func (client *APIECSClient) buildContainerStateChangePayload(change api.ContainerStateChange) *ecs.ContainerStateChange {
 	statechange := &ecs.ContainerStateChange{
 		ContainerName: aws.String(change.ContainerName),
 	}
 
-	if change.Reason != "" {
-		if len(change.Reason) > ecsMaxReasonLength {
-			trimmed := change.Reason[0:ecsMaxReasonLength]
+	exitCode, reason := client.containerExitCodeAndReason(change)
+	if reason != "" {
+		if len(reason) > ecsMaxReasonLength {
+			trimmed := reason[0:ecsMaxReasonLength]
 			statechange.Reason = aws.String(trimmed)
 		} else {
-			statechange.Reason = aws.String(change.Reason)
+			statechange.Reason = aws.String(reason)
 		}
 	}
 	status := change.Status
@@ -408,11 +676,7 @@ func (client *APIECSClient) buildContainerStateChangePayload(change api.Containe
 	}
 
 	statechange.Status = aws.String(status.String())
-
-	if change.ExitCode != nil {
-		exitCode := int64(aws.IntValue(change.ExitCode))
-		statechange.ExitCode = aws.Int64(exitCode)
-	}
+	statechange.ExitCode = exitCode
 	networkBindings := make([]*ecs.NetworkBinding, len(change.PortBindings))
 	for i, binding := range change.PortBindings {
 		hostPort := int64(binding.HostPort)
@@ -432,18 +696,46 @@ func (client *APIECSClient) buildContainerStateChangePayload(change api.Containe
 	return statechange
 }
 
+// buildManagedAgentStateChangePayload converts the status changes of the
+// managed agents of a task into the payload of SubmitTaskStateChange. Only the
+// RUNNING and STOPPED statuses are sent
+func (client *APIECSClient) buildManagedAgentStateChangePayload(changes []api.ManagedAgentStateChange) []*ecs.ManagedAgentStateChange {
+	var managedAgents []*ecs.ManagedAgentStateChange
+	for _, change := range changes {
+		if change.Status != apicontainerstatus.ContainerStopped && change.Status != apicontainerstatus.ContainerRunning {
+			seelog.Warnf("Not submitting unsupported upstream managed agent state %s for container %s in task %s",
+				change.Status.String(), change.ContainerName, change.TaskArn)
+			continue
+		}
+		managedAgent := &ecs.ManagedAgentStateChange{
+			ContainerName:    aws.String(change.ContainerName),
+			ManagedAgentName: aws.String(change.Name),
+			Status:           aws.String(change.Status.String()),
+		}
+		if reason := change.Reason; reason != "" {
+			if len(reason) > ecsMaxReasonLength {
+				reason = reason[0:ecsMaxReasonLength]
+			}
+			managedAgent.Reason = aws.String(reason)
+		}
+		managedAgents = append(managedAgents, managedAgent)
+	}
+	return managedAgents
+}
+
 func (client *APIECSClient) SubmitContainerStateChange(change api.ContainerStateChange) error {
 	req := ecs.SubmitContainerStateChangeInput{
 		Cluster:       &client.config.Cluster,
 		Task:          &change.TaskArn,
 		ContainerName: &change.ContainerName,
 	}
-	if change.Reason != "" {
-		if len(change.Reason) > ecsMaxReasonLength {
-			trimmed := change.Reason[0:ecsMaxReasonLength]
+	exitCode, reason := client.containerExitCodeAndReason(change)
+	if reason != "" {
+		if len(reason) > ecsMaxReasonLength {
+			trimmed := reason[0:ecsMaxReasonLength]
 			req.Reason = &trimmed
 		} else {
-			req.Reason = &change.Reason
+			req.Reason = &reason
 		}
 	}
 	stat := change.Status.String()
@@ -455,10 +747,7 @@ func (client *APIECSClient) SubmitContainerStateChange(change api.ContainerState
 		return nil
 	}
 	req.Status = &stat
-	if change.ExitCode != nil {
-		exitCode := int64(*change.ExitCode)
-		req.ExitCode = &exitCode
-	}
+	req.ExitCode = exitCode
 	networkBindings := make([]*ecs.NetworkBinding, len(change.PortBindings))
 	for i, binding := range change.PortBindings {
 		hostPort := int64(binding.HostPort)
@@ -477,7 +766,7 @@ func (client *APIECSClient) SubmitContainerStateChange(change api.ContainerState
 	_, err := client.submitStateChangeClient.SubmitContainerStateChange(&req)
 	if err != nil {
 		seelog.Warnf("Could not submit container state change: [%s]: %v", change.String(), err)
-		return err
+		return apierrors.NewAPIError(err, 0)
 	}
 	return nil
 }
@@ -504,6 +793,9 @@ func (client *APIECSClient) DiscoverTelemetryEndpoint(containerInstanceArn strin
 }
 
 func (client *APIECSClient) discoverPollEndpoint(containerInstanceArn string) (*ecs.DiscoverPollEndpointOutput, error) {
+	identity := client.pollEndpointIdentity()
+	client.invalidatePollEndpointOnIdentityChange(containerInstanceArn, identity)
+
 	// Try getting an entry from the cache
 	cachedEndpoint, found := client.pollEndpoinCache.Get(containerInstanceArn)
 	if found {
@@ -513,27 +805,159 @@ func (client *APIECSClient) discoverPollEndpoint(containerInstanceArn string) (*
 		}
 	}
 
-	// Cache miss, invoke the ECS DiscoverPollEndpoint API.
+	// Cache miss, invoke the ECS DiscoverPollEndpoint API. The SDK does not
+	// retry on its own, throttled calls are retried here with backoff that
+	// honors Retry-After.
 	seelog.Debugf("Invoking DiscoverPollEndpoint for '%s'", containerInstanceArn)
-	output, err := client.standardClient.DiscoverPollEndpoint(&ecs.DiscoverPollEndpointInput{
-		ContainerInstance: &containerInstanceArn,
-		Cluster:           &client.config.Cluster,
+	var output *ecs.DiscoverPollEndpointOutput
+	err := client.retryAPICall("DiscoverPollEndpoint", func() error {
+		var callErr error
+		output, callErr = client.standardClient.DiscoverPollEndpoint(&ecs.DiscoverPollEndpointInput{
+			ContainerInstance: &containerInstanceArn,
+			Cluster:           &client.config.Cluster,
+		})
+		return callErr
 	})
 	if err != nil {
+		if request.IsErrorThrottle(err) {
+			if lastKnown, ok := client.getLastKnownPollEndpoint(containerInstanceArn); ok {
+				seelog.Warnf("DiscoverPollEndpoint for '%s' is throttled, using the last known endpoint", containerInstanceArn)
+				return lastKnown, nil
+			}
+		}
 		return nil, err
 	}
 
 	// Cache the response from ECS.
 	client.pollEndpoinCache.Set(containerInstanceArn, output)
+	client.setLastKnownPollEndpoint(containerInstanceArn, identity, output)
 	return output, nil
 }
 
+// pollEndpointIdentity identifies the cluster and the credentials the poll
+// endpoint is discovered with
+func (client *APIECSClient) pollEndpointIdentity() string {
+	var accessKeyID string
+	if client.credentialProvider != nil {
+		if creds, err := client.credentialProvider.Get(); err == nil {
+			accessKeyID = creds.AccessKeyID
+		}
+	}
+	return client.config.Cluster + "/" + accessKeyID
+}
+
+// invalidatePollEndpointOnIdentityChange drops the cached and the last known
+// poll endpoints of the container instance if they were discovered for a
+// different cluster or with different credentials, as they may no longer be
+// valid
+func (client *APIECSClient) invalidatePollEndpointOnIdentityChange(containerInstanceArn string, identity string) {
+	client.pollEndpointLock.Lock()
+	defer client.pollEndpointLock.Unlock()
+
+	previous, ok := client.pollEndpointIdentities[containerInstanceArn]
+	if !ok || previous == identity {
+		return
+	}
+	seelog.Infof("Cluster or credentials changed, discovering the poll endpoint for '%s' again", containerInstanceArn)
+	client.pollEndpoinCache.Delete(containerInstanceArn)
+	delete(client.lastKnownPollEndpoints, containerInstanceArn)
+	delete(client.pollEndpointIdentities, containerInstanceArn)
+}
+
+func (client *APIECSClient) getLastKnownPollEndpoint(containerInstanceArn string) (*ecs.DiscoverPollEndpointOutput, bool) {
+	client.pollEndpointLock.RLock()
+	defer client.pollEndpointLock.RUnlock()
+
+	output, ok := client.lastKnownPollEndpoints[containerInstanceArn]
+	return output, ok
+}
+
+func (client *APIECSClient) setLastKnownPollEndpoint(containerInstanceArn string, identity string, output *ecs.DiscoverPollEndpointOutput) {
+	client.pollEndpointLock.Lock()
+	defer client.pollEndpointLock.Unlock()
+
+	if client.lastKnownPollEndpoints == nil {
+		client.lastKnownPollEndpoints = make(map[string]*ecs.DiscoverPollEndpointOutput)
+	}
+	if client.pollEndpointIdentities == nil {
+		client.pollEndpointIdentities = make(map[string]string)
+	}
+	client.pollEndpointIdentities[containerInstanceArn] = identity
+	client.lastKnownPollEndpoints[containerInstanceArn] = output
+}
+
 func (client *APIECSClient) GetResourceTags(resourceArn string) ([]*ecs.Tag, error) {
-	output, err := client.standardClient.ListTagsForResource(&ecs.ListTagsForResourceInput{
-		ResourceArn: &resourceArn,
+	var output *ecs.ListTagsForResourceOutput
+	err := client.retryAPICall("ListTagsForResource", func() error {
+		var callErr error
+		output, callErr = client.standardClient.ListTagsForResource(&ecs.ListTagsForResourceInput{
+			ResourceArn: &resourceArn,
+		})
+		return callErr
 	})
 	if err != nil {
 		return nil, err
 	}
 	return output.Tags, nil
 }
+
+func (client *APIECSClient) PutAttributes(containerInstanceArn string, attributes []*ecs.Attribute) error {
+	for _, attribute := range attributes {
+		attribute.TargetId = aws.String(containerInstanceArn)
+		attribute.TargetType = aws.String(ecs.TargetTypeContainerInstance)
+	}
+	return client.retryAPICall("PutAttributes", func() error {
+		_, err := client.standardClient.PutAttributes(&ecs.PutAttributesInput{
+			Attributes: attributes,
+			Cluster:    &client.config.Cluster,
+		})
+		return err
+	})
+}
+
+// DeleteAttributes removes the attributes from the container instance
+func (client *APIECSClient) DeleteAttributes(containerInstanceArn string, attributes []*ecs.Attribute) error {
+	for _, attribute := range attributes {
+		attribute.TargetId = aws.String(containerInstanceArn)
+		attribute.TargetType = aws.String(ecs.TargetTypeContainerInstance)
+	}
+	return client.retryAPICall("DeleteAttributes", func() error {
+		_, err := client.standardClient.DeleteAttributes(&ecs.DeleteAttributesInput{
+			Attributes: attributes,
+			Cluster:    &client.config.Cluster,
+		})
+		return err
+	})
+}
+
+// UpdateContainerInstanceState changes the status of the container instance
+func (client *APIECSClient) UpdateContainerInstanceState(containerInstanceArn string, status string) error {
+	return client.retryAPICall("UpdateContainerInstancesState", func() error {
+		output, err := client.standardClient.UpdateContainerInstancesState(&ecs.UpdateContainerInstancesStateInput{
+			Cluster:            &client.config.Cluster,
+			ContainerInstances: []*string{aws.String(containerInstanceArn)},
+			Status:             aws.String(status),
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.Failures) > 0 {
+			return fmt.Errorf("unable to update the state of container instance %s: %s",
+				containerInstanceArn, aws.StringValue(output.Failures[0].Reason))
+		}
+		return nil
+	})
+}
+
+// DeregisterContainerInstance deregisters the container instance from the
+// cluster
+func (client *APIECSClient) DeregisterContainerInstance(containerInstanceArn string, force bool) error {
+	return client.retryAPICall("DeregisterContainerInstance", func() error {
+		_, err := client.standardClient.DeregisterContainerInstance(&ecs.DeregisterContainerInstanceInput{
+			Cluster:           &client.config.Cluster,
+			ContainerInstance: aws.String(containerInstanceArn),
+			Force:             aws.Bool(force),
+		})
+		return err
+	})
+}
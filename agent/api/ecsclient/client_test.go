@@ -25,11 +25,13 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/agent/async"
@@ -38,6 +40,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ec2"
 	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -85,7 +89,7 @@ func NewMockClientWithConfig(ctrl *gomock.Controller,
 	ec2Metadata ec2.EC2MetadataClient,
 	additionalAttributes map[string]string,
 	cfg *config.Config) (api.ECSClient, *mock_api.MockECSSDK, *mock_api.MockECSSubmitStateSDK) {
-	client := NewECSClient(credentials.AnonymousCredentials, cfg, ec2Metadata)
+	client := NewECSClient(credentials.AnonymousCredentials, cfg, ec2Metadata, nil)
 	mockSDK := mock_api.NewMockECSSDK(ctrl)
 	mockSubmitStateSDK := mock_api.NewMockECSSubmitStateSDK(ctrl)
 	client.(*APIECSClient).SetSDK(mockSDK)
@@ -101,6 +105,20 @@ type taskSubmitInputMatcher struct {
 	ecs.SubmitTaskStateChangeInput
 }
 
+// testAPIRetryAttempts is the number of attempts of retried API calls in tests
+const testAPIRetryAttempts = 3
+
+// testAPIRetryConfig returns a config that retries API calls without delay
+func testAPIRetryConfig() *config.Config {
+	return &config.Config{
+		Cluster:               configuredCluster,
+		AWSRegion:             "us-east-1",
+		ECSAPIRetryAttempts:   testAPIRetryAttempts,
+		ECSAPIRetryMinBackoff: time.Millisecond,
+		ECSAPIRetryMaxBackoff: time.Millisecond,
+	}
+}
+
 func strptr(s string) *string { return &s }
 func intptr(i int) *int       { return &i }
 func int64ptr(i *int) *int64 {
@@ -156,6 +174,20 @@ func (lhs *taskSubmitInputMatcher) String() string {
 	return fmt.Sprintf("%+v", *lhs)
 }
 
+func TestNewECSClientSharesIdleConnPool(t *testing.T) {
+	client := NewECSClient(credentials.AnonymousCredentials, &config.Config{
+		Cluster:                  configuredCluster,
+		AWSRegion:                "us-east-1",
+		ECSClientMaxIdleConns:    4,
+		ECSClientIdleConnTimeout: 30 * time.Second,
+	}, ec2.NewBlackholeEC2MetadataClient(), nil).(*APIECSClient)
+
+	standardHTTPClient := client.standardClient.(*ecs.ECS).Config.HTTPClient
+	submitStateChangeHTTPClient := client.submitStateChangeClient.(*ecs.ECS).Config.HTTPClient
+	assert.True(t, standardHTTPClient == submitStateChangeHTTPClient,
+		"API calls should share the same HTTP client and connection pool")
+}
+
 func TestSubmitContainerStateChange(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -245,6 +277,132 @@ func TestSubmitContainerStateChangeFull(t *testing.T) {
 	}
 }
 
+func TestSubmitContainerStateChangePullError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	reason := "CannotPullContainerError: manifest for busybox:missing not found"
+
+	mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&containerSubmitInputMatcher{
+		ecs.SubmitContainerStateChangeInput{
+			Cluster:         strptr(configuredCluster),
+			Task:            strptr("arn"),
+			ContainerName:   strptr("cont"),
+			Status:          strptr("STOPPED"),
+			Reason:          strptr("[ManifestNotFound] " + reason),
+			NetworkBindings: []*ecs.NetworkBinding{},
+		},
+	})
+	err := client.SubmitContainerStateChange(api.ContainerStateChange{
+		TaskArn:       "arn",
+		ContainerName: "cont",
+		Status:        apicontainerstatus.ContainerStopped,
+		Reason:        reason,
+		PullError:     api.PullErrorManifestNotFound,
+	})
+	assert.NoError(t, err)
+}
+
+func TestSubmitContainerStateChangeMissingExitCode(t *testing.T) {
+	inferredErrorExitCode := 1
+	inferredKilledExitCode := 137
+	testCases := []struct {
+		name             string
+		behavior         config.MissingExitCodeBehaviorType
+		reason           string
+		expectedExitCode *int
+		expectedReason   *string
+	}{
+		{
+			name:     "omit",
+			behavior: config.MissingExitCodeOmitBehavior,
+		},
+		{
+			name:             "infer without reason",
+			behavior:         config.MissingExitCodeInferBehavior,
+			expectedExitCode: &inferredKilledExitCode,
+		},
+		{
+			name:             "infer with reason",
+			behavior:         config.MissingExitCodeInferBehavior,
+			reason:           "CannotStartContainerError",
+			expectedExitCode: &inferredErrorExitCode,
+			expectedReason:   strptr("CannotStartContainerError"),
+		},
+		{
+			name:           "flag without reason",
+			behavior:       config.MissingExitCodeFlagBehavior,
+			expectedReason: strptr("[ExitCodeUnknown]"),
+		},
+		{
+			name:           "flag with reason",
+			behavior:       config.MissingExitCodeFlagBehavior,
+			reason:         "CannotStartContainerError",
+			expectedReason: strptr("[ExitCodeUnknown] CannotStartContainerError"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client, _, mockSubmitStateClient := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+				&config.Config{
+					Cluster:                 configuredCluster,
+					AWSRegion:               "us-east-1",
+					MissingExitCodeBehavior: tc.behavior,
+				})
+
+			mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&containerSubmitInputMatcher{
+				ecs.SubmitContainerStateChangeInput{
+					Cluster:         strptr(configuredCluster),
+					Task:            strptr("arn"),
+					ContainerName:   strptr("cont"),
+					Status:          strptr("STOPPED"),
+					ExitCode:        int64ptr(tc.expectedExitCode),
+					Reason:          tc.expectedReason,
+					NetworkBindings: []*ecs.NetworkBinding{},
+				},
+			})
+			err := client.SubmitContainerStateChange(api.ContainerStateChange{
+				TaskArn:       "arn",
+				ContainerName: "cont",
+				Status:        apicontainerstatus.ContainerStopped,
+				Reason:        tc.reason,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSubmitContainerStateChangeMissingExitCodeRunning(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, _, mockSubmitStateClient := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			Cluster:                 configuredCluster,
+			AWSRegion:               "us-east-1",
+			MissingExitCodeBehavior: config.MissingExitCodeInferBehavior,
+		})
+
+	// Running containers have no exit code yet, none is inferred for them
+	mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&containerSubmitInputMatcher{
+		ecs.SubmitContainerStateChangeInput{
+			Cluster:         strptr(configuredCluster),
+			Task:            strptr("arn"),
+			ContainerName:   strptr("cont"),
+			Status:          strptr("RUNNING"),
+			NetworkBindings: []*ecs.NetworkBinding{},
+		},
+	})
+	err := client.SubmitContainerStateChange(api.ContainerStateChange{
+		TaskArn:       "arn",
+		ContainerName: "cont",
+		Status:        apicontainerstatus.ContainerRunning,
+	})
+	assert.NoError(t, err)
+}
+
 func TestSubmitContainerStateChangeReason(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -522,29 +680,191 @@ func TestRegisterContainerInstanceNoIID(t *testing.T) {
 	assert.Equal(t, "us-west-2b", availabilityzone)
 }
 
-// TestRegisterContainerInstanceWithNegativeResource tests the registeration should fail with negative resource
-func TestRegisterContainerInstanceWithNegativeResource(t *testing.T) {
+// TestRegisterContainerInstanceReservedResourcesExceedAvailable tests that the
+// advertised resources are clamped at zero when more is reserved than available
+func TestRegisterContainerInstanceReservedResourcesExceedAvailable(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	_, mem := getCpuAndMemory()
-	mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
-	client := NewECSClient(credentials.AnonymousCredentials,
-		&config.Config{Cluster: configuredCluster,
+	defer mockCpuAndMemory(2048, 4096)()
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			Cluster:        configuredCluster,
 			AWSRegion:      "us-east-1",
-			ReservedMemory: uint16(mem) + 1,
-		}, mockEC2Metadata)
-	mockSDK := mock_api.NewMockECSSDK(mockCtrl)
-	mockSubmitStateSDK := mock_api.NewMockECSSubmitStateSDK(mockCtrl)
-	client.(*APIECSClient).SetSDK(mockSDK)
-	client.(*APIECSClient).SetSubmitStateChangeSDK(mockSubmitStateSDK)
+			NoIID:          true,
+			ReservedCPU:    2049,
+			ReservedMemory: 4097,
+		})
+
+	expectedAttributes := map[string]string{
+		"ecs.os-type": config.OSType,
+	}
+	mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+		cpuResource, ok := findResource(req.TotalResources, "CPU")
+		require.True(t, ok, `Could not find resource "CPU"`)
+		assert.Equal(t, int64(0), aws.Int64Value(cpuResource.IntegerValue))
+		memResource, ok := findResource(req.TotalResources, "MEMORY")
+		require.True(t, ok, `Could not find resource "MEMORY"`)
+		assert.Equal(t, int64(0), aws.Int64Value(memResource.IntegerValue))
+	}).Return(&ecs.RegisterContainerInstanceOutput{
+		ContainerInstance: &ecs.ContainerInstance{
+			ContainerInstanceArn: aws.String("registerArn"),
+			Attributes:           buildAttributeList(nil, expectedAttributes)}},
+		nil)
+
+	arn, _, err := client.RegisterContainerInstance("", nil, nil, registrationToken, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "registerArn", arn)
+}
+
+// TestRegisterContainerInstanceReservedResources tests that the reserved
+// resources are subtracted from the advertised resources
+func TestRegisterContainerInstanceReservedResources(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	defer mockCpuAndMemory(2048, 4096)()
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			Cluster:        configuredCluster,
+			AWSRegion:      "us-east-1",
+			NoIID:          true,
+			ReservedCPU:    1,
+			ReservedMemory: 1,
+		})
+
+	expectedAttributes := map[string]string{
+		"ecs.os-type": config.OSType,
+	}
+	mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+		cpuResource, ok := findResource(req.TotalResources, "CPU")
+		require.True(t, ok, `Could not find resource "CPU"`)
+		assert.Equal(t, int64(2047), aws.Int64Value(cpuResource.IntegerValue))
+		memResource, ok := findResource(req.TotalResources, "MEMORY")
+		require.True(t, ok, `Could not find resource "MEMORY"`)
+		assert.Equal(t, int64(4095), aws.Int64Value(memResource.IntegerValue))
+	}).Return(&ecs.RegisterContainerInstanceOutput{
+		ContainerInstance: &ecs.ContainerInstance{
+			ContainerInstanceArn: aws.String("registerArn"),
+			Attributes:           buildAttributeList(nil, expectedAttributes)}},
+		nil)
+
+	_, _, err := client.RegisterContainerInstance("", nil, nil, registrationToken, nil)
+	assert.NoError(t, err)
+}
+
+// mockCpuAndMemory makes the client advertise the given CPU units and memory,
+// and returns a function restoring the detected amounts
+func mockCpuAndMemory(cpu, mem int64) func() {
+	getCpuAndMemoryFunc := getCpuAndMemory
+	getCpuAndMemory = func() (int64, int64) {
+		return cpu, mem
+	}
+	return func() {
+		getCpuAndMemory = getCpuAndMemoryFunc
+	}
+}
+
+func TestSubtractReservedResource(t *testing.T) {
+	testCases := []struct {
+		name      string
+		detected  int64
+		reserved  int64
+		remaining int64
+	}{
+		{
+			name:      "nothing reserved",
+			detected:  2048,
+			reserved:  0,
+			remaining: 2048,
+		},
+		{
+			name:      "reserved subtracted",
+			detected:  2048,
+			reserved:  256,
+			remaining: 1792,
+		},
+		{
+			name:      "reserved exceeds detected",
+			detected:  2048,
+			reserved:  4096,
+			remaining: 0,
+		},
+		{
+			name:      "detection failed",
+			detected:  0,
+			reserved:  512,
+			remaining: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.remaining, subtractReservedResource("memory", tc.detected, tc.reserved))
+		})
+	}
+}
+
+func TestRegisterContainerInstanceIIDSignatureFallback(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
+	client, mc, _ := NewMockClient(mockCtrl, mockEC2Metadata, nil)
+
+	expectedAttributes := map[string]string{
+		"ecs.os-type": config.OSType,
+	}
+	registerOutput := &ecs.RegisterContainerInstanceOutput{
+		ContainerInstance: &ecs.ContainerInstance{
+			ContainerInstanceArn: aws.String("registerArn"),
+			Attributes:           buildAttributeList(nil, expectedAttributes)}}
+	signatureErr := awserr.New(ecs.ErrCodeClientException, "Invalid instance identity document signature", nil)
 
 	gomock.InOrder(
-		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return("instanceIdentityDocument", nil),
-		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return("signature", nil),
+		// The default signature format is rejected
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return(iid, nil),
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return(iidSignature, nil),
+		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+			assert.Equal(t, iidSignature, aws.StringValue(req.InstanceIdentityDocumentSignature))
+		}).Return(nil, signatureErr),
+		// The RSA-2048 signature is accepted
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return(iid, nil),
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentRSA2048Resource).Return("rsa2048", nil),
+		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+			assert.Equal(t, "rsa2048", aws.StringValue(req.InstanceIdentityDocumentSignature))
+		}).Return(registerOutput, nil),
+		// The accepted format is used first on the next registration
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return(iid, nil),
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentRSA2048Resource).Return("rsa2048", nil),
+		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+			assert.Equal(t, "rsa2048", aws.StringValue(req.InstanceIdentityDocumentSignature))
+		}).Return(registerOutput, nil),
 	)
-	_, _, err := client.RegisterContainerInstance("", nil, nil, "", nil)
-	assert.Error(t, err, "Register resource with negative value should cause registration fail")
+
+	arn, _, err := client.RegisterContainerInstance("", nil, nil, registrationToken, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "registerArn", arn)
+
+	arn, _, err = client.RegisterContainerInstance("registerArn", nil, nil, registrationToken, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "registerArn", arn)
+}
+
+func TestRegisterContainerInstanceIIDSignatureNotRetriedOnOtherErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
+	client, mc, _ := NewMockClient(mockCtrl, mockEC2Metadata, nil)
+
+	gomock.InOrder(
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return(iid, nil),
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return(iidSignature, nil),
+		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Return(nil,
+			awserr.New(ecs.ErrCodeServerException, "Internal error", nil)),
+	)
+
+	_, _, err := client.RegisterContainerInstance("", nil, nil, registrationToken, nil)
+	assert.Error(t, err)
 }
 
 func TestRegisterContainerInstanceWithEmptyTags(t *testing.T) {
@@ -577,6 +897,55 @@ func TestRegisterContainerInstanceWithEmptyTags(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRegisterContainerInstanceVersionInfo(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		dockerVersion         func() (string, error)
+		expectedDockerVersion *string
+	}{
+		{
+			name:                  "docker version resolved",
+			dockerVersion:         func() (string, error) { return "17.12.0-ce", nil },
+			expectedDockerVersion: aws.String("DockerVersion: 17.12.0-ce"),
+		},
+		{
+			name:          "docker version error",
+			dockerVersion: func() (string, error) { return "", errors.New("error") },
+		},
+		{
+			name: "no docker version resolver",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
+			client, mc, _ := NewMockClient(mockCtrl, mockEC2Metadata, nil)
+			client.(*APIECSClient).dockerVersion = tc.dockerVersion
+
+			gomock.InOrder(
+				mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return("instanceIdentityDocument", nil),
+				mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return("signature", nil),
+				mc.EXPECT().RegisterContainerInstance(gomock.Any()).Do(func(req *ecs.RegisterContainerInstanceInput) {
+					require.NotNil(t, req.VersionInfo)
+					assert.Equal(t, version.Version, aws.StringValue(req.VersionInfo.AgentVersion))
+					assert.Equal(t, version.GitHashString(), aws.StringValue(req.VersionInfo.AgentHash))
+					assert.Equal(t, tc.expectedDockerVersion, req.VersionInfo.DockerVersion)
+				}).Return(&ecs.RegisterContainerInstanceOutput{
+					ContainerInstance: &ecs.ContainerInstance{
+						ContainerInstanceArn: aws.String("registerArn"),
+						Attributes:           buildAttributeList(nil, map[string]string{"ecs.os-type": config.OSType})}},
+					nil),
+			)
+
+			_, _, err := client.RegisterContainerInstance("", nil, nil, registrationToken, nil)
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestValidateRegisteredAttributes(t *testing.T) {
 	origAttributes := []*ecs.Attribute{
 		{Name: aws.String("foo"), Value: aws.String("bar")},
@@ -604,6 +973,151 @@ func findResource(resources []*ecs.Resource, name string) (*ecs.Resource, bool)
 	return nil, false
 }
 
+func newCreateClusterTestClient(mockCtrl *gomock.Controller, retries int) (*APIECSClient, *mock_api.MockECSSDK) {
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			AWSRegion:             "us-east-1",
+			CreateClusterAttempts: retries + 1,
+		})
+	ecsClient := client.(*APIECSClient)
+	ecsClient.newCreateClusterBackoff = func() retry.Backoff {
+		return retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1)
+	}
+	return ecsClient, mc
+}
+
+func TestCreateClusterRetriesThrottling(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 2)
+
+	defaultCluster := config.DefaultClusterName
+	throttleErr := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	gomock.InOrder(
+		mc.EXPECT().CreateCluster(&ecs.CreateClusterInput{ClusterName: &defaultCluster}).Return(nil, throttleErr),
+		mc.EXPECT().CreateCluster(&ecs.CreateClusterInput{ClusterName: &defaultCluster}).Return(nil, throttleErr),
+		mc.EXPECT().CreateCluster(&ecs.CreateClusterInput{ClusterName: &defaultCluster}).Return(
+			&ecs.CreateClusterOutput{Cluster: &ecs.Cluster{ClusterName: &defaultCluster}}, nil),
+	)
+
+	cluster, err := client.CreateCluster(defaultCluster)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCluster, cluster)
+}
+
+func TestCreateClusterRetriesExhausted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 1)
+
+	throttleErr := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	mc.EXPECT().CreateCluster(gomock.Any()).Return(nil, throttleErr).Times(2)
+
+	_, err := client.CreateCluster(config.DefaultClusterName)
+	assert.Equal(t, throttleErr, err)
+}
+
+func TestCreateClusterDoesNotRetryNonRetriableError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 3)
+
+	accessDeniedErr := awserr.New(ecs.ErrCodeAccessDeniedException, "Access denied", nil)
+	mc.EXPECT().CreateCluster(gomock.Any()).Return(nil, accessDeniedErr)
+
+	_, err := client.CreateCluster(config.DefaultClusterName)
+	assert.Equal(t, accessDeniedErr, err)
+}
+
+func TestCreateClusterConflictResolvesExistingCluster(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 3)
+
+	defaultCluster := config.DefaultClusterName
+	clusterArn := "arn:aws:ecs:us-east-1:123456789012:cluster/default"
+	gomock.InOrder(
+		mc.EXPECT().CreateCluster(gomock.Any()).Return(nil,
+			awserr.New(ecs.ErrCodeUpdateInProgressException, "Cluster update in progress", nil)),
+		mc.EXPECT().DescribeClusters(&ecs.DescribeClustersInput{Clusters: []*string{&defaultCluster}}).Return(
+			&ecs.DescribeClustersOutput{
+				Clusters: []*ecs.Cluster{{
+					ClusterName: aws.String(defaultCluster),
+					ClusterArn:  aws.String(clusterArn),
+					Status:      aws.String("ACTIVE"),
+				}},
+			}, nil),
+	)
+
+	cluster, err := client.CreateCluster(defaultCluster)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCluster, cluster)
+}
+
+func TestCreateClusterConflictRetriesDescribeClusters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 1)
+
+	defaultCluster := config.DefaultClusterName
+	throttleErr := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	gomock.InOrder(
+		mc.EXPECT().CreateCluster(gomock.Any()).Return(nil,
+			awserr.New(ecs.ErrCodeUpdateInProgressException, "Cluster update in progress", nil)),
+		mc.EXPECT().DescribeClusters(gomock.Any()).Return(nil, throttleErr),
+		mc.EXPECT().DescribeClusters(gomock.Any()).Return(
+			&ecs.DescribeClustersOutput{
+				Clusters: []*ecs.Cluster{{
+					ClusterName: aws.String(defaultCluster),
+					Status:      aws.String("ACTIVE"),
+				}},
+			}, nil),
+	)
+
+	cluster, err := client.CreateCluster(defaultCluster)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCluster, cluster)
+}
+
+func TestCreateClusterRetriesDisabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 0)
+
+	throttleErr := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	mc.EXPECT().CreateCluster(gomock.Any()).Return(nil, throttleErr)
+
+	_, err := client.CreateCluster(config.DefaultClusterName)
+	assert.Equal(t, throttleErr, err)
+}
+
+func TestCreateClusterAlreadyExistsMessageIsNotConflict(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 3)
+
+	clientErr := awserr.New(ecs.ErrCodeClientException, "Cluster default already exists", nil)
+	mc.EXPECT().CreateCluster(gomock.Any()).Return(nil, clientErr)
+
+	_, err := client.CreateCluster(config.DefaultClusterName)
+	assert.Equal(t, clientErr, err)
+}
+
+func TestCreateClusterConflictClusterNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc := newCreateClusterTestClient(mockCtrl, 3)
+
+	gomock.InOrder(
+		mc.EXPECT().CreateCluster(gomock.Any()).Return(nil,
+			awserr.New(ecs.ErrCodeUpdateInProgressException, "Cluster update in progress", nil)),
+		mc.EXPECT().DescribeClusters(gomock.Any()).Return(&ecs.DescribeClustersOutput{}, nil),
+	)
+
+	_, err := client.CreateCluster(config.DefaultClusterName)
+	assert.Error(t, err)
+}
+
 func TestRegisterBlankCluster(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -615,7 +1129,7 @@ func TestRegisterBlankCluster(t *testing.T) {
 			Cluster:   "",
 			AWSRegion: "us-east-1",
 		},
-		mockEC2Metadata)
+		mockEC2Metadata, nil)
 	mc := mock_api.NewMockECSSDK(mockCtrl)
 	client.(*APIECSClient).SetSDK(mc)
 
@@ -670,7 +1184,7 @@ func TestRegisterBlankClusterNotCreatingClusterWhenErrorNotClusterNotFound(t *te
 			Cluster:   "",
 			AWSRegion: "us-east-1",
 		},
-		mockEC2Metadata)
+		mockEC2Metadata, nil)
 	mc := mock_api.NewMockECSSDK(mockCtrl)
 	client.(*APIECSClient).SetSDK(mc)
 
@@ -721,6 +1235,104 @@ func TestDiscoverTelemetryEndpoint(t *testing.T) {
 	}
 }
 
+func TestPutAttributes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().PutAttributes(gomock.Any()).Do(func(req *ecs.PutAttributesInput) {
+		assert.Equal(t, configuredCluster, aws.StringValue(req.Cluster))
+		require.Len(t, req.Attributes, 1)
+		assert.Equal(t, "ecs.host-ports.tcp", aws.StringValue(req.Attributes[0].Name))
+		assert.Equal(t, "22 80", aws.StringValue(req.Attributes[0].Value))
+		assert.Equal(t, "containerInstance", aws.StringValue(req.Attributes[0].TargetId))
+		assert.Equal(t, ecs.TargetTypeContainerInstance, aws.StringValue(req.Attributes[0].TargetType))
+	}).Return(&ecs.PutAttributesOutput{}, nil)
+
+	err := client.PutAttributes("containerInstance", []*ecs.Attribute{
+		{
+			Name:  aws.String("ecs.host-ports.tcp"),
+			Value: aws.String("22 80"),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestPutAttributesError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().PutAttributes(gomock.Any()).Return(nil, errors.New("error"))
+
+	err := client.PutAttributes("containerInstance", []*ecs.Attribute{
+		{
+			Name:  aws.String("ecs.host-ports.tcp"),
+			Value: aws.String("22"),
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestDeleteAttributes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().DeleteAttributes(gomock.Any()).Do(func(req *ecs.DeleteAttributesInput) {
+		assert.Equal(t, configuredCluster, aws.StringValue(req.Cluster))
+		require.Len(t, req.Attributes, 1)
+		assert.Equal(t, "ecs.instance-weight", aws.StringValue(req.Attributes[0].Name))
+		assert.Nil(t, req.Attributes[0].Value)
+		assert.Equal(t, "containerInstance", aws.StringValue(req.Attributes[0].TargetId))
+		assert.Equal(t, ecs.TargetTypeContainerInstance, aws.StringValue(req.Attributes[0].TargetType))
+	}).Return(&ecs.DeleteAttributesOutput{}, nil)
+
+	err := client.DeleteAttributes("containerInstance", []*ecs.Attribute{
+		{
+			Name: aws.String("ecs.instance-weight"),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestUpdateContainerInstanceState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().UpdateContainerInstancesState(gomock.Any()).Do(func(req *ecs.UpdateContainerInstancesStateInput) {
+		assert.Equal(t, configuredCluster, aws.StringValue(req.Cluster))
+		assert.Equal(t, []string{"containerInstance"}, aws.StringValueSlice(req.ContainerInstances))
+		assert.Equal(t, ecs.ContainerInstanceStatusDraining, aws.StringValue(req.Status))
+	}).Return(&ecs.UpdateContainerInstancesStateOutput{}, nil)
+
+	err := client.UpdateContainerInstanceState("containerInstance", ecs.ContainerInstanceStatusDraining)
+	assert.NoError(t, err)
+}
+
+func TestUpdateContainerInstanceStateFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().UpdateContainerInstancesState(gomock.Any()).Return(&ecs.UpdateContainerInstancesStateOutput{
+		Failures: []*ecs.Failure{{Arn: aws.String("containerInstance"), Reason: aws.String("MISSING")}},
+	}, nil)
+
+	err := client.UpdateContainerInstanceState("containerInstance", ecs.ContainerInstanceStatusDraining)
+	assert.Error(t, err)
+}
+
+func TestDeregisterContainerInstance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc.EXPECT().DeregisterContainerInstance(gomock.Any()).Do(func(req *ecs.DeregisterContainerInstanceInput) {
+		assert.Equal(t, configuredCluster, aws.StringValue(req.Cluster))
+		assert.Equal(t, "containerInstance", aws.StringValue(req.ContainerInstance))
+		assert.True(t, aws.BoolValue(req.Force))
+	}).Return(&ecs.DeregisterContainerInstanceOutput{}, nil)
+
+	err := client.DeregisterContainerInstance("containerInstance", true)
+	assert.NoError(t, err)
+}
+
 func TestDiscoverTelemetryEndpointError(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -811,6 +1423,136 @@ func TestDiscoverPollEndpointCacheMiss(t *testing.T) {
 	}
 }
 
+func TestDiscoverPollEndpointThrottledFallback(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockSDK := mock_api.NewMockECSSDK(mockCtrl)
+	pollEndpoinCache := mock_async.NewMockCache(mockCtrl)
+	client := &APIECSClient{
+		credentialProvider: credentials.AnonymousCredentials,
+		config: &config.Config{
+			Cluster:               configuredCluster,
+			AWSRegion:             "us-east-1",
+			ECSAPIRetryAttempts:   testAPIRetryAttempts,
+			ECSAPIRetryMinBackoff: time.Millisecond,
+			ECSAPIRetryMaxBackoff: time.Millisecond,
+		},
+		standardClient:   mockSDK,
+		ec2metadata:      ec2.NewBlackholeEC2MetadataClient(),
+		pollEndpoinCache: pollEndpoinCache,
+	}
+	firstOutput := &ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.1")}
+	refreshedOutput := &ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.2")}
+	throttleErr := awserr.New("ThrottlingException", "Rate exceeded", nil)
+
+	gomock.InOrder(
+		pollEndpoinCache.EXPECT().Get("containerInstance").Return(nil, false),
+		mockSDK.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(firstOutput, nil),
+		pollEndpoinCache.EXPECT().Set("containerInstance", firstOutput),
+		// The cache entry expires and discovery is throttled on every attempt
+		pollEndpoinCache.EXPECT().Get("containerInstance").Return(nil, false),
+		mockSDK.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(nil, throttleErr).Times(testAPIRetryAttempts),
+		// Discovery recovers and the endpoint is refreshed
+		pollEndpoinCache.EXPECT().Get("containerInstance").Return(nil, false),
+		mockSDK.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(nil, throttleErr),
+		mockSDK.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(refreshedOutput, nil),
+		pollEndpoinCache.EXPECT().Set("containerInstance", refreshedOutput),
+	)
+
+	endpoint, err := client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1", endpoint)
+
+	endpoint, err = client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err, "Throttled discovery should fall back to the last known endpoint")
+	assert.Equal(t, "http://127.0.0.1", endpoint)
+
+	endpoint, err = client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.2", endpoint)
+}
+
+func TestDiscoverPollEndpointThrottledNoFallback(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, testAPIRetryConfig())
+	mc.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(nil,
+		awserr.New("ThrottlingException", "Rate exceeded", nil)).Times(testAPIRetryAttempts)
+
+	_, err := client.DiscoverPollEndpoint("containerInstance")
+	assert.Error(t, err)
+}
+
+func TestDiscoverPollEndpointClusterChangeInvalidatesCache(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := &config.Config{
+		Cluster:   configuredCluster,
+		AWSRegion: "us-east-1",
+	}
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, cfg)
+	gomock.InOrder(
+		mc.EXPECT().DiscoverPollEndpoint(&ecs.DiscoverPollEndpointInput{
+			ContainerInstance: aws.String("containerInstance"),
+			Cluster:           aws.String(configuredCluster),
+		}).Return(&ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.1")}, nil),
+		mc.EXPECT().DiscoverPollEndpoint(&ecs.DiscoverPollEndpointInput{
+			ContainerInstance: aws.String("containerInstance"),
+			Cluster:           aws.String("otherCluster"),
+		}).Return(&ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.2")}, nil),
+	)
+
+	endpoint, err := client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1", endpoint)
+
+	// The cached endpoint is reused while the cluster is unchanged
+	endpoint, err = client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1", endpoint)
+
+	cfg.Cluster = "otherCluster"
+	endpoint, err = client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.2", endpoint, "Cluster change should trigger rediscovery")
+}
+
+func TestDiscoverPollEndpointCredentialRotationInvalidatesCache(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	provider := &credentials.StaticProvider{Value: credentials.Value{
+		AccessKeyID:     "AKID1",
+		SecretAccessKey: "secret",
+	}}
+	creds := credentials.NewCredentials(provider)
+	client := NewECSClient(creds, &config.Config{
+		Cluster:   configuredCluster,
+		AWSRegion: "us-east-1",
+	}, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mc := mock_api.NewMockECSSDK(mockCtrl)
+	client.(*APIECSClient).SetSDK(mc)
+	gomock.InOrder(
+		mc.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(
+			&ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.1")}, nil),
+		mc.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(
+			&ecs.DiscoverPollEndpointOutput{Endpoint: aws.String("http://127.0.0.2")}, nil),
+	)
+
+	endpoint, err := client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1", endpoint)
+
+	provider.Value.AccessKeyID = "AKID2"
+	creds.Expire()
+	endpoint, err = client.DiscoverPollEndpoint("containerInstance")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.2", endpoint, "Credential rotation should trigger rediscovery")
+}
+
 func TestDiscoverTelemetryEndpointAfterPollEndpointCacheHit(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -901,6 +1643,154 @@ func TestSubmitTaskStateChangeWithoutAttachments(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit task state change with no attachments")
 }
 
+func TestSubmitTaskStateChangeWithManagedAgents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mockSubmitStateClient.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(input *ecs.SubmitTaskStateChangeInput) {
+		// only the statuses known to ECS are submitted
+		require.Len(t, input.ManagedAgents, 1)
+		assert.Equal(t, "cont", aws.StringValue(input.ManagedAgents[0].ContainerName))
+		assert.Equal(t, apicontainer.ExecuteCommandAgentName, aws.StringValue(input.ManagedAgents[0].ManagedAgentName))
+		assert.Equal(t, "STOPPED", aws.StringValue(input.ManagedAgents[0].Status))
+		assert.Len(t, aws.StringValue(input.ManagedAgents[0].Reason), ecsMaxReasonLength)
+	})
+
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskRunning,
+		ManagedAgents: []api.ManagedAgentStateChange{
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "cont",
+				Name:          apicontainer.ExecuteCommandAgentName,
+				Status:        apicontainerstatus.ContainerStopped,
+				Reason:        strings.Repeat("a", ecsMaxReasonLength+1),
+			},
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "cont",
+				Name:          apicontainer.ExecuteCommandAgentName,
+				Status:        apicontainerstatus.ContainerPulled,
+			},
+		},
+	})
+	assert.NoError(t, err, "Unable to submit task state change with managed agents")
+}
+
+func TestSubmitTaskStateChangeDuplicateContainerNames(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// SubmitTaskStateChange is not expected to be invoked on the SDK client
+	client, _, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	web := &apicontainer.Container{Name: "web"}
+	sidecar := &apicontainer.Container{Name: "sidecar"}
+	otherWeb := &apicontainer.Container{Name: "web"}
+
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskRunning,
+		Containers: []api.ContainerStateChange{
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerRunning,
+				Container:     web,
+			},
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "sidecar",
+				Status:        apicontainerstatus.ContainerRunning,
+				Container:     sidecar,
+			},
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerStopped,
+				Container:     otherWeb,
+			},
+		},
+	})
+	require.Error(t, err)
+	duplicateErr, ok := err.(*apierrors.DuplicateContainerNameError)
+	require.True(t, ok, "Expected a DuplicateContainerNameError, got %v", err)
+	assert.Equal(t, "web", duplicateErr.ContainerName)
+	assert.False(t, duplicateErr.Retry(), "Duplicate container names should not be retried")
+}
+
+// TestSubmitTaskStateChangeDuplicateContainerNamesWithoutContainer tests that
+// changes with the same container name are rejected when they do not hold the
+// container they belong to
+func TestSubmitTaskStateChangeDuplicateContainerNamesWithoutContainer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// SubmitTaskStateChange is not expected to be invoked on the SDK client
+	client, _, _ := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskRunning,
+		Containers: []api.ContainerStateChange{
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerRunning,
+			},
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerRunning,
+			},
+		},
+	})
+	require.Error(t, err)
+	duplicateErr, ok := err.(*apierrors.DuplicateContainerNameError)
+	require.True(t, ok, "Expected a DuplicateContainerNameError, got %v", err)
+	assert.Equal(t, "web", duplicateErr.ContainerName)
+}
+
+// TestSubmitTaskStateChangeBatchedContainerTransitions tests that a task state
+// change holding several transitions of the same container is submitted
+func TestSubmitTaskStateChangeBatchedContainerTransitions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mockSubmitStateClient.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(input *ecs.SubmitTaskStateChangeInput) {
+		assert.Equal(t, "STOPPED", aws.StringValue(input.Status))
+		require.Len(t, input.Containers, 2)
+		assert.Equal(t, "web", aws.StringValue(input.Containers[0].ContainerName))
+		assert.Equal(t, "RUNNING", aws.StringValue(input.Containers[0].Status))
+		assert.Equal(t, "web", aws.StringValue(input.Containers[1].ContainerName))
+		assert.Equal(t, "STOPPED", aws.StringValue(input.Containers[1].Status))
+	})
+
+	web := &apicontainer.Container{Name: "web"}
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskStopped,
+		Containers: []api.ContainerStateChange{
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerRunning,
+				Container:     web,
+			},
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "web",
+				Status:        apicontainerstatus.ContainerStopped,
+				Container:     web,
+			},
+		},
+	})
+	assert.NoError(t, err, "Unable to submit batched container transitions")
+}
+
 // TestSubmitContainerStateChangeWhileTaskInPending tests the container state change was submitted
 // when the task is still in pending state
 func TestSubmitContainerStateChangeWhileTaskInPending(t *testing.T) {
@@ -16,13 +16,20 @@ package ecsclient
 import (
 	"math"
 	"math/rand"
+	"net/http"
+	"strconv"
 	"time"
 
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cihub/seelog"
 )
 
 const (
@@ -43,8 +50,143 @@ const (
 	// 24 hours ~= 12 minutes + (n * 5 minutes)
 	// n ~= 285
 	submitStateChangeExtraRetries = 285
+
+	// API calls are retried with a widely jittered backoff so that instances
+	// booting together spread out their retries
+	apiRetryBackoffJitter     = 0.5
+	apiRetryBackoffMultiplier = 2
 )
 
+// retryAPICall invokes an ECS API call, retrying it with capped exponential
+// backoff and jitter while it is throttled or fails with a transient error.
+// The delay requested by the server with the Retry-After header is honored.
+// The categorized error of the last attempt is returned
+func (client *APIECSClient) retryAPICall(name string, call func() error) error {
+	attempts := client.config.ECSAPIRetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := &retryAfterBackoff{
+		Backoff: retry.NewExponentialBackoff(client.config.ECSAPIRetryMinBackoff, client.config.ECSAPIRetryMaxBackoff,
+			apiRetryBackoffJitter, apiRetryBackoffMultiplier),
+	}
+	var err error
+	retry.RetryNWithBackoff(backoff, attempts, func() error {
+		err = apierrors.NewAPIError(call(), 0)
+		if err == nil {
+			return nil
+		}
+		retriable := apierrors.IsRetriableAPIError(err)
+		if retriable {
+			seelog.Warnf("%s failed with a retriable error: %v", name, err)
+			if apiErr, ok := err.(*apierrors.APIError); ok {
+				backoff.retryAfter = apiErr.RetryAfter
+			}
+		}
+		return apierrors.NewRetriableError(apierrors.NewRetriable(retriable), err)
+	})
+	return err
+}
+
+// retryAfterBackoff waits for at least the delay requested by the server
+// before the next retry, if any
+type retryAfterBackoff struct {
+	retry.Backoff
+	retryAfter time.Duration
+}
+
+// Duration returns the larger of the backoff duration and the delay requested
+// by the server for the last failed attempt
+func (backoff *retryAfterBackoff) Duration() time.Duration {
+	duration := backoff.Backoff.Duration()
+	if backoff.retryAfter > duration {
+		duration = backoff.retryAfter
+	}
+	backoff.retryAfter = 0
+	return duration
+}
+
+// categorizeError is a request handler that categorizes the error of a failed
+// request, retaining the delay requested by the server with the Retry-After
+// header
+func categorizeError(r *request.Request) {
+	if r.Error == nil {
+		return
+	}
+	var retryAfter time.Duration
+	if r.HTTPResponse != nil {
+		retryAfter = parseRetryAfter(r.HTTPResponse.Header.Get("Retry-After"), time.Now())
+	}
+	r.Error = apierrors.NewAPIError(r.Error, retryAfter)
+}
+
+// recordAPICallMetric records the duration and the outcome of an ECS API call,
+// including its retries, once it has completed
+func recordAPICallMetric(r *request.Request) {
+	if r.Operation == nil {
+		return
+	}
+	metrics.MetricsEngineGlobal.RecordECSClientCall(r.Operation.Name, time.Since(r.Time), r.Error)
+}
+
+// traceAPICall records a span of an ECS API call, including its retries, once
+// it has completed. State changes are traced as part of the trace of their task
+func traceAPICall(r *request.Request) {
+	if r.Operation == nil {
+		return
+	}
+	taskARN := ""
+	switch input := r.Params.(type) {
+	case *ecs.SubmitTaskStateChangeInput:
+		taskARN = aws.StringValue(input.Task)
+	case *ecs.SubmitContainerStateChangeInput:
+		taskARN = aws.StringValue(input.Task)
+	}
+	span := tracing.StartClientSpanAt(taskARN, r.Operation.Name, r.Time)
+	span.SetAttribute("rpc.system", "aws-api")
+	span.SetAttribute("rpc.service", r.ClientInfo.ServiceName)
+	span.SetAttribute("rpc.method", r.Operation.Name)
+	span.SetAttribute("aws.request_id", r.RequestID)
+	if taskARN != "" {
+		span.SetAttribute("aws.ecs.task.arn", taskARN)
+	}
+	span.End(r.Error)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date. Zero is returned when the value is
+// missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
+
+// newStandardClient returns a client intended to be used for the ECS APIs other
+// than Submit*StateChange. Its calls are retried by retryAPICall or by their
+// caller, so the SDK does not retry them on its own, which would multiply the
+// attempts
+func newStandardClient(awsConfig *aws.Config) *ecs.ECS {
+	standardConfig := awsConfig.Copy()
+	standardConfig.MaxRetries = aws.Int(0)
+	client := ecs.New(session.New(standardConfig))
+	client.Handlers.UnmarshalError.PushBack(categorizeError)
+	client.Handlers.Complete.PushBack(recordAPICallMetric)
+	client.Handlers.Complete.PushBack(traceAPICall)
+	return client
+}
+
 // newSubmitStateChangeClient returns a client intended to be used for
 // Submit*StateChange APIs which has the behavior of retrying the call on
 // retriable errors for an extended period of time (roughly 24 hours).
@@ -52,6 +194,9 @@ func newSubmitStateChangeClient(awsConfig *aws.Config) *ecs.ECS {
 	sscConfig := awsConfig.Copy()
 	sscConfig.Retryer = &oneDayRetrier{}
 	client := ecs.New(session.New(sscConfig))
+	client.Handlers.UnmarshalError.PushBack(categorizeError)
+	client.Handlers.Complete.PushBack(recordAPICallMetric)
+	client.Handlers.Complete.PushBack(traceAPICall)
 	return client
 }
 
@@ -73,8 +218,17 @@ func (retrier *oneDayRetrier) MaxRetries() int {
 // instance of a retry. For the first 14 requests, it follows an exponential
 // backoff between 30ms and 1 minute.
 // See the const comments for math on how this gets us to around 24 hours
-// total.
+// total. A longer delay requested by the server with the Retry-After header is
+// honored.
 func (retrier *oneDayRetrier) RetryRules(r *request.Request) time.Duration {
+	delay := retrier.backoffDelay(r)
+	if apiErr, ok := r.Error.(*apierrors.APIError); ok && apiErr.RetryAfter > delay {
+		return apiErr.RetryAfter
+	}
+	return delay
+}
+
+func (retrier *oneDayRetrier) backoffDelay(r *request.Request) time.Duration {
 	// This logic is the same as the default retrier, but duplicated here such
 	// that upstream changes do not invalidate the math done above.
 	if r.RetryCount <= submitStateChangeInitialRetries {
@@ -21,9 +21,15 @@ import (
 	"testing"
 	"time"
 
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestOneDayRetrier(t *testing.T) {
@@ -55,3 +61,117 @@ func TestOneDayRetrier(t *testing.T) {
 		t.Errorf("Expected accumulated retry delay to be roughly 24 hours; was %v", totalDelay)
 	}
 }
+
+func TestStandardClientDoesNotRetry(t *testing.T) {
+	standardClient := newStandardClient(defaults.Config())
+	assert.Equal(t, 0, standardClient.Retryer.MaxRetries())
+
+	request, _ := standardClient.PutAttributesRequest(&ecs.PutAttributesInput{})
+	request.Error = awserr.NewRequestFailure(awserr.New("ServerException", "error", nil), 500, "")
+	request.HTTPResponse = &http.Response{StatusCode: 500}
+	assert.False(t, request.WillRetry(), "Expected the SDK to leave retries to retryAPICall")
+}
+
+func TestPutAttributesRetriesServerErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, testAPIRetryConfig())
+	serverErr := awserr.NewRequestFailure(awserr.New("ServerException", "error", nil), 500, "")
+	gomock.InOrder(
+		mc.EXPECT().PutAttributes(gomock.Any()).Return(nil, serverErr).Times(testAPIRetryAttempts-1),
+		mc.EXPECT().PutAttributes(gomock.Any()).Return(&ecs.PutAttributesOutput{}, nil),
+	)
+
+	assert.NoError(t, client.PutAttributes("containerInstance", []*ecs.Attribute{{Name: aws.String("name")}}))
+}
+
+func TestPutAttributesRetriesUpToAttempts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, testAPIRetryConfig())
+	mc.EXPECT().PutAttributes(gomock.Any()).Return(nil,
+		awserr.New("ThrottlingException", "Rate exceeded", nil)).Times(testAPIRetryAttempts)
+
+	assert.Error(t, client.PutAttributes("containerInstance", []*ecs.Attribute{{Name: aws.String("name")}}))
+}
+
+func TestPutAttributesDoesNotRetryClientErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, testAPIRetryConfig())
+	mc.EXPECT().PutAttributes(gomock.Any()).Return(nil,
+		awserr.NewRequestFailure(awserr.New("ClientException", "error", nil), 400, ""))
+
+	assert.Error(t, client.PutAttributes("containerInstance", []*ecs.Attribute{{Name: aws.String("name")}}))
+}
+
+func TestPutAttributesDoesNotRetryValidationErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil, testAPIRetryConfig())
+	mc.EXPECT().PutAttributes(gomock.Any()).Return(nil,
+		awserr.NewRequestFailure(awserr.New(ecs.ErrCodeInvalidParameterException, "error", nil), 400, ""))
+
+	err := client.PutAttributes("containerInstance", []*ecs.Attribute{{Name: aws.String("name")}})
+	apiErr, ok := err.(*apierrors.APIError)
+	assert.True(t, ok, "expected an APIError, got %v", err)
+	assert.Equal(t, apierrors.APIErrorValidation, apiErr.Kind)
+	assert.False(t, apiErr.Retry())
+}
+
+func TestRetryAfterBackoff(t *testing.T) {
+	backoff := &retryAfterBackoff{
+		Backoff: retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1),
+	}
+	assert.Equal(t, time.Millisecond, backoff.Duration())
+
+	backoff.retryAfter = time.Second
+	assert.Equal(t, time.Second, backoff.Duration())
+	// The requested delay only applies to the next retry
+	assert.Equal(t, time.Millisecond, backoff.Duration())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, time.June, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"negative seconds", "-5", 0},
+		{"http date", now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{"past http date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"invalid", "soon", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseRetryAfter(tc.value, now))
+		})
+	}
+}
+
+func TestCategorizeErrorRetainsRetryAfter(t *testing.T) {
+	stateChangeClient := newSubmitStateChangeClient(defaults.Config())
+	request, _ := stateChangeClient.SubmitContainerStateChangeRequest(&ecs.SubmitContainerStateChangeInput{})
+	request.Error = awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "")
+	request.HTTPResponse = &http.Response{
+		StatusCode: 400,
+		Header:     http.Header{"Retry-After": []string{"120"}},
+	}
+
+	categorizeError(request)
+	apiErr, ok := request.Error.(*apierrors.APIError)
+	assert.True(t, ok, "expected an APIError, got %v", request.Error)
+	assert.Equal(t, apierrors.APIErrorThrottle, apiErr.Kind)
+	assert.Equal(t, 2*time.Minute, apiErr.RetryAfter)
+
+	// The retrier waits for at least the requested delay
+	request.RetryCount = 0
+	assert.Equal(t, 2*time.Minute, stateChangeClient.Retryer.RetryRules(request))
+}
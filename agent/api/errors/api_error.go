@@ -0,0 +1,183 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// APIErrorKind categorizes the errors returned by the ECS APIs
+type APIErrorKind int
+
+const (
+	// APIErrorUnknown is used for the errors that fit no other category
+	APIErrorUnknown APIErrorKind = iota
+	// APIErrorThrottle is used when the request was throttled
+	APIErrorThrottle
+	// APIErrorAuth is used when the request was not authenticated or not
+	// authorized
+	APIErrorAuth
+	// APIErrorValidation is used when the request was rejected as invalid,
+	// sending it again cannot succeed
+	APIErrorValidation
+	// APIErrorTransient is used for network failures and server errors
+	APIErrorTransient
+)
+
+func (kind APIErrorKind) String() string {
+	switch kind {
+	case APIErrorThrottle:
+		return "throttle"
+	case APIErrorAuth:
+		return "auth"
+	case APIErrorValidation:
+		return "validation"
+	case APIErrorTransient:
+		return "transient"
+	default:
+		return "unknown"
+	}
+}
+
+// authErrorCodes are the error codes returned for requests that could not be
+// authenticated or authorized
+var authErrorCodes = map[string]struct{}{
+	ecs.ErrCodeAccessDeniedException: {},
+	"UnrecognizedClientException":    {},
+	"InvalidSignatureException":      {},
+	"IncompleteSignature":            {},
+	"MissingAuthenticationToken":     {},
+	"ExpiredTokenException":          {},
+	"InvalidClientTokenId":           {},
+}
+
+// validationErrorCodes are the error codes returned for requests that are
+// invalid
+var validationErrorCodes = map[string]struct{}{
+	ecs.ErrCodeInvalidParameterException: {},
+	"ValidationException":                {},
+	"SerializationException":             {},
+}
+
+// APIError is an ECS API error with its category. It implements
+// awserr.RequestFailure, retaining the code, message and status code of the
+// underlying AWS error
+type APIError struct {
+	err awserr.Error
+	// Kind is the category of the error
+	Kind APIErrorKind
+	// RetryAfter is the delay before retrying requested by the server with the
+	// Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+// Error returns the error string of the underlying AWS error
+func (err *APIError) Error() string {
+	return err.err.Error()
+}
+
+// Code returns the error code of the underlying AWS error
+func (err *APIError) Code() string {
+	return err.err.Code()
+}
+
+// Message returns the error message of the underlying AWS error
+func (err *APIError) Message() string {
+	return err.err.Message()
+}
+
+// OrigErr returns the original error of the underlying AWS error
+func (err *APIError) OrigErr() error {
+	return err.err.OrigErr()
+}
+
+// StatusCode returns the HTTP status code of the response, or 0 if the
+// request did not get a response
+func (err *APIError) StatusCode() int {
+	if requestFailure, ok := err.err.(awserr.RequestFailure); ok {
+		return requestFailure.StatusCode()
+	}
+	return 0
+}
+
+// RequestID returns the request id of the failed request, if any
+func (err *APIError) RequestID() string {
+	if requestFailure, ok := err.err.(awserr.RequestFailure); ok {
+		return requestFailure.RequestID()
+	}
+	return ""
+}
+
+// Retry returns false for the errors that cannot succeed when retried
+func (err *APIError) Retry() bool {
+	return err.Kind != APIErrorValidation
+}
+
+// NewAPIError categorizes an error returned by an ECS API call. Errors that
+// are not AWS errors are returned as is
+func NewAPIError(err error, retryAfter time.Duration) error {
+	if apiErr, ok := err.(*APIError); ok {
+		if retryAfter > apiErr.RetryAfter {
+			apiErr.RetryAfter = retryAfter
+		}
+		return apiErr
+	}
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	return &APIError{
+		err:        awsErr,
+		Kind:       apiErrorKind(awsErr),
+		RetryAfter: retryAfter,
+	}
+}
+
+// IsRetriableAPIError returns true for throttling errors and transient errors,
+// which may succeed when the call is made again later
+func IsRetriableAPIError(err error) bool {
+	apiErr, ok := NewAPIError(err, 0).(*APIError)
+	if !ok {
+		return false
+	}
+	return apiErr.Kind == APIErrorThrottle || apiErr.Kind == APIErrorTransient
+}
+
+func apiErrorKind(err awserr.Error) APIErrorKind {
+	statusCode := 0
+	if requestFailure, ok := err.(awserr.RequestFailure); ok {
+		statusCode = requestFailure.StatusCode()
+	}
+	if _, ok := authErrorCodes[err.Code()]; ok {
+		return APIErrorAuth
+	}
+	if _, ok := validationErrorCodes[err.Code()]; ok {
+		return APIErrorValidation
+	}
+	switch {
+	case request.IsErrorThrottle(err) || statusCode == http.StatusTooManyRequests:
+		return APIErrorThrottle
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return APIErrorAuth
+	case statusCode >= http.StatusInternalServerError || request.IsErrorRetryable(err):
+		return APIErrorTransient
+	default:
+		return APIErrorUnknown
+	}
+}
@@ -0,0 +1,90 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIErrorKind(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		kind APIErrorKind
+	}{
+		{"throttling", awserr.New("ThrottlingException", "Rate exceeded", nil), APIErrorThrottle},
+		{"too many requests", awserr.NewRequestFailure(awserr.New("TooManyRequests", "error", nil), 429, ""), APIErrorThrottle},
+		{"access denied", awserr.NewRequestFailure(awserr.New(ecs.ErrCodeAccessDeniedException, "error", nil), 400, ""), APIErrorAuth},
+		{"forbidden", awserr.NewRequestFailure(awserr.New("Forbidden", "error", nil), 403, ""), APIErrorAuth},
+		{"invalid parameter", awserr.NewRequestFailure(awserr.New(ecs.ErrCodeInvalidParameterException, "error", nil), 400, ""), APIErrorValidation},
+		{"server error", awserr.NewRequestFailure(awserr.New(ecs.ErrCodeServerException, "error", nil), 500, ""), APIErrorTransient},
+		{"network error", awserr.New("RequestError", "send request failed", errors.New("connection reset")), APIErrorTransient},
+		{"client error", awserr.NewRequestFailure(awserr.New(ecs.ErrCodeClientException, "error", nil), 400, ""), APIErrorUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr, ok := NewAPIError(tc.err, 0).(*APIError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.kind, apiErr.Kind)
+			assert.Equal(t, tc.kind != APIErrorValidation, apiErr.Retry())
+			assert.Equal(t, tc.err.Error(), apiErr.Error())
+		})
+	}
+}
+
+func TestNewAPIErrorRetainsAWSError(t *testing.T) {
+	err := NewAPIError(awserr.NewRequestFailure(awserr.New(ecs.ErrCodeServerException, "error", nil), 500, "id"), time.Second)
+
+	awsErr, ok := err.(awserr.RequestFailure)
+	assert.True(t, ok)
+	assert.Equal(t, ecs.ErrCodeServerException, awsErr.Code())
+	assert.Equal(t, 500, awsErr.StatusCode())
+	assert.Equal(t, "id", awsErr.RequestID())
+
+	// Categorizing the error again keeps the longest requested delay
+	assert.Equal(t, 2*time.Second, NewAPIError(NewAPIError(err, 2*time.Second), 0).(*APIError).RetryAfter)
+}
+
+func TestNewAPIErrorNotAWSError(t *testing.T) {
+	err := errors.New("error")
+	assert.Equal(t, err, NewAPIError(err, time.Second))
+	assert.Nil(t, NewAPIError(nil, time.Second))
+}
+
+func TestIsRetriableAPIError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retriable bool
+	}{
+		{"throttling", awserr.New("ThrottlingException", "Rate exceeded", nil), true},
+		{"server error", awserr.NewRequestFailure(awserr.New("ServerException", "error", nil), 500, ""), true},
+		{"service unavailable", awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "error", nil), 503, ""), true},
+		{"client error", awserr.NewRequestFailure(awserr.New("ClientException", "error", nil), 400, ""), false},
+		{"other error", errors.New("error"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retriable, IsRetriableAPIError(tc.err))
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 )
 
@@ -36,6 +37,29 @@ func IsInstanceTypeChangedError(err error) bool {
 	return false
 }
 
+// invalidInstanceErrorCode is the error code returned when the container
+// instance referenced in a request is not known to ECS
+const invalidInstanceErrorCode = "InvalidInstanceException"
+
+// inactiveInstanceErrorMessage is contained in the message of the client
+// error returned when re-registering a container instance that was
+// deregistered
+const inactiveInstanceErrorMessage = "inactive"
+
+// IsInvalidInstanceError returns true if the error when re-registering the
+// container instance is because the instance is no longer registered with ECS
+func IsInvalidInstanceError(err error) bool {
+	if awserr, ok := err.(awserr.Error); ok {
+		switch awserr.Code() {
+		case invalidInstanceErrorCode:
+			return true
+		case ecs.ErrCodeClientException:
+			return strings.Contains(strings.ToLower(awserr.Message()), inactiveInstanceErrorMessage)
+		}
+	}
+	return false
+}
+
 func IsClusterNotFoundError(err error) bool {
 	if awserr, ok := err.(awserr.Error); ok {
 		return strings.Contains(awserr.Message(), ClusterNotFoundErrorMessage)
@@ -56,6 +80,23 @@ func (err *BadVolumeError) ErrorName() string { return "InvalidVolumeError" }
 // Retry implements Retirable interface
 func (err *BadVolumeError) Retry() bool { return false }
 
+// DuplicateContainerNameError represents an error caused by a task state change
+// that reports more than one container with the same name
+type DuplicateContainerNameError struct {
+	TaskARN       string
+	ContainerName string
+}
+
+func (err *DuplicateContainerNameError) Error() string {
+	return fmt.Sprintf("task %s has more than one container named %q", err.TaskARN, err.ContainerName)
+}
+
+// ErrorName returns name of the DuplicateContainerNameError
+func (err *DuplicateContainerNameError) ErrorName() string { return "DuplicateContainerNameError" }
+
+// Retry implements Retirable interface
+func (err *DuplicateContainerNameError) Retry() bool { return false }
+
 // DefaultNamedError is a wrapper type for 'error' which adds an optional name and provides a symmetric
 // marshal/unmarshal
 type DefaultNamedError struct {
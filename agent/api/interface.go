@@ -42,6 +42,18 @@ type ECSClient interface {
 	DiscoverTelemetryEndpoint(containerInstanceArn string) (string, error)
 	// GetTaskTags retrieves the Tags associated with a certain Task
 	GetResourceTags(resourceArn string) ([]*ecs.Tag, error)
+	// PutAttributes creates or updates the given attributes on the container
+	// instance
+	PutAttributes(containerInstanceArn string, attributes []*ecs.Attribute) error
+	// DeleteAttributes removes the given attributes from the container
+	// instance
+	DeleteAttributes(containerInstanceArn string, attributes []*ecs.Attribute) error
+	// UpdateContainerInstanceState changes the status of the container
+	// instance, such as to DRAINING
+	UpdateContainerInstanceState(containerInstanceArn string, status string) error
+	// DeregisterContainerInstance deregisters the container instance from the
+	// cluster. Forcing it deregisters the instance even if tasks are running
+	DeregisterContainerInstance(containerInstanceArn string, force bool) error
 }
 
 // ECSSDK is an interface that specifies the subset of the AWS Go SDK's ECS
@@ -49,9 +61,14 @@ type ECSClient interface {
 // mock for testing.
 type ECSSDK interface {
 	CreateCluster(*ecs.CreateClusterInput) (*ecs.CreateClusterOutput, error)
+	DescribeClusters(*ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error)
 	RegisterContainerInstance(*ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error)
 	DiscoverPollEndpoint(*ecs.DiscoverPollEndpointInput) (*ecs.DiscoverPollEndpointOutput, error)
 	ListTagsForResource(*ecs.ListTagsForResourceInput) (*ecs.ListTagsForResourceOutput, error)
+	PutAttributes(*ecs.PutAttributesInput) (*ecs.PutAttributesOutput, error)
+	DeleteAttributes(*ecs.DeleteAttributesInput) (*ecs.DeleteAttributesOutput, error)
+	UpdateContainerInstancesState(*ecs.UpdateContainerInstancesStateInput) (*ecs.UpdateContainerInstancesStateOutput, error)
+	DeregisterContainerInstance(*ecs.DeregisterContainerInstanceInput) (*ecs.DeregisterContainerInstanceOutput, error)
 }
 
 // ECSSubmitStateSDK is an interface with customized ecs client that
@@ -61,6 +61,45 @@ func (mr *MockECSSDKMockRecorder) CreateCluster(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCluster", reflect.TypeOf((*MockECSSDK)(nil).CreateCluster), arg0)
 }
 
+// DeleteAttributes mocks base method
+func (m *MockECSSDK) DeleteAttributes(arg0 *ecs.DeleteAttributesInput) (*ecs.DeleteAttributesOutput, error) {
+	ret := m.ctrl.Call(m, "DeleteAttributes", arg0)
+	ret0, _ := ret[0].(*ecs.DeleteAttributesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAttributes indicates an expected call of DeleteAttributes
+func (mr *MockECSSDKMockRecorder) DeleteAttributes(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttributes", reflect.TypeOf((*MockECSSDK)(nil).DeleteAttributes), arg0)
+}
+
+// DeregisterContainerInstance mocks base method
+func (m *MockECSSDK) DeregisterContainerInstance(arg0 *ecs.DeregisterContainerInstanceInput) (*ecs.DeregisterContainerInstanceOutput, error) {
+	ret := m.ctrl.Call(m, "DeregisterContainerInstance", arg0)
+	ret0, _ := ret[0].(*ecs.DeregisterContainerInstanceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeregisterContainerInstance indicates an expected call of DeregisterContainerInstance
+func (mr *MockECSSDKMockRecorder) DeregisterContainerInstance(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterContainerInstance", reflect.TypeOf((*MockECSSDK)(nil).DeregisterContainerInstance), arg0)
+}
+
+// DescribeClusters mocks base method
+func (m *MockECSSDK) DescribeClusters(arg0 *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error) {
+	ret := m.ctrl.Call(m, "DescribeClusters", arg0)
+	ret0, _ := ret[0].(*ecs.DescribeClustersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeClusters indicates an expected call of DescribeClusters
+func (mr *MockECSSDKMockRecorder) DescribeClusters(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeClusters", reflect.TypeOf((*MockECSSDK)(nil).DescribeClusters), arg0)
+}
+
 // DiscoverPollEndpoint mocks base method
 func (m *MockECSSDK) DiscoverPollEndpoint(arg0 *ecs.DiscoverPollEndpointInput) (*ecs.DiscoverPollEndpointOutput, error) {
 	ret := m.ctrl.Call(m, "DiscoverPollEndpoint", arg0)
@@ -87,6 +126,19 @@ func (mr *MockECSSDKMockRecorder) ListTagsForResource(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsForResource", reflect.TypeOf((*MockECSSDK)(nil).ListTagsForResource), arg0)
 }
 
+// PutAttributes mocks base method
+func (m *MockECSSDK) PutAttributes(arg0 *ecs.PutAttributesInput) (*ecs.PutAttributesOutput, error) {
+	ret := m.ctrl.Call(m, "PutAttributes", arg0)
+	ret0, _ := ret[0].(*ecs.PutAttributesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutAttributes indicates an expected call of PutAttributes
+func (mr *MockECSSDKMockRecorder) PutAttributes(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAttributes", reflect.TypeOf((*MockECSSDK)(nil).PutAttributes), arg0)
+}
+
 // RegisterContainerInstance mocks base method
 func (m *MockECSSDK) RegisterContainerInstance(arg0 *ecs.RegisterContainerInstanceInput) (*ecs.RegisterContainerInstanceOutput, error) {
 	ret := m.ctrl.Call(m, "RegisterContainerInstance", arg0)
@@ -100,6 +152,19 @@ func (mr *MockECSSDKMockRecorder) RegisterContainerInstance(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterContainerInstance", reflect.TypeOf((*MockECSSDK)(nil).RegisterContainerInstance), arg0)
 }
 
+// UpdateContainerInstancesState mocks base method
+func (m *MockECSSDK) UpdateContainerInstancesState(arg0 *ecs.UpdateContainerInstancesStateInput) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	ret := m.ctrl.Call(m, "UpdateContainerInstancesState", arg0)
+	ret0, _ := ret[0].(*ecs.UpdateContainerInstancesStateOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateContainerInstancesState indicates an expected call of UpdateContainerInstancesState
+func (mr *MockECSSDKMockRecorder) UpdateContainerInstancesState(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContainerInstancesState", reflect.TypeOf((*MockECSSDK)(nil).UpdateContainerInstancesState), arg0)
+}
+
 // MockECSSubmitStateSDK is a mock of ECSSubmitStateSDK interface
 type MockECSSubmitStateSDK struct {
 	ctrl     *gomock.Controller
@@ -172,6 +237,30 @@ func (m *MockECSClient) EXPECT() *MockECSClientMockRecorder {
 	return m.recorder
 }
 
+// DeleteAttributes mocks base method
+func (m *MockECSClient) DeleteAttributes(arg0 string, arg1 []*ecs.Attribute) error {
+	ret := m.ctrl.Call(m, "DeleteAttributes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttributes indicates an expected call of DeleteAttributes
+func (mr *MockECSClientMockRecorder) DeleteAttributes(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttributes", reflect.TypeOf((*MockECSClient)(nil).DeleteAttributes), arg0, arg1)
+}
+
+// DeregisterContainerInstance mocks base method
+func (m *MockECSClient) DeregisterContainerInstance(arg0 string, arg1 bool) error {
+	ret := m.ctrl.Call(m, "DeregisterContainerInstance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeregisterContainerInstance indicates an expected call of DeregisterContainerInstance
+func (mr *MockECSClientMockRecorder) DeregisterContainerInstance(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterContainerInstance", reflect.TypeOf((*MockECSClient)(nil).DeregisterContainerInstance), arg0, arg1)
+}
+
 // DiscoverPollEndpoint mocks base method
 func (m *MockECSClient) DiscoverPollEndpoint(arg0 string) (string, error) {
 	ret := m.ctrl.Call(m, "DiscoverPollEndpoint", arg0)
@@ -211,6 +300,18 @@ func (mr *MockECSClientMockRecorder) GetResourceTags(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResourceTags", reflect.TypeOf((*MockECSClient)(nil).GetResourceTags), arg0)
 }
 
+// PutAttributes mocks base method
+func (m *MockECSClient) PutAttributes(arg0 string, arg1 []*ecs.Attribute) error {
+	ret := m.ctrl.Call(m, "PutAttributes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutAttributes indicates an expected call of PutAttributes
+func (mr *MockECSClientMockRecorder) PutAttributes(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAttributes", reflect.TypeOf((*MockECSClient)(nil).PutAttributes), arg0, arg1)
+}
+
 // RegisterContainerInstance mocks base method
 func (m *MockECSClient) RegisterContainerInstance(arg0 string, arg1 []*ecs.Attribute, arg2 []*ecs.Tag, arg3 string, arg4 []*ecs.PlatformDevice) (string, string, error) {
 	ret := m.ctrl.Call(m, "RegisterContainerInstance", arg0, arg1, arg2, arg3, arg4)
@@ -248,3 +349,15 @@ func (m *MockECSClient) SubmitTaskStateChange(arg0 api.TaskStateChange) error {
 func (mr *MockECSClientMockRecorder) SubmitTaskStateChange(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitTaskStateChange", reflect.TypeOf((*MockECSClient)(nil).SubmitTaskStateChange), arg0)
 }
+
+// UpdateContainerInstanceState mocks base method
+func (m *MockECSClient) UpdateContainerInstanceState(arg0, arg1 string) error {
+	ret := m.ctrl.Call(m, "UpdateContainerInstanceState", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContainerInstanceState indicates an expected call of UpdateContainerInstanceState
+func (mr *MockECSClientMockRecorder) UpdateContainerInstanceState(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContainerInstanceState", reflect.TypeOf((*MockECSClient)(nil).UpdateContainerInstanceState), arg0, arg1)
+}
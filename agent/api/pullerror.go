@@ -0,0 +1,106 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package api
+
+import (
+	"strings"
+
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+)
+
+// PullErrorCode categorizes why a container image could not be pulled
+type PullErrorCode string
+
+const (
+	// PullErrorNone is used when the container did not fail to pull its image
+	PullErrorNone PullErrorCode = ""
+	// PullErrorRegistryAuth is used when the registry rejected the credentials
+	// or the credentials could not be retrieved
+	PullErrorRegistryAuth PullErrorCode = "RegistryAuthFailure"
+	// PullErrorManifestNotFound is used when the image or tag does not exist
+	PullErrorManifestNotFound PullErrorCode = "ManifestNotFound"
+	// PullErrorNetworkTimeout is used when the pull timed out
+	PullErrorNetworkTimeout PullErrorCode = "NetworkTimeout"
+	// PullErrorUnknown is used for any other pull failure
+	PullErrorUnknown PullErrorCode = "Unknown"
+)
+
+// Names of the pull errors returned by the docker client
+const (
+	cannotPullContainerErrorName     = "CannotPullContainerError"
+	cannotPullContainerAuthErrorName = "CannotPullContainerAuthError"
+	cannotPullECRContainerErrorName  = "CannotPullECRContainerError"
+	dockerTimeoutErrorName           = "DockerTimeoutError"
+)
+
+var (
+	registryAuthErrorMessages = []string{
+		"unauthorized",
+		"authentication required",
+		"access denied",
+		"no basic auth credentials",
+	}
+	manifestNotFoundErrorMessages = []string{
+		"manifest unknown",
+		"not found",
+	}
+	networkTimeoutErrorMessages = []string{
+		"timeout",
+		"timed out",
+	}
+)
+
+// PullErrorCodeFromError returns the category of the image pull failure
+// described by the container's applying error, or PullErrorNone if the error
+// is not a pull failure
+func PullErrorCodeFromError(err *apierrors.DefaultNamedError) PullErrorCode {
+	if err == nil {
+		return PullErrorNone
+	}
+	message := strings.ToLower(err.Err)
+	switch err.Name {
+	case cannotPullContainerAuthErrorName, cannotPullECRContainerErrorName:
+		return PullErrorRegistryAuth
+	case dockerTimeoutErrorName:
+		// Docker timeouts are reported for every transition, only the
+		// ones for pulling are pull failures
+		if strings.Contains(message, "pull") {
+			return PullErrorNetworkTimeout
+		}
+		return PullErrorNone
+	case cannotPullContainerErrorName:
+	default:
+		return PullErrorNone
+	}
+
+	switch {
+	case containsAny(message, registryAuthErrorMessages):
+		return PullErrorRegistryAuth
+	case containsAny(message, manifestNotFoundErrorMessages):
+		return PullErrorManifestNotFound
+	case containsAny(message, networkTimeoutErrorMessages):
+		return PullErrorNetworkTimeout
+	default:
+		return PullErrorUnknown
+	}
+}
+
+func containsAny(message string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -46,6 +46,9 @@ type ContainerStateChange struct {
 	// PortBindings are the details of the host ports picked for the specified
 	// container ports
 	PortBindings []apicontainer.PortBinding
+	// PullError categorizes the image pull failure of a stopped container, if
+	// the container stopped because its image could not be pulled
+	PullError PullErrorCode
 
 	// Container is a pointer to the container involved in the state change that gives the event handler a hook into
 	// storing what status was sent.  This is used to ensure the same event is handled only once.
@@ -65,6 +68,9 @@ type TaskStateChange struct {
 	Reason string
 	// Containers holds the events generated by containers owned by this task
 	Containers []ContainerStateChange
+	// ManagedAgents holds the status changes of the agents run by the ECS
+	// agent in the containers of this task
+	ManagedAgents []ManagedAgentStateChange
 
 	// PullStartedAt is the timestamp when the task start pulling
 	PullStartedAt *time.Time
@@ -78,6 +84,25 @@ type TaskStateChange struct {
 	Task *apitask.Task
 }
 
+// ManagedAgentStateChange represents a status change of an agent run by the
+// ECS agent in a container, sent along with a task state change
+type ManagedAgentStateChange struct {
+	// TaskArn is the unique identifier for the task
+	TaskArn string
+	// ContainerName is the name of the container the agent runs in
+	ContainerName string
+	// Name is the name of the managed agent
+	Name string
+	// Status is the status of the managed agent to send
+	Status apicontainerstatus.ContainerStatus
+	// Reason may contain details of why the agent failed to start or stopped
+	Reason string
+
+	// Container is a pointer to the container the agent runs in, used to
+	// record the status sent for the agent
+	Container *apicontainer.Container
+}
+
 // NewTaskStateChangeEvent creates a new task state change event
 func NewTaskStateChangeEvent(task *apitask.Task, reason string) (TaskStateChange, error) {
 	var event TaskStateChange
@@ -94,10 +119,11 @@ func NewTaskStateChangeEvent(task *apitask.Task, reason string) (TaskStateChange
 	}
 
 	event = TaskStateChange{
-		TaskARN: task.Arn,
-		Status:  taskKnownStatus,
-		Reason:  reason,
-		Task:    task,
+		TaskARN:       task.Arn,
+		Status:        taskKnownStatus,
+		Reason:        reason,
+		Task:          task,
+		ManagedAgents: managedAgentStateChanges(task),
 	}
 
 	event.SetTaskTimestamps()
@@ -105,6 +131,57 @@ func NewTaskStateChangeEvent(task *apitask.Task, reason string) (TaskStateChange
 	return event, nil
 }
 
+// NewManagedAgentChangeEvent creates a task state change event reporting the
+// status changes of the managed agents of the task's containers that have not
+// been sent yet, while the task itself stays in its known status
+func NewManagedAgentChangeEvent(task *apitask.Task) (TaskStateChange, error) {
+	var event TaskStateChange
+	taskKnownStatus := task.GetKnownStatus()
+	if !taskKnownStatus.BackendRecognized() || taskKnownStatus.Terminal() {
+		return event, errors.Errorf(
+			"create managed agent state change event api: task status not recognized by ECS or terminal: %v",
+			taskKnownStatus)
+	}
+	managedAgents := managedAgentStateChanges(task)
+	if len(managedAgents) == 0 {
+		return event, errors.Errorf(
+			"create managed agent state change event api: no managed agent changes to send for task %s",
+			task.Arn)
+	}
+
+	event = TaskStateChange{
+		TaskARN:       task.Arn,
+		Status:        taskKnownStatus,
+		Task:          task,
+		ManagedAgents: managedAgents,
+	}
+	event.SetTaskTimestamps()
+	return event, nil
+}
+
+// managedAgentStateChanges returns the status changes of the managed agents
+// of the task's containers that have not been sent yet
+func managedAgentStateChanges(task *apitask.Task) []ManagedAgentStateChange {
+	var changes []ManagedAgentStateChange
+	for _, container := range task.Containers {
+		for _, managedAgent := range container.GetManagedAgents() {
+			if managedAgent.Status == apicontainerstatus.ContainerStatusNone ||
+				managedAgent.Status == managedAgent.SentStatus {
+				continue
+			}
+			changes = append(changes, ManagedAgentStateChange{
+				TaskArn:       task.Arn,
+				ContainerName: container.Name,
+				Name:          managedAgent.Name,
+				Status:        managedAgent.Status,
+				Reason:        managedAgent.Reason,
+				Container:     container,
+			})
+		}
+	}
+	return changes
+}
+
 // NewContainerStateChangeEvent creates a new container state change event
 func NewContainerStateChangeEvent(task *apitask.Task, cont *apicontainer.Container, reason string) (ContainerStateChange, error) {
 	var event ContainerStateChange
@@ -137,6 +214,9 @@ func NewContainerStateChangeEvent(task *apitask.Task, cont *apicontainer.Contain
 		Reason:        reason,
 		Container:     cont,
 	}
+	if event.Status == apicontainerstatus.ContainerStopped {
+		event.PullError = PullErrorCodeFromError(cont.ApplyingError)
+	}
 
 	return event, nil
 }
@@ -150,6 +230,9 @@ func (c *ContainerStateChange) String() string {
 	if c.Reason != "" {
 		res += ", Reason " + c.Reason
 	}
+	if c.PullError != PullErrorNone {
+		res += ", PullError " + string(c.PullError)
+	}
 	if len(c.PortBindings) != 0 {
 		res += fmt.Sprintf(", Ports %v", c.PortBindings)
 	}
@@ -189,7 +272,7 @@ func (change *TaskStateChange) ShouldBeReported() bool {
 		return true
 	}
 
-	if len(change.Containers) != 0 {
+	if len(change.Containers) != 0 || len(change.ManagedAgents) != 0 {
 		return true
 	}
 
@@ -212,7 +295,19 @@ func (change *TaskStateChange) String() string {
 	for _, containerChange := range change.Containers {
 		res += ", " + containerChange.String()
 	}
+	for _, managedAgentChange := range change.ManagedAgents {
+		res += ", " + managedAgentChange.String()
+	}
+
+	return res
+}
 
+// String returns a human readable string representation of this object
+func (change *ManagedAgentStateChange) String() string {
+	res := fmt.Sprintf("%s %s %s -> %s", change.TaskArn, change.ContainerName, change.Name, change.Status.String())
+	if change.Reason != "" {
+		res += ", Reason " + change.Reason
+	}
 	return res
 }
 
@@ -20,9 +20,13 @@ import (
 	"testing"
 	"time"
 
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShouldBeReported(t *testing.T) {
@@ -83,3 +87,158 @@ func TestSetTaskTimestamps(t *testing.T) {
 	assert.Equal(t, t2.UTC().String(), change.PullStoppedAt.String())
 	assert.Equal(t, t3.UTC().String(), change.ExecutionStoppedAt.String())
 }
+
+func TestPullErrorCodeFromError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      *apierrors.DefaultNamedError
+		expected PullErrorCode
+	}{
+		{
+			name:     "no error",
+			expected: PullErrorNone,
+		},
+		{
+			name: "registry auth failure",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerError",
+				Err:  "Error response from daemon: pull access denied for private/image, repository does not exist or may require 'docker login'",
+			},
+			expected: PullErrorRegistryAuth,
+		},
+		{
+			name: "registry unauthorized",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerError",
+				Err:  "Error response from daemon: Get https://registry/v2/image/manifests/latest: unauthorized: authentication required",
+			},
+			expected: PullErrorRegistryAuth,
+		},
+		{
+			name: "ecr auth failure",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullECRContainerError",
+				Err:  "AccessDeniedException: User is not authorized to perform: ecr:GetAuthorizationToken",
+			},
+			expected: PullErrorRegistryAuth,
+		},
+		{
+			name: "asm auth failure",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerAuthError",
+				Err:  "missing credentials",
+			},
+			expected: PullErrorRegistryAuth,
+		},
+		{
+			name: "manifest unknown",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerError",
+				Err:  "Error response from daemon: manifest for busybox:missing not found: manifest unknown",
+			},
+			expected: PullErrorManifestNotFound,
+		},
+		{
+			name: "network timeout",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerError",
+				Err:  "Error response from daemon: Get https://registry-1.docker.io/v2/: net/http: TLS handshake timeout",
+			},
+			expected: PullErrorNetworkTimeout,
+		},
+		{
+			name: "docker pull timeout",
+			err: &apierrors.DefaultNamedError{
+				Name: "DockerTimeoutError",
+				Err:  "Could not transition to pulled; timed out after waiting 2h0m0s",
+			},
+			expected: PullErrorNetworkTimeout,
+		},
+		{
+			name: "docker start timeout",
+			err: &apierrors.DefaultNamedError{
+				Name: "DockerTimeoutError",
+				Err:  "Could not transition to started; timed out after waiting 3m0s",
+			},
+			expected: PullErrorNone,
+		},
+		{
+			name: "unknown pull error",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotPullContainerError",
+				Err:  "Error response from daemon: no space left on device",
+			},
+			expected: PullErrorUnknown,
+		},
+		{
+			name: "not a pull error",
+			err: &apierrors.DefaultNamedError{
+				Name: "CannotStartContainerError",
+				Err:  "image not found",
+			},
+			expected: PullErrorNone,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, PullErrorCodeFromError(tc.err))
+		})
+	}
+}
+
+func TestNewContainerStateChangeEventPullError(t *testing.T) {
+	task := &apitask.Task{Arn: "arn"}
+	container := &apicontainer.Container{
+		Name: "web",
+		ApplyingError: &apierrors.DefaultNamedError{
+			Name: "CannotPullContainerError",
+			Err:  "manifest for busybox:missing not found: manifest unknown",
+		},
+	}
+	container.SetKnownStatus(apicontainerstatus.ContainerStopped)
+
+	event, err := NewContainerStateChangeEvent(task, container, "")
+	require.NoError(t, err)
+	assert.Equal(t, PullErrorManifestNotFound, event.PullError)
+}
+
+func TestNewManagedAgentChangeEvent(t *testing.T) {
+	container := &apicontainer.Container{
+		Name: "app",
+		ManagedAgentsUnsafe: []apicontainer.ManagedAgent{
+			{
+				Name: apicontainer.ExecuteCommandAgentName,
+				ManagedAgentState: apicontainer.ManagedAgentState{
+					Status: apicontainerstatus.ContainerRunning,
+				},
+			},
+		},
+	}
+	task := &apitask.Task{
+		Arn:               "taskarn",
+		Containers:        []*apicontainer.Container{container},
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+	}
+
+	event, err := NewManagedAgentChangeEvent(task)
+	require.NoError(t, err)
+	require.Len(t, event.ManagedAgents, 1)
+	assert.Equal(t, apitaskstatus.TaskRunning, event.Status)
+	assert.Equal(t, "app", event.ManagedAgents[0].ContainerName)
+	assert.Equal(t, apicontainer.ExecuteCommandAgentName, event.ManagedAgents[0].Name)
+	assert.Equal(t, apicontainerstatus.ContainerRunning, event.ManagedAgents[0].Status)
+	assert.True(t, event.ShouldBeReported())
+
+	// once sent, the status of the agent is not reported again
+	container.UpdateManagedAgentSentStatus(apicontainer.ExecuteCommandAgentName, apicontainerstatus.ContainerRunning)
+	_, err = NewManagedAgentChangeEvent(task)
+	assert.Error(t, err)
+
+	task.SetKnownStatus(apitaskstatus.TaskStopped)
+	container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, apicontainer.ManagedAgentState{
+		Status: apicontainerstatus.ContainerStopped,
+	})
+	_, err = NewManagedAgentChangeEvent(task)
+	assert.Error(t, err)
+}
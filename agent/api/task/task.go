@@ -38,6 +38,9 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/envfiles"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	resourcetype "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
@@ -79,6 +82,8 @@ const (
 	// dockerMappingContainerPrefix specifies the prefix string used for setting the
 	// container's option (network, ipc, or pid) to that of another existing container
 	dockerMappingContainerPrefix = "container:"
+	// networkSysctlPrefix is the prefix of the sysctls of the network namespace
+	networkSysctlPrefix = "net."
 
 	// awslogsCredsEndpointOpt is the awslogs option that is used to pass in an
 	// http endpoint for authentication
@@ -242,6 +247,10 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 	// TODO, add rudimentary plugin support and call any plugins that want to
 	// hook into this
 	task.adjustForPlatform(cfg)
+	if err := task.validateHostNamespaces(cfg); err != nil {
+		seelog.Errorf("Task [%s]: %v", task.Arn, err)
+		return err
+	}
 	if task.MemoryCPULimitsEnabled {
 		err := task.initializeCgroupResourceSpec(cfg.CgroupPath, resourceFields)
 		if err != nil {
@@ -262,6 +271,22 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		task.initializeASMSecretResource(credentialsManager, resourceFields)
 	}
 
+	if task.requiresEnvFiles() {
+		err := task.initializeEnvFilesResource(cfg, credentialsManager, resourceFields)
+		if err != nil {
+			seelog.Errorf("Task [%s]: could not initialize environment file resource: %v", task.Arn, err)
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
+	if task.requiresFirelens() {
+		err := task.initializeFirelensResource(cfg)
+		if err != nil {
+			seelog.Errorf("Task [%s]: could not initialize firelens resource: %v", task.Arn, err)
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
 	err := task.initializeDockerLocalVolumes(dockerClient, ctx)
 	if err != nil {
 		return apierrors.NewResourceInitError(task.Arn, err)
@@ -270,6 +295,11 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 	if err != nil {
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
+	err = task.initializeEFSVolumes(cfg)
+	if err != nil {
+		seelog.Errorf("Task [%s]: could not initialize efs volumes: %v", task.Arn, err)
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
 	if cfg.GPUSupportEnabled {
 		err = task.addGPUResource()
 		if err != nil {
@@ -492,6 +522,37 @@ func (task *Task) addSharedVolumes(SharedVolumeMatchFullConfig bool, ctx context
 	return nil
 }
 
+// initializeEFSVolumes adds an efs resource for every efs volume of the task,
+// which mounts the file system on the host before the containers using the
+// volume are created
+func (task *Task) initializeEFSVolumes(cfg *config.Config) error {
+	for _, vol := range task.Volumes {
+		if vol.Type != EFSVolumeType {
+			continue
+		}
+
+		efsVolume, ok := vol.Volume.(*taskresourcevolume.EFSVolumeConfig)
+		if !ok {
+			return errors.New("task volume: volume configuration does not match the type 'efs'")
+		}
+		var credentialsRelativeURI string
+		if credentialsID := task.GetCredentialsID(); credentialsID != "" {
+			roleCredentials := &credentials.IAMRoleCredentials{CredentialsID: credentialsID}
+			credentialsRelativeURI = roleCredentials.GenerateCredentialsEndpointRelativeURI()
+		}
+		efsResource, err := efs.NewEFSResource(task.Arn, vol.Name, efsVolume, credentialsRelativeURI,
+			cfg.DataDir, cfg.DataDirOnHost)
+		if err != nil {
+			return err
+		}
+
+		efsVolume.HostPath = efsResource.HostPath()
+		task.AddResource(resourcetype.EFSKey, efsResource)
+		task.updateContainerVolumeDependency(vol.Name)
+	}
+	return nil
+}
+
 // updateContainerVolumeDependency adds the volume resource to container dependency
 func (task *Task) updateContainerVolumeDependency(name string) {
 	// Find all the container that depends on the volume
@@ -613,6 +674,45 @@ func (task *Task) initializeSSMSecretResource(credentialsManager credentials.Man
 	}
 }
 
+// requiresEnvFiles returns true if at least one container in the task has
+// environment files
+func (task *Task) requiresEnvFiles() bool {
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithEnvFiles() {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeEnvFilesResource adds the resource downloading the environment
+// files of the task's containers with the task role, which the containers
+// with environment files wait for before they are created
+func (task *Task) initializeEnvFilesResource(cfg *config.Config, credentialsManager credentials.Manager,
+	resourceFields *taskresource.ResourceFields) error {
+	envFiles := make(map[string][]apicontainer.EnvironmentFile)
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithEnvFiles() {
+			envFiles[container.Name] = container.EnvironmentFiles
+		}
+	}
+	envFileResource, err := envfiles.NewEnvironmentFileResource(task.Arn, cfg.AWSRegion, envFiles,
+		task.GetCredentialsID(), credentialsManager, resourceFields.S3ClientCreator, cfg.DataDir)
+	if err != nil {
+		return err
+	}
+	task.AddResource(envfiles.ResourceName, envFileResource)
+
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithEnvFiles() {
+			container.BuildResourceDependency(envFileResource.GetName(),
+				resourcestatus.ResourceStatus(envfiles.EnvFileCreated),
+				apicontainerstatus.ContainerCreated)
+		}
+	}
+	return nil
+}
+
 // getAllSSMSecretRequirements stores all secrets in a map whose key is region and value is all
 // secrets in that region
 func (task *Task) getAllSSMSecretRequirements() map[string][]apicontainer.Secret {
@@ -632,6 +732,98 @@ func (task *Task) getAllSSMSecretRequirements() map[string][]apicontainer.Secret
 	return reqs
 }
 
+// requiresFirelens returns true if a container of the task is a FireLens log
+// router
+func (task *Task) requiresFirelens() bool {
+	_, ok := task.firelensContainer()
+	return ok
+}
+
+// firelensContainer returns the FireLens log router container of the task
+func (task *Task) firelensContainer() (*apicontainer.Container, bool) {
+	for _, container := range task.Containers {
+		if container.FirelensConfig != nil {
+			return container, true
+		}
+	}
+	return nil, false
+}
+
+// initializeFirelensResource builds the resource that generates the config of
+// the FireLens log router, and makes the containers logging to the router
+// start after it and stop before it
+func (task *Task) initializeFirelensResource(cfg *config.Config) error {
+	firelensContainer, _ := task.firelensContainer()
+	containerToLogOptions := make(map[string]map[string]string)
+	var loggingContainers []*apicontainer.Container
+	for _, container := range task.Containers {
+		logConfig, err := container.GetLogConfig()
+		if err != nil {
+			return errors.Wrapf(err, "unable to decode host config of container %s", container.Name)
+		}
+		if logConfig.Type != apicontainer.FirelensLogDriver {
+			continue
+		}
+		containerToLogOptions[container.Name] = logConfig.Config
+		loggingContainers = append(loggingContainers, container)
+	}
+
+	firelensResource, err := firelens.NewFirelensResource(cfg.Cluster, task.Arn, task.Family+":"+task.Version,
+		firelensContainer.FirelensConfig.Type, firelensContainer.FirelensConfig.Options,
+		containerToLogOptions, cfg.DataDir, cfg.DataDirOnHost)
+	if err != nil {
+		return err
+	}
+	task.AddResource(firelens.ResourceName, firelensResource)
+
+	firelensContainer.BuildResourceDependency(firelensResource.GetName(),
+		resourcestatus.ResourceStatus(firelens.FirelensCreated),
+		apicontainerstatus.ContainerCreated)
+	for _, container := range loggingContainers {
+		if container == firelensContainer {
+			continue
+		}
+		container.BuildContainerDependency(firelensContainer.Name, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerCreated)
+		firelensContainer.BuildContainerDependency(container.Name, apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerStopped)
+	}
+	return nil
+}
+
+// getFirelensResource retrieves the firelens resource from the resource map
+func (task *Task) getFirelensResource() (*firelens.FirelensResource, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	res, ok := task.ResourcesMapUnsafe[firelens.ResourceName]
+	if !ok || len(res) == 0 {
+		return nil, false
+	}
+	firelensResource, ok := res[0].(*firelens.FirelensResource)
+	return firelensResource, ok
+}
+
+// applyFirelensSetup mounts the generated config and socket directory to the
+// FireLens log router container, and points the containers using the
+// awsfirelens log driver at the socket of the router
+func (task *Task) applyFirelensSetup(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	firelensResource, ok := task.getFirelensResource()
+	if !ok {
+		if hostConfig.LogConfig.Type == apicontainer.FirelensLogDriver {
+			return errors.Errorf("container %s uses the %s log driver but the task has no log router",
+				container.Name, apicontainer.FirelensLogDriver)
+		}
+		return nil
+	}
+
+	if container.FirelensConfig != nil {
+		hostConfig.Binds = append(hostConfig.Binds, firelensResource.LogRouterBinds()...)
+	}
+	if hostConfig.LogConfig.Type == apicontainer.FirelensLogDriver {
+		hostConfig.LogConfig = firelensResource.LogConfig(container.Name)
+	}
+	return nil
+}
+
 // requiresASMSecret returns true if at least one container in the task
 // needs to retrieve secret from AWS Secrets Manager
 func (task *Task) requiresASMSecret() bool {
@@ -875,8 +1067,27 @@ func (task *Task) DockerConfig(container *apicontainer.Container, apiVersion doc
 }
 
 func (task *Task) dockerConfig(container *apicontainer.Container, apiVersion dockerclient.DockerVersion) (*dockercontainer.Config, *apierrors.DockerClientConfigError) {
-	dockerEnv := make([]string, 0, len(container.Environment))
+	secretEnv := container.GetSecretEnvironment()
+	envFileEnv := container.GetEnvFileEnvironment()
+	dockerEnv := make([]string, 0, len(container.Environment)+len(secretEnv)+len(envFileEnv))
+	for envKey, envVal := range envFileEnv {
+		if _, ok := container.Environment[envKey]; ok {
+			// env vars take precedence over the env files
+			continue
+		}
+		if _, ok := secretEnv[envKey]; ok {
+			continue
+		}
+		dockerEnv = append(dockerEnv, envKey+"="+envVal)
+	}
 	for envKey, envVal := range container.Environment {
+		if _, ok := secretEnv[envKey]; ok {
+			// secrets take precedence over env vars of the same name
+			continue
+		}
+		dockerEnv = append(dockerEnv, envKey+"="+envVal)
+	}
+	for envKey, envVal := range secretEnv {
 		dockerEnv = append(dockerEnv, envKey+"="+envVal)
 	}
 
@@ -891,6 +1102,8 @@ func (task *Task) dockerConfig(container *apicontainer.Container, apiVersion doc
 		Entrypoint:   entryPoint,
 		ExposedPorts: task.dockerExposedPorts(container),
 		Env:          dockerEnv,
+		Tty:          container.PseudoTerminal,
+		OpenStdin:    container.Interactive,
 	}
 
 	if container.DockerConfig.Config != nil {
@@ -980,6 +1193,7 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		PortBindings: dockerPortMap,
 		VolumesFrom:  volumesFrom,
 		Resources:    resources,
+		Sysctls:      task.dockerSysctls(container),
 	}
 
 	if task.shouldRequireNvidiaRuntime(container) {
@@ -997,11 +1211,21 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		}
 	}
 
+	err = task.applyLinuxParameters(container, hostConfig)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{err.Error()}
+	}
+
 	err = task.platformHostConfigOverride(hostConfig)
 	if err != nil {
 		return nil, &apierrors.HostConfigError{err.Error()}
 	}
 
+	err = task.applyFirelensSetup(container, hostConfig)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{err.Error()}
+	}
+
 	// Determine if network mode should be overridden and override it if needed
 	ok, networkMode := task.shouldOverrideNetworkMode(container, dockerContainerMap)
 	if ok {
@@ -1032,6 +1256,39 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 	return hostConfig, nil
 }
 
+// dockerSysctls returns the sysctls of the container's system controls. The
+// containers of a task with a pause container join the network namespace of
+// the pause container, so their network sysctls are set on it instead
+func (task *Task) dockerSysctls(container *apicontainer.Container) map[string]string {
+	sysctls := make(map[string]string)
+	if container.Type == apicontainer.ContainerCNIPause {
+		for _, taskContainer := range task.Containers {
+			for _, systemControl := range taskContainer.SystemControls {
+				if isNetworkSysctl(systemControl.Namespace) {
+					sysctls[systemControl.Namespace] = systemControl.Value
+				}
+			}
+		}
+	} else {
+		joinsPauseNetwork := task.GetTaskENI() != nil
+		for _, systemControl := range container.SystemControls {
+			if joinsPauseNetwork && isNetworkSysctl(systemControl.Namespace) {
+				continue
+			}
+			sysctls[systemControl.Namespace] = systemControl.Value
+		}
+	}
+
+	if len(sysctls) == 0 {
+		return nil
+	}
+	return sysctls
+}
+
+func isNetworkSysctl(name string) bool {
+	return strings.HasPrefix(name, networkSysctlPrefix)
+}
+
 // Requires an *apicontainer.Container and returns the Resources for the HostConfig struct
 func (task *Task) getDockerResources(container *apicontainer.Container) dockercontainer.Resources {
 	// Convert MB to B and set Memory
@@ -1737,7 +1994,9 @@ func (task *Task) getSSMSecretsResource() ([]taskresource.TaskResource, bool) {
 	return res, ok
 }
 
-// PopulateSecretsAsEnv appends the container's env var map with secrets
+// PopulateSecretsAsEnv sets the container's secret env vars from the values
+// fetched by the secret resources. They are passed to docker along with the
+// container's environment, but are not part of it so that they are not saved
 func (task *Task) PopulateSecretsAsEnv(container *apicontainer.Container) *apierrors.DockerClientConfigError {
 	var ssmRes *ssmsecret.SSMSecretResource
 	var asmRes *asmsecret.ASMSecretResource
@@ -1776,10 +2035,35 @@ func (task *Task) PopulateSecretsAsEnv(container *apicontainer.Container) *apier
 		}
 	}
 
-	container.MergeEnvironmentVariables(envVars)
+	container.SetSecretEnvironment(envVars)
 	return nil
 }
 
+// PopulateEnvFilesAsEnv reads the variables of the container's environment
+// files, downloaded by the environment file resource, which are added to the
+// env of the docker config when the container is created
+func (task *Task) PopulateEnvFilesAsEnv(container *apicontainer.Container) *apierrors.DockerClientConfigError {
+	resource, ok := task.getEnvFilesResource()
+	if !ok {
+		return &apierrors.DockerClientConfigError{"task environment files: unable to fetch environment file resource"}
+	}
+	envVarsList, err := resource[0].(*envfiles.EnvironmentFileResource).ReadEnvVarsFromEnvFiles(container.Name)
+	if err != nil {
+		return &apierrors.DockerClientConfigError{err.Error()}
+	}
+	container.SetEnvFileEnvironment(envVarsList)
+	return nil
+}
+
+// getEnvFilesResource retrieves the environment file resource from resource map
+func (task *Task) getEnvFilesResource() ([]taskresource.TaskResource, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	res, ok := task.ResourcesMapUnsafe[envfiles.ResourceName]
+	return res, ok
+}
+
 // getASMSecretsResource retrieves asmsecret resource from resource map
 func (task *Task) getASMSecretsResource() ([]taskresource.TaskResource, bool) {
 	task.lock.RLock()
@@ -1811,6 +2095,18 @@ func (task *Task) getPIDMode() string {
 	return task.PIDMode
 }
 
+// validateHostNamespaces fails if the task shares the host's PID or IPC
+// namespace while the agent is configured to disallow it
+func (task *Task) validateHostNamespaces(cfg *config.Config) error {
+	if cfg.HostPIDDisabled && task.getPIDMode() == pidModeHost {
+		return errors.New("sharing the host's PID namespace is disallowed on this container instance")
+	}
+	if cfg.HostIPCDisabled && task.getIPCMode() == ipcModeHost {
+		return errors.New("sharing the host's IPC namespace is disallowed on this container instance")
+	}
+	return nil
+}
+
 // Retrieves a Task's IPCMode
 func (task *Task) getIPCMode() string {
 	task.lock.RLock()
@@ -16,9 +16,12 @@
 package task
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -40,8 +43,19 @@ const (
 
 	minimumCPUPercent = 0
 	bytesPerMegabyte  = 1024 * 1024
+
+	minimumSwappiness = 0
+	maximumSwappiness = 100
 )
 
+// devicePermissionFlags maps the device permissions of the linux parameters to
+// the cgroup permission flags docker expects
+var devicePermissionFlags = map[string]string{
+	"read":  "r",
+	"write": "w",
+	"mknod": "m",
+}
+
 // PlatformFields consists of fields specific to Linux for a task
 type PlatformFields struct{}
 
@@ -189,6 +203,109 @@ func (task *Task) platformHostConfigOverride(hostConfig *dockercontainer.HostCon
 	return task.overrideCgroupParent(hostConfig)
 }
 
+// applyLinuxParameters sets the kernel capabilities, devices, tmpfs mounts,
+// shared memory size, swap and init process of the container's linux
+// parameters on its host config
+func (task *Task) applyLinuxParameters(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	params := container.LinuxParameters
+	if params == nil {
+		return nil
+	}
+
+	if params.Capabilities != nil {
+		hostConfig.CapAdd = append(hostConfig.CapAdd, params.Capabilities.Add...)
+		hostConfig.CapDrop = append(hostConfig.CapDrop, params.Capabilities.Drop...)
+	}
+
+	for _, device := range params.Devices {
+		deviceMapping, err := dockerDeviceMapping(device)
+		if err != nil {
+			return err
+		}
+		hostConfig.Devices = append(hostConfig.Devices, deviceMapping)
+	}
+
+	for _, tmpfs := range params.Tmpfs {
+		if tmpfs.ContainerPath == "" || tmpfs.Size <= 0 {
+			return errors.Errorf("linux parameters: tmpfs mount requires a container path and a positive size")
+		}
+		if hostConfig.Tmpfs == nil {
+			hostConfig.Tmpfs = make(map[string]string)
+		}
+		options := append([]string{fmt.Sprintf("size=%dm", tmpfs.Size)}, tmpfs.MountOptions...)
+		hostConfig.Tmpfs[tmpfs.ContainerPath] = strings.Join(options, ",")
+	}
+
+	if params.SharedMemorySize != nil {
+		if *params.SharedMemorySize <= 0 {
+			return errors.Errorf("linux parameters: invalid shared memory size %d", *params.SharedMemorySize)
+		}
+		hostConfig.ShmSize = *params.SharedMemorySize * bytesPerMegabyte
+	}
+
+	if params.InitProcessEnabled != nil {
+		initProcessEnabled := *params.InitProcessEnabled
+		hostConfig.Init = &initProcessEnabled
+	}
+
+	return applySwapParameters(params, hostConfig)
+}
+
+// applySwapParameters sets the memory swap and swappiness of the container.
+// Docker expects the memory swap as the total of the memory and swap limits,
+// so a swap limit is only possible for a container with a memory limit
+func applySwapParameters(params *apicontainer.LinuxParameters, hostConfig *dockercontainer.HostConfig) error {
+	if params.MaxSwap != nil {
+		if *params.MaxSwap < 0 {
+			return errors.Errorf("linux parameters: invalid max swap %d", *params.MaxSwap)
+		}
+		if hostConfig.Memory == 0 {
+			return errors.New("linux parameters: max swap requires a container memory limit")
+		}
+		hostConfig.MemorySwap = hostConfig.Memory + *params.MaxSwap*bytesPerMegabyte
+	}
+
+	if params.Swappiness != nil {
+		if *params.Swappiness < minimumSwappiness || *params.Swappiness > maximumSwappiness {
+			return errors.Errorf("linux parameters: invalid swappiness %d, expected a value between %d and %d",
+				*params.Swappiness, minimumSwappiness, maximumSwappiness)
+		}
+		swappiness := *params.Swappiness
+		hostConfig.MemorySwappiness = &swappiness
+	}
+	return nil
+}
+
+// dockerDeviceMapping converts a device of the linux parameters to a docker
+// device mapping. The device is mounted at its host path in the container when
+// no container path is given, with all permissions when none is given
+func dockerDeviceMapping(device apicontainer.Device) (dockercontainer.DeviceMapping, error) {
+	if device.HostPath == "" {
+		return dockercontainer.DeviceMapping{}, errors.New("linux parameters: device requires a host path")
+	}
+	containerPath := device.ContainerPath
+	if containerPath == "" {
+		containerPath = device.HostPath
+	}
+	permissions := "rwm"
+	if len(device.Permissions) > 0 {
+		permissions = ""
+		for _, permission := range device.Permissions {
+			flag, ok := devicePermissionFlags[permission]
+			if !ok {
+				return dockercontainer.DeviceMapping{}, errors.Errorf(
+					"linux parameters: invalid permission %q of device %s", permission, device.HostPath)
+			}
+			permissions += flag
+		}
+	}
+	return dockercontainer.DeviceMapping{
+		PathOnHost:        device.HostPath,
+		PathInContainer:   containerPath,
+		CgroupPermissions: permissions,
+	}, nil
+}
+
 // overrideCgroupParent updates hostconfig with cgroup parent when task cgroups
 // are enabled
 func (task *Task) overrideCgroupParent(hostConfig *dockercontainer.HostConfig) error {
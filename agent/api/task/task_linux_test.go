@@ -64,7 +64,7 @@ func TestAddNetworkResourceProvisioningDependencyWithENI(t *testing.T) {
 		ENI: &apieni.ENI{},
 		Containers: []*apicontainer.Container{
 			{
-				Name: "c1",
+				Name:                      "c1",
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
@@ -350,6 +350,149 @@ func TestDockerHostConfigRawConfigMerging(t *testing.T) {
 	assertSetStructFieldsEqual(t, expected, *hostConfig)
 }
 
+func TestDockerHostConfigLinuxParameters(t *testing.T) {
+	sharedMemorySize := int64(128)
+	testTask := &Task{
+		Arn: validTaskArn,
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				LinuxParameters: &apicontainer.LinuxParameters{
+					Capabilities: &apicontainer.KernelCapabilities{
+						Add:  []string{"SYS_PTRACE"},
+						Drop: []string{"MKNOD"},
+					},
+					Devices: []apicontainer.Device{
+						{HostPath: "/dev/fuse"},
+						{HostPath: "/dev/sda", ContainerPath: "/dev/xvda", Permissions: []string{"read", "mknod"}},
+					},
+					SharedMemorySize: &sharedMemorySize,
+					Tmpfs: []apicontainer.Tmpfs{
+						{ContainerPath: "/run", Size: 64, MountOptions: []string{"rw", "noexec"}},
+					},
+				},
+				DockerConfig: apicontainer.DockerConfig{
+					HostConfig: strptr(`{"CapAdd":["NET_ADMIN"]}`),
+				},
+			},
+		},
+	}
+
+	hostConfig, configErr := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), minDockerClientAPIVersion)
+	require.Nil(t, configErr)
+	assert.Equal(t, []string{"NET_ADMIN", "SYS_PTRACE"}, []string(hostConfig.CapAdd))
+	assert.Equal(t, []string{"MKNOD"}, []string(hostConfig.CapDrop))
+	assert.Equal(t, []dockercontainer.DeviceMapping{
+		{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"},
+		{PathOnHost: "/dev/sda", PathInContainer: "/dev/xvda", CgroupPermissions: "rm"},
+	}, hostConfig.Devices)
+	assert.Equal(t, map[string]string{"/run": "size=64m,rw,noexec"}, hostConfig.Tmpfs)
+	assert.Equal(t, int64(128*1024*1024), hostConfig.ShmSize)
+}
+
+func TestDockerHostConfigInitProcessEnabled(t *testing.T) {
+	initProcessEnabled := true
+	testTask := &Task{
+		Arn: validTaskArn,
+		Containers: []*apicontainer.Container{
+			{
+				Name:            "c1",
+				LinuxParameters: &apicontainer.LinuxParameters{InitProcessEnabled: &initProcessEnabled},
+			},
+			{
+				Name: "c2",
+			},
+		},
+	}
+
+	hostConfig, configErr := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), minDockerClientAPIVersion)
+	require.Nil(t, configErr)
+	require.NotNil(t, hostConfig.Init)
+	assert.True(t, *hostConfig.Init)
+
+	hostConfig, configErr = testTask.DockerHostConfig(testTask.Containers[1], dockerMap(testTask), minDockerClientAPIVersion)
+	require.Nil(t, configErr)
+	assert.Nil(t, hostConfig.Init)
+}
+
+func TestDockerHostConfigSwapParameters(t *testing.T) {
+	maxSwap := int64(256)
+	swappiness := int64(10)
+	testTask := &Task{
+		Arn: validTaskArn,
+		Containers: []*apicontainer.Container{
+			{
+				Name:   "c1",
+				Memory: 512,
+				LinuxParameters: &apicontainer.LinuxParameters{
+					MaxSwap:    &maxSwap,
+					Swappiness: &swappiness,
+				},
+			},
+		},
+	}
+
+	hostConfig, configErr := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), minDockerClientAPIVersion)
+	require.Nil(t, configErr)
+	assert.Equal(t, int64((512+256)*1024*1024), hostConfig.MemorySwap)
+	require.NotNil(t, hostConfig.MemorySwappiness)
+	assert.Equal(t, swappiness, *hostConfig.MemorySwappiness)
+
+	// A max swap of 0 keeps the container from swapping
+	maxSwap = 0
+	hostConfig, configErr = testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), minDockerClientAPIVersion)
+	require.Nil(t, configErr)
+	assert.Equal(t, hostConfig.Memory, hostConfig.MemorySwap)
+}
+
+func TestDockerHostConfigInvalidLinuxParameters(t *testing.T) {
+	invalidSharedMemorySize := int64(-1)
+	maxSwap := int64(256)
+	invalidSwappiness := int64(101)
+	testCases := []struct {
+		name   string
+		params *apicontainer.LinuxParameters
+	}{
+		{
+			name:   "device without host path",
+			params: &apicontainer.LinuxParameters{Devices: []apicontainer.Device{{ContainerPath: "/dev/fuse"}}},
+		},
+		{
+			name: "invalid device permission",
+			params: &apicontainer.LinuxParameters{
+				Devices: []apicontainer.Device{{HostPath: "/dev/fuse", Permissions: []string{"execute"}}},
+			},
+		},
+		{
+			name:   "tmpfs without size",
+			params: &apicontainer.LinuxParameters{Tmpfs: []apicontainer.Tmpfs{{ContainerPath: "/run"}}},
+		},
+		{
+			name:   "negative shared memory size",
+			params: &apicontainer.LinuxParameters{SharedMemorySize: &invalidSharedMemorySize},
+		},
+		{
+			name:   "max swap without memory limit",
+			params: &apicontainer.LinuxParameters{MaxSwap: &maxSwap},
+		},
+		{
+			name:   "swappiness out of range",
+			params: &apicontainer.LinuxParameters{Swappiness: &invalidSwappiness},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTask := &Task{
+				Arn:        validTaskArn,
+				Containers: []*apicontainer.Container{{Name: "c1", LinuxParameters: tc.params}},
+			}
+			_, configErr := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), minDockerClientAPIVersion)
+			assert.NotNil(t, configErr)
+		})
+	}
+}
+
 func TestInitCgroupResourceSpecHappyPath(t *testing.T) {
 	taskMemoryLimit := int64(taskMemoryLimit)
 	task := &Task{
@@ -358,7 +501,7 @@ func TestInitCgroupResourceSpecHappyPath(t *testing.T) {
 		Memory: taskMemoryLimit,
 		Containers: []*apicontainer.Container{
 			{
-				Name: "c1",
+				Name:                      "c1",
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
@@ -386,7 +529,7 @@ func TestInitCgroupResourceSpecInvalidARN(t *testing.T) {
 		Version: "1",
 		Containers: []*apicontainer.Container{
 			{
-				Name: "c1",
+				Name:                      "c1",
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
@@ -406,8 +549,8 @@ func TestInitCgroupResourceSpecInvalidMem(t *testing.T) {
 		Memory: taskMemoryLimit,
 		Containers: []*apicontainer.Container{
 			{
-				Name:   "C1",
-				Memory: uint(2048), // container memory > task memory
+				Name:                      "C1",
+				Memory:                    uint(2048), // container memory > task memory
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
@@ -426,7 +569,7 @@ func TestPostUnmarshalWithCPULimitsFail(t *testing.T) {
 		Version: "1",
 		Containers: []*apicontainer.Container{
 			{
-				Name: "c1",
+				Name:                      "c1",
 				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
@@ -47,6 +47,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/envfiles"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/docker/docker/api/types"
@@ -100,6 +101,31 @@ func TestDockerConfigPortBinding(t *testing.T) {
 	}
 }
 
+func TestDockerConfigPseudoTerminalAndInteractive(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:           "c1",
+				PseudoTerminal: true,
+				Interactive:    true,
+			},
+			{
+				Name: "c2",
+			},
+		},
+	}
+
+	config, err := testTask.DockerConfig(testTask.Containers[0], defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.True(t, config.Tty)
+	assert.True(t, config.OpenStdin)
+
+	config, err = testTask.DockerConfig(testTask.Containers[1], defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.False(t, config.Tty)
+	assert.False(t, config.OpenStdin)
+}
+
 func TestDockerHostConfigCPUShareZero(t *testing.T) {
 	testTask := &Task{
 		Containers: []*apicontainer.Container{
@@ -268,6 +294,54 @@ func TestDockerHostConfigRawConfig(t *testing.T) {
 	assertSetStructFieldsEqual(t, expectedOutput, *config)
 }
 
+func TestDockerHostConfigSysctls(t *testing.T) {
+	systemControls := []apicontainer.SystemControl{
+		{Namespace: "net.core.somaxconn", Value: "1024"},
+		{Namespace: "kernel.shmmax", Value: "68719476736"},
+	}
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:           "c1",
+				SystemControls: systemControls,
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"net.core.somaxconn": "1024",
+		"kernel.shmmax":      "68719476736",
+	}, config.Sysctls)
+
+	// The network sysctls of a task with a pause container are set on the
+	// pause container, whose network namespace the other containers join
+	testTask = &Task{
+		ENI: &apieni.ENI{
+			ID: "eniID",
+		},
+		Containers: []*apicontainer.Container{
+			{
+				Name:           "c1",
+				SystemControls: systemControls,
+			},
+			{
+				Name: NetworkPauseContainerName,
+				Type: apicontainer.ContainerCNIPause,
+			},
+		},
+	}
+
+	config, err = testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"kernel.shmmax": "68719476736"}, config.Sysctls)
+
+	config, err = testTask.DockerHostConfig(testTask.Containers[1], dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"net.core.somaxconn": "1024"}, config.Sysctls)
+}
+
 func TestDockerHostConfigPauseContainer(t *testing.T) {
 	testTask := &Task{
 		ENI: &apieni.ENI{
@@ -1503,6 +1577,125 @@ func TestTaskGetENI(t *testing.T) {
 }
 
 // TestTaskFromACSWithOverrides tests the container command is overridden correctly
+func TestTaskFromACSWithDependsOn(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("app"),
+				DependsOn: []*ecsacs.ContainerDependency{
+					{
+						ContainerName: strptr("init"),
+						Condition:     strptr("SUCCESS"),
+					},
+				},
+			},
+			{
+				Name: strptr("init"),
+			},
+		},
+	}
+
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.Nil(t, err, "Should be able to handle acs task")
+	assert.Equal(t, []apicontainer.DependsOn{{ContainerName: "init", Condition: apicontainer.DependsOnConditionSuccess}},
+		task.Containers[0].DependsOn)
+	assert.Empty(t, task.Containers[1].DependsOn)
+}
+
+func TestTaskFromACSWithStopTimeout(t *testing.T) {
+	stopTimeout := int64(120)
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		Containers: []*ecsacs.Container{
+			{
+				Name:        strptr("app"),
+				StopTimeout: &stopTimeout,
+			},
+			{
+				Name: strptr("sidecar"),
+			},
+		},
+	}
+
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.Nil(t, err, "Should be able to handle acs task")
+	assert.Equal(t, 120*time.Second, task.Containers[0].GetStopTimeout())
+	assert.Equal(t, time.Duration(0), task.Containers[1].GetStopTimeout())
+}
+
+func TestTaskFromACSWithFirelensConfiguration(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("log_router"),
+				FirelensConfiguration: &ecsacs.FirelensConfiguration{
+					Type:    strptr("fluentbit"),
+					Options: map[string]*string{"enable-ecs-log-metadata": strptr("false")},
+				},
+			},
+		},
+	}
+
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.Nil(t, err, "Should be able to handle acs task")
+	assert.Equal(t, &apicontainer.FirelensConfig{
+		Type:    apicontainer.FirelensConfigTypeFluentbit,
+		Options: map[string]string{"enable-ecs-log-metadata": "false"},
+	}, task.Containers[0].FirelensConfig)
+}
+
+func TestTaskFromACSWithEFSVolumeConfiguration(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		Volumes: []*ecsacs.Volume{
+			{
+				Name: strptr("efsvolume"),
+				Type: strptr("efs"),
+				EfsVolumeConfiguration: &ecsacs.EFSVolumeConfiguration{
+					FileSystemId:      strptr("fs-12345678"),
+					RootDirectory:     strptr("/export"),
+					TransitEncryption: strptr("ENABLED"),
+					AuthorizationConfig: &ecsacs.EFSAuthorizationConfig{
+						AccessPointId: strptr("fsap-12345678"),
+						Iam:           strptr("ENABLED"),
+					},
+				},
+			},
+		},
+	}
+
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.Nil(t, err, "Should be able to handle acs task")
+	assert.Equal(t, EFSVolumeType, task.Volumes[0].Type)
+	assert.Equal(t, &taskresourcevolume.EFSVolumeConfig{
+		FileSystemID:      "fs-12345678",
+		RootDirectory:     "/export",
+		TransitEncryption: "ENABLED",
+		AuthorizationConfig: taskresourcevolume.EFSAuthorizationConfig{
+			AccessPointID: "fsap-12345678",
+			IAM:           "ENABLED",
+		},
+	}, task.Volumes[0].Volume)
+}
+
 func TestTaskFromACSWithOverrides(t *testing.T) {
 	taskFromACS := ecsacs.Task{
 		Arn:           strptr("myArn"),
@@ -2128,6 +2321,62 @@ func TestPostUnmarshalTaskSecret(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPostUnmarshalTaskWithFirelens(t *testing.T) {
+	hostConfig := `{"LogConfig":{"Type":"awsfirelens","Config":{"Name":"cloudwatch","region":"us-west-2"}}}`
+	appContainer := &apicontainer.Container{
+		Name:                      "app",
+		Image:                     "app:latest",
+		DockerConfig:              apicontainer.DockerConfig{HostConfig: &hostConfig},
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	routerContainer := &apicontainer.Container{
+		Name:  "log_router",
+		Image: "fluent-bit:latest",
+		FirelensConfig: &apicontainer.FirelensConfig{
+			Type: apicontainer.FirelensConfigTypeFluentbit,
+		},
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	task := &Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id",
+		Family:             "myfamily",
+		Version:            "1",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{appContainer, routerContainer},
+	}
+	cfg := &config.Config{
+		Cluster:       "mycluster",
+		DataDir:       "/data",
+		DataDirOnHost: "/var/lib/ecs",
+	}
+
+	err := task.PostUnmarshalTask(cfg, nil, &taskresource.ResourceFields{}, nil, nil)
+	require.NoError(t, err)
+
+	_, ok := task.getFirelensResource()
+	require.True(t, ok, "Expected firelens resource to be created")
+	assert.Equal(t, []apicontainer.ContainerDependency{
+		{ContainerName: "log_router", SatisfiedStatus: apicontainerstatus.ContainerRunning},
+	}, appContainer.TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ContainerDependencies)
+	assert.Len(t, routerContainer.TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies, 1)
+	assert.Equal(t, []apicontainer.ContainerDependency{
+		{ContainerName: "app", SatisfiedStatus: apicontainerstatus.ContainerStopped},
+	}, routerContainer.TransitionDependenciesMap[apicontainerstatus.ContainerStopped].ContainerDependencies)
+
+	appHostConfig, hcErr := task.DockerHostConfig(appContainer, dockerMap(task), defaultDockerClientAPIVersion)
+	require.Nil(t, hcErr)
+	assert.Equal(t, "fluentd", appHostConfig.LogConfig.Type)
+	assert.Equal(t, "unix:///var/lib/ecs/data/firelens/task-id/socket/fluent.sock",
+		appHostConfig.LogConfig.Config["fluentd-address"])
+	assert.Equal(t, "app-firelens-task-id", appHostConfig.LogConfig.Config["tag"])
+
+	routerHostConfig, hcErr := task.DockerHostConfig(routerContainer, dockerMap(task), defaultDockerClientAPIVersion)
+	require.Nil(t, hcErr)
+	assert.Contains(t, routerHostConfig.Binds,
+		"/var/lib/ecs/data/firelens/task-id/config/fluent-bit.conf:/fluent-bit/etc/fluent-bit.conf")
+	assert.Contains(t, routerHostConfig.Binds, "/var/lib/ecs/data/firelens/task-id/socket:/var/run")
+}
+
 func TestPostUnmarshalTaskASMSecret(t *testing.T) {
 	secret := apicontainer.Secret{
 		Provider:  "asm",
@@ -2218,6 +2467,41 @@ func TestGetAllSSMSecretRequirements(t *testing.T) {
 	assert.Equal(t, 1, len(reqs[regionWest]))
 }
 
+func TestInitializeEnvFilesResource(t *testing.T) {
+	container := &apicontainer.Container{
+		Name:  "app",
+		Image: "image:tag",
+		EnvironmentFiles: []apicontainer.EnvironmentFile{
+			{Value: "arn:aws:s3:::bucket/app.env", Type: apicontainer.EnvironmentFileTypeS3},
+		},
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	container1 := &apicontainer.Container{
+		Name:                      "sidecar",
+		Image:                     "image:tag",
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	task := &Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{container, container1},
+	}
+	require.True(t, task.requiresEnvFiles())
+
+	err := task.initializeEnvFilesResource(&config.Config{AWSRegion: "us-west-2", DataDir: "/data"}, nil,
+		&taskresource.ResourceFields{ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{}})
+	require.NoError(t, err)
+
+	resourceDep := apicontainer.ResourceDependency{
+		Name:           envfiles.ResourceName,
+		RequiredStatus: resourcestatus.ResourceStatus(envfiles.EnvFileCreated),
+	}
+	assert.Equal(t, resourceDep, task.Containers[0].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies[0])
+	assert.Equal(t, 0, len(task.Containers[1].TransitionDependenciesMap))
+	_, ok := task.getEnvFilesResource()
+	assert.True(t, ok)
+}
+
 func TestInitializeAndGetSSMSecretResource(t *testing.T) {
 	secret := apicontainer.Secret{
 		Provider:  "ssm",
@@ -2529,8 +2813,41 @@ func TestPopulateSecretsAsEnv(t *testing.T) {
 	task.AddResource(asmsecret.ResourceName, asmRes)
 
 	task.PopulateSecretsAsEnv(container)
-	assert.Equal(t, "secretValue1", container.Environment["secret1"])
-	assert.Equal(t, "secretValue2", container.Environment["secret2"])
+	assert.Equal(t, "secretValue1", container.GetSecretEnvironment()["secret1"])
+	assert.Equal(t, "secretValue2", container.GetSecretEnvironment()["secret2"])
+
+	// The secret values are passed to docker, but are not saved with the container
+	config, err := task.DockerConfig(container, defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Contains(t, config.Env, "secret1=secretValue1")
+	assert.Contains(t, config.Env, "secret2=secretValue2")
+	containerJSON, jsonErr := json.Marshal(container)
+	require.NoError(t, jsonErr)
+	assert.NotContains(t, string(containerJSON), "secretValue")
+}
+
+func TestDockerConfigEnvFiles(t *testing.T) {
+	container := &apicontainer.Container{
+		Name:        "myName",
+		Environment: map[string]string{"CONFIG1": "config1"},
+	}
+	container.SetSecretEnvironment(map[string]string{"CONFIG2": "secret2"})
+	container.SetEnvFileEnvironment([]map[string]string{
+		{"CONFIG1": "file1", "CONFIG2": "file1", "CONFIG3": "fileValue3"},
+	})
+	task := &Task{
+		Arn:        "test",
+		Containers: []*apicontainer.Container{container},
+	}
+
+	// The variables of the env files are passed to docker, but are not saved
+	// with the container. The environment and the secrets take precedence
+	config, err := task.DockerConfig(container, defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"CONFIG1=config1", "CONFIG2=secret2", "CONFIG3=fileValue3"}, config.Env)
+	containerJSON, jsonErr := json.Marshal(container)
+	require.NoError(t, jsonErr)
+	assert.NotContains(t, string(containerJSON), "fileValue3")
 }
 
 func TestPopulateSecretsAsEnvOnlySSM(t *testing.T) {
@@ -2573,8 +2890,9 @@ func TestPopulateSecretsAsEnvOnlySSM(t *testing.T) {
 	task.AddResource(asmsecret.ResourceName, asmRes)
 
 	task.PopulateSecretsAsEnv(container)
-	assert.Equal(t, "secretValue2", container.Environment["secret2"])
-	assert.Equal(t, 1, len(container.Environment))
+	assert.Equal(t, "secretValue2", container.GetSecretEnvironment()["secret2"])
+	assert.Equal(t, 1, len(container.GetSecretEnvironment()))
+	assert.Empty(t, container.Environment)
 }
 
 func TestAddGPUResource(t *testing.T) {
@@ -2816,3 +3134,35 @@ func TestAssociationByTypeAndName(t *testing.T) {
 	association, ok = task.AssociationByTypeAndName("other-type", "dev1")
 	assert.False(t, ok)
 }
+
+func TestPostUnmarshalTaskHostNamespacesDisallowed(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pidMode string
+		ipcMode string
+		cfg     config.Config
+		allowed bool
+	}{
+		{"host pid disallowed", pidModeHost, "", config.Config{HostPIDDisabled: true}, false},
+		{"host ipc disallowed", "", ipcModeHost, config.Config{HostIPCDisabled: true}, false},
+		{"task pid allowed", pidModeTask, ipcModeTask, config.Config{HostPIDDisabled: true, HostIPCDisabled: true}, true},
+		{"host namespaces allowed", pidModeHost, ipcModeHost, config.Config{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn:     "myArn",
+				PIDMode: tc.pidMode,
+				IPCMode: tc.ipcMode,
+			}
+			err := task.PostUnmarshalTask(&tc.cfg, nil, nil, nil, nil)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "disallowed on this container instance")
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package task
 import (
 	"time"
 
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/cihub/seelog"
@@ -50,6 +51,10 @@ func (task *Task) initializeCgroupResourceSpec(cgroupPath string, resourceFields
 	return nil
 }
 
+func (task *Task) applyLinuxParameters(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	return nil
+}
+
 func (task *Task) platformHostConfigOverride(hostConfig *dockercontainer.HostConfig) error {
 	return nil
 }
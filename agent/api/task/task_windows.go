@@ -21,6 +21,7 @@ import (
 	"runtime"
 	"strings"
 
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
@@ -90,6 +91,15 @@ func isBareDrive(path string) bool {
 	return false
 }
 
+// applyLinuxParameters fails for containers with linux parameters, which are
+// not supported on windows
+func (task *Task) applyLinuxParameters(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	if container.LinuxParameters != nil {
+		return errors.New("linux parameters are not supported on windows")
+	}
+	return nil
+}
+
 // platformHostConfigOverride provides an entry point to set up default HostConfig options to be
 // passed to Docker API.
 func (task *Task) platformHostConfigOverride(hostConfig *dockercontainer.HostConfig) error {
@@ -15,8 +15,11 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
 
@@ -37,22 +40,25 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
 	"github.com/aws/amazon-ecs-agent/agent/eni/pause"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/tcs/handler"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper"
 	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/aws-sdk-go/aws"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/cihub/seelog"
 	"github.com/pborman/uuid"
 )
@@ -111,6 +117,21 @@ type ecsAgent struct {
 	mobyPlugins           mobypkgwrapper.Plugins
 	resourceFields        *taskresource.ResourceFields
 	availabilityZone      string
+	// primaryInterfaceMTU returns the MTU of the primary network interface. It
+	// is nil on platforms where it cannot be detected
+	primaryInterfaceMTU func() (int, error)
+	// swapAccountingEnabled returns whether the kernel accounts for swap usage
+	// in cgroups. It is nil on platforms where it cannot be detected
+	swapAccountingEnabled func() bool
+	// execAgentAvailable returns whether the execute command agent is
+	// installed on the host
+	execAgentAvailable func() bool
+	// metadataAttributes are the zone, instance type and AMI attributes of the
+	// instance, read from EC2 metadata when the agent is created
+	metadataAttributes []*ecs.Attribute
+	// instanceWeightLock guards the instance weight of the configuration,
+	// which can be reloaded at runtime
+	instanceWeightLock sync.RWMutex
 }
 
 // newAgent returns a new ecsAgent object, but does not start anything
@@ -119,7 +140,13 @@ func newAgent(
 	blackholeEC2Metadata bool,
 	acceptInsecureCert *bool) (agent, error) {
 
+	// Make sure the instance metadata is not requested via a proxy, before
+	// the first request is made
+	httpclient.SetDefaultNoProxy(os.Getenv("DOCKER_HOST"))
 	ec2MetadataClient := ec2.NewEC2MetadataClient(nil)
+	// Without an instance metadata service, the metadata requests would only
+	// time out
+	blackholeEC2Metadata = blackholeEC2Metadata || ec2.MetadataDisabled()
 	if blackholeEC2Metadata {
 		ec2MetadataClient = ec2.NewBlackholeEC2MetadataClient()
 	}
@@ -133,11 +160,20 @@ func newAgent(
 		return nil, err
 	}
 	cfg.AcceptInsecureCert = aws.BoolValue(acceptInsecureCert)
+	if blackholeEC2Metadata {
+		// There is no instance identity document to register the instance with
+		cfg.NoIID = true
+	}
 	if cfg.AcceptInsecureCert {
 		seelog.Warn("SSL certificate verification disabled. This is not recommended.")
 	}
 	seelog.Infof("Amazon ECS agent Version: %s, Commit: %s", version.Version, version.GitShortHash)
 	seelog.Debugf("Loaded config: %s", cfg.String())
+	// Check the trusted certificates settings before any client uses them
+	if err := httpclient.ConfigureTLSTrust(&tls.Config{}, cfg.CABundlePath, cfg.PinnedPublicKeys); err != nil {
+		seelog.Criticalf("Error loading config: %v", err)
+		return nil, err
+	}
 
 	ec2Client := ec2.NewClientImpl(cfg.AWSRegion)
 	dockerClient, err := dockerapi.NewDockerGoClient(sdkclientfactory.NewFactory(ctx, cfg.DockerEndpoint), cfg, ctx)
@@ -148,6 +184,13 @@ func newAgent(
 		return nil, err
 	}
 
+	// We instantiate our own credentialProvider for use in acs/tcs.
+	credentialProvider, err := newCredentialProvider(cfg)
+	if err != nil {
+		seelog.Criticalf("Error creating credential provider: %v", err)
+		return nil, err
+	}
+
 	var metadataManager containermetadata.Manager
 	if cfg.ContainerMetadataEnabled {
 		// We use the default API client for the metadata inspect call. This version has some information
@@ -156,16 +199,18 @@ func newAgent(
 		metadataManager = containermetadata.NewManager(dockerClient, cfg)
 	}
 
+	var metadataAttributes []*ecs.Attribute
+	if !blackholeEC2Metadata {
+		metadataAttributes = append(zoneAttributes(ec2MetadataClient), instanceAttributes(ec2MetadataClient)...)
+	}
+
 	return &ecsAgent{
-		ctx:               ctx,
-		ec2MetadataClient: ec2MetadataClient,
-		ec2Client:         ec2Client,
-		cfg:               cfg,
-		dockerClient:      dockerClient,
-		// We instantiate our own credentialProvider for use in acs/tcs. This tries
-		// to mimic roughly the way it's instantiated by the SDK for a default
-		// session.
-		credentialProvider:    defaults.CredChain(defaults.Config(), defaults.Handlers()),
+		ctx:                   ctx,
+		ec2MetadataClient:     ec2MetadataClient,
+		ec2Client:             ec2Client,
+		cfg:                   cfg,
+		dockerClient:          dockerClient,
+		credentialProvider:    credentialProvider,
 		stateManagerFactory:   factory.NewStateManager(),
 		saveableOptionFactory: factory.NewSaveableOption(),
 		pauseLoader:           pause.New(),
@@ -173,10 +218,14 @@ func newAgent(
 			PluginsPath:            cfg.CNIPluginsPath,
 			MinSupportedCNIVersion: config.DefaultMinSupportedCNIVersion,
 		}),
-		os:                 oswrapper.New(),
-		metadataManager:    metadataManager,
-		terminationHandler: sighandlers.StartDefaultTerminationHandler,
-		mobyPlugins:        mobypkgwrapper.NewPlugins(),
+		os:                    oswrapper.New(),
+		metadataManager:       metadataManager,
+		terminationHandler:    sighandlers.StartDefaultTerminationHandler,
+		mobyPlugins:           mobypkgwrapper.NewPlugins(),
+		primaryInterfaceMTU:   newPrimaryInterfaceMTUResolver(),
+		swapAccountingEnabled: newSwapAccountingResolver(),
+		execAgentAvailable:    execcmd.AgentAvailable,
+		metadataAttributes:    metadataAttributes,
 	}, nil
 }
 
@@ -206,7 +255,8 @@ func (agent *ecsAgent) start() int {
 	credentialsManager := credentials.NewManager()
 	state := dockerstate.NewTaskEngineState()
 	imageManager := engine.NewImageManager(agent.cfg, agent.dockerClient, state)
-	client := ecsclient.NewECSClient(agent.credentialProvider, agent.cfg, agent.ec2MetadataClient)
+	client := ecsclient.NewECSClient(agent.credentialProvider, agent.cfg, agent.ec2MetadataClient,
+		agent.dockerVersion)
 
 	agent.initializeResourceFields(credentialsManager)
 	return agent.doStart(containerChangeEventStream, credentialsManager, state, imageManager, client)
@@ -241,17 +291,21 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		}
 	}
 
-	// Create the task engine
+	// Create the task engine, the payload messages applied by the ACS session
+	// are restored with it
+	appliedPayloads := acshandler.NewAppliedPayloads()
 	taskEngine, currentEC2InstanceID, err := agent.newTaskEngine(containerChangeEventStream,
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, appliedPayloads)
 	if err != nil {
 		return exitcodes.ExitTerminal
 	}
 
-	agent.initMetricsEngine()
+	agent.initMetricsEngine(state)
+	// Export traces of the task lifecycle and of the ECS API calls, if enabled
+	tracing.Init(agent.ctx, agent.cfg.OTLPTracesEndpoint)
 
 	// Initialize the state manager
-	stateManager, err := agent.newStateManager(taskEngine,
+	stateManager, err := agent.newStateManager(taskEngine, appliedPayloads,
 		&agent.cfg.Cluster, &agent.containerInstanceARN, &currentEC2InstanceID, &agent.availabilityZone)
 	if err != nil {
 		seelog.Criticalf("Error creating state manager: %v", err)
@@ -286,7 +340,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 	}
 
 	// Register the container instance
-	err = agent.registerContainerInstance(stateManager, client, vpcSubnetAttributes)
+	err = agent.registerContainerInstance(stateManager, state, client, vpcSubnetAttributes)
 	if err != nil {
 		if isTransient(err) {
 			return exitcodes.ExitError
@@ -310,12 +364,18 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		deregisterContainerInstanceEventStreamName, agent.ctx)
 	deregisterInstanceEventStream.StartListening()
 	taskHandler := eventhandler.NewTaskHandler(agent.ctx, stateManager, state, client)
+	metrics.MetricsEngineGlobal.RegisterGaugeFunc(metrics.TaskEngineSubsystem, "submit_queue_depth",
+		"Number of state changes waiting to be submitted to ECS", func() float64 {
+			return float64(taskHandler.SubmitQueueDepth())
+		})
+	acsSession := agent.newACSSession(credentialsManager, taskEngine, stateManager,
+		deregisterInstanceEventStream, client, state, taskHandler, appliedPayloads)
 	agent.startAsyncRoutines(containerChangeEventStream, credentialsManager, imageManager,
-		taskEngine, stateManager, deregisterInstanceEventStream, client, taskHandler, state)
+		taskEngine, stateManager, deregisterInstanceEventStream, client, taskHandler, state,
+		agent.healthChecks(acsSession))
 
 	// Start the acs session, which should block doStart
-	return agent.startACSSession(credentialsManager, taskEngine, stateManager,
-		deregisterInstanceEventStream, client, state, taskHandler)
+	return agent.startACSSession(acsSession)
 }
 
 // newTaskEngine creates a new docker task engine object. It tries to load the
@@ -323,7 +383,8 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 func (agent *ecsAgent) newTaskEngine(containerChangeEventStream *eventstream.EventStream,
 	credentialsManager credentials.Manager,
 	state dockerstate.TaskEngineState,
-	imageManager engine.ImageManager) (engine.TaskEngine, string, error) {
+	imageManager engine.ImageManager,
+	appliedPayloads *acshandler.AppliedPayloads) (engine.TaskEngine, string, error) {
 
 	containerChangeEventStream.StartListening()
 
@@ -342,7 +403,7 @@ func (agent *ecsAgent) newTaskEngine(containerChangeEventStream *eventstream.Eve
 
 	// previousStateManager is used to verify that our current runtime configuration is
 	// compatible with our past configuration as reflected by our state-file
-	previousStateManager, err := agent.newStateManager(previousTaskEngine, &previousCluster,
+	previousStateManager, err := agent.newStateManager(previousTaskEngine, appliedPayloads, &previousCluster,
 		&previousContainerInstanceArn, &previousEC2InstanceID, &previousAZ)
 	if err != nil {
 		seelog.Criticalf("Error creating state manager: %v", err)
@@ -368,6 +429,7 @@ func (agent *ecsAgent) newTaskEngine(containerChangeEventStream *eventstream.Eve
 
 		// Reset agent state as a new container instance
 		state.Reset()
+		appliedPayloads.Reset()
 		// Reset taskEngine; all the other values are still default
 		return engine.NewTaskEngine(agent.cfg, agent.dockerClient, credentialsManager,
 			containerChangeEventStream, imageManager, state, agent.metadataManager,
@@ -386,7 +448,7 @@ func (agent *ecsAgent) newTaskEngine(containerChangeEventStream *eventstream.Eve
 	return previousTaskEngine, currentEC2InstanceID, nil
 }
 
-func (agent *ecsAgent) initMetricsEngine() {
+func (agent *ecsAgent) initMetricsEngine(state dockerstate.TaskEngineState) {
 	// In case of a panic during set-up, we will recover quietly and resume
 	// normal Agent execution.
 	defer func() {
@@ -397,6 +459,13 @@ func (agent *ecsAgent) initMetricsEngine() {
 
 	// We init the global MetricsEngine before we publish metrics
 	metrics.MustInit(agent.cfg)
+	metrics.MetricsEngineGlobal.RegisterTaskStatusCounter(func() map[string]int {
+		counts := make(map[string]int)
+		for _, task := range state.AllTasks() {
+			counts[task.GetKnownStatus().String()]++
+		}
+		return counts
+	})
 	metrics.PublishMetrics()
 }
 
@@ -439,6 +508,7 @@ func (agent *ecsAgent) getEC2InstanceID() string {
 // will be backfilled when state manager's Load() method is invoked
 func (agent *ecsAgent) newStateManager(
 	taskEngine engine.TaskEngine,
+	appliedPayloads *acshandler.AppliedPayloads,
 	cluster *string,
 	containerInstanceArn *string,
 	savedInstanceID *string,
@@ -450,6 +520,7 @@ func (agent *ecsAgent) newStateManager(
 
 	return agent.stateManagerFactory.NewStateManager(agent.cfg,
 		statemanager.AddSaveable("TaskEngine", taskEngine),
+		statemanager.AddSaveable("ACSAppliedPayloads", appliedPayloads),
 		// This is for making testing easier as we can mock this
 		agent.saveableOptionFactory.AddSaveable("ContainerInstanceArn",
 			containerInstanceArn),
@@ -478,6 +549,7 @@ func (agent *ecsAgent) constructVPCSubnetAttributes() []*ecs.Attribute {
 // registerContainerInstance registers the container instance ID for the ECS Agent
 func (agent *ecsAgent) registerContainerInstance(
 	stateManager statemanager.StateManager,
+	state dockerstate.TaskEngineState,
 	client api.ECSClient,
 	additionalAttributes []*ecs.Attribute) error {
 	// Preflight request to make sure they're good
@@ -490,6 +562,8 @@ func (agent *ecsAgent) registerContainerInstance(
 		return err
 	}
 	capabilities := append(agentCapabilities, additionalAttributes...)
+	capabilities = append(capabilities, instanceWeightAttributes(agent.getInstanceWeight())...)
+	capabilities = append(capabilities, agent.metadataAttributes...)
 
 	// Get the tags of this container instance defined in config file
 	tags := utils.MapToTags(agent.cfg.ContainerInstanceTags)
@@ -509,7 +583,16 @@ func (agent *ecsAgent) registerContainerInstance(
 
 	if agent.containerInstanceARN != "" {
 		seelog.Infof("Restored from checkpoint file. I am running as '%s' in cluster '%s'", agent.containerInstanceARN, agent.cfg.Cluster)
-		return agent.reregisterContainerInstance(client, capabilities, tags, uuid.New(), platformDevices)
+		err := agent.reregisterContainerInstance(client, capabilities, tags, uuid.New(), platformDevices)
+		if !apierrors.IsInvalidInstanceError(err) {
+			return err
+		}
+		// The container instance was deregistered, forget it and register
+		// as a new container instance
+		seelog.Warnf("Container instance '%s' is no longer registered with ECS, registering a new container instance: %v",
+			agent.containerInstanceARN, err)
+		agent.containerInstanceARN = ""
+		agent.removeDeregisteredInstanceTasks(state)
 	}
 
 	seelog.Info("Registering Instance with ECS")
@@ -537,6 +620,32 @@ func (agent *ecsAgent) registerContainerInstance(
 	return nil
 }
 
+// removeDeregisteredInstanceTasks stops and removes the containers of the tasks
+// restored from the checkpoint of a container instance that is no longer
+// registered with ECS, and removes the tasks from the state. ECS does not know
+// about these tasks anymore, so they would never be reconciled or cleaned up
+func (agent *ecsAgent) removeDeregisteredInstanceTasks(state dockerstate.TaskEngineState) {
+	for _, task := range state.AllTasks() {
+		containers, _ := state.ContainerMapByArn(task.Arn)
+		for _, container := range containers {
+			if container.DockerID == "" {
+				continue
+			}
+			seelog.Infof("Stopping container '%s' of task '%s' of the deregistered container instance",
+				container.DockerName, task.Arn)
+			metadata := agent.dockerClient.StopContainer(agent.ctx, container.DockerID, agent.cfg.DockerStopTimeout)
+			if metadata.Error != nil {
+				seelog.Warnf("Unable to stop container '%s' of task '%s': %v", container.DockerName, task.Arn, metadata.Error)
+			}
+			err := agent.dockerClient.RemoveContainer(agent.ctx, container.DockerID, dockerclient.RemoveContainerTimeout)
+			if err != nil {
+				seelog.Warnf("Unable to remove container '%s' of task '%s': %v", container.DockerName, task.Arn, err)
+			}
+		}
+	}
+	state.Reset()
+}
+
 // reregisterContainerInstance registers a container instance that has already been
 // registered with ECS. This is for cases where the ECS Agent is being restored
 // from a check point.
@@ -558,6 +667,9 @@ func (agent *ecsAgent) reregisterContainerInstance(client api.ECSClient,
 		seelog.Critical("Instance re-registration attempt with an invalid attribute")
 		return err
 	}
+	if apierrors.IsInvalidInstanceError(err) {
+		return err
+	}
 	return transientError{err}
 }
 
@@ -571,19 +683,23 @@ func (agent *ecsAgent) startAsyncRoutines(
 	deregisterInstanceEventStream *eventstream.EventStream,
 	client api.ECSClient,
 	taskHandler *eventhandler.TaskHandler,
-	state dockerstate.TaskEngineState) {
+	state dockerstate.TaskEngineState,
+	healthChecks map[string]v1.HealthCheck) {
 
 	// Start of the periodic image cleanup process
 	if !agent.cfg.ImageCleanupDisabled {
 		go imageManager.StartImageCleanupProcess(agent.ctx)
 	}
 
-	go agent.terminationHandler(stateManager, taskEngine)
-
-	// Agent introspection api
-	go handlers.ServeIntrospectionHTTPEndpoint(&agent.containerInstanceARN, taskEngine, agent.cfg)
+	go agent.terminationHandler(stateManager, taskEngine, func(ctx context.Context) error {
+		return agent.shutdown(ctx, client, state, agent.cfg.ForceShutdownDrain)
+	})
 
 	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream)
+	statsEngine.RegisterDiskUsageMetrics()
+
+	// Agent introspection api
+	go handlers.ServeIntrospectionHTTPEndpoint(&agent.containerInstanceARN, taskEngine, statsEngine, healthChecks, agent.cfg)
 
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
@@ -596,6 +712,25 @@ func (agent *ecsAgent) startAsyncRoutines(
 	// Start sending events to the backend
 	go eventhandler.HandleEngineEvents(taskEngine, client, taskHandler)
 
+	// Report updates of the dynamic attributes in batches
+	attributeRefresher := newAttributeRefresher(client, agent.containerInstanceARN)
+	go attributeRefresher.start(agent.ctx, agent.cfg.AttributeRefreshInterval)
+
+	// Report configuration changes, such as the instance weight, on SIGHUP
+	sighandlers.StartReloadHandler(agent.ctx, func() {
+		agent.reloadInstanceWeight(attributeRefresher)
+	})
+
+	// Drain the instance and stop its tasks when EC2 is about to interrupt it
+	if agent.cfg.SpotInstanceDrainingEnabled {
+		go agent.startSpotInstanceNoticePoller(agent.ctx, client, taskEngine)
+	}
+
+	// Start the periodic reporting of the host ports in use
+	if agent.cfg.HostPortsRefreshInterval > 0 {
+		go agent.startHostPortsReporter(attributeRefresher, state)
+	}
+
 	telemetrySessionParams := tcshandler.TelemetrySessionParams{
 		Ctx:                           agent.ctx,
 		CredentialProvider:            agent.credentialProvider,
@@ -611,18 +746,18 @@ func (agent *ecsAgent) startAsyncRoutines(
 	go tcshandler.StartMetricsSession(&telemetrySessionParams)
 }
 
-// startACSSession starts a session with ECS's Agent Communication service. This
-// is a blocking call and only returns when the handler returns
-func (agent *ecsAgent) startACSSession(
+// newACSSession creates the session with ECS's Agent Communication service
+func (agent *ecsAgent) newACSSession(
 	credentialsManager credentials.Manager,
 	taskEngine engine.TaskEngine,
 	stateManager statemanager.StateManager,
 	deregisterInstanceEventStream *eventstream.EventStream,
 	client api.ECSClient,
 	state dockerstate.TaskEngineState,
-	taskHandler *eventhandler.TaskHandler) int {
+	taskHandler *eventhandler.TaskHandler,
+	appliedPayloads *acshandler.AppliedPayloads) acshandler.Session {
 
-	acsSession := acshandler.NewSession(
+	return acshandler.NewSession(
 		agent.ctx,
 		agent.cfg,
 		deregisterInstanceEventStream,
@@ -634,7 +769,13 @@ func (agent *ecsAgent) startACSSession(
 		taskEngine,
 		credentialsManager,
 		taskHandler,
+		appliedPayloads,
 	)
+}
+
+// startACSSession starts a session with ECS's Agent Communication service. This
+// is a blocking call and only returns when the handler returns
+func (agent *ecsAgent) startACSSession(acsSession acshandler.Session) int {
 	seelog.Info("Beginning Polling for updates")
 	err := acsSession.Start()
 	if err != nil {
@@ -645,6 +786,11 @@ func (agent *ecsAgent) startACSSession(
 	return exitcodes.ExitError
 }
 
+// dockerVersion returns the version of the docker daemon
+func (agent *ecsAgent) dockerVersion() (string, error) {
+	return agent.dockerClient.Version(agent.ctx, dockerclient.VersionTimeout)
+}
+
 // validateRequiredVersion validates docker version.
 // Minimum docker version supported is 1.9.0, maps to api version 1.21
 // see https://docs.docker.com/develop/sdk/#api-version-matrix
@@ -0,0 +1,238 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+// maxAttributesPerPutAttributes is the maximum number of attributes accepted
+// by a single PutAttributes or DeleteAttributes call
+const maxAttributesPerPutAttributes = 10
+
+// attributeRefresher coalesces the updates of dynamic container instance
+// attributes, so that all the updates made within an interval are reported
+// with a single PutAttributes call, and all the removals with a single
+// DeleteAttributes call
+type attributeRefresher struct {
+	client               api.ECSClient
+	containerInstanceARN string
+	// pending holds the attributes updated since the last report, by name
+	pending map[string]*ecs.Attribute
+	// removed holds the attributes removed since the last report, by name
+	removed map[string]*ecs.Attribute
+	// reported holds the values of the attributes last reported successfully,
+	// by name. Removed attributes have an empty value
+	reported map[string]string
+	// rejected holds the values of the attributes last dropped because ECS
+	// rejected them with an error that is not retriable, by name. Removed
+	// attributes have an empty value
+	rejected map[string]string
+	lock     sync.Mutex
+}
+
+func newAttributeRefresher(client api.ECSClient, containerInstanceARN string) *attributeRefresher {
+	return &attributeRefresher{
+		client:               client,
+		containerInstanceARN: containerInstanceARN,
+		pending:              make(map[string]*ecs.Attribute),
+		removed:              make(map[string]*ecs.Attribute),
+		reported:             make(map[string]string),
+		rejected:             make(map[string]string),
+	}
+}
+
+// update queues the attributes to be reported with the next refresh. An
+// attribute replaces any pending update or removal of the attribute with the
+// same name
+func (refresher *attributeRefresher) update(attributes ...*ecs.Attribute) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, attribute := range attributes {
+		name := aws.StringValue(attribute.Name)
+		refresher.pending[name] = attribute
+		delete(refresher.removed, name)
+	}
+}
+
+// remove queues the attributes with the given names to be removed with the
+// next refresh, replacing any pending update of these attributes
+func (refresher *attributeRefresher) remove(names ...string) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, name := range names {
+		refresher.removed[name] = &ecs.Attribute{Name: aws.String(name)}
+		delete(refresher.pending, name)
+	}
+}
+
+// reportedValue returns the value of the attribute last reported
+// successfully, which is empty for a removed attribute. It returns false if
+// the attribute has not been reported since the agent started
+func (refresher *attributeRefresher) reportedValue(name string) (string, bool) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	value, ok := refresher.reported[name]
+	return value, ok
+}
+
+// rejectedValue returns the value of the attribute last dropped because ECS
+// rejected it, which is empty for a removal. It returns false if the
+// attribute has not been rejected since it was last reported successfully
+func (refresher *attributeRefresher) rejectedValue(name string) (string, bool) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	value, ok := refresher.rejected[name]
+	return value, ok
+}
+
+// start reports the pending attributes at every interval, until the context
+// is canceled
+func (refresher *attributeRefresher) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresher.refresh()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh reports the pending attributes and removals. Attributes that could
+// not be reported because of throttling or a transient error are kept for the
+// next refresh, unless they were updated or removed meanwhile. Attributes
+// rejected for any other reason are dropped, as reporting them again cannot
+// succeed
+func (refresher *attributeRefresher) refresh() {
+	updated, removed := refresher.takePending()
+	refresher.report(updated, refresher.client.PutAttributes, refresher.pending)
+	refresher.report(removed, refresher.client.DeleteAttributes, refresher.removed)
+}
+
+// report invokes the API call with batches of the attributes, requeuing the
+// attributes of the batches that failed with a retriable error in the given
+// queue
+func (refresher *attributeRefresher) report(attributes []*ecs.Attribute,
+	call func(string, []*ecs.Attribute) error, queue map[string]*ecs.Attribute) {
+	for len(attributes) > 0 {
+		batch := attributes
+		if len(batch) > maxAttributesPerPutAttributes {
+			batch = batch[:maxAttributesPerPutAttributes]
+		}
+		attributes = attributes[len(batch):]
+
+		err := call(refresher.containerInstanceARN, batch)
+		if err == nil {
+			refresher.recordReported(batch)
+			continue
+		}
+		if !apierrors.IsRetriableAPIError(err) {
+			seelog.Errorf("Dropping attributes %s rejected for container instance %s: %v",
+				attributeNames(batch), refresher.containerInstanceARN, err)
+			refresher.recordRejected(batch)
+			continue
+		}
+		seelog.Warnf("Unable to report attributes for container instance %s: %v", refresher.containerInstanceARN, err)
+		refresher.requeue(batch, queue)
+	}
+}
+
+// takePending returns the pending updates and removals sorted by name and
+// clears them
+func (refresher *attributeRefresher) takePending() ([]*ecs.Attribute, []*ecs.Attribute) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	updated := sortedAttributes(refresher.pending)
+	removed := sortedAttributes(refresher.removed)
+	for name := range refresher.pending {
+		delete(refresher.pending, name)
+	}
+	for name := range refresher.removed {
+		delete(refresher.removed, name)
+	}
+	return updated, removed
+}
+
+func sortedAttributes(attributesByName map[string]*ecs.Attribute) []*ecs.Attribute {
+	names := make([]string, 0, len(attributesByName))
+	for name := range attributesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := make([]*ecs.Attribute, 0, len(names))
+	for _, name := range names {
+		attributes = append(attributes, attributesByName[name])
+	}
+	return attributes
+}
+
+func attributeNames(attributes []*ecs.Attribute) []string {
+	names := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		names = append(names, aws.StringValue(attribute.Name))
+	}
+	return names
+}
+
+func (refresher *attributeRefresher) recordReported(attributes []*ecs.Attribute) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, attribute := range attributes {
+		name := aws.StringValue(attribute.Name)
+		refresher.reported[name] = aws.StringValue(attribute.Value)
+		delete(refresher.rejected, name)
+	}
+}
+
+func (refresher *attributeRefresher) recordRejected(attributes []*ecs.Attribute) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, attribute := range attributes {
+		refresher.rejected[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+	}
+}
+
+func (refresher *attributeRefresher) requeue(attributes []*ecs.Attribute, queue map[string]*ecs.Attribute) {
+	refresher.lock.Lock()
+	defer refresher.lock.Unlock()
+
+	for _, attribute := range attributes {
+		name := aws.StringValue(attribute.Name)
+		_, updated := refresher.pending[name]
+		_, removed := refresher.removed[name]
+		if !updated && !removed {
+			queue[name] = attribute
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func attributeValues(attributes []*ecs.Attribute) map[string]string {
+	values := make(map[string]string)
+	for _, attribute := range attributes {
+		values[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+	}
+	return values
+}
+
+func TestAttributeRefresherCoalescesUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			assert.Equal(t, map[string]string{
+				hostPortsTCPAttributeName:   "22 80",
+				hostPortsUDPAttributeName:   "53",
+				instanceWeightAttributeName: "10",
+			}, attributeValues(attributes))
+		}).Return(nil)
+
+	refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22")})
+	refresher.update(instanceWeightAttributes(10)...)
+	refresher.update(
+		&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22 80")},
+		&ecs.Attribute{Name: aws.String(hostPortsUDPAttributeName), Value: aws.String("53")},
+	)
+	refresher.refresh()
+
+	// Nothing is reported when there are no updates
+	refresher.refresh()
+}
+
+func TestAttributeRefresherOneCallPerInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	refresher.update(instanceWeightAttributes(10)...)
+	refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22")})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			assert.Len(t, attributes, 2)
+			wg.Done()
+		}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		refresher.start(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	wg.Wait()
+	// Let a few more intervals pass without updates
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestAttributeRefresherRetriesFailedUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+
+	gomock.InOrder(
+		client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Return(
+			awserr.New("ThrottlingException", "Rate exceeded", nil)),
+		client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute) {
+				// The failed weight update is superseded by the newer one
+				assert.Equal(t, map[string]string{
+					hostPortsTCPAttributeName:   "22",
+					instanceWeightAttributeName: "20",
+				}, attributeValues(attributes))
+			}).Return(nil),
+	)
+
+	refresher.update(instanceWeightAttributes(10)...)
+	refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22")})
+	refresher.refresh()
+
+	refresher.update(instanceWeightAttributes(20)...)
+	refresher.refresh()
+}
+
+func TestAttributeRefresherDropsRejectedUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+
+	for _, err := range []error{
+		awserr.NewRequestFailure(awserr.New(ecs.ErrCodeInvalidParameterException, "error", nil), 400, ""),
+		errors.New("error"),
+	} {
+		client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Return(err)
+		refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22")})
+		refresher.refresh()
+
+		// PutAttributes is not expected to be invoked again for the rejected
+		// attribute
+		refresher.refresh()
+		rejected, ok := refresher.rejectedValue(hostPortsTCPAttributeName)
+		assert.True(t, ok)
+		assert.Equal(t, "22", rejected)
+	}
+
+	// A value reported successfully is no longer rejected
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Return(nil)
+	refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22 80")})
+	refresher.refresh()
+	_, ok := refresher.rejectedValue(hostPortsTCPAttributeName)
+	assert.False(t, ok)
+}
+
+func TestAttributeRefresherSplitsLargeUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	for i := 0; i < maxAttributesPerPutAttributes+1; i++ {
+		refresher.update(&ecs.Attribute{Name: aws.String(fmt.Sprintf("attribute-%02d", i))})
+	}
+
+	var batchSizes []int
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			batchSizes = append(batchSizes, len(attributes))
+		}).Return(nil).Times(2)
+
+	refresher.refresh()
+	require.Equal(t, []int{maxAttributesPerPutAttributes, 1}, batchSizes)
+}
+
+func TestAttributeRefresherRemovesAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			assert.Equal(t, map[string]string{hostPortsTCPAttributeName: "22"}, attributeValues(attributes))
+		}).Return(nil)
+	client.EXPECT().DeleteAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			// The removal supersedes the pending weight update
+			require.Len(t, attributes, 1)
+			assert.Equal(t, instanceWeightAttributeName, aws.StringValue(attributes[0].Name))
+			assert.Nil(t, attributes[0].Value)
+		}).Return(nil)
+
+	refresher.update(instanceWeightAttributes(10)...)
+	refresher.update(&ecs.Attribute{Name: aws.String(hostPortsTCPAttributeName), Value: aws.String("22")})
+	refresher.remove(instanceWeightAttributeName)
+	refresher.refresh()
+}
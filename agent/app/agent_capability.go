@@ -14,6 +14,8 @@
 package app
 
 import (
+	"strconv"
+
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
@@ -21,6 +23,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
 )
 
@@ -41,8 +44,24 @@ const (
 	capabilitySecretEnvASM                      = "secrets.asm.environment-variables"
 	capabiltyPIDAndIPCNamespaceSharing          = "pid-ipc-namespace-sharing"
 	capabilityNvidiaDriverVersionInfix          = "nvidia-driver-version."
+	capabilityNvidiaGPUInfix                    = "nvidia-gpu."
 	capabilityECREndpoint                       = "ecr-endpoint"
 	taskEIAAttributeSuffix                      = "task-eia"
+	capabilityFireLensFluentbit                 = "firelens.fluentbit"
+	capabilityFireLensFluentd                   = "firelens.fluentd"
+	capabilityMemorySwap                        = "memory-swap"
+	capabilityExecuteCommand                    = "execute-command"
+	dockerAttributePrefix                       = "ecs.docker."
+	dockerLiveRestoreAttributeSuffix            = "live-restore"
+	dockerUsernsRemapAttributeSuffix            = "userns-remap"
+	dockerDefaultRuntimeAttributeSuffix         = "default-runtime"
+	dockerUsernsSecurityOption                  = "userns"
+	networkMTUAttributeName                     = "ecs.network-mtu"
+	allowPrivilegedAttributeName                = "ecs.allow-privileged"
+	allowHostPIDAttributeName                   = "ecs.allow-host-pid"
+	allowHostIPCAttributeName                   = "ecs.allow-host-ipc"
+	drainProtectedAttributeName                 = "ecs.drain-protected"
+	complianceAttributePrefix                   = "ecs.compliance/"
 )
 
 // capabilities returns the supported capabilities of this agent / docker-client pair.
@@ -56,11 +75,14 @@ const (
 //    com.amazonaws.ecs.capability.logging-driver.json-file
 //    com.amazonaws.ecs.capability.logging-driver.syslog
 //    com.amazonaws.ecs.capability.logging-driver.fluentd
+//    com.amazonaws.ecs.capability.firelens.fluentbit
+//    com.amazonaws.ecs.capability.firelens.fluentd
 //    com.amazonaws.ecs.capability.logging-driver.journald
 //    com.amazonaws.ecs.capability.logging-driver.gelf
 //    com.amazonaws.ecs.capability.logging-driver.none
 //    com.amazonaws.ecs.capability.selinux
 //    com.amazonaws.ecs.capability.apparmor
+//    com.amazonaws.ecs.capability.memory-swap
 //    com.amazonaws.ecs.capability.ecr-auth
 //    com.amazonaws.ecs.capability.task-iam-role
 //    com.amazonaws.ecs.capability.task-iam-role-network-host
@@ -76,6 +98,20 @@ const (
 //    ecs.capability.ecr-endpoint
 //    ecs.capability.secrets.asm.environment-variables
 //    ecs.capability.task-eia
+//    ecs.capability.nvidia-driver-version.${driverVersion}
+//    ecs.capability.nvidia-gpu.${gpuIndex}
+//    ecs.capability.execute-command
+//    ecs.allow-privileged
+//    ecs.allow-host-pid
+//    ecs.allow-host-ipc
+//    ecs.drain-protected
+//    ecs.compliance/${framework}
+//    ecs.network-mtu
+//    ecs.eni-trunking
+//    ecs.branch-eni-limit
+//    ecs.docker.live-restore
+//    ecs.docker.userns-remap
+//    ecs.docker.default-runtime
 func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	var capabilities []*ecs.Attribute
 
@@ -99,6 +135,10 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	if agent.cfg.AppArmorCapable {
 		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"apparmor")
 	}
+	// memory-swap limits are silently ignored unless the kernel accounts for swap
+	if agent.swapAccountingEnabled != nil && agent.swapAccountingEnabled() {
+		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityMemorySwap)
+	}
 
 	capabilities = agent.appendTaskIamRoleCapabilities(capabilities, supportedVersions)
 
@@ -108,6 +148,7 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	}
 
 	capabilities = agent.appendTaskENICapabilities(capabilities)
+	capabilities = agent.appendENITrunkingAttributes(capabilities)
 	capabilities = agent.appendDockerDependentCapabilities(capabilities, supportedVersions)
 
 	// TODO: gate this on docker api version when ecs supported docker includes
@@ -131,6 +172,7 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 
 	if agent.cfg.GPUSupportEnabled {
 		capabilities = agent.appendNvidiaDriverVersionAttribute(capabilities)
+		capabilities = agent.appendNvidiaGPUAttributes(capabilities)
 	}
 	// support ecr endpoint override
 	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityECREndpoint)
@@ -141,9 +183,110 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	// support elastic inference in agent
 	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+taskEIAAttributeSuffix)
 
+	// containers can only enable execute command when its agent is installed
+	if agent.execAgentAvailable != nil && agent.execAgentAvailable() {
+		capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityExecuteCommand)
+	}
+
+	capabilities = appendHostAccessAttributes(capabilities, agent.cfg)
+
+	if agent.cfg.DrainProtection {
+		capabilities = appendNameOnlyAttribute(capabilities, drainProtectedAttributeName)
+	}
+
+	capabilities = appendComplianceAttributes(capabilities, agent.cfg.ComplianceFrameworks)
+
+	capabilities = agent.appendNetworkMTUAttribute(capabilities)
+
+	capabilities = agent.appendDockerDaemonAttributes(capabilities)
+
 	return capabilities, nil
 }
 
+// appendHostAccessAttributes reports whether the instance is configured to allow
+// privileged containers and tasks sharing the host's PID and IPC namespaces, so
+// that such tasks can be kept off locked-down instances with placement constraints
+func appendHostAccessAttributes(capabilities []*ecs.Attribute, cfg *config.Config) []*ecs.Attribute {
+	return append(capabilities,
+		&ecs.Attribute{
+			Name:  aws.String(allowPrivilegedAttributeName),
+			Value: aws.String(strconv.FormatBool(!cfg.PrivilegedDisabled)),
+		},
+		&ecs.Attribute{
+			Name:  aws.String(allowHostPIDAttributeName),
+			Value: aws.String(strconv.FormatBool(!cfg.HostPIDDisabled)),
+		},
+		&ecs.Attribute{
+			Name:  aws.String(allowHostIPCAttributeName),
+			Value: aws.String(strconv.FormatBool(!cfg.HostIPCDisabled)),
+		},
+	)
+}
+
+// appendComplianceAttributes reports each compliance framework the instance is
+// certified for as a distinct attribute
+func appendComplianceAttributes(capabilities []*ecs.Attribute, frameworks []string) []*ecs.Attribute {
+	for _, framework := range frameworks {
+		capabilities = appendNameOnlyAttribute(capabilities, complianceAttributePrefix+framework)
+	}
+	return capabilities
+}
+
+// appendNetworkMTUAttribute reports the MTU of the primary network interface,
+// for platforms where it can be detected
+func (agent *ecsAgent) appendNetworkMTUAttribute(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if agent.primaryInterfaceMTU == nil {
+		return capabilities
+	}
+	mtu, err := agent.primaryInterfaceMTU()
+	if err != nil {
+		seelog.Warnf("Unable to determine the MTU of the primary network interface: %v", err)
+		return capabilities
+	}
+	return append(capabilities, &ecs.Attribute{
+		Name:  aws.String(networkMTUAttributeName),
+		Value: aws.String(strconv.Itoa(mtu)),
+	})
+}
+
+// appendDockerDaemonAttributes reports the docker daemon options that affect
+// whether a task can be placed on this instance. Failing to query the daemon
+// is not fatal, the attributes are simply omitted.
+func (agent *ecsAgent) appendDockerDaemonAttributes(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	info, err := agent.dockerClient.Info(agent.ctx, dockerclient.InfoTimeout)
+	if err != nil {
+		seelog.Warnf("Unable to get docker daemon info, daemon attributes will not be reported: %v", err)
+		return capabilities
+	}
+	return append(capabilities, dockerDaemonAttributes(info)...)
+}
+
+func dockerDaemonAttributes(info types.Info) []*ecs.Attribute {
+	var attributes []*ecs.Attribute
+	if info.LiveRestoreEnabled {
+		attributes = appendNameOnlyAttribute(attributes, dockerAttributePrefix+dockerLiveRestoreAttributeSuffix)
+	}
+
+	securityOptions, err := types.DecodeSecurityOptions(info.SecurityOptions)
+	if err != nil {
+		seelog.Warnf("Unable to decode docker daemon security options: %v", err)
+	}
+	for _, option := range securityOptions {
+		if option.Name == dockerUsernsSecurityOption {
+			attributes = appendNameOnlyAttribute(attributes, dockerAttributePrefix+dockerUsernsRemapAttributeSuffix)
+			break
+		}
+	}
+
+	if info.DefaultRuntime != "" {
+		attributes = append(attributes, &ecs.Attribute{
+			Name:  aws.String(dockerAttributePrefix + dockerDefaultRuntimeAttributeSuffix),
+			Value: aws.String(info.DefaultRuntime),
+		})
+	}
+	return attributes
+}
+
 func (agent *ecsAgent) appendDockerDependentCapabilities(capabilities []*ecs.Attribute,
 	supportedVersions map[dockerclient.DockerVersion]bool) []*ecs.Attribute {
 	if _, ok := supportedVersions[dockerclient.Version_1_19]; ok {
@@ -166,10 +309,25 @@ func (agent *ecsAgent) appendLoggingDriverCapabilities(capabilities []*ecs.Attri
 		knownVersions[version] = struct{}{}
 	}
 
+	fluentdAvailable := false
 	for _, loggingDriver := range agent.cfg.AvailableLoggingDrivers {
 		requiredVersion := dockerclient.LoggingDriverMinimumVersion[loggingDriver]
 		if _, ok := knownVersions[requiredVersion]; ok {
 			capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"logging-driver."+string(loggingDriver))
+			if loggingDriver == dockerclient.FluentdDriver {
+				fluentdAvailable = true
+			}
+		}
+	}
+
+	// FireLens log routers receive the container logs through the fluentd
+	// logging driver
+	if agent.cfg.FireLensCapable {
+		if fluentdAvailable {
+			capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityFireLensFluentbit)
+			capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityFireLensFluentd)
+		} else {
+			seelog.Warn("FireLens is not enabled as the fluentd logging driver is not available")
 		}
 	}
 	return capabilities
@@ -16,7 +16,9 @@
 package app
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -33,6 +35,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper/mocks"
 	"github.com/aws/aws-sdk-go/aws"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -77,6 +80,7 @@ func TestCapabilities(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	expectedCapabilityNames := []string{
@@ -159,6 +163,7 @@ func TestCapabilitiesECR(t *testing.T) {
 		dockerclient.Version_1_19,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -201,6 +206,7 @@ func TestCapabilitiesTaskIAMRoleForSupportedDockerVersion(t *testing.T) {
 		dockerclient.Version_1_19,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -240,6 +246,7 @@ func TestCapabilitiesTaskIAMRoleForUnSupportedDockerVersion(t *testing.T) {
 		dockerclient.Version_1_18,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -280,6 +287,7 @@ func TestCapabilitiesTaskIAMRoleNetworkHostForSupportedDockerVersion(t *testing.
 		dockerclient.Version_1_19,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -320,6 +328,7 @@ func TestCapabilitiesTaskIAMRoleNetworkHostForUnSupportedDockerVersion(t *testin
 		dockerclient.Version_1_18,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -375,6 +384,7 @@ func TestAWSVPCBlockInstanceMetadataWhenTaskENIIsDisabled(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	expectedCapabilityNames := []string{
@@ -432,6 +442,7 @@ func TestCapabilitiesExecutionRoleAWSLogs(t *testing.T) {
 		dockerclient.Version_1_17,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return("v1", errors.New("some error happened"))
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -474,6 +485,7 @@ func TestCapabilitiesTaskResourceLimit(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
@@ -514,6 +526,7 @@ func TestCapabilitesTaskResourceLimitDisabledByMissingDockerVersion(t *testing.T
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
@@ -576,6 +589,7 @@ func TestCapabilitiesContainerHealth(t *testing.T) {
 		dockerclient.Version_1_24,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -613,6 +627,7 @@ func TestCapabilitiesContainerHealthDisabled(t *testing.T) {
 		dockerclient.Version_1_24,
 	})
 	client.EXPECT().KnownVersions().Return(nil)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -651,6 +666,7 @@ func TestCapabilitesListPluginsErrorCase(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return(nil, errors.New("listPlugins error happened")),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
@@ -685,6 +701,7 @@ func TestCapabilitesScanPluginsErrorCase(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return(nil, errors.New("Scan plugins error happened")),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
@@ -705,3 +722,403 @@ func TestCapabilitesScanPluginsErrorCase(t *testing.T) {
 		}
 	}
 }
+
+func TestCapabilitiesDockerDaemonAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Trimmed down response of the docker /info API
+	sampleInfo := `{
+		"ID": "7TRN:IPZB:QYBB:VPBQ:UWRH:4ZS3:6JOQ:JCYV:XLMS:K3ZM:ZLSX:EFEP",
+		"Driver": "overlay2",
+		"LiveRestoreEnabled": true,
+		"DefaultRuntime": "runc",
+		"Runtimes": {"runc": {"path": "docker-runc"}},
+		"SecurityOptions": ["name=seccomp,profile=default", "name=userns"]
+	}`
+	var info types.Info
+	require.NoError(t, json.Unmarshal([]byte(sampleInfo), &info))
+
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+	gomock.InOrder(
+		client.EXPECT().SupportedVersions().Return(versionList),
+		client.EXPECT().KnownVersions().Return(versionList),
+		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(info, nil),
+	)
+	mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	// Cancel the context to cancel async routines
+	defer cancel()
+	agent := &ecsAgent{
+		ctx:          ctx,
+		cfg:          &config.Config{},
+		dockerClient: client,
+		mobyPlugins:  mockMobyPlugins,
+	}
+
+	capabilities, err := agent.capabilities()
+	require.NoError(t, err)
+
+	attributes := make(map[string]string)
+	for _, capability := range capabilities {
+		attributes[aws.StringValue(capability.Name)] = aws.StringValue(capability.Value)
+	}
+	assert.Contains(t, attributes, "ecs.docker.live-restore")
+	assert.Contains(t, attributes, "ecs.docker.userns-remap")
+	assert.Equal(t, "runc", attributes["ecs.docker.default-runtime"])
+}
+
+func TestCapabilitiesDockerDaemonAttributesInfoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+	gomock.InOrder(
+		client.EXPECT().SupportedVersions().Return(versionList),
+		client.EXPECT().KnownVersions().Return(versionList),
+		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, errors.New("info error")),
+	)
+	mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	// Cancel the context to cancel async routines
+	defer cancel()
+	agent := &ecsAgent{
+		ctx:          ctx,
+		cfg:          &config.Config{},
+		dockerClient: client,
+		mobyPlugins:  mockMobyPlugins,
+	}
+
+	capabilities, err := agent.capabilities()
+	require.NoError(t, err)
+
+	for _, capability := range capabilities {
+		assert.False(t, strings.HasPrefix(aws.StringValue(capability.Name), dockerAttributePrefix))
+	}
+}
+
+func TestCapabilitiesNetworkMTU(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mtu           int
+		mtuErr        error
+		expectedValue string
+	}{
+		{
+			name:          "mtu detected",
+			mtu:           9001,
+			expectedValue: "9001",
+		},
+		{
+			name:   "mtu detection error",
+			mtuErr: errors.New("no default route"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+			gomock.InOrder(
+				client.EXPECT().SupportedVersions().Return(versionList),
+				client.EXPECT().KnownVersions().Return(versionList),
+				client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any()).AnyTimes().Return([]string{}, nil),
+				client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+			)
+			mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+			mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+			ctx, cancel := context.WithCancel(context.TODO())
+			// Cancel the context to cancel async routines
+			defer cancel()
+			agent := &ecsAgent{
+				ctx:          ctx,
+				cfg:          &config.Config{},
+				dockerClient: client,
+				mobyPlugins:  mockMobyPlugins,
+				primaryInterfaceMTU: func() (int, error) {
+					return tc.mtu, tc.mtuErr
+				},
+			}
+
+			capabilities, err := agent.capabilities()
+			require.NoError(t, err)
+
+			var mtuAttributes []*ecs.Attribute
+			for _, capability := range capabilities {
+				if aws.StringValue(capability.Name) == networkMTUAttributeName {
+					mtuAttributes = append(mtuAttributes, capability)
+				}
+			}
+			if tc.mtuErr != nil {
+				assert.Empty(t, mtuAttributes)
+				return
+			}
+			require.Len(t, mtuAttributes, 1)
+			assert.Equal(t, tc.expectedValue, aws.StringValue(mtuAttributes[0].Value))
+		})
+	}
+}
+
+func TestAppendHostAccessAttributes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      *config.Config
+		expected map[string]string
+	}{
+		{
+			name: "all allowed",
+			cfg:  &config.Config{},
+			expected: map[string]string{
+				allowPrivilegedAttributeName: "true",
+				allowHostPIDAttributeName:    "true",
+				allowHostIPCAttributeName:    "true",
+			},
+		},
+		{
+			name: "privileged disabled",
+			cfg:  &config.Config{PrivilegedDisabled: true},
+			expected: map[string]string{
+				allowPrivilegedAttributeName: "false",
+				allowHostPIDAttributeName:    "true",
+				allowHostIPCAttributeName:    "true",
+			},
+		},
+		{
+			name: "all disabled",
+			cfg: &config.Config{
+				PrivilegedDisabled: true,
+				HostPIDDisabled:    true,
+				HostIPCDisabled:    true,
+			},
+			expected: map[string]string{
+				allowPrivilegedAttributeName: "false",
+				allowHostPIDAttributeName:    "false",
+				allowHostIPCAttributeName:    "false",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attributes := appendHostAccessAttributes(nil, tc.cfg)
+			actual := make(map[string]string)
+			for _, attribute := range attributes {
+				actual[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCapabilitiesDrainProtection(t *testing.T) {
+	for _, drainProtection := range []bool{true, false} {
+		t.Run(fmt.Sprintf("drain protection %t", drainProtection), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+			gomock.InOrder(
+				client.EXPECT().SupportedVersions().Return(versionList),
+				client.EXPECT().KnownVersions().Return(versionList),
+				client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any()).AnyTimes().Return([]string{}, nil),
+				client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+			)
+			mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+			mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+			ctx, cancel := context.WithCancel(context.TODO())
+			// Cancel the context to cancel async routines
+			defer cancel()
+			agent := &ecsAgent{
+				ctx:          ctx,
+				cfg:          &config.Config{DrainProtection: drainProtection},
+				dockerClient: client,
+				mobyPlugins:  mockMobyPlugins,
+			}
+
+			capabilities, err := agent.capabilities()
+			require.NoError(t, err)
+
+			found := false
+			for _, capability := range capabilities {
+				if aws.StringValue(capability.Name) == drainProtectedAttributeName {
+					found = true
+				}
+			}
+			assert.Equal(t, drainProtection, found)
+		})
+	}
+}
+
+func TestCapabilitiesMemorySwap(t *testing.T) {
+	for _, swapAccountingEnabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("swap accounting %t", swapAccountingEnabled), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+			gomock.InOrder(
+				client.EXPECT().SupportedVersions().Return(versionList),
+				client.EXPECT().KnownVersions().Return(versionList),
+				client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any()).AnyTimes().Return([]string{}, nil),
+				client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+			)
+			mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+			mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+			ctx, cancel := context.WithCancel(context.TODO())
+			// Cancel the context to cancel async routines
+			defer cancel()
+			enabled := swapAccountingEnabled
+			agent := &ecsAgent{
+				ctx:                   ctx,
+				cfg:                   &config.Config{},
+				dockerClient:          client,
+				mobyPlugins:           mockMobyPlugins,
+				swapAccountingEnabled: func() bool { return enabled },
+			}
+
+			capabilities, err := agent.capabilities()
+			require.NoError(t, err)
+
+			found := false
+			for _, capability := range capabilities {
+				if aws.StringValue(capability.Name) == capabilityPrefix+capabilityMemorySwap {
+					found = true
+				}
+			}
+			assert.Equal(t, swapAccountingEnabled, found)
+		})
+	}
+}
+
+func TestCapabilitiesExecuteCommand(t *testing.T) {
+	for _, execAgentAvailable := range []bool{true, false} {
+		t.Run(fmt.Sprintf("execute command agent available %t", execAgentAvailable), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			versionList := []dockerclient.DockerVersion{dockerclient.Version_1_19}
+			gomock.InOrder(
+				client.EXPECT().SupportedVersions().Return(versionList),
+				client.EXPECT().KnownVersions().Return(versionList),
+				client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any()).AnyTimes().Return([]string{}, nil),
+				client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+			)
+			mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+			mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
+			ctx, cancel := context.WithCancel(context.TODO())
+			// Cancel the context to cancel async routines
+			defer cancel()
+			available := execAgentAvailable
+			agent := &ecsAgent{
+				ctx:                ctx,
+				cfg:                &config.Config{},
+				dockerClient:       client,
+				mobyPlugins:        mockMobyPlugins,
+				execAgentAvailable: func() bool { return available },
+			}
+
+			capabilities, err := agent.capabilities()
+			require.NoError(t, err)
+
+			found := false
+			for _, capability := range capabilities {
+				if aws.StringValue(capability.Name) == attributePrefix+capabilityExecuteCommand {
+					found = true
+				}
+			}
+			assert.Equal(t, execAgentAvailable, found)
+		})
+	}
+}
+
+func TestAppendComplianceAttributes(t *testing.T) {
+	capabilities := appendComplianceAttributes(nil, []string{config.ComplianceFrameworkHIPAA, config.ComplianceFrameworkPCI})
+	assert.Equal(t, []*ecs.Attribute{
+		{Name: aws.String("ecs.compliance/hipaa")},
+		{Name: aws.String("ecs.compliance/pci")},
+	}, capabilities)
+
+	assert.Empty(t, appendComplianceAttributes(nil, nil))
+}
+
+func TestAppendLoggingDriverCapabilitiesFireLens(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		fireLensCapable      bool
+		loggingDrivers       []dockerclient.LoggingDriver
+		expectedCapabilities []string
+	}{
+		{
+			name:            "firelens capable with fluentd",
+			fireLensCapable: true,
+			loggingDrivers:  []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.FluentdDriver},
+			expectedCapabilities: []string{
+				capabilityPrefix + "logging-driver.json-file",
+				capabilityPrefix + "logging-driver.fluentd",
+				capabilityPrefix + capabilityFireLensFluentbit,
+				capabilityPrefix + capabilityFireLensFluentd,
+			},
+		},
+		{
+			name:            "firelens capable without fluentd",
+			fireLensCapable: true,
+			loggingDrivers:  []dockerclient.LoggingDriver{dockerclient.JSONFileDriver},
+			expectedCapabilities: []string{
+				capabilityPrefix + "logging-driver.json-file",
+			},
+		},
+		{
+			name:            "firelens not capable",
+			fireLensCapable: false,
+			loggingDrivers:  []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.FluentdDriver},
+			expectedCapabilities: []string{
+				capabilityPrefix + "logging-driver.json-file",
+				capabilityPrefix + "logging-driver.fluentd",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			client.EXPECT().KnownVersions().Return([]dockerclient.DockerVersion{
+				dockerclient.Version_1_18,
+				dockerclient.Version_1_20,
+			})
+			agent := &ecsAgent{
+				cfg: &config.Config{
+					AvailableLoggingDrivers: tc.loggingDrivers,
+					FireLensCapable:         tc.fireLensCapable,
+				},
+				dockerClient: client,
+			}
+
+			var capabilityNames []string
+			for _, capability := range agent.appendLoggingDriverCapabilities(nil) {
+				capabilityNames = append(capabilityNames, aws.StringValue(capability.Name))
+			}
+			assert.Equal(t, tc.expectedCapabilities, capabilityNames)
+		})
+	}
+}
@@ -16,16 +16,39 @@
 package app
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/eni/netlinkwrapper"
+	"github.com/aws/amazon-ecs-agent/agent/eni/networkutils"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/cihub/seelog"
 )
 
+const (
+	// gpuAttributeValueFormat is the format of the value of the per-device
+	// GPU attribute, which is kept compact to stay within the attribute value
+	// length limit, e.g. "memory-mb:15109/compute-capability:7.5"
+	gpuAttributeValueFormat = "memory-mb:%d/compute-capability:%s"
+
+	kernelCmdlinePath = "/proc/cmdline"
+	// memswLimitPath only exists in the cgroup memory controller when swap
+	// accounting is enabled
+	memswLimitPath = "/sys/fs/cgroup/memory/memory.memsw.limit_in_bytes"
+	// swapAccountKernelParameter enables or disables swap accounting on the
+	// kernel command line
+	swapAccountKernelParameter = "swapaccount"
+)
+
 func (agent *ecsAgent) appendVolumeDriverCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
 	// "local" is default docker driver
 	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityDockerPluginInfix+volume.DockerLocalVolumeDriver)
@@ -75,3 +98,73 @@ func (agent *ecsAgent) appendNvidiaDriverVersionAttribute(capabilities []*ecs.At
 	}
 	return capabilities
 }
+
+// appendNvidiaGPUAttributes appends an attribute per GPU device, describing
+// its memory and compute capability. The attributes are named after the index
+// of the device, so that placement constraints do not depend on the host's
+// GPUs. The devices are only known when the GPU info file written by ecs-init
+// lists them, the agent does not query NVML itself
+func (agent *ecsAgent) appendNvidiaGPUAttributes(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if agent.resourceFields == nil || agent.resourceFields.NvidiaGPUManager == nil {
+		return capabilities
+	}
+	index := 0
+	for _, gpuInfo := range agent.resourceFields.NvidiaGPUManager.GetGPUs() {
+		if gpuInfo == nil || gpuInfo.ID == "" {
+			continue
+		}
+		capabilities = append(capabilities, &ecs.Attribute{
+			Name:  aws.String(attributePrefix + capabilityNvidiaGPUInfix + strconv.Itoa(index)),
+			Value: aws.String(gpuAttributeValue(gpuInfo)),
+		})
+		index++
+	}
+	return capabilities
+}
+
+func gpuAttributeValue(gpuInfo *gpu.GPUInfo) string {
+	return fmt.Sprintf(gpuAttributeValueFormat, gpuInfo.MemoryMB, gpuInfo.ComputeCapability)
+}
+
+// newPrimaryInterfaceMTUResolver returns a function that reads the MTU of the
+// interface with the default route
+func newPrimaryInterfaceMTUResolver() func() (int, error) {
+	netlinkClient := netlinkwrapper.New()
+	return func() (int, error) {
+		return networkutils.GetPrimaryInterfaceMTU(netlinkClient)
+	}
+}
+
+// newSwapAccountingResolver returns a function that checks whether swap
+// accounting is enabled on the host
+func newSwapAccountingResolver() func() bool {
+	return func() bool {
+		return isSwapAccountingEnabled(kernelCmdlinePath, memswLimitPath)
+	}
+}
+
+// isSwapAccountingEnabled checks that the swap accounting control file exists
+// in the cgroup memory controller and that swap accounting was not disabled on
+// the kernel command line
+func isSwapAccountingEnabled(cmdlinePath string, memswPath string) bool {
+	if _, err := os.Stat(memswPath); err != nil {
+		seelog.Debugf("Swap accounting is not available, unable to stat %s: %v", memswPath, err)
+		return false
+	}
+
+	cmdline, err := ioutil.ReadFile(cmdlinePath)
+	if err != nil {
+		seelog.Warnf("Unable to read the kernel command line from %s: %v", cmdlinePath, err)
+		return true
+	}
+	enabled := true
+	// The last occurrence of the parameter takes effect
+	for _, parameter := range strings.Fields(string(cmdline)) {
+		if parameter == swapAccountKernelParameter+"=0" {
+			enabled = false
+		} else if strings.HasPrefix(parameter, swapAccountKernelParameter+"=") {
+			enabled = true
+		}
+	}
+	return enabled
+}
@@ -16,6 +16,7 @@
 package app
 
 import (
+	"path/filepath"
 	"testing"
 
 	"context"
@@ -32,6 +33,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper/mocks"
 	"github.com/aws/aws-sdk-go/aws"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -75,6 +77,7 @@ func TestVolumeDriverCapabilitiesUnix(t *testing.T) {
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).Return(
 			[]string{"coolvolumedriver", "volumedriver:latest"}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	expectedCapabilityNames := []string{
@@ -162,6 +165,7 @@ func TestNvidiaDriverCapabilitiesUnix(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	expectedCapabilityNames := []string{
@@ -218,6 +222,27 @@ func TestNvidiaDriverCapabilitiesUnix(t *testing.T) {
 	}
 }
 
+func TestNvidiaGPUAttributesUnix(t *testing.T) {
+	agent := &ecsAgent{
+		resourceFields: &taskresource.ResourceFields{
+			NvidiaGPUManager: &gpu.NvidiaGPUManager{
+				GPUs: []*gpu.GPUInfo{
+					{ID: "GPU-0d2b1c3a", MemoryMB: 15109, ComputeCapability: "7.5"},
+					{ID: "GPU-5e8f4a21", MemoryMB: 32510, ComputeCapability: "7.0"},
+					{MemoryMB: 1024, ComputeCapability: "3.7"},
+				},
+			},
+		},
+	}
+
+	attributes := agent.appendNvidiaGPUAttributes(nil)
+	assert.Len(t, attributes, 2)
+	assert.Equal(t, attributePrefix+"nvidia-gpu.0", aws.StringValue(attributes[0].Name))
+	assert.Equal(t, "memory-mb:15109/compute-capability:7.5", aws.StringValue(attributes[0].Value))
+	assert.Equal(t, attributePrefix+"nvidia-gpu.1", aws.StringValue(attributes[1].Name))
+	assert.Equal(t, "memory-mb:32510/compute-capability:7.0", aws.StringValue(attributes[1].Value))
+}
+
 func TestEmptyNvidiaDriverCapabilitiesUnix(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -240,6 +265,7 @@ func TestEmptyNvidiaDriverCapabilitiesUnix(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	expectedCapabilityNames := []string{
@@ -291,3 +317,21 @@ func TestEmptyNvidiaDriverCapabilitiesUnix(t *testing.T) {
 		assert.Equal(t, aws.StringValue(expected.Value), aws.StringValue(capabilities[i].Value))
 	}
 }
+
+func TestIsSwapAccountingEnabled(t *testing.T) {
+	testCases := []struct {
+		fixture  string
+		expected bool
+	}{
+		{"swap-accounting-on", true},
+		{"swap-accounting-off", false},
+		{"swap-accounting-disabled", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			dir := filepath.Join("testdata", tc.fixture)
+			assert.Equal(t, tc.expected, isSwapAccountingEnabled(
+				filepath.Join(dir, "cmdline"), filepath.Join(dir, "memory.memsw.limit_in_bytes")))
+		})
+	}
+}
@@ -69,3 +69,15 @@ func (agent *ecsAgent) appendVolumeDriverCapabilities(capabilities []*ecs.Attrib
 func (agent *ecsAgent) appendNvidiaDriverVersionAttribute(capabilities []*ecs.Attribute) []*ecs.Attribute {
 	return capabilities
 }
+
+func (agent *ecsAgent) appendNvidiaGPUAttributes(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
+
+func newPrimaryInterfaceMTUResolver() func() (int, error) {
+	return nil
+}
+
+func newSwapAccountingResolver() func() bool {
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// defaultCredentialSourceOrder is the order in which the credential sources
+// are tried when none is configured, the same as the SDK's default chain
+var defaultCredentialSourceOrder = []string{
+	config.CredentialSourceEnv,
+	config.CredentialSourceFile,
+	config.CredentialSourceInstanceRole,
+}
+
+// newCredentialProvider returns the credential provider used by the agent for
+// its own calls to ECS (including acs/tcs). The sources are tried in the
+// configured order, or in the order of the SDK's default chain when none is
+// configured.
+func newCredentialProvider(cfg *config.Config) (*aws_credentials.Credentials, error) {
+	sources := cfg.CredentialSourceOrder
+	if len(sources) == 0 {
+		sources = defaultCredentialSourceOrder
+	}
+	providers, err := credentialProviders(sources, defaults.Config(), defaults.Handlers())
+	if err != nil {
+		return nil, err
+	}
+	return aws_credentials.NewCredentials(&aws_credentials.ChainProvider{
+		VerboseErrors: true,
+		Providers:     providers,
+	}), nil
+}
+
+// credentialProviders maps each credential source name to its provider,
+// preserving the order of sources
+func credentialProviders(sources []string,
+	sdkConfig *aws.Config,
+	handlers request.Handlers) ([]aws_credentials.Provider, error) {
+	var providers []aws_credentials.Provider
+	for _, source := range sources {
+		switch source {
+		case config.CredentialSourceEnv:
+			providers = append(providers, &aws_credentials.EnvProvider{})
+		case config.CredentialSourceFile:
+			providers = append(providers, &aws_credentials.SharedCredentialsProvider{})
+		case config.CredentialSourceInstanceRole:
+			providers = append(providers, remoteCredentialProvider(sdkConfig, handlers))
+		default:
+			return nil, fmt.Errorf("unknown credential source %q", source)
+		}
+	}
+	return providers, nil
+}
+
+// remoteCredentialProvider returns the SDK's provider for the remote
+// credential endpoints, except that the instance role credentials are fetched
+// with instance metadata session tokens (IMDSv2), which the vendored SDK
+// doesn't support
+func remoteCredentialProvider(sdkConfig *aws.Config, handlers request.Handlers) aws_credentials.Provider {
+	provider := defaults.RemoteCredProvider(*sdkConfig, handlers)
+	if _, ok := provider.(*ec2rolecreds.EC2RoleProvider); ok {
+		return ec2.NewRoleCredentialsProvider()
+	}
+	return provider
+}
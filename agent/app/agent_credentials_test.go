@@ -0,0 +1,97 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSharedCredentials = `[default]
+aws_access_key_id = fileAccessKey
+aws_secret_access_key = fileSecretKey
+`
+
+func TestCredentialProvidersOrder(t *testing.T) {
+	providers, err := credentialProviders([]string{
+		config.CredentialSourceFile,
+		config.CredentialSourceEnv,
+		config.CredentialSourceInstanceRole,
+	}, defaults.Config(), defaults.Handlers())
+	require.NoError(t, err)
+	require.Len(t, providers, 3)
+	assert.IsType(t, &aws_credentials.SharedCredentialsProvider{}, providers[0])
+	assert.IsType(t, &aws_credentials.EnvProvider{}, providers[1])
+	// The instance role credentials are fetched with IMDSv2 session tokens
+	assert.IsType(t, &ec2rolecreds.EC2RoleProvider{}, providers[2])
+}
+
+func TestCredentialProvidersUnknownSource(t *testing.T) {
+	_, err := credentialProviders([]string{config.CredentialSourceEnv, "vault"},
+		defaults.Config(), defaults.Handlers())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "vault")
+}
+
+func TestNewCredentialProviderRespectsConfiguredOrder(t *testing.T) {
+	credentialsFile, err := ioutil.TempFile("", "ecs-agent-credentials")
+	require.NoError(t, err)
+	defer os.Remove(credentialsFile.Name())
+	_, err = credentialsFile.WriteString(testSharedCredentials)
+	require.NoError(t, err)
+	credentialsFile.Close()
+
+	defer setEnv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile.Name())()
+	defer setEnv("AWS_ACCESS_KEY_ID", "envAccessKey")()
+	defer setEnv("AWS_SECRET_ACCESS_KEY", "envSecretKey")()
+
+	testCases := []struct {
+		order             []string
+		expectedAccessKey string
+	}{
+		{[]string{config.CredentialSourceEnv, config.CredentialSourceFile}, "envAccessKey"},
+		{[]string{config.CredentialSourceFile, config.CredentialSourceEnv}, "fileAccessKey"},
+	}
+
+	for _, tc := range testCases {
+		provider, err := newCredentialProvider(&config.Config{CredentialSourceOrder: tc.order})
+		require.NoError(t, err)
+		creds, err := provider.Get()
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedAccessKey, creds.AccessKeyID)
+	}
+}
+
+func TestNewCredentialProviderDefaultChain(t *testing.T) {
+	provider, err := newCredentialProvider(&config.Config{})
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+}
+
+func setEnv(key, value string) func() {
+	os.Setenv(key, value)
+	return func() {
+		os.Unsetenv(key)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"strconv"
+
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+const (
+	eniTrunkingAttributeName    = "ecs.eni-trunking"
+	branchENILimitAttributeName = "ecs.branch-eni-limit"
+)
+
+// appendENITrunkingAttributes reports whether awsvpc tasks can use branch ENIs
+// on this instance and, if they can, how many branch ENIs are available. The
+// attributes are only reported when ENI trunking is enabled, and trunking is
+// only reported as usable when a branch ENI limit is configured for the
+// instance type
+func (agent *ecsAgent) appendENITrunkingAttributes(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if !agent.cfg.ENITrunkingEnabled {
+		return capabilities
+	}
+	if !agent.cfg.TaskENIEnabled {
+		seelog.Warn("ENI trunking is enabled, but task networking is not, ENI trunking will not be used")
+		return appendENITrunkingAttribute(capabilities, false)
+	}
+
+	instanceType, err := agent.ec2MetadataClient.GetMetadata(ec2.InstanceTypeResource)
+	if err != nil {
+		seelog.Warnf("Unable to get the instance type from EC2 metadata, ENI trunking will not be used: %v", err)
+		return appendENITrunkingAttribute(capabilities, false)
+	}
+	limit, ok := agent.cfg.BranchENILimits[instanceType]
+	if !ok {
+		seelog.Warnf("No branch ENI limit is configured for instance type %s in ECS_BRANCH_ENI_LIMITS, ENI trunking will not be used",
+			instanceType)
+		return appendENITrunkingAttribute(capabilities, false)
+	}
+
+	capabilities = appendENITrunkingAttribute(capabilities, true)
+	return append(capabilities, &ecs.Attribute{
+		Name:  aws.String(branchENILimitAttributeName),
+		Value: aws.String(strconv.Itoa(limit)),
+	})
+}
+
+func appendENITrunkingAttribute(capabilities []*ecs.Attribute, enabled bool) []*ecs.Attribute {
+	return append(capabilities, &ecs.Attribute{
+		Name:  aws.String(eniTrunkingAttributeName),
+		Value: aws.String(strconv.FormatBool(enabled)),
+	})
+}
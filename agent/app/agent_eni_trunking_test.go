@@ -0,0 +1,109 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendENITrunkingAttributes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceType string
+		expected     map[string]string
+	}{
+		{
+			name:         "trunking capable instance type",
+			instanceType: "c5.xlarge",
+			expected: map[string]string{
+				eniTrunkingAttributeName:    "true",
+				branchENILimitAttributeName: "18",
+			},
+		},
+		{
+			name:         "non capable instance type",
+			instanceType: "t2.micro",
+			expected: map[string]string{
+				eniTrunkingAttributeName: "false",
+			},
+		},
+		{
+			name:         "instance size without a configured limit",
+			instanceType: "c5.2xlarge",
+			expected: map[string]string{
+				eniTrunkingAttributeName: "false",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+			ec2MetadataClient.EXPECT().GetMetadata(ec2.InstanceTypeResource).Return(tc.instanceType, nil)
+			agent := &ecsAgent{
+				cfg: &config.Config{
+					TaskENIEnabled:     true,
+					ENITrunkingEnabled: true,
+					BranchENILimits:    map[string]int{"c5.xlarge": 18},
+				},
+				ec2MetadataClient: ec2MetadataClient,
+			}
+
+			assert.Equal(t, tc.expected, attributeValues(agent.appendENITrunkingAttributes(nil)))
+		})
+	}
+}
+
+func TestAppendENITrunkingAttributesInstanceTypeUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.InstanceTypeResource).Return("", errors.New("error"))
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			TaskENIEnabled:     true,
+			ENITrunkingEnabled: true,
+		},
+		ec2MetadataClient: ec2MetadataClient,
+	}
+
+	assert.Equal(t, map[string]string{eniTrunkingAttributeName: "false"},
+		attributeValues(agent.appendENITrunkingAttributes(nil)))
+}
+
+func TestAppendENITrunkingAttributesDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// EC2 metadata is not expected to be queried when trunking is disabled
+	agent := &ecsAgent{
+		cfg:               &config.Config{TaskENIEnabled: true},
+		ec2MetadataClient: mock_ec2.NewMockEC2MetadataClient(ctrl),
+	}
+
+	assert.Empty(t, agent.appendENITrunkingAttributes(nil))
+}
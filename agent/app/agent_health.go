@@ -0,0 +1,83 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	acshandler "github.com/aws/amazon-ecs-agent/agent/acs/handler"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// healthCheckDockerTimeout bounds the request made to the docker daemon by
+	// the health check, so that it completes within the write timeout of the
+	// introspection server
+	healthCheckDockerTimeout = 3 * time.Second
+	// acsDisconnectedGracePeriod is how long the agent may stay disconnected
+	// from ACS, such as while it reconnects with backoff, before it is reported
+	// as unhealthy
+	acsDisconnectedGracePeriod = 5 * time.Minute
+)
+
+// healthChecks returns the checks run by the health endpoint of the
+// introspection server
+func (agent *ecsAgent) healthChecks(acsSession acshandler.Session) map[string]v1.HealthCheck {
+	checks := map[string]v1.HealthCheck{
+		"docker": agent.checkDockerHealth,
+		"acs": func() error {
+			return checkACSHealth(acsSession, time.Now())
+		},
+	}
+	if agent.cfg.Checkpoint {
+		checks["state"] = agent.checkStateHealth
+	}
+	return checks
+}
+
+// checkDockerHealth checks that the docker daemon responds to requests
+func (agent *ecsAgent) checkDockerHealth() error {
+	_, err := agent.dockerClient.Info(agent.ctx, healthCheckDockerTimeout)
+	if err != nil {
+		return errors.Wrap(err, "docker daemon is unreachable")
+	}
+	return nil
+}
+
+// checkACSHealth checks that the agent is connected to ACS, or was
+// disconnected recently enough to be reconnecting
+func checkACSHealth(acsSession acshandler.Session, now time.Time) error {
+	connected, since := acsSession.Connected()
+	if connected {
+		return nil
+	}
+	if disconnected := now.Sub(since); disconnected > acsDisconnectedGracePeriod {
+		return errors.Errorf("not connected to ACS for %s", disconnected.Round(time.Second).String())
+	}
+	return nil
+}
+
+// checkStateHealth checks that the state file can be written to the data
+// directory
+func (agent *ecsAgent) checkStateHealth() error {
+	file, err := ioutil.TempFile(agent.cfg.DataDir, ".healthz")
+	if err != nil {
+		return errors.Wrap(err, "data directory is not writable")
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
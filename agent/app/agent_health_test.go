@@ -0,0 +1,92 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeACSSession reports a fixed connection state
+type fakeACSSession struct {
+	connected bool
+	since     time.Time
+}
+
+func (session *fakeACSSession) Start() error {
+	return nil
+}
+
+func (session *fakeACSSession) Connected() (bool, time.Time) {
+	return session.connected, session.since
+}
+
+func TestCheckACSHealth(t *testing.T) {
+	now := time.Now()
+	assert.NoError(t, checkACSHealth(&fakeACSSession{connected: true, since: now.Add(-time.Hour)}, now))
+	assert.NoError(t, checkACSHealth(&fakeACSSession{since: now.Add(-time.Minute)}, now),
+		"Recent disconnections should be healthy while reconnecting")
+	assert.Error(t, checkACSHealth(&fakeACSSession{since: now.Add(-time.Hour)}, now))
+}
+
+func TestCheckDockerHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	agent := &ecsAgent{ctx: context.TODO(), dockerClient: dockerClient}
+
+	gomock.InOrder(
+		dockerClient.EXPECT().Info(gomock.Any(), healthCheckDockerTimeout).Return(types.Info{}, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), healthCheckDockerTimeout).Return(types.Info{}, errors.New("connection refused")),
+	)
+	assert.NoError(t, agent.checkDockerHealth())
+	assert.Error(t, agent.checkDockerHealth())
+}
+
+func TestCheckStateHealth(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "healthz")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	agent := &ecsAgent{cfg: &config.Config{DataDir: dataDir, Checkpoint: true}}
+	assert.NoError(t, agent.checkStateHealth())
+	files, err := ioutil.ReadDir(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, files, "Health check should not leave files behind")
+
+	agent.cfg.DataDir = dataDir + "/does-not-exist"
+	assert.Error(t, agent.checkStateHealth())
+}
+
+func TestHealthChecksSkipStateWithoutCheckpoint(t *testing.T) {
+	agent := &ecsAgent{cfg: &config.Config{}}
+	checks := agent.healthChecks(&fakeACSSession{connected: true})
+	assert.Contains(t, checks, "docker")
+	assert.Contains(t, checks, "acs")
+	assert.NotContains(t, checks, "state")
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+const (
+	hostPortsTCPAttributeName = "ecs.host-ports.tcp"
+	hostPortsUDPAttributeName = "ecs.host-ports.udp"
+	// maxAttributeValueLength is the maximum length of an attribute value
+	// accepted by ECS
+	maxAttributeValueLength = 128
+	// maxHostPortsAttributes is the number of attributes the host ports of a
+	// protocol may be split across. The first attribute has the protocol's
+	// attribute name, the others add the suffix .2, .3 and so on
+	maxHostPortsAttributes = 5
+)
+
+// startHostPortsReporter periodically reports the reserved ports and the host
+// ports bound by running containers as container instance attributes, until
+// the agent's context is canceled
+func (agent *ecsAgent) startHostPortsReporter(refresher *attributeRefresher, state dockerstate.TaskEngineState) {
+	ticker := time.NewTicker(agent.cfg.HostPortsRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			agent.reportHostPorts(refresher, state)
+		case <-agent.ctx.Done():
+			return
+		}
+	}
+}
+
+// reportHostPorts queues the host ports attributes that differ from the values
+// last reported successfully to be reported with the next attribute refresh.
+// An attribute without ports is removed so that ECS does not keep showing
+// stale host ports. Values that ECS rejected are not queued again until they
+// change, as they would be rejected at every interval
+func (agent *ecsAgent) reportHostPorts(refresher *attributeRefresher, state dockerstate.TaskEngineState) {
+	values := hostPortsValues(agent.cfg.ReservedPorts, agent.cfg.ReservedPortsUDP, state)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := values[name]
+		if reported, ok := refresher.reportedValue(name); ok && reported == value {
+			continue
+		}
+		if rejected, ok := refresher.rejectedValue(name); ok && rejected == value {
+			continue
+		}
+		if value == "" {
+			refresher.remove(name)
+			continue
+		}
+		refresher.update(&ecs.Attribute{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+}
+
+// hostPortsValues builds the values of the host ports attributes from the
+// reserved ports and the ports bound by the running containers in the task
+// engine state, by attribute name. The value is empty for the attributes the
+// ports do not need
+func hostPortsValues(reservedTCP []uint16, reservedUDP []uint16, state dockerstate.TaskEngineState) map[string]string {
+	tcpPorts := make(map[uint16]struct{})
+	udpPorts := make(map[uint16]struct{})
+	for _, port := range reservedTCP {
+		tcpPorts[port] = struct{}{}
+	}
+	for _, port := range reservedUDP {
+		udpPorts[port] = struct{}{}
+	}
+
+	for _, task := range state.AllTasks() {
+		for _, container := range task.Containers {
+			if !container.GetKnownStatus().IsRunning() {
+				continue
+			}
+			for _, binding := range container.GetKnownPortBindings() {
+				switch binding.Protocol {
+				case apicontainer.TransportProtocolTCP:
+					tcpPorts[binding.HostPort] = struct{}{}
+				case apicontainer.TransportProtocolUDP:
+					udpPorts[binding.HostPort] = struct{}{}
+				}
+			}
+		}
+	}
+
+	values := make(map[string]string)
+	addHostPortsValues(values, hostPortsTCPAttributeName, tcpPorts)
+	addHostPortsValues(values, hostPortsUDPAttributeName, udpPorts)
+	return values
+}
+
+// addHostPortsValues splits the ports across the attributes of the protocol.
+// When the ports do not fit in these attributes, all of them are removed
+// rather than reporting part of the ports
+func addHostPortsValues(values map[string]string, name string, ports map[uint16]struct{}) {
+	chunks := hostPortsChunks(ports)
+	if len(chunks) > maxHostPortsAttributes {
+		seelog.Warnf("Too many host ports to report as attributes %s, removing them: %s",
+			name, strings.Join(chunks, " "))
+		chunks = nil
+	}
+	for i := 0; i < maxHostPortsAttributes; i++ {
+		value := ""
+		if i < len(chunks) {
+			value = chunks[i]
+		}
+		values[hostPortsAttributeName(name, i)] = value
+	}
+}
+
+// hostPortsAttributeName returns the name of the attribute at the index among
+// the attributes of the protocol
+func hostPortsAttributeName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s.%d", name, index+1)
+}
+
+// hostPortsChunks returns the sorted ports, with consecutive ports collapsed
+// into ranges such as "32768-32790", as values that each fit in an attribute
+func hostPortsChunks(ports map[uint16]struct{}) []string {
+	sortedPorts := make([]int, 0, len(ports))
+	for port := range ports {
+		sortedPorts = append(sortedPorts, int(port))
+	}
+	sort.Ints(sortedPorts)
+
+	var chunks []string
+	var chunk []string
+	chunkLength := 0
+	for i := 0; i < len(sortedPorts); {
+		first := sortedPorts[i]
+		last := first
+		for i++; i < len(sortedPorts) && sortedPorts[i] == last+1; i++ {
+			last = sortedPorts[i]
+		}
+		portRange := strconv.Itoa(first)
+		if last != first {
+			portRange += "-" + strconv.Itoa(last)
+		}
+		// Attribute values may not contain commas, use spaces as the separator
+		if len(chunk) > 0 && chunkLength+1+len(portRange) > maxAttributeValueLength {
+			chunks = append(chunks, strings.Join(chunk, " "))
+			chunk = nil
+			chunkLength = 0
+		}
+		if len(chunk) > 0 {
+			chunkLength++
+		}
+		chunk = append(chunk, portRange)
+		chunkLength += len(portRange)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, strings.Join(chunk, " "))
+	}
+	return chunks
+}
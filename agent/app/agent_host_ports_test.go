@@ -0,0 +1,265 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testHostPortsContainerInstanceARN = "arn:aws:ecs:us-west-2:123456789012:container-instance/instance"
+
+// hostPortsAttributes returns the values of all the host ports attributes,
+// with the given values for the first attributes of each protocol and empty
+// values for the others
+func hostPortsAttributes(tcp []string, udp []string) map[string]string {
+	values := make(map[string]string)
+	for i := 0; i < maxHostPortsAttributes; i++ {
+		values[hostPortsAttributeName(hostPortsTCPAttributeName, i)] = ""
+		values[hostPortsAttributeName(hostPortsUDPAttributeName, i)] = ""
+	}
+	for i, value := range tcp {
+		values[hostPortsAttributeName(hostPortsTCPAttributeName, i)] = value
+	}
+	for i, value := range udp {
+		values[hostPortsAttributeName(hostPortsUDPAttributeName, i)] = value
+	}
+	return values
+}
+
+func TestReportHostPortsAfterTaskBind(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	state := dockerstate.NewTaskEngineState()
+	container := &apicontainer.Container{Name: "web"}
+	container.SetKnownStatus(apicontainerstatus.ContainerPulled)
+	state.AddTask(&apitask.Task{
+		Arn:        "task",
+		Containers: []*apicontainer.Container{container},
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	agent := &ecsAgent{
+		ctx: ctx,
+		cfg: &config.Config{
+			ReservedPorts:    []uint16{22, 2375},
+			ReservedPortsUDP: []uint16{53},
+		},
+		containerInstanceARN: testHostPortsContainerInstanceARN,
+	}
+
+	var reported []map[string]string
+	client.EXPECT().PutAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			reported = append(reported, attributeValues(attributes))
+		}).Return(nil).Times(2)
+	// The attributes the ports do not need are removed once
+	client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Return(nil)
+
+	refresher := newAttributeRefresher(client, testHostPortsContainerInstanceARN)
+	agent.reportHostPorts(refresher, state)
+	refresher.refresh()
+
+	// Simulate the container starting and binding host ports
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	container.SetKnownPortBindings([]apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 32768, Protocol: apicontainer.TransportProtocolTCP},
+		{ContainerPort: 81, HostPort: 32769, Protocol: apicontainer.TransportProtocolTCP},
+		{ContainerPort: 8125, HostPort: 8125, Protocol: apicontainer.TransportProtocolUDP},
+	})
+	agent.reportHostPorts(refresher, state)
+	refresher.refresh()
+
+	assert.Equal(t, []map[string]string{
+		{
+			hostPortsTCPAttributeName: "22 2375",
+			hostPortsUDPAttributeName: "53",
+		},
+		{
+			hostPortsTCPAttributeName: "22 2375 32768-32769",
+			hostPortsUDPAttributeName: "53 8125",
+		},
+	}, reported)
+}
+
+func TestReportHostPortsSkipsStoppedContainers(t *testing.T) {
+	state := dockerstate.NewTaskEngineState()
+	container := &apicontainer.Container{Name: "web"}
+	container.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	container.SetKnownPortBindings([]apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 80, Protocol: apicontainer.TransportProtocolTCP},
+	})
+	state.AddTask(&apitask.Task{
+		Arn:        "task",
+		Containers: []*apicontainer.Container{container},
+	})
+
+	assert.Equal(t, hostPortsAttributes([]string{"22"}, nil), hostPortsValues([]uint16{22}, nil, state))
+}
+
+func TestHostPortsValuesSplitsPorts(t *testing.T) {
+	// Ports that are not consecutive take 6 characters each, they do not fit
+	// in a single attribute value
+	var reserved []uint16
+	var expected []string
+	for port := 40000; port < 40060; port += 2 {
+		reserved = append(reserved, uint16(port))
+		expected = append(expected, strconv.Itoa(port))
+	}
+	// Consecutive ports are collapsed into a range
+	reserved = append(reserved, 50000, 50001, 50002)
+	expected = append(expected, "50000-50002")
+
+	values := hostPortsValues(reserved, nil, dockerstate.NewTaskEngineState())
+	first := values[hostPortsTCPAttributeName]
+	second := values[hostPortsAttributeName(hostPortsTCPAttributeName, 1)]
+	assert.True(t, len(first) <= maxAttributeValueLength)
+	assert.NotEmpty(t, second)
+	assert.Empty(t, values[hostPortsAttributeName(hostPortsTCPAttributeName, 2)])
+	assert.Equal(t, strings.Join(expected, " "), first+" "+second)
+	assert.Equal(t, hostPortsTCPAttributeName+".2", hostPortsAttributeName(hostPortsTCPAttributeName, 1))
+}
+
+func TestReportHostPortsNoPorts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{},
+	}
+	// Attributes left over by a previous run of the agent are removed
+	client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			assert.Equal(t, hostPortsAttributes(nil, nil), attributeValues(attributes))
+		}).Return(nil)
+
+	refresher := newAttributeRefresher(client, testHostPortsContainerInstanceARN)
+	agent.reportHostPorts(refresher, dockerstate.NewTaskEngineState())
+	refresher.refresh()
+
+	// Unchanged attributes are not reported again
+	agent.reportHostPorts(refresher, dockerstate.NewTaskEngineState())
+	refresher.refresh()
+}
+
+func TestReportHostPortsRemovesStaleAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	state := dockerstate.NewTaskEngineState()
+	container := &apicontainer.Container{Name: "web"}
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	container.SetKnownPortBindings([]apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 32768, Protocol: apicontainer.TransportProtocolTCP},
+	})
+	state.AddTask(&apitask.Task{
+		Arn:        "task",
+		Containers: []*apicontainer.Container{container},
+	})
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{ReservedPortsUDP: []uint16{53}},
+	}
+
+	gomock.InOrder(
+		client.EXPECT().PutAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute) {
+				assert.Equal(t, map[string]string{
+					hostPortsTCPAttributeName: "32768",
+					hostPortsUDPAttributeName: "53",
+				}, attributeValues(attributes))
+			}).Return(nil),
+		client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Return(nil),
+		// The container stops, no TCP ports are left
+		client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute) {
+				assert.Equal(t, map[string]string{hostPortsTCPAttributeName: ""}, attributeValues(attributes))
+			}).Return(nil),
+		// The ports no longer fit in the attributes
+		client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute) {
+				assert.Equal(t, map[string]string{hostPortsUDPAttributeName: ""}, attributeValues(attributes))
+			}).Return(nil),
+	)
+
+	refresher := newAttributeRefresher(client, testHostPortsContainerInstanceARN)
+	agent.reportHostPorts(refresher, state)
+	refresher.refresh()
+
+	container.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	agent.reportHostPorts(refresher, state)
+	refresher.refresh()
+
+	for port := uint16(40000); port < 40400; port += 2 {
+		agent.cfg.ReservedPortsUDP = append(agent.cfg.ReservedPortsUDP, port)
+	}
+	agent.reportHostPorts(refresher, state)
+	refresher.refresh()
+}
+
+func TestReportHostPortsSkipsRejectedValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{
+			ReservedPorts:    []uint16{22},
+			ReservedPortsUDP: []uint16{53},
+		},
+	}
+
+	client.EXPECT().DeleteAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Return(nil)
+	gomock.InOrder(
+		client.EXPECT().PutAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Return(errors.New("error")),
+		// The rejected values are not reported again until they change
+		client.EXPECT().PutAttributes(testHostPortsContainerInstanceARN, gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute) {
+				assert.Equal(t, map[string]string{hostPortsTCPAttributeName: "22 80"}, attributeValues(attributes))
+			}).Return(nil),
+	)
+
+	refresher := newAttributeRefresher(client, testHostPortsContainerInstanceARN)
+	agent.reportHostPorts(refresher, dockerstate.NewTaskEngineState())
+	refresher.refresh()
+
+	agent.reportHostPorts(refresher, dockerstate.NewTaskEngineState())
+	refresher.refresh()
+
+	agent.cfg.ReservedPorts = append(agent.cfg.ReservedPorts, 80)
+	agent.reportHostPorts(refresher, dockerstate.NewTaskEngineState())
+	refresher.refresh()
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+const instanceWeightAttributeName = "ecs.instance-weight"
+
+// instanceWeightAttributes returns the instance weight attribute if a weight
+// has been configured
+func instanceWeightAttributes(weight int) []*ecs.Attribute {
+	if weight <= 0 {
+		return nil
+	}
+	return []*ecs.Attribute{
+		{
+			Name:  aws.String(instanceWeightAttributeName),
+			Value: aws.String(strconv.Itoa(weight)),
+		},
+	}
+}
+
+// reloadInstanceWeight reads the instance weight from the configuration file
+// again and reports it if it has changed. A weight set in the environment
+// takes precedence over the file, and cannot change at runtime
+func (agent *ecsAgent) reloadInstanceWeight(refresher *attributeRefresher) {
+	if os.Getenv("ECS_INSTANCE_WEIGHT") != "" {
+		seelog.Infof("Instance weight is set by ECS_INSTANCE_WEIGHT, ignoring the configuration file")
+		return
+	}
+	weight, err := config.InstanceWeightFromFile()
+	if err != nil {
+		seelog.Errorf("Unable to reload the instance weight: %v", err)
+		return
+	}
+	agent.updateInstanceWeight(refresher, weight)
+}
+
+// getInstanceWeight returns the instance weight currently configured
+func (agent *ecsAgent) getInstanceWeight() int {
+	agent.instanceWeightLock.RLock()
+	defer agent.instanceWeightLock.RUnlock()
+
+	return agent.cfg.InstanceWeight
+}
+
+// updateInstanceWeight queues the new instance weight to be reported with the
+// next attribute refresh. A removed weight removes the attribute
+func (agent *ecsAgent) updateInstanceWeight(refresher *attributeRefresher, weight int) {
+	agent.instanceWeightLock.Lock()
+	defer agent.instanceWeightLock.Unlock()
+
+	if weight == agent.cfg.InstanceWeight {
+		return
+	}
+	if attributes := instanceWeightAttributes(weight); len(attributes) > 0 {
+		refresher.update(attributes...)
+	} else {
+		refresher.remove(instanceWeightAttributeName)
+	}
+	seelog.Infof("Updated instance weight from %d to %d", agent.cfg.InstanceWeight, weight)
+	agent.cfg.InstanceWeight = weight
+}
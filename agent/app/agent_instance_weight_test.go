@@ -0,0 +1,139 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceWeightAttributes(t *testing.T) {
+	assert.Empty(t, instanceWeightAttributes(0))
+
+	attributes := instanceWeightAttributes(5)
+	require.Len(t, attributes, 1)
+	assert.Equal(t, instanceWeightAttributeName, aws.StringValue(attributes[0].Name))
+	assert.Equal(t, "5", aws.StringValue(attributes[0].Value))
+}
+
+func TestUpdateInstanceWeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	agent := &ecsAgent{
+		ctx:                  context.TODO(),
+		cfg:                  &config.Config{InstanceWeight: 5},
+		containerInstanceARN: containerInstanceARN,
+	}
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			require.Len(t, attributes, 1)
+			assert.Equal(t, instanceWeightAttributeName, aws.StringValue(attributes[0].Name))
+			assert.Equal(t, "20", aws.StringValue(attributes[0].Value))
+		}).Return(nil)
+
+	agent.updateInstanceWeight(refresher, 20)
+	assert.Equal(t, 20, agent.getInstanceWeight())
+
+	// An unchanged weight is not reported again
+	agent.updateInstanceWeight(refresher, 20)
+	refresher.refresh()
+}
+
+func TestUpdateInstanceWeightUnset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{InstanceWeight: 5},
+	}
+	client.EXPECT().DeleteAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			require.Len(t, attributes, 1)
+			assert.Equal(t, instanceWeightAttributeName, aws.StringValue(attributes[0].Name))
+			assert.Nil(t, attributes[0].Value)
+		}).Return(nil)
+
+	agent.updateInstanceWeight(refresher, 0)
+	assert.Equal(t, 0, agent.getInstanceWeight())
+	refresher.refresh()
+}
+
+func TestReloadInstanceWeightFromFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	file, err := ioutil.TempFile("", "ecs-agent-config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"InstanceWeight": 7}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	os.Setenv("ECS_AGENT_CONFIG_FILE_PATH", file.Name())
+	defer os.Unsetenv("ECS_AGENT_CONFIG_FILE_PATH")
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{InstanceWeight: 5},
+	}
+	client.EXPECT().PutAttributes(containerInstanceARN, gomock.Any()).Do(
+		func(arn string, attributes []*ecs.Attribute) {
+			require.Len(t, attributes, 1)
+			assert.Equal(t, "7", aws.StringValue(attributes[0].Value))
+		}).Return(nil)
+
+	agent.reloadInstanceWeight(refresher)
+	assert.Equal(t, 7, agent.getInstanceWeight())
+	refresher.refresh()
+}
+
+func TestReloadInstanceWeightSetInEnvironment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	os.Setenv("ECS_INSTANCE_WEIGHT", "5")
+	defer os.Unsetenv("ECS_INSTANCE_WEIGHT")
+
+	client := mock_api.NewMockECSClient(ctrl)
+	refresher := newAttributeRefresher(client, containerInstanceARN)
+	agent := &ecsAgent{
+		ctx: context.TODO(),
+		cfg: &config.Config{InstanceWeight: 5},
+	}
+
+	agent.reloadInstanceWeight(refresher)
+	assert.Equal(t, 5, agent.getInstanceWeight())
+	// PutAttributes is not expected to be invoked when the weight is set in
+	// the environment
+	refresher.refresh()
+}
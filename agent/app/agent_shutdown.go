@@ -0,0 +1,112 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/cihub/seelog"
+)
+
+// shutdownTaskPollInterval is the interval at which the running tasks are
+// checked while waiting for them to stop on shutdown
+const shutdownTaskPollInterval = 5 * time.Second
+
+// shutdown drains the container instance and deregisters it from the cluster,
+// when configured to, before the agent exits. Draining stops ECS from placing
+// new tasks on the instance and stops the running ones, the agent waits for
+// them to stop until the shutdown drain timeout elapses or the context is
+// canceled. A drain protected instance is neither drained nor deregistered
+// unless force is set
+func (agent *ecsAgent) shutdown(ctx context.Context, client api.ECSClient, state dockerstate.TaskEngineState, force bool) error {
+	if agent.containerInstanceARN == "" {
+		return nil
+	}
+	if !agent.cfg.DrainOnShutdown && !agent.cfg.DeregisterOnShutdown {
+		return nil
+	}
+	if agent.cfg.DrainProtection && !force {
+		seelog.Warnf("Container instance %s is drain protected, not draining or deregistering it on shutdown",
+			agent.containerInstanceARN)
+		return nil
+	}
+
+	if agent.cfg.DrainOnShutdown {
+		seelog.Infof("Draining container instance %s before shutting down", agent.containerInstanceARN)
+		err := client.UpdateContainerInstanceState(agent.containerInstanceARN, ecs.ContainerInstanceStatusDraining)
+		if err != nil {
+			seelog.Errorf("Unable to drain container instance %s: %v", agent.containerInstanceARN, err)
+		} else {
+			agent.waitForTasksToStop(ctx, state)
+		}
+	}
+
+	if !agent.cfg.DeregisterOnShutdown {
+		return nil
+	}
+	seelog.Infof("Deregistering container instance %s before shutting down", agent.containerInstanceARN)
+	err := client.DeregisterContainerInstance(agent.containerInstanceARN, false)
+	if err == nil {
+		return nil
+	}
+	// Only tasks that are still running prevent the instance from being
+	// deregistered without force, any other error is returned as is
+	running := runningTaskCount(state)
+	if running == 0 {
+		return err
+	}
+	seelog.Warnf("Unable to deregister container instance %s with %d tasks running, deregistering it with force: %v",
+		agent.containerInstanceARN, running, err)
+	return client.DeregisterContainerInstance(agent.containerInstanceARN, true)
+}
+
+// waitForTasksToStop waits until no task is running, the shutdown drain
+// timeout elapses or the context is canceled
+func (agent *ecsAgent) waitForTasksToStop(ctx context.Context, state dockerstate.TaskEngineState) {
+	ctx, cancel := context.WithTimeout(ctx, agent.cfg.ShutdownDrainTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(shutdownTaskPollInterval)
+	defer ticker.Stop()
+	for {
+		running := runningTaskCount(state)
+		if running == 0 {
+			seelog.Info("All tasks have stopped")
+			return
+		}
+		seelog.Infof("Waiting for %d tasks to stop", running)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			seelog.Warnf("Stopped waiting for %d tasks to stop: %v", running, ctx.Err())
+			return
+		}
+	}
+}
+
+// runningTaskCount returns the number of tasks that have not stopped yet
+func runningTaskCount(state dockerstate.TaskEngineState) int {
+	running := 0
+	for _, task := range state.AllTasks() {
+		if task.GetKnownStatus() < apitaskstatus.TaskStopped {
+			running++
+		}
+	}
+	return running
+}
@@ -0,0 +1,202 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	agent := &ecsAgent{
+		cfg:                  &config.Config{ShutdownDrainTimeout: time.Minute},
+		containerInstanceARN: containerInstanceARN,
+	}
+	// No ECS API is expected to be invoked
+	assert.NoError(t, agent.shutdown(context.TODO(), client, dockerstate.NewTaskEngineState(), false))
+}
+
+func TestShutdownDrainAndDeregister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+		client.EXPECT().DeregisterContainerInstance(containerInstanceARN, false).Return(nil),
+	)
+
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(&apitask.Task{
+		Arn:               "stopped",
+		KnownStatusUnsafe: apitaskstatus.TaskStopped,
+	})
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainOnShutdown:      true,
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: time.Minute,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	assert.NoError(t, agent.shutdown(context.TODO(), client, state, false))
+}
+
+func TestShutdownDrainTimeoutDeregistersWithForce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+		client.EXPECT().DeregisterContainerInstance(containerInstanceARN, false).Return(errors.New("tasks running")),
+		client.EXPECT().DeregisterContainerInstance(containerInstanceARN, true).Return(nil),
+	)
+
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(&apitask.Task{
+		Arn:               "running",
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+	})
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainOnShutdown:      true,
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: 10 * time.Millisecond,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	assert.NoError(t, agent.shutdown(context.TODO(), client, state, false))
+}
+
+func TestShutdownDeregisterErrorDoesNotForce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	deregisterErr := errors.New("throttled")
+	client.EXPECT().DeregisterContainerInstance(containerInstanceARN, false).Return(deregisterErr)
+
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(&apitask.Task{
+		Arn:               "stopped",
+		KnownStatusUnsafe: apitaskstatus.TaskStopped,
+	})
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: time.Minute,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	// No task is running, so the instance is not deregistered with force
+	assert.Equal(t, deregisterErr, agent.shutdown(context.TODO(), client, state, false))
+}
+
+func TestShutdownDrainCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil)
+
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(&apitask.Task{
+		Arn:               "running",
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+	})
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainOnShutdown:      true,
+			ShutdownDrainTimeout: time.Hour,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	assert.NoError(t, agent.shutdown(ctx, client, state, false))
+}
+
+func TestShutdownNotRegistered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainOnShutdown:      true,
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: time.Minute,
+		},
+	}
+	// No ECS API is expected to be invoked before the instance is registered
+	assert.NoError(t, agent.shutdown(context.TODO(), client, dockerstate.NewTaskEngineState(), false))
+}
+
+func TestShutdownDrainProtected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainProtection:      true,
+			DrainOnShutdown:      true,
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: time.Minute,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	// The instance is neither drained nor deregistered
+	assert.NoError(t, agent.shutdown(context.TODO(), client, dockerstate.NewTaskEngineState(), false))
+}
+
+func TestShutdownDrainProtectedForced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_api.NewMockECSClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+		client.EXPECT().DeregisterContainerInstance(containerInstanceARN, false).Return(nil),
+	)
+
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			DrainProtection:      true,
+			DrainOnShutdown:      true,
+			DeregisterOnShutdown: true,
+			ShutdownDrainTimeout: time.Minute,
+		},
+		containerInstanceARN: containerInstanceARN,
+	}
+	assert.NoError(t, agent.shutdown(context.TODO(), client, dockerstate.NewTaskEngineState(), true))
+}
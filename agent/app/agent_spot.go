@@ -0,0 +1,181 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/cihub/seelog"
+)
+
+// spotInstanceNoticePollInterval is the interval at which the instance
+// metadata is checked for a spot instance interruption notice. EC2 sends the
+// notice two minutes before interrupting the instance
+const spotInstanceNoticePollInterval = 5 * time.Second
+
+// spotInstanceInterruptionReason is the reason reported for the tasks stopped
+// because of a spot instance interruption notice
+const spotInstanceInterruptionReason = "Spot instance interruption notice received"
+
+// spotInstanceAction is the spot instance interruption notice
+type spotInstanceAction struct {
+	Action string `json:"action"`
+	Time   string `json:"time"`
+}
+
+// rebalanceRecommendation is the notice that the spot instance is at an
+// elevated risk of interruption
+type rebalanceRecommendation struct {
+	NoticeTime string `json:"noticeTime"`
+}
+
+// spotInstanceNoticeHandler acts on the spot instance notices of EC2
+type spotInstanceNoticeHandler struct {
+	agent      *ecsAgent
+	client     api.ECSClient
+	taskEngine engine.TaskEngine
+	// draining is set once the container instance has been set to DRAINING
+	draining bool
+	// tasksStopped is set once the tasks were stopped on an interruption notice
+	tasksStopped bool
+}
+
+// startSpotInstanceNoticePoller polls the instance metadata for a spot
+// instance interruption notice or a rebalance recommendation. On a rebalance
+// recommendation, the container instance is set to DRAINING, so that ECS
+// stops placing tasks on it and replaces the tasks of the services on other
+// instances, unless the instance is drain protected. On an interruption
+// notice, the running tasks are also stopped gracefully, as the instance is
+// about to be interrupted. The poller stops once the interruption notice was
+// handled or the context is canceled
+func (agent *ecsAgent) startSpotInstanceNoticePoller(ctx context.Context,
+	client api.ECSClient,
+	taskEngine engine.TaskEngine) {
+	handler := &spotInstanceNoticeHandler{
+		agent:      agent,
+		client:     client,
+		taskEngine: taskEngine,
+	}
+	ticker := time.NewTicker(spotInstanceNoticePollInterval)
+	defer ticker.Stop()
+	for {
+		if handler.poll() {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll checks the instance metadata for notices once, and returns true once
+// the interruption notice was handled
+func (handler *spotInstanceNoticeHandler) poll() bool {
+	if action, ok := handler.interruptionNotice(); ok {
+		seelog.Infof("Received a spot instance interruption notice [action: %s, time: %s]", action.Action, action.Time)
+		// The tasks are stopped even if the instance could not be drained, as
+		// there are only two minutes left before the interruption. The drain
+		// is retried at the next poll
+		drained := handler.drain()
+		if !handler.tasksStopped {
+			handler.stopTasks()
+			handler.tasksStopped = true
+		}
+		return drained
+	}
+	if handler.draining {
+		return false
+	}
+	if recommendation, ok := handler.rebalanceRecommendation(); ok {
+		seelog.Infof("Received a rebalance recommendation [time: %s]", recommendation.NoticeTime)
+		if handler.agent.cfg.DrainProtection {
+			seelog.Warnf("Container instance %s is drain protected, not draining it on a rebalance recommendation",
+				handler.agent.containerInstanceARN)
+			return false
+		}
+		handler.drain()
+	}
+	return false
+}
+
+// drain sets the container instance to DRAINING, unless it already is, and
+// returns true if it is draining
+func (handler *spotInstanceNoticeHandler) drain() bool {
+	if handler.draining {
+		return true
+	}
+	arn := handler.agent.containerInstanceARN
+	seelog.Infof("Draining container instance %s", arn)
+	err := handler.client.UpdateContainerInstanceState(arn, ecs.ContainerInstanceStatusDraining)
+	if err != nil {
+		seelog.Errorf("Unable to drain container instance %s: %v", arn, err)
+		return false
+	}
+	handler.draining = true
+	return true
+}
+
+// stopTasks stops the tasks that have not stopped yet. Their STOPPED state
+// changes report the interruption, so that ECS replaces them elsewhere
+func (handler *spotInstanceNoticeHandler) stopTasks() {
+	tasks, err := handler.taskEngine.ListTasks()
+	if err != nil {
+		seelog.Errorf("Unable to list the tasks to stop: %v", err)
+		return
+	}
+	for _, task := range tasks {
+		if task.GetDesiredStatus().Terminal() {
+			continue
+		}
+		handler.taskEngine.StopTask(task.Arn, spotInstanceInterruptionReason)
+	}
+}
+
+// interruptionNotice returns the spot instance interruption notice, if any.
+// The metadata is not found until EC2 sends a notice
+func (handler *spotInstanceNoticeHandler) interruptionNotice() (spotInstanceAction, bool) {
+	var action spotInstanceAction
+	resp, err := handler.agent.ec2MetadataClient.GetMetadata(ec2.SpotInstanceActionResource)
+	if err != nil {
+		return action, false
+	}
+	if err := json.Unmarshal([]byte(resp), &action); err != nil {
+		seelog.Warnf("Unable to parse the spot instance interruption notice %q: %v", resp, err)
+		return action, false
+	}
+	return action, true
+}
+
+// rebalanceRecommendation returns the rebalance recommendation, if any. The
+// metadata is not found until EC2 sends a recommendation
+func (handler *spotInstanceNoticeHandler) rebalanceRecommendation() (rebalanceRecommendation, bool) {
+	var recommendation rebalanceRecommendation
+	resp, err := handler.agent.ec2MetadataClient.GetMetadata(ec2.RebalanceRecommendationResource)
+	if err != nil {
+		return recommendation, false
+	}
+	if err := json.Unmarshal([]byte(resp), &recommendation); err != nil {
+		seelog.Warnf("Unable to parse the rebalance recommendation %q: %v", resp, err)
+		return recommendation, false
+	}
+	return recommendation, true
+}
@@ -0,0 +1,155 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpotInstanceAction = `{"action": "terminate", "time": "2018-09-18T08:22:00Z"}`
+
+func newTestSpotInstanceNoticeHandler(ec2MetadataClient ec2.EC2MetadataClient,
+	client api.ECSClient,
+	taskEngine engine.TaskEngine) *spotInstanceNoticeHandler {
+	return &spotInstanceNoticeHandler{
+		agent: &ecsAgent{
+			cfg:                  &config.Config{},
+			ec2MetadataClient:    ec2MetadataClient,
+			containerInstanceARN: containerInstanceARN,
+		},
+		client:     client,
+		taskEngine: taskEngine,
+	}
+}
+
+func TestSpotInstanceNoticePollerNoNotice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return("", errors.New("404"))
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.RebalanceRecommendationResource).Return("", errors.New("404"))
+
+	handler := newTestSpotInstanceNoticeHandler(ec2MetadataClient, client, taskEngine)
+	assert.False(t, handler.poll())
+}
+
+func TestSpotInstanceNoticePollerInterruptionNotice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runningTask := &apitask.Task{Arn: "running", DesiredStatusUnsafe: apitaskstatus.TaskRunning}
+	stoppedTask := &apitask.Task{Arn: "stopped", DesiredStatusUnsafe: apitaskstatus.TaskStopped}
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return(testSpotInstanceAction, nil),
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+		taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{runningTask, stoppedTask}, nil),
+		// Only the task that is not stopping yet is stopped
+		taskEngine.EXPECT().StopTask("running", spotInstanceInterruptionReason),
+	)
+
+	handler := newTestSpotInstanceNoticeHandler(ec2MetadataClient, client, taskEngine)
+	assert.True(t, handler.poll())
+}
+
+func TestSpotInstanceNoticePollerRebalanceRecommendation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return("", errors.New("404")),
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.RebalanceRecommendationResource).Return(
+			`{"noticeTime": "2018-09-18T08:20:00Z"}`, nil),
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+		// The tasks are not stopped on a recommendation, the poller keeps
+		// waiting for an interruption notice
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return("", errors.New("404")),
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return(testSpotInstanceAction, nil),
+		taskEngine.EXPECT().ListTasks().Return(nil, nil),
+	)
+
+	handler := newTestSpotInstanceNoticeHandler(ec2MetadataClient, client, taskEngine)
+	assert.False(t, handler.poll())
+	assert.False(t, handler.poll())
+	// The instance is already draining
+	assert.True(t, handler.poll())
+}
+
+func TestSpotInstanceNoticePollerDrainFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runningTask := &apitask.Task{Arn: "running", DesiredStatusUnsafe: apitaskstatus.TaskRunning}
+	notice := `{"action": "stop", "time": "2018-09-18T08:22:00Z"}`
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return(notice, nil),
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(
+			errors.New("error")),
+		// The tasks are stopped even though the instance is not draining
+		taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{runningTask}, nil),
+		taskEngine.EXPECT().StopTask("running", spotInstanceInterruptionReason),
+		// The drain is retried at the next poll, the tasks are not stopped again
+		ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return(notice, nil),
+		client.EXPECT().UpdateContainerInstanceState(containerInstanceARN, ecs.ContainerInstanceStatusDraining).Return(nil),
+	)
+
+	handler := newTestSpotInstanceNoticeHandler(ec2MetadataClient, client, taskEngine)
+	assert.False(t, handler.poll())
+	assert.True(t, handler.poll())
+}
+
+func TestSpotInstanceNoticePollerRebalanceRecommendationDrainProtection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	// A drain protected instance is not drained on a recommendation
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.SpotInstanceActionResource).Return("", errors.New("404"))
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.RebalanceRecommendationResource).Return(
+		`{"noticeTime": "2018-09-18T08:20:00Z"}`, nil)
+
+	handler := newTestSpotInstanceNoticeHandler(ec2MetadataClient, client, taskEngine)
+	handler.agent.cfg.DrainProtection = true
+	assert.False(t, handler.poll())
+	assert.False(t, handler.draining)
+}
@@ -23,8 +23,11 @@ import (
 	"sync"
 	"testing"
 
+	acshandler "github.com/aws/amazon-ecs-agent/agent/acs/handler"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/app/factory/mocks"
 	app_mocks "github.com/aws/amazon-ecs-agent/agent/app/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -36,9 +39,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/engine/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager/mocks"
@@ -46,6 +51,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -156,7 +162,7 @@ func TestDoStartNewTaskEngineError(t *testing.T) {
 		// An error in creating the state manager should result in an
 		// error from newTaskEngine as well
 		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(), gomock.Any(),
-			gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			nil, errors.New("error")),
 	)
 
@@ -191,7 +197,7 @@ func TestDoStartNewStateManagerError(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			statemanager.NewNoopStateManager(), nil),
 		state.EXPECT().AllTasks().AnyTimes(),
@@ -200,7 +206,7 @@ func TestDoStartNewStateManagerError(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			nil, errors.New("error")),
 	)
@@ -240,6 +246,7 @@ func TestDoStartRegisterContainerInstanceErrorTerminal(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", apierrors.NewAttributeError("error")),
 	)
@@ -277,6 +284,7 @@ func TestDoStartRegisterContainerInstanceErrorNonTerminal(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", errors.New("error")),
 	)
@@ -335,6 +343,7 @@ func TestDoStartRegisterAvailabilityZone(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"arn:123", availabilityZone, nil),
 		containermetadata.EXPECT().SetContainerInstanceARN("arn:123"),
@@ -359,7 +368,7 @@ func TestDoStartRegisterAvailabilityZone(t *testing.T) {
 		credentialProvider: aws_credentials.NewCredentials(mockCredentialsProvider),
 		mobyPlugins:        mockMobyPlugins,
 		metadataManager:    containermetadata,
-		terminationHandler: func(saver statemanager.Saver, taskEngine engine.TaskEngine) {},
+		terminationHandler: func(saver statemanager.Saver, taskEngine engine.TaskEngine, shutdown sighandlers.ShutdownFunc) {},
 		ec2MetadataClient:  ec2MetadataClient,
 	}
 
@@ -388,7 +397,7 @@ func TestNewTaskEngineRestoreFromCheckpointNoEC2InstanceIDToLoadHappyPath(t *tes
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			statemanager.NewNoopStateManager(), nil),
 		state.EXPECT().AllTasks().AnyTimes(),
@@ -408,7 +417,7 @@ func TestNewTaskEngineRestoreFromCheckpointNoEC2InstanceIDToLoadHappyPath(t *tes
 	}
 
 	_, instanceID, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.NoError(t, err)
 	assert.Equal(t, expectedInstanceID, instanceID)
 	assert.Equal(t, "prev-container-inst", agent.containerInstanceARN)
@@ -444,7 +453,7 @@ func TestNewTaskEngineRestoreFromCheckpointPreviousEC2InstanceIDLoadedHappyPath(
 				assert.True(t, ok)
 				*previousAZ = "us-west-2b"
 			}).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			statemanager.NewNoopStateManager(), nil),
 		state.EXPECT().AllTasks().AnyTimes(),
@@ -465,7 +474,7 @@ func TestNewTaskEngineRestoreFromCheckpointPreviousEC2InstanceIDLoadedHappyPath(
 	}
 
 	_, instanceID, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.NoError(t, err)
 	assert.Equal(t, expectedInstanceID, instanceID)
 	assert.NotEqual(t, "prev-container-inst", agent.containerInstanceARN)
@@ -499,7 +508,7 @@ func TestNewTaskEngineRestoreFromCheckpointClusterIDMismatch(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
 
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			statemanager.NewNoopStateManager(), nil),
 		state.EXPECT().AllTasks().AnyTimes(),
@@ -519,7 +528,7 @@ func TestNewTaskEngineRestoreFromCheckpointClusterIDMismatch(t *testing.T) {
 	}
 
 	_, _, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.Error(t, err)
 	assert.True(t, isClusterMismatch(err))
 }
@@ -536,7 +545,7 @@ func TestNewTaskEngineRestoreFromCheckpointNewStateManagerError(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			nil, errors.New("error")),
 	)
@@ -553,7 +562,7 @@ func TestNewTaskEngineRestoreFromCheckpointNewStateManagerError(t *testing.T) {
 	}
 
 	_, _, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -571,7 +580,7 @@ func TestNewTaskEngineRestoreFromCheckpointStateLoadError(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		).Return(stateManager, nil),
 		stateManager.EXPECT().Load().Return(errors.New("error")),
@@ -589,7 +598,7 @@ func TestNewTaskEngineRestoreFromCheckpointStateLoadError(t *testing.T) {
 	}
 
 	_, _, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -608,7 +617,7 @@ func TestNewTaskEngineRestoreFromCheckpoint(t *testing.T) {
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("EC2InstanceID", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("availabilityZone", gomock.Any()).Return(nil),
-		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(),
+		stateManagerFactory.EXPECT().NewStateManager(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		).Return(statemanager.NewNoopStateManager(), nil),
 		state.EXPECT().AllTasks().AnyTimes(),
@@ -628,7 +637,7 @@ func TestNewTaskEngineRestoreFromCheckpoint(t *testing.T) {
 	}
 
 	_, instanceID, err := agent.newTaskEngine(eventstream.NewEventStream("events", ctx),
-		credentialsManager, state, imageManager)
+		credentialsManager, state, imageManager, acshandler.NewAppliedPayloads())
 	assert.NoError(t, err)
 	assert.Equal(t, expectedInstanceID, instanceID)
 }
@@ -682,6 +691,7 @@ func TestReregisterContainerInstanceHappyPath(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(containerInstanceARN, gomock.Any(),
 			gomock.Any(), gomock.Any(), gomock.Any()).Return(containerInstanceARN, availabilityZone, nil),
 	)
@@ -700,7 +710,7 @@ func TestReregisterContainerInstanceHappyPath(t *testing.T) {
 	agent.containerInstanceARN = containerInstanceARN
 	agent.availabilityZone = availabilityZone
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.NoError(t, err)
 }
 
@@ -721,6 +731,7 @@ func TestReregisterContainerInstanceInstanceTypeChanged(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(containerInstanceARN, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", awserr.New("", apierrors.InstanceTypeChangedErrorMessage, errors.New(""))),
 	)
@@ -740,7 +751,7 @@ func TestReregisterContainerInstanceInstanceTypeChanged(t *testing.T) {
 	agent.containerInstanceARN = containerInstanceARN
 	agent.availabilityZone = availabilityZone
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -762,6 +773,7 @@ func TestReregisterContainerInstanceAttributeError(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(containerInstanceARN, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", apierrors.NewAttributeError("error")),
 	)
@@ -781,7 +793,7 @@ func TestReregisterContainerInstanceAttributeError(t *testing.T) {
 	agent.containerInstanceARN = containerInstanceARN
 	agent.availabilityZone = availabilityZone
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -803,6 +815,7 @@ func TestReregisterContainerInstanceNonTerminalError(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(containerInstanceARN, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", errors.New("error")),
 	)
@@ -822,11 +835,95 @@ func TestReregisterContainerInstanceNonTerminalError(t *testing.T) {
 	agent.containerInstanceARN = containerInstanceARN
 	agent.availabilityZone = availabilityZone
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.True(t, isTransient(err))
 }
 
+func TestReregisterContainerInstanceInvalidInstanceRegistersNewInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	stateManager := mock_statemanager.NewMockStateManager(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	mockCredentialsProvider := app_mocks.NewMockProvider(ctrl)
+	mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+
+	// The tasks of the deregistered container instance are restored from
+	// the checkpoint
+	state := dockerstate.NewTaskEngineState()
+	container := &apicontainer.Container{Name: "web"}
+	task := &apitask.Task{Arn: "old-task", Containers: []*apicontainer.Container{container}}
+	state.AddTask(task)
+	state.AddContainer(&apicontainer.DockerContainer{
+		DockerID:   "docker-id",
+		DockerName: "docker-name",
+		Container:  container,
+	}, task)
+
+	newContainerInstanceARN := containerInstanceARN + "-new"
+	gomock.InOrder(
+		mockCredentialsProvider.EXPECT().Retrieve().Return(aws_credentials.Value{}, nil),
+		mockDockerClient.EXPECT().SupportedVersions().Return(nil),
+		mockDockerClient.EXPECT().KnownVersions().Return(nil),
+		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
+			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+		client.EXPECT().RegisterContainerInstance(containerInstanceARN, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			"", "", awserr.New(ecs.ErrCodeClientException, "The referenced container instance is inactive", nil)),
+		mockDockerClient.EXPECT().StopContainer(gomock.Any(), "docker-id", gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{}),
+		mockDockerClient.EXPECT().RemoveContainer(gomock.Any(), "docker-id", gomock.Any()).Return(nil),
+		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			newContainerInstanceARN, availabilityZone, nil),
+		stateManager.EXPECT().Save(),
+	)
+
+	cfg := getTestConfig()
+	cfg.Cluster = clusterName
+	ctx, cancel := context.WithCancel(context.TODO())
+	// Cancel the context to cancel async routines
+	defer cancel()
+	agent := &ecsAgent{
+		ctx:                ctx,
+		cfg:                &cfg,
+		dockerClient:       mockDockerClient,
+		credentialProvider: aws_credentials.NewCredentials(mockCredentialsProvider),
+		mobyPlugins:        mockMobyPlugins,
+	}
+	agent.containerInstanceARN = containerInstanceARN
+
+	err := agent.registerContainerInstance(stateManager, state, client, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, newContainerInstanceARN, agent.containerInstanceARN)
+	assert.Equal(t, availabilityZone, agent.availabilityZone)
+	assert.Empty(t, state.AllTasks(), "Tasks of the deregistered container instance should be removed")
+	_, ok := state.ContainerByID("docker-id")
+	assert.False(t, ok, "Containers of the deregistered container instance should be removed")
+}
+
+func TestIsInvalidInstanceError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"invalid instance", awserr.New("InvalidInstanceException", "", nil), true},
+		{"inactive instance", awserr.New(ecs.ErrCodeClientException, "Container instance is INACTIVE", nil), true},
+		{"other client error", awserr.New(ecs.ErrCodeClientException, "some error", nil), false},
+		{"server error", awserr.New(ecs.ErrCodeServerException, "inactive", nil), false},
+		{"not an aws error", errors.New("inactive"), false},
+		{"no error", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, apierrors.IsInvalidInstanceError(tc.err))
+		})
+	}
+}
+
 func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetHappyPath(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -844,6 +941,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetHappyPath(t *t
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(containerInstanceARN, availabilityZone, nil),
 		stateManager.EXPECT().Save(),
 	)
@@ -860,12 +958,60 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetHappyPath(t *t
 		credentialProvider: aws_credentials.NewCredentials(mockCredentialsProvider),
 		mobyPlugins:        mockMobyPlugins,
 	}
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, containerInstanceARN, agent.containerInstanceARN)
 	assert.Equal(t, availabilityZone, agent.availabilityZone)
 }
 
+func TestRegisterContainerInstanceWithInstanceWeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	stateManager := mock_statemanager.NewMockStateManager(ctrl)
+	client := mock_api.NewMockECSClient(ctrl)
+	mockCredentialsProvider := app_mocks.NewMockProvider(ctrl)
+	mockMobyPlugins := mock_mobypkgwrapper.NewMockPlugins(ctrl)
+
+	gomock.InOrder(
+		mockCredentialsProvider.EXPECT().Retrieve().Return(aws_credentials.Value{}, nil),
+		mockDockerClient.EXPECT().SupportedVersions().Return(nil),
+		mockDockerClient.EXPECT().KnownVersions().Return(nil),
+		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
+			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
+		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(arn string, attributes []*ecs.Attribute, tags []*ecs.Tag, token string, devices []*ecs.PlatformDevice) {
+				var weight string
+				for _, attribute := range attributes {
+					if aws.StringValue(attribute.Name) == instanceWeightAttributeName {
+						weight = aws.StringValue(attribute.Value)
+					}
+				}
+				assert.Equal(t, "10", weight)
+			}).Return(containerInstanceARN, availabilityZone, nil),
+		stateManager.EXPECT().Save(),
+	)
+
+	cfg := getTestConfig()
+	cfg.Cluster = clusterName
+	cfg.InstanceWeight = 10
+	ctx, cancel := context.WithCancel(context.TODO())
+	// Cancel the context to cancel async routines
+	defer cancel()
+	agent := &ecsAgent{
+		ctx:                ctx,
+		cfg:                &cfg,
+		dockerClient:       mockDockerClient,
+		credentialProvider: aws_credentials.NewCredentials(mockCredentialsProvider),
+		mobyPlugins:        mockMobyPlugins,
+	}
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
+	assert.NoError(t, err)
+}
+
 func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetCanRetryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -884,6 +1030,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetCanRetryError(
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", "", retriableError),
 	)
 
@@ -900,7 +1047,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetCanRetryError(
 		mobyPlugins:        mockMobyPlugins,
 	}
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.True(t, isTransient(err))
 }
@@ -923,6 +1070,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetCannotRetryErr
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", "", cannotRetryError),
 	)
 
@@ -939,7 +1087,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetCannotRetryErr
 		mobyPlugins:        mockMobyPlugins,
 	}
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -961,6 +1109,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetAttributeError
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		mockDockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(),
 			gomock.Any(), gomock.Any()).AnyTimes().Return([]string{}, nil),
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance("", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", apierrors.NewAttributeError("error")),
 	)
@@ -978,7 +1127,7 @@ func TestRegisterContainerInstanceWhenContainerInstanceARNIsNotSetAttributeError
 		mobyPlugins:        mockMobyPlugins,
 	}
 
-	err := agent.registerContainerInstance(stateManager, client, nil)
+	err := agent.registerContainerInstance(stateManager, dockerstate.NewTaskEngineState(), client, nil)
 	assert.Error(t, err)
 	assert.False(t, isTransient(err))
 }
@@ -999,6 +1148,7 @@ func TestRegisterContainerInstanceInvalidParameterTerminalError(t *testing.T) {
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		client.EXPECT().RegisterContainerInstance(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			"", "", awserr.New("InvalidParameterException", "", nil)),
 	)
@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
@@ -153,7 +154,7 @@ func (h *handler) runAgent(ctx context.Context) uint32 {
 	agentCtx, cancel := context.WithCancel(ctx)
 	indicator := newTermHandlerIndicator()
 
-	terminationHandler := func(saver statemanager.Saver, taskEngine engine.TaskEngine) {
+	terminationHandler := func(saver statemanager.Saver, taskEngine engine.TaskEngine, shutdown sighandlers.ShutdownFunc) {
 		// We're using a custom indicator to record that the handler is scheduled to be executed (has been invoked) and
 		// to determine whether it should run (we skip when the agent engine has already exited).  After recording to
 		// the indicator that the handler has been invoked, we wait on the context.  When we wake up, we determine
@@ -166,6 +167,10 @@ func (h *handler) runAgent(ctx context.Context) uint32 {
 		}
 
 		seelog.Info("Termination handler received signal to stop")
+		sighandlers.SaveBeforeShutdown(saver)
+		if err := shutdown(context.Background()); err != nil {
+			seelog.Errorf("Error shutting down gracefully: %v", err)
+		}
 		err := sighandlers.FinalSave(saver, taskEngine)
 		if err != nil {
 			seelog.Criticalf("Error saving state before final shutdown: %v", err)
@@ -257,6 +262,7 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			ASMClientCreator:   asmfactory.NewClientCreator(),
 			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
+			S3ClientCreator:    s3factory.NewS3ClientCreator(),
 			CredentialsManager: credentialsManager,
 		},
 		Ctx:          agent.ctx,
@@ -108,7 +108,7 @@ func TestHandler_RunAgent_ForceSaveWithTerminationHandler(t *testing.T) {
 	done := make(chan struct{})
 	defer func() { done <- struct{}{} }()
 	startFunc := func() int {
-		go agent.terminationHandler(stateManager, taskEngine)
+		go agent.terminationHandler(stateManager, taskEngine, func(ctx context.Context) error { return nil })
 		<-done // block until after the test ends so that we can test that runAgent returns when cancelled
 		return 0
 	}
@@ -203,7 +203,7 @@ func TestHandler_Execute_WindowsStops(t *testing.T) {
 	done := make(chan struct{})
 	defer func() { done <- struct{}{} }()
 	startFunc := func() int {
-		go agent.terminationHandler(stateManager, taskEngine)
+		go agent.terminationHandler(stateManager, taskEngine, func(ctx context.Context) error { return nil })
 		<-done // block until after the test ends so that we can test that Execute returns when Stopped
 		return 0
 	}
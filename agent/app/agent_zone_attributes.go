@@ -0,0 +1,92 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+const (
+	regionAttributeName           = "ecs.region"
+	availabilityZoneAttributeName = "ecs.availability-zone"
+	zoneIDAttributeName           = "ecs.zone-id"
+	instanceTypeAttributeName     = "ecs.instance-type"
+	amiIDAttributeName            = "ecs.ami-id"
+)
+
+// zoneAttributes returns the attributes for the region, the availability zone
+// and the zone id of the instance. For Local Zones and Wavelength Zones, the
+// region is the parent region of the zone. Attributes whose metadata is
+// unavailable are skipped
+func zoneAttributes(ec2MetadataClient ec2.EC2MetadataClient) []*ecs.Attribute {
+	var attributes []*ecs.Attribute
+
+	availabilityZone, err := ec2MetadataClient.GetMetadata(ec2.AvailabilityZoneResource)
+	if err != nil {
+		seelog.Warnf("Unable to get the availability zone from EC2 metadata: %v", err)
+	} else {
+		attributes = append(attributes, &ecs.Attribute{
+			Name:  aws.String(availabilityZoneAttributeName),
+			Value: aws.String(availabilityZone),
+		})
+		if region, err := ec2.RegionFromAvailabilityZone(availabilityZone); err != nil {
+			seelog.Warnf("Unable to determine the region of the instance: %v", err)
+		} else {
+			attributes = append(attributes, &ecs.Attribute{
+				Name:  aws.String(regionAttributeName),
+				Value: aws.String(region),
+			})
+		}
+	}
+
+	zoneID, err := ec2MetadataClient.GetMetadata(ec2.AvailabilityZoneIDResource)
+	if err != nil {
+		seelog.Warnf("Unable to get the availability zone id from EC2 metadata: %v", err)
+	} else {
+		attributes = append(attributes, &ecs.Attribute{
+			Name:  aws.String(zoneIDAttributeName),
+			Value: aws.String(zoneID),
+		})
+	}
+
+	return attributes
+}
+
+// instanceAttributes returns the attributes for the instance type and the AMI
+// id of the instance, so that placement constraints may target them.
+// Attributes whose metadata is unavailable are skipped
+func instanceAttributes(ec2MetadataClient ec2.EC2MetadataClient) []*ecs.Attribute {
+	var attributes []*ecs.Attribute
+	for _, metadata := range []struct {
+		resource      string
+		attributeName string
+	}{
+		{ec2.InstanceTypeResource, instanceTypeAttributeName},
+		{ec2.AMIIDResource, amiIDAttributeName},
+	} {
+		value, err := ec2MetadataClient.GetMetadata(metadata.resource)
+		if err != nil {
+			seelog.Warnf("Unable to get %s from EC2 metadata: %v", metadata.resource, err)
+			continue
+		}
+		attributes = append(attributes, &ecs.Attribute{
+			Name:  aws.String(metadata.attributeName),
+			Value: aws.String(value),
+		})
+	}
+	return attributes
+}
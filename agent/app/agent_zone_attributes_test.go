@@ -0,0 +1,127 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneAttributes(t *testing.T) {
+	testCases := []struct {
+		name             string
+		availabilityZone string
+		zoneID           string
+		expected         map[string]string
+	}{
+		{
+			name:             "standard zone",
+			availabilityZone: "us-west-2a",
+			zoneID:           "usw2-az1",
+			expected: map[string]string{
+				regionAttributeName:           "us-west-2",
+				availabilityZoneAttributeName: "us-west-2a",
+				zoneIDAttributeName:           "usw2-az1",
+			},
+		},
+		{
+			name:             "local zone",
+			availabilityZone: "us-west-2-lax-1a",
+			zoneID:           "usw2-lax1-az1",
+			expected: map[string]string{
+				regionAttributeName:           "us-west-2",
+				availabilityZoneAttributeName: "us-west-2-lax-1a",
+				zoneIDAttributeName:           "usw2-lax1-az1",
+			},
+		},
+		{
+			name:             "wavelength zone",
+			availabilityZone: "us-east-1-wl1-bos-wlz-1",
+			zoneID:           "use1-wl1-bos-wlz1",
+			expected: map[string]string{
+				regionAttributeName:           "us-east-1",
+				availabilityZoneAttributeName: "us-east-1-wl1-bos-wlz-1",
+				zoneIDAttributeName:           "use1-wl1-bos-wlz1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+			ec2MetadataClient.EXPECT().GetMetadata(ec2.AvailabilityZoneResource).Return(tc.availabilityZone, nil)
+			ec2MetadataClient.EXPECT().GetMetadata(ec2.AvailabilityZoneIDResource).Return(tc.zoneID, nil)
+
+			assert.Equal(t, tc.expected, attributeValues(zoneAttributes(ec2MetadataClient)))
+		})
+	}
+}
+
+func TestZoneAttributesPartialMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.AvailabilityZoneResource).Return("us-west-2-lax-1a", nil)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.AvailabilityZoneIDResource).Return("", errors.New("not found"))
+
+	assert.Equal(t, map[string]string{
+		regionAttributeName:           "us-west-2",
+		availabilityZoneAttributeName: "us-west-2-lax-1a",
+	}, attributeValues(zoneAttributes(ec2MetadataClient)))
+}
+
+func TestZoneAttributesNoMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(gomock.Any()).Return("", errors.New("error")).Times(2)
+
+	assert.Empty(t, zoneAttributes(ec2MetadataClient))
+}
+
+func TestInstanceAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.InstanceTypeResource).Return("c5.xlarge", nil)
+	ec2MetadataClient.EXPECT().GetMetadata(ec2.AMIIDResource).Return("ami-123", nil)
+
+	assert.Equal(t, map[string]string{
+		instanceTypeAttributeName: "c5.xlarge",
+		amiIDAttributeName:        "ami-123",
+	}, attributeValues(instanceAttributes(ec2MetadataClient)))
+}
+
+func TestInstanceAttributesNoMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2MetadataClient.EXPECT().GetMetadata(gomock.Any()).Return("", errors.New("error")).Times(2)
+
+	assert.Empty(t, instanceAttributes(ec2MetadataClient))
+}
@@ -18,6 +18,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
 	"strings"
@@ -106,6 +108,92 @@ const (
 	// image cleanup.
 	minimumImageCleanupInterval = 10 * time.Minute
 
+	// minimumHostPortsRefreshInterval specifies the minimum time for agent to wait
+	// between reporting the host ports bound on the instance.
+	minimumHostPortsRefreshInterval = 1 * time.Minute
+
+	// DefaultAttributeRefreshInterval specifies the default interval at which
+	// updated dynamic attributes are reported.
+	DefaultAttributeRefreshInterval = 1 * time.Minute
+
+	// minimumAttributeRefreshInterval specifies the minimum time for agent to wait
+	// between reporting updated dynamic attributes.
+	minimumAttributeRefreshInterval = 10 * time.Second
+
+	// DefaultPollEndpointCacheMaxAge specifies the default time for which a
+	// discovered poll endpoint is reused before it is discovered again.
+	DefaultPollEndpointCacheMaxAge = 20 * time.Minute
+
+	// minimumPollEndpointCacheMaxAge specifies the minimum time for which a
+	// discovered poll endpoint is reused.
+	minimumPollEndpointCacheMaxAge = 1 * time.Minute
+
+	// DefaultACSHeartbeatTimeout specifies the default maximum time without any
+	// message from ACS, heartbeats included, before the connection is
+	// considered stale and re-established.
+	DefaultACSHeartbeatTimeout = 1 * time.Minute
+
+	// minimumACSHeartbeatTimeout specifies the minimum value for the ACS
+	// heartbeat timeout.
+	minimumACSHeartbeatTimeout = 10 * time.Second
+
+	// minimumACSConnectionMaxAge specifies the minimum time a connection to
+	// ACS is kept before the agent reconnects.
+	minimumACSConnectionMaxAge = 1 * time.Minute
+
+	// DefaultPublishMetricsInterval specifies the default interval at which
+	// container metrics are published to the telemetry backend.
+	DefaultPublishMetricsInterval = 20 * time.Second
+
+	// minimumPublishMetricsInterval specifies the minimum interval at which
+	// container metrics are published to the telemetry backend.
+	minimumPublishMetricsInterval = 5 * time.Second
+
+	// DefaultDiskFreeSpaceWarnThreshold specifies the default percentage of
+	// free space on the docker data root below which a warning is logged.
+	DefaultDiskFreeSpaceWarnThreshold = 10
+
+	// DefaultDiskUsageCheckInterval specifies the default interval at which
+	// the disk usage of docker is checked.
+	DefaultDiskUsageCheckInterval = 1 * time.Hour
+
+	// minimumDiskUsageCheckInterval specifies the minimum interval at which
+	// the disk usage of docker is checked, as computing it is costly on hosts
+	// with many images.
+	minimumDiskUsageCheckInterval = 5 * time.Minute
+
+	// DefaultACSConnectTimeout specifies the default maximum time to establish
+	// a connection to ACS.
+	DefaultACSConnectTimeout = 30 * time.Second
+
+	// DefaultShutdownDrainTimeout specifies the default time for which the agent
+	// waits for the running tasks to stop when draining on shutdown.
+	DefaultShutdownDrainTimeout = 5 * time.Minute
+
+	// DefaultCreateClusterRetries specifies the default number of retries of the
+	// CreateCluster call when creating the default cluster.
+	DefaultCreateClusterRetries = 3
+
+	// DefaultECSClientMaxIdleConns specifies the default number of idle
+	// connections to the ECS endpoint kept alive for reuse.
+	DefaultECSClientMaxIdleConns = 10
+
+	// DefaultECSClientIdleConnTimeout specifies the default time an idle
+	// connection to the ECS endpoint is kept alive for reuse.
+	DefaultECSClientIdleConnTimeout = 90 * time.Second
+
+	// DefaultECSAPIRetryAttempts specifies the default number of attempts of
+	// an ECS API call that fails with a throttling or server error.
+	DefaultECSAPIRetryAttempts = 4
+
+	// DefaultECSAPIRetryMinBackoff specifies the default backoff before the
+	// first retry of an ECS API call.
+	DefaultECSAPIRetryMinBackoff = 1 * time.Second
+
+	// DefaultECSAPIRetryMaxBackoff specifies the default maximum backoff
+	// between retries of an ECS API call.
+	DefaultECSAPIRetryMaxBackoff = 10 * time.Second
+
 	// minimumNumImagesToDeletePerCycle specifies the minimum number of images that to be deleted when
 	// performing image cleanup.
 	minimumNumImagesToDeletePerCycle = 1
@@ -154,6 +242,22 @@ const (
 	ImagePullPreferCachedBehavior
 )
 
+const (
+	// MissingExitCodeOmitBehavior specifies that no exit code is reported for
+	// a stopped container without one.
+	MissingExitCodeOmitBehavior MissingExitCodeBehaviorType = iota
+
+	// MissingExitCodeInferBehavior specifies that a synthetic exit code, inferred
+	// from the reason the container stopped, is reported for a stopped container
+	// without one.
+	MissingExitCodeInferBehavior
+
+	// MissingExitCodeFlagBehavior specifies that the reason of a stopped
+	// container without an exit code is flagged to state that the exit code is
+	// unknown.
+	MissingExitCodeFlagBehavior
+)
+
 const (
 	// When ContainerInstancePropagateTagsFromNoneType is specified, no DescribeTags
 	// API call will be made.
@@ -164,6 +268,41 @@ const (
 	ContainerInstancePropagateTagsFromEC2InstanceType
 )
 
+const (
+	// CredentialSourceEnv specifies that credentials should be read from the
+	// AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY environment variables.
+	CredentialSourceEnv = "env"
+
+	// CredentialSourceFile specifies that credentials should be read from the
+	// shared credentials file.
+	CredentialSourceFile = "file"
+
+	// CredentialSourceInstanceRole specifies that credentials should be fetched
+	// from the remote endpoint (EC2 instance role or container credentials endpoint).
+	CredentialSourceInstanceRole = "instance-role"
+)
+
+const (
+	// ComplianceFrameworkHIPAA labels the instance as HIPAA compliant.
+	ComplianceFrameworkHIPAA = "hipaa"
+
+	// ComplianceFrameworkPCI labels the instance as PCI DSS compliant.
+	ComplianceFrameworkPCI = "pci"
+
+	// ComplianceFrameworkFedRAMP labels the instance as FedRAMP compliant.
+	ComplianceFrameworkFedRAMP = "fedramp"
+)
+
+var (
+	// validCredentialSources is the set of credential source names accepted in
+	// CredentialSourceOrder
+	validCredentialSources = []string{CredentialSourceEnv, CredentialSourceFile, CredentialSourceInstanceRole}
+
+	// validComplianceFrameworks is the set of compliance framework names
+	// accepted in ComplianceFrameworks
+	validComplianceFrameworks = []string{ComplianceFrameworkHIPAA, ComplianceFrameworkPCI, ComplianceFrameworkFedRAMP}
+)
+
 var (
 	// DefaultPauseContainerImageName is the name of the pause container image. The linker's
 	// load flags are used to populate this value from the Makefile
@@ -298,6 +437,20 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		return errors.New("Invalid logging drivers: " + strings.Join(badDrivers, ", "))
 	}
 
+	if err := cfg.validateCredentialSourceOrder(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateComplianceFrameworks(); err != nil {
+		return err
+	}
+
+	// An invalid bind address is not overridden, since listening on all the
+	// addresses instead would expose the introspection server
+	if cfg.IntrospectionBindAddress != "" && net.ParseIP(cfg.IntrospectionBindAddress) == nil {
+		return fmt.Errorf("config: invalid value for introspection bind address: %s, expected an IP address", cfg.IntrospectionBindAddress)
+	}
+
 	// If a value has been set for taskCleanupWaitDuration and the value is less than the minimum allowed cleanup duration,
 	// print a warning and override it
 	if cfg.TaskCleanupWaitDuration < minimumTaskCleanupWaitDuration {
@@ -315,11 +468,120 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.ImageCleanupInterval = DefaultImageCleanupTimeInterval
 	}
 
+	if cfg.HostPortsRefreshInterval != 0 && cfg.HostPortsRefreshInterval < minimumHostPortsRefreshInterval {
+		seelog.Warnf("Invalid value for host ports refresh interval, will be overridden with the minimum value: %s. Parsed value: %v.", minimumHostPortsRefreshInterval.String(), cfg.HostPortsRefreshInterval)
+		cfg.HostPortsRefreshInterval = minimumHostPortsRefreshInterval
+	}
+
+	if cfg.PollEndpointCacheMaxAge < minimumPollEndpointCacheMaxAge {
+		seelog.Warnf("Invalid value for poll endpoint cache max age, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultPollEndpointCacheMaxAge.String(), cfg.PollEndpointCacheMaxAge, minimumPollEndpointCacheMaxAge)
+		cfg.PollEndpointCacheMaxAge = DefaultPollEndpointCacheMaxAge
+	}
+
+	if cfg.ACSHeartbeatTimeout < minimumACSHeartbeatTimeout {
+		seelog.Warnf("Invalid value for ACS heartbeat timeout, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultACSHeartbeatTimeout.String(), cfg.ACSHeartbeatTimeout, minimumACSHeartbeatTimeout)
+		cfg.ACSHeartbeatTimeout = DefaultACSHeartbeatTimeout
+	}
+
+	if cfg.ACSConnectionMaxAge != 0 && cfg.ACSConnectionMaxAge < minimumACSConnectionMaxAge {
+		seelog.Warnf("Invalid value for ACS connection max age, will be overridden to keep connections until they fail. Parsed value: %v, minimum value: %v.", cfg.ACSConnectionMaxAge, minimumACSConnectionMaxAge)
+		cfg.ACSConnectionMaxAge = 0
+	}
+
+	if cfg.PublishMetricsInterval < minimumPublishMetricsInterval {
+		seelog.Warnf("Invalid value for publish metrics interval, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultPublishMetricsInterval.String(), cfg.PublishMetricsInterval, minimumPublishMetricsInterval)
+		cfg.PublishMetricsInterval = DefaultPublishMetricsInterval
+	}
+
+	if cfg.DiskFreeSpaceWarnThreshold < 1 || cfg.DiskFreeSpaceWarnThreshold > 100 {
+		seelog.Warnf("Invalid value for disk free space warn threshold, will be overridden with the default value: %d. Parsed value: %d, expected a percentage between 1 and 100.", DefaultDiskFreeSpaceWarnThreshold, cfg.DiskFreeSpaceWarnThreshold)
+		cfg.DiskFreeSpaceWarnThreshold = DefaultDiskFreeSpaceWarnThreshold
+	}
+
+	if cfg.DiskUsageCheckInterval < minimumDiskUsageCheckInterval {
+		seelog.Warnf("Invalid value for disk usage check interval, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultDiskUsageCheckInterval.String(), cfg.DiskUsageCheckInterval, minimumDiskUsageCheckInterval)
+		cfg.DiskUsageCheckInterval = DefaultDiskUsageCheckInterval
+	}
+
+	if cfg.OTLPTracesEndpoint != "" {
+		endpoint, err := url.Parse(cfg.OTLPTracesEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			seelog.Warnf("Invalid value for OTLP traces endpoint, tracing will be disabled. Parsed value: %s, expected an http or https URL.", cfg.OTLPTracesEndpoint)
+			cfg.OTLPTracesEndpoint = ""
+		}
+	}
+
+	if cfg.ACSConnectTimeout <= 0 {
+		seelog.Warnf("Invalid value for ACS connect timeout, will be overridden with the default value: %s. Parsed value: %v.", DefaultACSConnectTimeout.String(), cfg.ACSConnectTimeout)
+		cfg.ACSConnectTimeout = DefaultACSConnectTimeout
+	}
+
+	if cfg.ACSReadWriteTimeout != 0 && cfg.ACSReadWriteTimeout <= cfg.ACSHeartbeatTimeout {
+		seelog.Warnf("Invalid value for ACS read write timeout, will be derived from the ACS heartbeat timeout. Parsed value: %v, heartbeat timeout: %v.", cfg.ACSReadWriteTimeout, cfg.ACSHeartbeatTimeout)
+		cfg.ACSReadWriteTimeout = 0
+	}
+
+	if cfg.ShutdownDrainTimeout <= 0 {
+		seelog.Warnf("Invalid value for shutdown drain timeout, will be overridden with the default value: %s. Parsed value: %v.", DefaultShutdownDrainTimeout.String(), cfg.ShutdownDrainTimeout)
+		cfg.ShutdownDrainTimeout = DefaultShutdownDrainTimeout
+	}
+
+	if cfg.AttributeRefreshInterval < minimumAttributeRefreshInterval {
+		seelog.Warnf("Invalid value for attribute refresh interval, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultAttributeRefreshInterval.String(), cfg.AttributeRefreshInterval, minimumAttributeRefreshInterval)
+		cfg.AttributeRefreshInterval = DefaultAttributeRefreshInterval
+	}
+
+	if cfg.ECSClientMaxIdleConns < 0 {
+		seelog.Warnf("Invalid value for ECS client max idle connections, will be overridden with the default value: %d. Parsed value: %d, minimum value: 0.", DefaultECSClientMaxIdleConns, cfg.ECSClientMaxIdleConns)
+		cfg.ECSClientMaxIdleConns = DefaultECSClientMaxIdleConns
+	}
+
+	if cfg.ECSClientIdleConnTimeout < 0 {
+		seelog.Warnf("Invalid value for ECS client idle connection timeout, will be overridden with the default value: %s. Parsed value: %v.", DefaultECSClientIdleConnTimeout.String(), cfg.ECSClientIdleConnTimeout)
+		cfg.ECSClientIdleConnTimeout = DefaultECSClientIdleConnTimeout
+	}
+
+	if cfg.ECSAPIRetryAttempts < 1 {
+		seelog.Warnf("Invalid value for ECS API retry attempts, will be overridden with the default value: %d. Parsed value: %d, minimum value: 1.", DefaultECSAPIRetryAttempts, cfg.ECSAPIRetryAttempts)
+		cfg.ECSAPIRetryAttempts = DefaultECSAPIRetryAttempts
+	}
+
+	if cfg.ECSAPIRetryMinBackoff <= 0 || cfg.ECSAPIRetryMaxBackoff < cfg.ECSAPIRetryMinBackoff {
+		seelog.Warnf("Invalid value for ECS API retry backoff, will be overridden with the default values: %s to %s. Parsed values: %v to %v.", DefaultECSAPIRetryMinBackoff.String(), DefaultECSAPIRetryMaxBackoff.String(), cfg.ECSAPIRetryMinBackoff, cfg.ECSAPIRetryMaxBackoff)
+		cfg.ECSAPIRetryMinBackoff = DefaultECSAPIRetryMinBackoff
+		cfg.ECSAPIRetryMaxBackoff = DefaultECSAPIRetryMaxBackoff
+	}
+
+	if cfg.CreateClusterAttempts < 1 {
+		seelog.Warnf("Invalid value for create cluster retries, will be overridden with the default value: %d. Minimum value: 0.", DefaultCreateClusterRetries)
+		cfg.CreateClusterAttempts = DefaultCreateClusterRetries + 1
+	}
+
+	if cfg.InstanceWeight < 0 {
+		seelog.Warnf("Invalid value for instance weight, it will not be reported. Parsed value: %d, minimum value: 0.", cfg.InstanceWeight)
+		cfg.InstanceWeight = 0
+	}
+
 	if cfg.NumImagesToDeletePerCycle < minimumNumImagesToDeletePerCycle {
 		seelog.Warnf("Invalid value for number of images to delete for image cleanup, will be overridden with the default value: %d. Parsed value: %d, minimum value: %d.", DefaultImageDeletionAge, cfg.NumImagesToDeletePerCycle, minimumNumImagesToDeletePerCycle)
 		cfg.NumImagesToDeletePerCycle = DefaultNumImagesToDeletePerCycle
 	}
 
+	if cfg.MaxConcurrentImagePulls < 0 {
+		seelog.Warnf("Invalid value for max concurrent image pulls, will be overridden to place no limit on concurrent pulls. Parsed value: %d.", cfg.MaxConcurrentImagePulls)
+		cfg.MaxConcurrentImagePulls = 0
+	}
+
+	if cfg.ContainerDefaultPidsLimit < 0 {
+		seelog.Warnf("Invalid value for default container pids limit, will be overridden to apply no default. Parsed value: %d.", cfg.ContainerDefaultPidsLimit)
+		cfg.ContainerDefaultPidsLimit = 0
+	}
+
+	if cfg.CNIPluginTimeout < 0 {
+		seelog.Warnf("Invalid value for cni plugin timeout, will be overridden to use the built-in timeouts. Parsed value: %v.", cfg.CNIPluginTimeout)
+		cfg.CNIPluginTimeout = 0
+	}
+
 	if cfg.TaskMetadataSteadyStateRate <= 0 || cfg.TaskMetadataBurstRate <= 0 {
 		seelog.Warnf("Invalid values for rate limits, will be overridden with default values: %d,%d.", DefaultTaskMetadataSteadyStateRate, DefaultTaskMetadataBurstRate)
 		cfg.TaskMetadataSteadyStateRate = DefaultTaskMetadataSteadyStateRate
@@ -334,6 +596,58 @@ func (cfg *Config) validateAndOverrideBounds() error {
 	return nil
 }
 
+// validateCredentialSourceOrder checks that every entry of CredentialSourceOrder
+// names a known credential source and that no source is listed twice.
+func (cfg *Config) validateCredentialSourceOrder() error {
+	seen := make(map[string]bool)
+	for _, source := range cfg.CredentialSourceOrder {
+		if !isValidCredentialSource(source) {
+			return fmt.Errorf("config: invalid credential source %q; valid sources are: %s",
+				source, strings.Join(validCredentialSources, ", "))
+		}
+		if seen[source] {
+			return fmt.Errorf("config: duplicate credential source %q", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+func isValidCredentialSource(source string) bool {
+	for _, valid := range validCredentialSources {
+		if source == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// validateComplianceFrameworks checks that every entry of ComplianceFrameworks
+// names a known compliance framework and that no framework is listed twice.
+func (cfg *Config) validateComplianceFrameworks() error {
+	seen := make(map[string]bool)
+	for _, framework := range cfg.ComplianceFrameworks {
+		if !isValidComplianceFramework(framework) {
+			return fmt.Errorf("config: invalid compliance framework %q; valid frameworks are: %s",
+				framework, strings.Join(validComplianceFrameworks, ", "))
+		}
+		if seen[framework] {
+			return fmt.Errorf("config: duplicate compliance framework %q", framework)
+		}
+		seen[framework] = true
+	}
+	return nil
+}
+
+func isValidComplianceFramework(framework string) bool {
+	for _, valid := range validComplianceFrameworks {
+		if framework == valid {
+			return true
+		}
+	}
+	return false
+}
+
 func (cfg *Config) pollMetricsOverrides() {
 	if cfg.PollMetrics {
 		if cfg.PollingMetricsWaitDuration < minimumPollingMetricsWaitDuration {
@@ -400,6 +714,20 @@ func (cfg *Config) complete() bool {
 	return true
 }
 
+// InstanceWeightFromFile reads the instance weight from the agent's
+// configuration file. A negative weight is treated as no weight
+func InstanceWeightFromFile() (int, error) {
+	cfg, err := fileConfig()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.InstanceWeight < 0 {
+		seelog.Warnf("Invalid value for instance weight, it will not be reported. Parsed value: %d, minimum value: 0.", cfg.InstanceWeight)
+		return 0, nil
+	}
+	return cfg.InstanceWeight, nil
+}
+
 func fileConfig() (Config, error) {
 	fileName := utils.DefaultIfBlank(os.Getenv("ECS_AGENT_CONFIG_FILE_PATH"), defaultConfigFileName)
 	cfg := Config{}
@@ -475,10 +803,22 @@ func environmentConfig() (Config, error) {
 	var errs []error
 	instanceAttributes, errs := parseInstanceAttributes(errs)
 
+	branchENILimits, errs := parseBranchENILimits(errs)
+
 	containerInstanceTags, errs := parseContainerInstanceTags(errs)
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	introspectionAllowedCIDRs, errs := parseIntrospectionAllowedCIDRs(errs)
+
+	pinnedPublicKeys, errs := parsePinnedPublicKeys(errs)
+
+	credentialSourceOrder, errs := parseCredentialSourceOrder(errs)
+
+	disallowedCapabilities, errs := parseDisallowedCapabilities(errs)
+
+	complianceFrameworks, errs := parseComplianceFrameworks(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -486,10 +826,30 @@ func environmentConfig() (Config, error) {
 	return Config{
 		Cluster:                             os.Getenv("ECS_CLUSTER"),
 		APIEndpoint:                         os.Getenv("ECS_BACKEND_HOST"),
+		APIPort:                             parseEnvVariableUint16("ECS_BACKEND_PORT"),
+		FIPSEndpointEnabled:                 parseFIPSEndpointEnabled(),
+		DualStackEndpointEnabled:            utils.ParseBool(os.Getenv("ECS_USE_DUALSTACK_ENDPOINT"), false),
 		AWSRegion:                           os.Getenv("AWS_DEFAULT_REGION"),
 		DockerEndpoint:                      os.Getenv("DOCKER_HOST"),
 		ReservedPorts:                       parseReservedPorts("ECS_RESERVED_PORTS"),
 		ReservedPortsUDP:                    parseReservedPorts("ECS_RESERVED_PORTS_UDP"),
+		HostPortsRefreshInterval:            parseEnvVariableDuration("ECS_HOST_PORTS_REFRESH_INTERVAL"),
+		AttributeRefreshInterval:            parseEnvVariableDuration("ECS_ATTRIBUTE_REFRESH_INTERVAL"),
+		PollEndpointCacheMaxAge:             parseEnvVariableDuration("ECS_POLL_ENDPOINT_CACHE_MAX_AGE"),
+		ACSHeartbeatTimeout:                 parseEnvVariableDuration("ECS_ACS_HEARTBEAT_TIMEOUT"),
+		ACSConnectionMaxAge:                 parseEnvVariableDuration("ECS_ACS_CONNECTION_MAX_AGE"),
+		ACSConnectTimeout:                   parseEnvVariableDuration("ECS_ACS_CONNECT_TIMEOUT"),
+		ACSReadWriteTimeout:                 parseEnvVariableDuration("ECS_ACS_READ_WRITE_TIMEOUT"),
+		PublishMetricsInterval:              parseEnvVariableDuration("ECS_PUBLISH_METRICS_INTERVAL"),
+		DiskFreeSpaceWarnThreshold:          parseDiskFreeSpaceWarnThreshold(),
+		DiskUsageCheckInterval:              parseEnvVariableDuration("ECS_DISK_USAGE_CHECK_INTERVAL"),
+		DockerDataRootPath:                  os.Getenv("ECS_DOCKER_DATA_ROOT_PATH"),
+		CreateClusterAttempts:               parseCreateClusterAttempts(),
+		ECSClientMaxIdleConns:               parseECSClientMaxIdleConns(),
+		ECSClientIdleConnTimeout:            parseEnvVariableDuration("ECS_CLIENT_IDLE_CONN_TIMEOUT"),
+		ECSAPIRetryAttempts:                 parseECSAPIRetryAttempts(),
+		ECSAPIRetryMinBackoff:               parseEnvVariableDuration("ECS_API_RETRY_MIN_BACKOFF"),
+		ECSAPIRetryMaxBackoff:               parseEnvVariableDuration("ECS_API_RETRY_MAX_BACKOFF"),
 		DataDir:                             dataDir,
 		Checkpoint:                          parseCheckpoint(dataDir),
 		EngineAuthType:                      os.Getenv("ECS_ENGINE_AUTH_TYPE"),
@@ -498,12 +858,24 @@ func environmentConfig() (Config, error) {
 		UpdateDownloadDir:                   os.Getenv("ECS_UPDATE_DOWNLOAD_DIR"),
 		DisableMetrics:                      utils.ParseBool(os.Getenv("ECS_DISABLE_METRICS"), false),
 		ReservedMemory:                      parseEnvVariableUint16("ECS_RESERVED_MEMORY"),
+		ReservedCPU:                         parseEnvVariableUint16("ECS_RESERVED_CPU"),
 		AvailableLoggingDrivers:             parseAvailableLoggingDrivers(),
 		PrivilegedDisabled:                  utils.ParseBool(os.Getenv("ECS_DISABLE_PRIVILEGED"), false),
+		HostPIDDisabled:                     utils.ParseBool(os.Getenv("ECS_DISABLE_HOST_PID"), false),
+		HostIPCDisabled:                     utils.ParseBool(os.Getenv("ECS_DISABLE_HOST_IPC"), false),
+		DrainProtection:                     utils.ParseBool(os.Getenv("ECS_DRAIN_PROTECTION"), false),
+		ForceShutdownDrain:                  utils.ParseBool(os.Getenv("ECS_FORCE_SHUTDOWN_DRAIN"), false),
+		DrainOnShutdown:                     utils.ParseBool(os.Getenv("ECS_DRAIN_ON_SHUTDOWN"), false),
+		DeregisterOnShutdown:                utils.ParseBool(os.Getenv("ECS_DEREGISTER_ON_SHUTDOWN"), false),
+		ShutdownDrainTimeout:                parseEnvVariableDuration("ECS_SHUTDOWN_DRAIN_TIMEOUT"),
+		SpotInstanceDrainingEnabled:         utils.ParseBool(os.Getenv("ECS_ENABLE_SPOT_INSTANCE_DRAINING"), false),
 		SELinuxCapable:                      utils.ParseBool(os.Getenv("ECS_SELINUX_CAPABLE"), false),
 		AppArmorCapable:                     utils.ParseBool(os.Getenv("ECS_APPARMOR_CAPABLE"), false),
+		FireLensCapable:                     utils.ParseBool(os.Getenv("ECS_FIRELENS_CAPABLE"), false),
 		TaskCleanupWaitDuration:             parseEnvVariableDuration("ECS_ENGINE_TASK_CLEANUP_WAIT_DURATION"),
 		TaskENIEnabled:                      utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_ENI"), false),
+		ENITrunkingEnabled:                  utils.ParseBool(os.Getenv("ECS_ENABLE_ENI_TRUNKING"), false),
+		BranchENILimits:                     branchENILimits,
 		TaskIAMRoleEnabled:                  utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_IAM_ROLE"), false),
 		DeleteNonECSImagesEnabled:           utils.ParseBool(os.Getenv("ECS_ENABLE_UNTRACKED_IMAGE_CLEANUP"), false),
 		TaskCPUMemLimit:                     parseTaskCPUMemLimitEnabled(),
@@ -519,8 +891,17 @@ func environmentConfig() (Config, error) {
 		NumImagesToDeletePerCycle:           parseNumImagesToDeletePerCycle(),
 		NumNonECSContainersToDeletePerCycle: parseNumNonECSContainersToDeletePerCycle(),
 		ImagePullBehavior:                   parseImagePullBehavior(),
+		MaxConcurrentImagePulls:             parseMaxConcurrentImagePulls(),
+		ContainerDefaultUlimits:             parseContainerDefaultUlimits(),
+		ContainerDefaultPidsLimit:           parseContainerDefaultPidsLimit(),
+		DisallowedCapabilities:              disallowedCapabilities,
+		UnsafeSysctlsAllowed:                utils.ParseBool(os.Getenv("ECS_ALLOW_UNSAFE_SYSCTLS"), false),
+		CNIPluginTimeout:                    parseEnvVariableDuration("ECS_CNI_PLUGIN_TIMEOUT"),
+		ImagePullRegistryMirrors:            parseImagePullRegistryMirrors(),
+		MissingExitCodeBehavior:             parseMissingExitCodeBehavior(),
 		ImageCleanupExclusionList:           parseImageCleanupExclusionList("ECS_EXCLUDE_UNTRACKED_IMAGE"),
 		InstanceAttributes:                  instanceAttributes,
+		InstanceWeight:                      parseInstanceWeight(),
 		CNIPluginsPath:                      os.Getenv("ECS_CNI_PLUGINS_PATH"),
 		AWSVPCBlockInstanceMetdata:          utils.ParseBool(os.Getenv("ECS_AWSVPC_BLOCK_IMDS"), false),
 		AWSVPCAdditionalLocalRoutes:         additionalLocalRoutes,
@@ -539,6 +920,15 @@ func environmentConfig() (Config, error) {
 		GPUSupportEnabled:                   utils.ParseBool(os.Getenv("ECS_ENABLE_GPU_SUPPORT"), false),
 		NvidiaRuntime:                       os.Getenv("ECS_NVIDIA_RUNTIME"),
 		TaskMetadataAZDisabled:              utils.ParseBool(os.Getenv("ECS_DISABLE_TASK_METADATA_AZ"), false),
+		CredentialSourceOrder:               credentialSourceOrder,
+		ComplianceFrameworks:                complianceFrameworks,
+		OTLPTracesEndpoint:                  os.Getenv("ECS_OTLP_TRACES_ENDPOINT"),
+		DebugEndpointsEnabled:               utils.ParseBool(os.Getenv("ECS_ENABLE_DEBUG_ENDPOINTS"), false),
+		IntrospectionBindAddress:            os.Getenv("ECS_INTROSPECTION_BIND_ADDRESS"),
+		IntrospectionAllowedCIDRs:           introspectionAllowedCIDRs,
+		IntrospectionAuthToken:              os.Getenv("ECS_INTROSPECTION_AUTH_TOKEN"),
+		CABundlePath:                        os.Getenv("ECS_CA_BUNDLE_PATH"),
+		PinnedPublicKeys:                    pinnedPublicKeys,
 	}, err
 }
 
@@ -565,6 +955,7 @@ func (cfg *Config) String() string {
 			"PollMetrics: %v, "+
 			"PollingMetricsWaitDuration: %v, "+
 			"ReservedMem: %v, "+
+			"ReservedCPU: %v, "+
 			"TaskCleanupWaitDuration: %v, "+
 			"DockerStopTimeout: %v, "+
 			"ContainerStartTimeout: %v, "+
@@ -580,6 +971,7 @@ func (cfg *Config) String() string {
 		cfg.PollMetrics,
 		cfg.PollingMetricsWaitDuration,
 		cfg.ReservedMemory,
+		cfg.ReservedCPU,
 		cfg.TaskCleanupWaitDuration,
 		cfg.DockerStopTimeout,
 		cfg.ContainerStartTimeout,
@@ -18,6 +18,7 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"net"
 	"os"
 	"testing"
 	"time"
@@ -27,8 +28,10 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/docker/go-units"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMerge(t *testing.T) {
@@ -97,7 +100,11 @@ func TestEnvironmentConfig(t *testing.T) {
 	defer setTestEnv("ECS_AVAILABLE_LOGGING_DRIVERS", "[\""+string(dockerclient.SyslogDriver)+"\"]")()
 	defer setTestEnv("ECS_SELINUX_CAPABLE", "true")()
 	defer setTestEnv("ECS_APPARMOR_CAPABLE", "true")()
+	defer setTestEnv("ECS_FIRELENS_CAPABLE", "true")()
 	defer setTestEnv("ECS_DISABLE_PRIVILEGED", "true")()
+	defer setTestEnv("ECS_DISABLE_HOST_PID", "true")()
+	defer setTestEnv("ECS_DISABLE_HOST_IPC", "true")()
+	defer setTestEnv("ECS_DRAIN_PROTECTION", "true")()
 	defer setTestEnv("ECS_ENGINE_TASK_CLEANUP_WAIT_DURATION", "90s")()
 	defer setTestEnv("ECS_ENABLE_TASK_IAM_ROLE", "true")()
 	defer setTestEnv("ECS_ENABLE_UNTRACKED_IMAGE_CLEANUP", "true")()
@@ -110,6 +117,8 @@ func TestEnvironmentConfig(t *testing.T) {
 	defer setTestEnv("ECS_INSTANCE_ATTRIBUTES", "{\"my_attribute\": \"testing\"}")()
 	defer setTestEnv("ECS_CONTAINER_INSTANCE_TAGS", `{"my_tag": "testing"}`)()
 	defer setTestEnv("ECS_ENABLE_TASK_ENI", "true")()
+	defer setTestEnv("ECS_ENABLE_ENI_TRUNKING", "true")()
+	defer setTestEnv("ECS_BRANCH_ENI_LIMITS", `{"c5.xlarge": 18}`)()
 	defer setTestEnv("ECS_TASK_METADATA_RPS_LIMIT", "1000,1100")()
 	defer setTestEnv("ECS_SHARED_VOLUME_MATCH_FULL_CONFIG", "true")()
 	defer setTestEnv("ECS_ENABLE_GPU_SUPPORT", "true")()
@@ -135,8 +144,12 @@ func TestEnvironmentConfig(t *testing.T) {
 	assert.Equal(t, expectedDurationContainerStartTimeout, conf.ContainerStartTimeout)
 	assert.Equal(t, []dockerclient.LoggingDriver{dockerclient.SyslogDriver}, conf.AvailableLoggingDrivers)
 	assert.True(t, conf.PrivilegedDisabled)
+	assert.True(t, conf.HostPIDDisabled, "Wrong value for HostPIDDisabled")
+	assert.True(t, conf.HostIPCDisabled, "Wrong value for HostIPCDisabled")
+	assert.True(t, conf.DrainProtection, "Wrong value for DrainProtection")
 	assert.True(t, conf.SELinuxCapable, "Wrong value for SELinuxCapable")
 	assert.True(t, conf.AppArmorCapable, "Wrong value for AppArmorCapable")
+	assert.True(t, conf.FireLensCapable, "Wrong value for FireLensCapable")
 	assert.True(t, conf.TaskIAMRoleEnabled, "Wrong value for TaskIAMRoleEnabled")
 	assert.True(t, conf.DeleteNonECSImagesEnabled, "Wrong value for DeleteNonECSImagesEnabled")
 	assert.True(t, conf.TaskIAMRoleEnabledForNetworkHost, "Wrong value for TaskIAMRoleEnabledForNetworkHost")
@@ -145,6 +158,8 @@ func TestEnvironmentConfig(t *testing.T) {
 	expectedDurationPollingMetricsWaitDuration, _ := time.ParseDuration("10s")
 	assert.Equal(t, expectedDurationPollingMetricsWaitDuration, conf.PollingMetricsWaitDuration)
 	assert.True(t, conf.TaskENIEnabled, "Wrong value for TaskNetwork")
+	assert.True(t, conf.ENITrunkingEnabled, "Wrong value for ENITrunkingEnabled")
+	assert.Equal(t, map[string]int{"c5.xlarge": 18}, conf.BranchENILimits, "Wrong value for BranchENILimits")
 	assert.Equal(t, (30 * time.Minute), conf.MinimumImageDeletionAge)
 	assert.Equal(t, (2 * time.Hour), conf.ImageCleanupInterval)
 	assert.Equal(t, 2, conf.NumImagesToDeletePerCycle)
@@ -432,6 +447,22 @@ func TestReservedMemory(t *testing.T) {
 	assert.Equal(t, cfg.ReservedMemory, uint16(1), "Wrong value for ReservedMemory.")
 }
 
+func TestReservedCPU(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_RESERVED_CPU", "256")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(256), cfg.ReservedCPU, "Wrong value for ReservedCPU.")
+}
+
+func TestInvalidReservedCPUOverridesToZero(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_RESERVED_CPU", "-1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.ReservedCPU, "Wrong value for ReservedCPU")
+}
+
 func TestTaskIAMRoleEnabled(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_ENABLE_TASK_IAM_ROLE", "true")()
@@ -481,6 +512,215 @@ func TestImageCleanupMinimumInterval(t *testing.T) {
 	assert.Equal(t, cfg.ImageCleanupInterval, DefaultImageCleanupTimeInterval, "Wrong value for ImageCleanupInterval")
 }
 
+func TestHostPortsRefreshInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_HOST_PORTS_REFRESH_INTERVAL", "5m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.HostPortsRefreshInterval, "Wrong value for HostPortsRefreshInterval")
+}
+
+func TestHostPortsRefreshMinimumInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_HOST_PORTS_REFRESH_INTERVAL", "10s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, minimumHostPortsRefreshInterval, cfg.HostPortsRefreshInterval, "Wrong value for HostPortsRefreshInterval")
+}
+
+func TestAttributeRefreshInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ATTRIBUTE_REFRESH_INTERVAL", "5m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.AttributeRefreshInterval, "Wrong value for AttributeRefreshInterval")
+}
+
+func TestInvalidAttributeRefreshIntervalOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ATTRIBUTE_REFRESH_INTERVAL", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAttributeRefreshInterval, cfg.AttributeRefreshInterval, "Wrong value for AttributeRefreshInterval")
+}
+
+func TestPollEndpointCacheMaxAge(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_POLL_ENDPOINT_CACHE_MAX_AGE", "5m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.PollEndpointCacheMaxAge, "Wrong value for PollEndpointCacheMaxAge")
+}
+
+func TestInvalidPollEndpointCacheMaxAgeOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_POLL_ENDPOINT_CACHE_MAX_AGE", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPollEndpointCacheMaxAge, cfg.PollEndpointCacheMaxAge, "Wrong value for PollEndpointCacheMaxAge")
+}
+
+func TestACSHeartbeatTimeout(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ACS_HEARTBEAT_TIMEOUT", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.ACSHeartbeatTimeout, "Wrong value for ACSHeartbeatTimeout")
+}
+
+func TestInvalidACSHeartbeatTimeoutOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ACS_HEARTBEAT_TIMEOUT", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultACSHeartbeatTimeout, cfg.ACSHeartbeatTimeout, "Wrong value for ACSHeartbeatTimeout")
+}
+
+func TestShutdownDrainAndDeregister(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DRAIN_ON_SHUTDOWN", "true")()
+	defer setTestEnv("ECS_DEREGISTER_ON_SHUTDOWN", "true")()
+	defer setTestEnv("ECS_SHUTDOWN_DRAIN_TIMEOUT", "10m")()
+	defer setTestEnv("ECS_FORCE_SHUTDOWN_DRAIN", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.DrainOnShutdown, "Wrong value for DrainOnShutdown")
+	assert.True(t, cfg.ForceShutdownDrain, "Wrong value for ForceShutdownDrain")
+	assert.True(t, cfg.DeregisterOnShutdown, "Wrong value for DeregisterOnShutdown")
+	assert.Equal(t, 10*time.Minute, cfg.ShutdownDrainTimeout, "Wrong value for ShutdownDrainTimeout")
+}
+
+func TestSpotInstanceDraining(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_SPOT_INSTANCE_DRAINING", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.SpotInstanceDrainingEnabled, "Wrong value for SpotInstanceDrainingEnabled")
+}
+
+func TestInvalidShutdownDrainTimeoutOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_SHUTDOWN_DRAIN_TIMEOUT", "-1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.DrainOnShutdown, "Wrong value for DrainOnShutdown")
+	assert.False(t, cfg.DeregisterOnShutdown, "Wrong value for DeregisterOnShutdown")
+	assert.Equal(t, DefaultShutdownDrainTimeout, cfg.ShutdownDrainTimeout, "Wrong value for ShutdownDrainTimeout")
+}
+
+func TestTLSTrust(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CA_BUNDLE_PATH", "/etc/ecs/ca-bundle.pem")()
+	defer setTestEnv("ECS_PINNED_PUBLIC_KEYS", `["pin1","pin2"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/ca-bundle.pem", cfg.CABundlePath, "Wrong value for CABundlePath")
+	assert.Equal(t, []string{"pin1", "pin2"}, cfg.PinnedPublicKeys, "Wrong value for PinnedPublicKeys")
+}
+
+func TestInvalidPinnedPublicKeys(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PINNED_PUBLIC_KEYS", "pin1")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for malformed pinned public keys")
+}
+
+func TestECSEndpointSelection(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_BACKEND_PORT", "8443")()
+	defer setTestEnv("ECS_USE_FIPS_ENDPOINT", "true")()
+	defer setTestEnv("ECS_USE_DUALSTACK_ENDPOINT", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(8443), cfg.APIPort, "Wrong value for APIPort")
+	assert.Equal(t, ExplicitlyEnabled, cfg.FIPSEndpointEnabled, "Wrong value for FIPSEndpointEnabled")
+	assert.True(t, cfg.DualStackEndpointEnabled, "Wrong value for DualStackEndpointEnabled")
+}
+
+func TestECSClientIdleConnPool(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CLIENT_MAX_IDLE_CONNS", "4")()
+	defer setTestEnv("ECS_CLIENT_IDLE_CONN_TIMEOUT", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, cfg.ECSClientMaxIdleConns, "Wrong value for ECSClientMaxIdleConns")
+	assert.Equal(t, 30*time.Second, cfg.ECSClientIdleConnTimeout, "Wrong value for ECSClientIdleConnTimeout")
+}
+
+func TestInvalidECSClientIdleConnPool(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CLIENT_MAX_IDLE_CONNS", "-1")()
+	defer setTestEnv("ECS_CLIENT_IDLE_CONN_TIMEOUT", "-1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultECSClientMaxIdleConns, cfg.ECSClientMaxIdleConns, "Wrong value for ECSClientMaxIdleConns")
+	assert.Equal(t, DefaultECSClientIdleConnTimeout, cfg.ECSClientIdleConnTimeout, "Wrong value for ECSClientIdleConnTimeout")
+}
+
+func TestECSAPIRetry(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_API_RETRY_ATTEMPTS", "6")()
+	defer setTestEnv("ECS_API_RETRY_MIN_BACKOFF", "2s")()
+	defer setTestEnv("ECS_API_RETRY_MAX_BACKOFF", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, cfg.ECSAPIRetryAttempts, "Wrong value for ECSAPIRetryAttempts")
+	assert.Equal(t, 2*time.Second, cfg.ECSAPIRetryMinBackoff, "Wrong value for ECSAPIRetryMinBackoff")
+	assert.Equal(t, 30*time.Second, cfg.ECSAPIRetryMaxBackoff, "Wrong value for ECSAPIRetryMaxBackoff")
+}
+
+func TestInvalidECSAPIRetry(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_API_RETRY_ATTEMPTS", "-1")()
+	defer setTestEnv("ECS_API_RETRY_MIN_BACKOFF", "1m")()
+	defer setTestEnv("ECS_API_RETRY_MAX_BACKOFF", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultECSAPIRetryAttempts, cfg.ECSAPIRetryAttempts, "Wrong value for ECSAPIRetryAttempts")
+	assert.Equal(t, DefaultECSAPIRetryMinBackoff, cfg.ECSAPIRetryMinBackoff, "Wrong value for ECSAPIRetryMinBackoff")
+	assert.Equal(t, DefaultECSAPIRetryMaxBackoff, cfg.ECSAPIRetryMaxBackoff, "Wrong value for ECSAPIRetryMaxBackoff")
+}
+
+func TestCreateClusterRetries(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CREATE_CLUSTER_RETRIES", "5")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, cfg.CreateClusterAttempts, "Wrong value for CreateClusterAttempts")
+}
+
+func TestCreateClusterRetriesDisabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CREATE_CLUSTER_RETRIES", "0")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cfg.CreateClusterAttempts, "Wrong value for CreateClusterAttempts")
+}
+
+func TestInvalidCreateClusterRetries(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CREATE_CLUSTER_RETRIES", "-1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultCreateClusterRetries+1, cfg.CreateClusterAttempts, "Wrong value for CreateClusterAttempts")
+}
+
+func TestInstanceWeight(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_INSTANCE_WEIGHT", "10")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 10, cfg.InstanceWeight, "Wrong value for InstanceWeight")
+}
+
+func TestInvalidInstanceWeight(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_INSTANCE_WEIGHT", "-1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.InstanceWeight, "Negative InstanceWeight should be unset")
+}
+
 func TestImageCleanupMinimumNumImagesToDeletePerCycle(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_NUM_IMAGES_DELETE_PER_CYCLE", "-1")()
@@ -497,6 +737,103 @@ func TestInvalidImagePullBehavior(t *testing.T) {
 	assert.Equal(t, cfg.ImagePullBehavior, ImagePullDefaultBehavior, "Wrong value for ImagePullBehavior")
 }
 
+func TestMaxConcurrentImagePulls(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_MAX_CONCURRENT_IMAGE_PULLS", "3")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.MaxConcurrentImagePulls, "Wrong value for MaxConcurrentImagePulls")
+}
+
+func TestInvalidMaxConcurrentImagePulls(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_MAX_CONCURRENT_IMAGE_PULLS", "-1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.MaxConcurrentImagePulls, "Wrong value for MaxConcurrentImagePulls")
+}
+
+func TestCNIPluginTimeout(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_PLUGIN_TIMEOUT", "2m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.CNIPluginTimeout, "Wrong value for CNIPluginTimeout")
+}
+
+func TestInvalidCNIPluginTimeout(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_PLUGIN_TIMEOUT", "-1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.CNIPluginTimeout, "Wrong value for CNIPluginTimeout")
+}
+
+func TestImagePullRegistryMirrors(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PULL_REGISTRY_MIRRORS", `{"docker.io":["mirror1.example.com","mirror2.example.com"]}`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"docker.io": {"mirror1.example.com", "mirror2.example.com"},
+	}, cfg.ImagePullRegistryMirrors)
+}
+
+func TestInvalidImagePullRegistryMirrors(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PULL_REGISTRY_MIRRORS", `["mirror.example.com"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.ImagePullRegistryMirrors)
+}
+
+func TestContainerDefaultLimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_DEFAULT_ULIMITS", `["nofile=1024:4096","core=0","invalid"]`)()
+	defer setTestEnv("ECS_CONTAINER_DEFAULT_PIDS_LIMIT", "100")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []*units.Ulimit{
+		{Name: "nofile", Soft: 1024, Hard: 4096},
+		{Name: "core", Soft: 0, Hard: 0},
+	}, cfg.ContainerDefaultUlimits, "Wrong value for ContainerDefaultUlimits")
+	assert.Equal(t, int64(100), cfg.ContainerDefaultPidsLimit, "Wrong value for ContainerDefaultPidsLimit")
+}
+
+func TestInvalidContainerDefaultLimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_DEFAULT_ULIMITS", "nofile=1024")()
+	defer setTestEnv("ECS_CONTAINER_DEFAULT_PIDS_LIMIT", "-1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ContainerDefaultUlimits, "Wrong value for ContainerDefaultUlimits")
+	assert.Equal(t, int64(0), cfg.ContainerDefaultPidsLimit, "Wrong value for ContainerDefaultPidsLimit")
+}
+
+func TestDisallowedCapabilities(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISALLOWED_CAPABILITIES", `["SYS_ADMIN","NET_ADMIN"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SYS_ADMIN", "NET_ADMIN"}, cfg.DisallowedCapabilities,
+		"Wrong value for DisallowedCapabilities")
+}
+
+func TestInvalidDisallowedCapabilities(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISALLOWED_CAPABILITIES", "SYS_ADMIN")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for malformed disallowed capabilities")
+}
+
+func TestUnsafeSysctlsAllowed(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ALLOW_UNSAFE_SYSCTLS", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.UnsafeSysctlsAllowed, "Wrong value for UnsafeSysctlsAllowed")
+}
+
 func TestSharedVolumeMatchFullConfigEnabled(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_SHARED_VOLUME_MATCH_FULL_CONFIG", "true")()
@@ -547,6 +884,27 @@ func TestParseImagePullBehavior(t *testing.T) {
 	}
 }
 
+func TestParseMissingExitCodeBehavior(t *testing.T) {
+	testcases := []struct {
+		name                            string
+		envVarVal                       string
+		expectedMissingExitCodeBehavior MissingExitCodeBehaviorType
+	}{
+		{"unset", "", MissingExitCodeOmitBehavior},
+		{"omit", "omit", MissingExitCodeOmitBehavior},
+		{"infer", "infer", MissingExitCodeInferBehavior},
+		{"flag", "flag", MissingExitCodeFlagBehavior},
+		{"invalid", "invalid", MissingExitCodeOmitBehavior},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setTestEnv("ECS_MISSING_EXIT_CODE_BEHAVIOR", tc.envVarVal)()
+			assert.Equal(t, tc.expectedMissingExitCodeBehavior, parseMissingExitCodeBehavior(), "Wrong value for MissingExitCodeBehavior")
+		})
+	}
+}
+
 func TestTaskResourceLimitsOverride(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_ENABLE_TASK_CPU_MEM_LIMIT", "false")()
@@ -754,6 +1112,86 @@ func TestTaskMetadataAZDisabled(t *testing.T) {
 	assert.True(t, cfg.TaskMetadataAZDisabled, "Wrong value for TaskMetadataAZDisabled")
 }
 
+func TestCredentialSourceOrder(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CREDENTIAL_SOURCE_ORDER", `["file","env","instance-role"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{CredentialSourceFile, CredentialSourceEnv, CredentialSourceInstanceRole},
+		cfg.CredentialSourceOrder, "Wrong value for CredentialSourceOrder")
+}
+
+func TestInvalidCredentialSourceOrder(t *testing.T) {
+	testCases := []struct {
+		name  string
+		order []string
+	}{
+		{"unknown source", []string{CredentialSourceEnv, "vault"}},
+		{"duplicate source", []string{CredentialSourceEnv, CredentialSourceEnv}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := DefaultConfig()
+			conf.AWSRegion = "us-west-2"
+			conf.CredentialSourceOrder = tc.order
+			assert.Error(t, conf.validateAndOverrideBounds())
+		})
+	}
+}
+
+func TestMalformedCredentialSourceOrder(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CREDENTIAL_SOURCE_ORDER", "env,instance-role")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for a malformed credential source order")
+}
+
+func TestComplianceFrameworks(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_COMPLIANCE_FRAMEWORKS", `["hipaa","fedramp"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ComplianceFrameworkHIPAA, ComplianceFrameworkFedRAMP},
+		cfg.ComplianceFrameworks, "Wrong value for ComplianceFrameworks")
+}
+
+func TestInvalidBranchENILimits(t *testing.T) {
+	for _, limits := range []string{`["c5.xlarge"]`, `{"c5.xlarge": "18"}`, `{"c5.xlarge": 0}`} {
+		t.Run(limits, func(t *testing.T) {
+			defer setTestRegion()()
+			defer setTestEnv("ECS_BRANCH_ENI_LIMITS", limits)()
+			_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+			assert.Error(t, err, "Expected an error for invalid branch ENI limits")
+		})
+	}
+}
+
+func TestMalformedComplianceFrameworks(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_COMPLIANCE_FRAMEWORKS", "hipaa")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for malformed compliance frameworks")
+}
+
+func TestInvalidComplianceFrameworks(t *testing.T) {
+	testCases := []struct {
+		name       string
+		frameworks []string
+	}{
+		{"unknown framework", []string{ComplianceFrameworkPCI, "sox"}},
+		{"wrong case", []string{"HIPAA"}},
+		{"duplicate framework", []string{ComplianceFrameworkPCI, ComplianceFrameworkPCI}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := DefaultConfig()
+			conf.AWSRegion = "us-west-2"
+			conf.ComplianceFrameworks = tc.frameworks
+			assert.Error(t, conf.validateAndOverrideBounds())
+		})
+	}
+}
+
 func setTestRegion() func() {
 	return setTestEnv("AWS_DEFAULT_REGION", "us-west-2")
 }
@@ -764,3 +1202,127 @@ func setTestEnv(k, v string) func() {
 		os.Unsetenv(k)
 	}
 }
+
+func TestACSConnectionSettings(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ACS_CONNECTION_MAX_AGE", "1h")()
+	defer setTestEnv("ECS_ACS_CONNECT_TIMEOUT", "10s")()
+	defer setTestEnv("ECS_ACS_READ_WRITE_TIMEOUT", "5m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, cfg.ACSConnectionMaxAge, "Wrong value for ACSConnectionMaxAge")
+	assert.Equal(t, 10*time.Second, cfg.ACSConnectTimeout, "Wrong value for ACSConnectTimeout")
+	assert.Equal(t, 5*time.Minute, cfg.ACSReadWriteTimeout, "Wrong value for ACSReadWriteTimeout")
+}
+
+func TestInvalidACSConnectionSettingsOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ACS_CONNECTION_MAX_AGE", "10s")()
+	defer setTestEnv("ECS_ACS_CONNECT_TIMEOUT", "-1s")()
+	defer setTestEnv("ECS_ACS_READ_WRITE_TIMEOUT", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.ACSConnectionMaxAge, "Wrong value for ACSConnectionMaxAge")
+	assert.Equal(t, DefaultACSConnectTimeout, cfg.ACSConnectTimeout, "Wrong value for ACSConnectTimeout")
+	assert.Zero(t, cfg.ACSReadWriteTimeout, "Wrong value for ACSReadWriteTimeout")
+}
+
+func TestPublishMetricsInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PUBLISH_METRICS_INTERVAL", "1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, cfg.PublishMetricsInterval, "Wrong value for PublishMetricsInterval")
+}
+
+func TestDiskFreeSpaceWarnThreshold(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISK_FREE_SPACE_WARN_THRESHOLD", "25")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 25, cfg.DiskFreeSpaceWarnThreshold, "Wrong value for DiskFreeSpaceWarnThreshold")
+}
+
+func TestDiskUsageCheckInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISK_USAGE_CHECK_INTERVAL", "30m")()
+	defer setTestEnv("ECS_DOCKER_DATA_ROOT_PATH", "/docker")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, cfg.DiskUsageCheckInterval, "Wrong value for DiskUsageCheckInterval")
+	assert.Equal(t, "/docker", cfg.DockerDataRootPath, "Wrong value for DockerDataRootPath")
+}
+
+func TestInvalidDiskUsageCheckIntervalOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISK_USAGE_CHECK_INTERVAL", "1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDiskUsageCheckInterval, cfg.DiskUsageCheckInterval, "Wrong value for DiskUsageCheckInterval")
+}
+
+func TestInvalidDiskFreeSpaceWarnThresholdOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISK_FREE_SPACE_WARN_THRESHOLD", "150")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDiskFreeSpaceWarnThreshold, cfg.DiskFreeSpaceWarnThreshold, "Wrong value for DiskFreeSpaceWarnThreshold")
+}
+
+func TestOTLPTracesEndpoint(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_OTLP_TRACES_ENDPOINT", " http://localhost:4318/v1/traces ")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:4318/v1/traces", cfg.OTLPTracesEndpoint, "Wrong value for OTLPTracesEndpoint")
+}
+
+func TestInvalidOTLPTracesEndpointDisablesTracing(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_OTLP_TRACES_ENDPOINT", "localhost:4318")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.OTLPTracesEndpoint, "Expected tracing to be disabled")
+}
+
+func TestDebugEndpointsEnabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_DEBUG_ENDPOINTS", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.DebugEndpointsEnabled, "Wrong value for DebugEndpointsEnabled")
+}
+
+func TestIntrospectionAccessControl(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_INTROSPECTION_BIND_ADDRESS", "127.0.0.1")()
+	defer setTestEnv("ECS_INTROSPECTION_ALLOWED_CIDRS", `["127.0.0.0/8","10.0.0.0/24"]`)()
+	defer setTestEnv("ECS_INTROSPECTION_AUTH_TOKEN", "token")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", cfg.IntrospectionBindAddress)
+	require.Len(t, cfg.IntrospectionAllowedCIDRs, 2)
+	allowedCIDR := net.IPNet(cfg.IntrospectionAllowedCIDRs[1])
+	assert.Equal(t, "10.0.0.0/24", allowedCIDR.String())
+	assert.Equal(t, "token", cfg.IntrospectionAuthToken)
+}
+
+func TestInvalidIntrospectionAccessControlFails(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_INTROSPECTION_BIND_ADDRESS", "not-an-ip")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for an invalid bind address")
+
+	os.Unsetenv("ECS_INTROSPECTION_BIND_ADDRESS")
+	defer setTestEnv("ECS_INTROSPECTION_ALLOWED_CIDRS", `["127.0.0.1"]`)()
+	_, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err, "Expected an error for an invalid CIDR")
+}
+
+func TestInvalidPublishMetricsIntervalOverridesToDefault(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PUBLISH_METRICS_INTERVAL", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPublishMetricsInterval, cfg.PublishMetricsInterval, "Wrong value for PublishMetricsInterval")
+}
@@ -52,6 +52,7 @@ func DefaultConfig() Config {
 		DataDirOnHost:                       "/var/lib/ecs",
 		DisableMetrics:                      false,
 		ReservedMemory:                      0,
+		ReservedCPU:                         0,
 		AvailableLoggingDrivers:             []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.NoneDriver},
 		TaskCleanupWaitDuration:             DefaultTaskCleanupWaitDuration,
 		DockerStopTimeout:                   defaultDockerStopTimeout,
@@ -64,6 +65,20 @@ func DefaultConfig() Config {
 		ImagePullInactivityTimeout:          defaultImagePullInactivityTimeout,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		CreateClusterAttempts:               DefaultCreateClusterRetries + 1,
+		ECSClientMaxIdleConns:               DefaultECSClientMaxIdleConns,
+		ECSClientIdleConnTimeout:            DefaultECSClientIdleConnTimeout,
+		ECSAPIRetryAttempts:                 DefaultECSAPIRetryAttempts,
+		ECSAPIRetryMinBackoff:               DefaultECSAPIRetryMinBackoff,
+		ECSAPIRetryMaxBackoff:               DefaultECSAPIRetryMaxBackoff,
+		AttributeRefreshInterval:            DefaultAttributeRefreshInterval,
+		PollEndpointCacheMaxAge:             DefaultPollEndpointCacheMaxAge,
+		ACSHeartbeatTimeout:                 DefaultACSHeartbeatTimeout,
+		ACSConnectTimeout:                   DefaultACSConnectTimeout,
+		PublishMetricsInterval:              DefaultPublishMetricsInterval,
+		DiskFreeSpaceWarnThreshold:          DefaultDiskFreeSpaceWarnThreshold,
+		DiskUsageCheckInterval:              DefaultDiskUsageCheckInterval,
+		ShutdownDrainTimeout:                DefaultShutdownDrainTimeout,
 		CNIPluginsPath:                      defaultCNIPluginsPath,
 		PauseContainerTarballPath:           pauseContainerTarballPath,
 		PauseContainerImageName:             DefaultPauseContainerImageName,
@@ -81,6 +81,7 @@ func DefaultConfig() Config {
 		// run as a container
 		DataDirOnHost:                       dataDir,
 		ReservedMemory:                      0,
+		ReservedCPU:                         0,
 		AvailableLoggingDrivers:             []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.NoneDriver, dockerclient.AWSLogsDriver},
 		TaskCleanupWaitDuration:             DefaultTaskCleanupWaitDuration,
 		DockerStopTimeout:                   defaultDockerStopTimeout,
@@ -93,6 +94,20 @@ func DefaultConfig() Config {
 		ImageCleanupInterval:                DefaultImageCleanupTimeInterval,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		CreateClusterAttempts:               DefaultCreateClusterRetries + 1,
+		ECSClientMaxIdleConns:               DefaultECSClientMaxIdleConns,
+		ECSClientIdleConnTimeout:            DefaultECSClientIdleConnTimeout,
+		ECSAPIRetryAttempts:                 DefaultECSAPIRetryAttempts,
+		ECSAPIRetryMinBackoff:               DefaultECSAPIRetryMinBackoff,
+		ECSAPIRetryMaxBackoff:               DefaultECSAPIRetryMaxBackoff,
+		AttributeRefreshInterval:            DefaultAttributeRefreshInterval,
+		PollEndpointCacheMaxAge:             DefaultPollEndpointCacheMaxAge,
+		ACSHeartbeatTimeout:                 DefaultACSHeartbeatTimeout,
+		ACSConnectTimeout:                   DefaultACSConnectTimeout,
+		PublishMetricsInterval:              DefaultPublishMetricsInterval,
+		DiskFreeSpaceWarnThreshold:          DefaultDiskFreeSpaceWarnThreshold,
+		DiskUsageCheckInterval:              DefaultDiskUsageCheckInterval,
+		ShutdownDrainTimeout:                DefaultShutdownDrainTimeout,
 		ContainerMetadataEnabled:            false,
 		TaskCPUMemLimit:                     ExplicitlyDisabled,
 		PlatformVariables:                   platformVariables,
@@ -117,6 +132,10 @@ func (cfg *Config) platformOverrides() {
 	// ensure TaskResourceLimit is disabled
 	cfg.TaskCPUMemLimit = ExplicitlyDisabled
 
+	// ulimits and pids limits are not supported for Windows containers
+	cfg.ContainerDefaultUlimits = nil
+	cfg.ContainerDefaultPidsLimit = 0
+
 	cpuUnbounded := utils.ParseBool(os.Getenv("ECS_ENABLE_CPU_UNBOUNDED_WINDOWS_WORKAROUND"), false)
 	platformVariables := PlatformVariables{
 		CPUUnbounded: cpuUnbounded,
@@ -0,0 +1,100 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	ecsServiceName     = "ecs"
+	ecsFIPSServiceName = "ecs-fips"
+
+	govCloudRegionPrefix = "us-gov-"
+	chinaRegionPrefix    = "cn-"
+
+	dnsSuffix               = "amazonaws.com"
+	chinaDNSSuffix          = "amazonaws.com.cn"
+	dualStackDNSSuffix      = "api.aws"
+	chinaDualStackDNSSuffix = "api.amazonwebservices.com.cn"
+)
+
+// ECSEndpoint returns the endpoint to make ECS API calls against. It is the
+// APIEndpoint when set, or else the FIPS or dual-stack endpoint of the
+// AWSRegion when selected. The APIPort, when set, overrides the port of the
+// endpoint. An empty string is returned when the default endpoint of the
+// AWSRegion should be used
+func (cfg *Config) ECSEndpoint() string {
+	useFIPS := cfg.useFIPSEndpoint()
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		if !useFIPS && !cfg.DualStackEndpointEnabled && cfg.APIPort == 0 {
+			return ""
+		}
+		endpoint = regionalECSEndpoint(cfg.AWSRegion, useFIPS, cfg.DualStackEndpointEnabled)
+	}
+	if cfg.APIPort == 0 {
+		return endpoint
+	}
+	return endpointWithPort(endpoint, cfg.APIPort)
+}
+
+// useFIPSEndpoint returns true if the FIPS endpoint is explicitly enabled, or
+// if it is not configured and the region is a GovCloud region
+func (cfg *Config) useFIPSEndpoint() bool {
+	if cfg.FIPSEndpointEnabled == ExplicitlyEnabled || cfg.FIPSEndpointEnabled == ExplicitlyDisabled {
+		return cfg.FIPSEndpointEnabled.Enabled()
+	}
+	return strings.HasPrefix(cfg.AWSRegion, govCloudRegionPrefix)
+}
+
+// regionalECSEndpoint returns the host of the ECS endpoint of the region
+func regionalECSEndpoint(region string, fips bool, dualStack bool) string {
+	service := ecsServiceName
+	if fips {
+		service = ecsFIPSServiceName
+	}
+	china := strings.HasPrefix(region, chinaRegionPrefix)
+	suffix := dnsSuffix
+	switch {
+	case dualStack && china:
+		suffix = chinaDualStackDNSSuffix
+	case dualStack:
+		suffix = dualStackDNSSuffix
+	case china:
+		suffix = chinaDNSSuffix
+	}
+	return service + "." + region + "." + suffix
+}
+
+// endpointWithPort replaces the port of the endpoint, which is either a URL
+// or a host
+func endpointWithPort(endpoint string, port uint16) string {
+	portString := strconv.Itoa(int(port))
+	if strings.Contains(endpoint, "://") {
+		endpointURL, err := url.Parse(endpoint)
+		if err == nil {
+			endpointURL.Host = net.JoinHostPort(endpointURL.Hostname(), portString)
+			return endpointURL.String()
+		}
+	}
+	host := endpoint
+	if h, _, err := net.SplitHostPort(endpoint); err == nil {
+		host = h
+	}
+	return net.JoinHostPort(host, portString)
+}
@@ -0,0 +1,96 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECSEndpoint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{
+			name:     "default endpoint",
+			cfg:      Config{AWSRegion: "us-west-2"},
+			expected: "",
+		},
+		{
+			name:     "endpoint override",
+			cfg:      Config{AWSRegion: "us-west-2", APIEndpoint: "https://ecs.example.com", DualStackEndpointEnabled: true},
+			expected: "https://ecs.example.com",
+		},
+		{
+			name:     "endpoint override with port",
+			cfg:      Config{AWSRegion: "us-west-2", APIEndpoint: "https://ecs.example.com:8443/path", APIPort: 443},
+			expected: "https://ecs.example.com:443/path",
+		},
+		{
+			name:     "host override with port",
+			cfg:      Config{AWSRegion: "us-west-2", APIEndpoint: "ecs.example.com", APIPort: 8443},
+			expected: "ecs.example.com:8443",
+		},
+		{
+			name:     "regional endpoint with port",
+			cfg:      Config{AWSRegion: "us-west-2", APIPort: 8443},
+			expected: "ecs.us-west-2.amazonaws.com:8443",
+		},
+		{
+			name:     "fips",
+			cfg:      Config{AWSRegion: "us-east-1", FIPSEndpointEnabled: ExplicitlyEnabled},
+			expected: "ecs-fips.us-east-1.amazonaws.com",
+		},
+		{
+			name:     "fips by default in govcloud",
+			cfg:      Config{AWSRegion: "us-gov-west-1"},
+			expected: "ecs-fips.us-gov-west-1.amazonaws.com",
+		},
+		{
+			name:     "fips disabled in govcloud",
+			cfg:      Config{AWSRegion: "us-gov-west-1", FIPSEndpointEnabled: ExplicitlyDisabled},
+			expected: "",
+		},
+		{
+			name:     "dual-stack",
+			cfg:      Config{AWSRegion: "us-east-1", DualStackEndpointEnabled: true},
+			expected: "ecs.us-east-1.api.aws",
+		},
+		{
+			name:     "fips dual-stack",
+			cfg:      Config{AWSRegion: "us-east-1", FIPSEndpointEnabled: ExplicitlyEnabled, DualStackEndpointEnabled: true},
+			expected: "ecs-fips.us-east-1.api.aws",
+		},
+		{
+			name:     "china dual-stack",
+			cfg:      Config{AWSRegion: "cn-north-1", DualStackEndpointEnabled: true},
+			expected: "ecs.cn-north-1.api.amazonwebservices.com.cn",
+		},
+		{
+			name:     "china with port",
+			cfg:      Config{AWSRegion: "cn-north-1", APIPort: 443},
+			expected: "ecs.cn-north-1.amazonaws.com.cn:443",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.cfg.ECSEndpoint())
+		})
+	}
+}
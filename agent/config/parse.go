@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/cihub/seelog"
 	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/docker/go-units"
 )
 
 func parseCheckpoint(dataDir string) bool {
@@ -120,6 +121,129 @@ func parseAvailableLoggingDrivers() []dockerclient.LoggingDriver {
 	return availableLoggingDrivers
 }
 
+func parseCredentialSourceOrder(errs []error) ([]string, []error) {
+	credentialSourceOrderEnv := os.Getenv("ECS_CREDENTIAL_SOURCE_ORDER")
+	credentialSourceDecoder := json.NewDecoder(strings.NewReader(credentialSourceOrderEnv))
+	var credentialSourceOrder []string
+	err := credentialSourceDecoder.Decode(&credentialSourceOrder)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not an error; the default credential chain is used. Anything
+	// else is, as ignoring it would silently fall back to the default order
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_CREDENTIAL_SOURCE_ORDER\" environment variable; expected a JSON array like [\"env\",\"instance-role\"]. err %v", err)
+		seelog.Error(err)
+		errs = append(errs, err)
+	}
+
+	return credentialSourceOrder, errs
+}
+
+func parseComplianceFrameworks(errs []error) ([]string, []error) {
+	complianceFrameworksEnv := os.Getenv("ECS_COMPLIANCE_FRAMEWORKS")
+	complianceFrameworksDecoder := json.NewDecoder(strings.NewReader(complianceFrameworksEnv))
+	var complianceFrameworks []string
+	err := complianceFrameworksDecoder.Decode(&complianceFrameworks)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not an error; the instance is not labeled. Anything else is,
+	// as ignoring it would silently drop the labels tasks are placed by
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_COMPLIANCE_FRAMEWORKS\" environment variable; expected a JSON array like [\"hipaa\",\"pci\"]. err %v", err)
+		seelog.Error(err)
+		errs = append(errs, err)
+	}
+
+	return complianceFrameworks, errs
+}
+
+func parseDisallowedCapabilities(errs []error) ([]string, []error) {
+	disallowedCapabilitiesEnv := os.Getenv("ECS_DISALLOWED_CAPABILITIES")
+	disallowedCapabilitiesDecoder := json.NewDecoder(strings.NewReader(disallowedCapabilitiesEnv))
+	var disallowedCapabilities []string
+	err := disallowedCapabilitiesDecoder.Decode(&disallowedCapabilities)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not an error; containers may add any capability. Anything else
+	// is, as ignoring it would silently allow every capability
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_DISALLOWED_CAPABILITIES\" environment variable; expected a JSON array like [\"SYS_ADMIN\",\"NET_ADMIN\"]. err %v", err)
+		seelog.Error(err)
+		errs = append(errs, err)
+	}
+
+	return disallowedCapabilities, errs
+}
+
+func parseContainerDefaultUlimits() []*units.Ulimit {
+	ulimitsEnv := os.Getenv("ECS_CONTAINER_DEFAULT_ULIMITS")
+	ulimitsDecoder := json.NewDecoder(strings.NewReader(ulimitsEnv))
+	var rawUlimits []string
+	err := ulimitsDecoder.Decode(&rawUlimits)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not a warning; no default ulimits are applied
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_CONTAINER_DEFAULT_ULIMITS\" environment variable; expected a JSON array like [\"nofile=1024:4096\"]. err %v", err)
+		seelog.Warn(err)
+		return nil
+	}
+
+	var ulimits []*units.Ulimit
+	for _, rawUlimit := range rawUlimits {
+		ulimit, err := units.ParseUlimit(rawUlimit)
+		if err != nil {
+			seelog.Warnf("Discarded invalid value for \"ECS_CONTAINER_DEFAULT_ULIMITS\": %s. err %v", rawUlimit, err)
+			continue
+		}
+		ulimits = append(ulimits, ulimit)
+	}
+	return ulimits
+}
+
+func parseImagePullRegistryMirrors() map[string][]string {
+	mirrorsEnv := os.Getenv("ECS_IMAGE_PULL_REGISTRY_MIRRORS")
+	mirrorsDecoder := json.NewDecoder(strings.NewReader(mirrorsEnv))
+	var mirrors map[string][]string
+	err := mirrorsDecoder.Decode(&mirrors)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not a warning; images are only pulled from their registry
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_IMAGE_PULL_REGISTRY_MIRRORS\" environment variable; expected a JSON object like {\"docker.io\":[\"mirror.example.com\"]}. err %v", err)
+		seelog.Warn(err)
+		return nil
+	}
+	return mirrors
+}
+
+func parseContainerDefaultPidsLimit() int64 {
+	pidsLimitEnvVal := os.Getenv("ECS_CONTAINER_DEFAULT_PIDS_LIMIT")
+	pidsLimit, err := strconv.ParseInt(pidsLimitEnvVal, 10, 64)
+	if pidsLimitEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_CONTAINER_DEFAULT_PIDS_LIMIT\", expected an integer. err %v", err)
+	}
+	return pidsLimit
+}
+
+func parsePinnedPublicKeys(errs []error) ([]string, []error) {
+	pinnedPublicKeysEnv := os.Getenv("ECS_PINNED_PUBLIC_KEYS")
+	pinnedPublicKeysDecoder := json.NewDecoder(strings.NewReader(pinnedPublicKeysEnv))
+	var pinnedPublicKeys []string
+	err := pinnedPublicKeysDecoder.Decode(&pinnedPublicKeys)
+	// EOF means the string was blank as opposed to UnexpectedEof which means an
+	// invalid parse
+	// Blank is not an error; no public key is pinned. Anything else is, as
+	// ignoring it would silently disable the pinning
+	if err != io.EOF && err != nil {
+		err := fmt.Errorf("Invalid format for \"ECS_PINNED_PUBLIC_KEYS\" environment variable; expected a JSON array like [\"base64-sha256\"]. err %v", err)
+		seelog.Error(err)
+		errs = append(errs, err)
+	}
+
+	return pinnedPublicKeys, errs
+}
+
 func parseNumImagesToDeletePerCycle() int {
 	numImagesToDeletePerCycleEnvVal := os.Getenv("ECS_NUM_IMAGES_DELETE_PER_CYCLE")
 	numImagesToDeletePerCycle, err := strconv.Atoi(numImagesToDeletePerCycleEnvVal)
@@ -139,6 +263,70 @@ func parseNumNonECSContainersToDeletePerCycle() int {
 	return numNonEcsContainersToDeletePerCycle
 }
 
+func parseDiskFreeSpaceWarnThreshold() int {
+	thresholdEnvVal := os.Getenv("ECS_DISK_FREE_SPACE_WARN_THRESHOLD")
+	threshold, err := strconv.Atoi(thresholdEnvVal)
+	if thresholdEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_DISK_FREE_SPACE_WARN_THRESHOLD\", expected an integer. err %v", err)
+	}
+	return threshold
+}
+
+// parseCreateClusterAttempts returns the number of CreateCluster attempts
+// from the number of retries, or zero if no valid number of retries is set
+func parseCreateClusterAttempts() int {
+	createClusterRetriesEnvVal := os.Getenv("ECS_CREATE_CLUSTER_RETRIES")
+	if createClusterRetriesEnvVal == "" {
+		return 0
+	}
+	createClusterRetries, err := strconv.Atoi(createClusterRetriesEnvVal)
+	if err != nil {
+		seelog.Warnf("Invalid format for \"ECS_CREATE_CLUSTER_RETRIES\", expected an integer. err %v", err)
+		return 0
+	}
+	if createClusterRetries < 0 {
+		// Left negative so that the validation overrides it with the default
+		return createClusterRetries
+	}
+	return createClusterRetries + 1
+}
+
+func parseECSClientMaxIdleConns() int {
+	maxIdleConnsEnvVal := os.Getenv("ECS_CLIENT_MAX_IDLE_CONNS")
+	maxIdleConns, err := strconv.Atoi(maxIdleConnsEnvVal)
+	if maxIdleConnsEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_CLIENT_MAX_IDLE_CONNS\", expected an integer. err %v", err)
+	}
+	return maxIdleConns
+}
+
+func parseECSAPIRetryAttempts() int {
+	retryAttemptsEnvVal := os.Getenv("ECS_API_RETRY_ATTEMPTS")
+	retryAttempts, err := strconv.Atoi(retryAttemptsEnvVal)
+	if retryAttemptsEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_API_RETRY_ATTEMPTS\", expected an integer. err %v", err)
+	}
+	return retryAttempts
+}
+
+func parseInstanceWeight() int {
+	instanceWeightEnvVal := os.Getenv("ECS_INSTANCE_WEIGHT")
+	instanceWeight, err := strconv.Atoi(instanceWeightEnvVal)
+	if instanceWeightEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_INSTANCE_WEIGHT\", expected an integer. err %v", err)
+	}
+	return instanceWeight
+}
+
+func parseMaxConcurrentImagePulls() int {
+	maxConcurrentImagePullsEnvVal := os.Getenv("ECS_MAX_CONCURRENT_IMAGE_PULLS")
+	maxConcurrentImagePulls, err := strconv.Atoi(maxConcurrentImagePullsEnvVal)
+	if maxConcurrentImagePullsEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_MAX_CONCURRENT_IMAGE_PULLS\", expected an integer. err %v", err)
+	}
+	return maxConcurrentImagePulls
+}
+
 func parseImagePullBehavior() ImagePullBehaviorType {
 	ImagePullBehaviorString := os.Getenv("ECS_IMAGE_PULL_BEHAVIOR")
 	switch ImagePullBehaviorString {
@@ -155,6 +343,20 @@ func parseImagePullBehavior() ImagePullBehaviorType {
 	}
 }
 
+func parseMissingExitCodeBehavior() MissingExitCodeBehaviorType {
+	missingExitCodeBehaviorString := os.Getenv("ECS_MISSING_EXIT_CODE_BEHAVIOR")
+	switch missingExitCodeBehaviorString {
+	case "infer":
+		return MissingExitCodeInferBehavior
+	case "flag":
+		return MissingExitCodeFlagBehavior
+	default:
+		// Use the default "omit" behavior when ECS_MISSING_EXIT_CODE_BEHAVIOR is
+		// "omit" or not valid
+		return MissingExitCodeOmitBehavior
+	}
+}
+
 func parseInstanceAttributes(errs []error) (map[string]string, []error) {
 	var instanceAttributes map[string]string
 	instanceAttributesEnv := os.Getenv("ECS_INSTANCE_ATTRIBUTES")
@@ -173,6 +375,29 @@ func parseInstanceAttributes(errs []error) (map[string]string, []error) {
 	return instanceAttributes, errs
 }
 
+func parseBranchENILimits(errs []error) (map[string]int, []error) {
+	var branchENILimits map[string]int
+	branchENILimitsEnv := os.Getenv("ECS_BRANCH_ENI_LIMITS")
+	if branchENILimitsEnv == "" {
+		return branchENILimits, errs
+	}
+	err := json.Unmarshal([]byte(branchENILimitsEnv), &branchENILimits)
+	if err != nil {
+		wrappedErr := fmt.Errorf("Invalid format for ECS_BRANCH_ENI_LIMITS. Expected a json hash like {\"c5.xlarge\":18}: %v", err)
+		seelog.Error(wrappedErr)
+		return nil, append(errs, wrappedErr)
+	}
+	for instanceType, limit := range branchENILimits {
+		if limit <= 0 {
+			err := fmt.Errorf("Invalid value for ECS_BRANCH_ENI_LIMITS. Branch ENI limit of %s must be positive: %d", instanceType, limit)
+			seelog.Error(err)
+			errs = append(errs, err)
+		}
+	}
+
+	return branchENILimits, errs
+}
+
 func parseAdditionalLocalRoutes(errs []error) ([]cnitypes.IPNet, []error) {
 	var additionalLocalRoutes []cnitypes.IPNet
 	additionalLocalRoutesEnv := os.Getenv("ECS_AWSVPC_ADDITIONAL_LOCAL_ROUTES")
@@ -187,6 +412,20 @@ func parseAdditionalLocalRoutes(errs []error) ([]cnitypes.IPNet, []error) {
 	return additionalLocalRoutes, errs
 }
 
+func parseIntrospectionAllowedCIDRs(errs []error) ([]cnitypes.IPNet, []error) {
+	var allowedCIDRs []cnitypes.IPNet
+	allowedCIDRsEnv := os.Getenv("ECS_INTROSPECTION_ALLOWED_CIDRS")
+	if allowedCIDRsEnv != "" {
+		err := json.Unmarshal([]byte(allowedCIDRsEnv), &allowedCIDRs)
+		if err != nil {
+			seelog.Errorf("Invalid format for ECS_INTROSPECTION_ALLOWED_CIDRS, expected a json array of CIDRs: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	return allowedCIDRs, errs
+}
+
 func parseTaskCPUMemLimitEnabled() Conditional {
 	var taskCPUMemLimitEnabled Conditional
 	taskCPUMemLimitConfigString := os.Getenv("ECS_ENABLE_TASK_CPU_MEM_LIMIT")
@@ -203,6 +442,22 @@ func parseTaskCPUMemLimitEnabled() Conditional {
 	return taskCPUMemLimitEnabled
 }
 
+func parseFIPSEndpointEnabled() Conditional {
+	var fipsEndpointEnabled Conditional
+	fipsEndpointConfigString := os.Getenv("ECS_USE_FIPS_ENDPOINT")
+
+	// The default depends on the region, only set it if it is explicitly set
+	// to true or false
+	if fipsEndpointConfigString != "" {
+		if utils.ParseBool(fipsEndpointConfigString, false) {
+			fipsEndpointEnabled = ExplicitlyEnabled
+		} else {
+			fipsEndpointEnabled = ExplicitlyDisabled
+		}
+	}
+	return fipsEndpointEnabled
+}
+
 func parseTaskMetadataThrottles() (int, int) {
 	var steadyStateRate, burstRate int
 	rpsLimitEnvVal := os.Getenv("ECS_TASK_METADATA_RPS_LIMIT")
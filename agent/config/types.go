@@ -18,6 +18,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/docker/go-units"
 )
 
 // ImagePullBehaviorType is an enum variable type corresponding to different agent pull
@@ -28,6 +29,11 @@ type ImagePullBehaviorType int8
 // ways to propagate tags, it includes none (default) and ec2_instance.
 type ContainerInstancePropagateTagsFromType int8
 
+// MissingExitCodeBehaviorType is an enum variable type corresponding to the
+// ways of reporting a stopped container without an exit code, it includes
+// omit (default), infer and flag.
+type MissingExitCodeBehaviorType int8
+
 type Config struct {
 	// DEPRECATED
 	// ClusterArn is the Name or full ARN of a Cluster to register into. It has
@@ -41,6 +47,16 @@ type Config struct {
 	// make calls against. If this value is not set, it will default to the
 	// endpoint for your current AWSRegion
 	APIEndpoint string `trim:"true"`
+	// APIPort is the port to make ECS API calls against. If this value is not
+	// set, the port of the endpoint is used
+	APIPort uint16
+	// FIPSEndpointEnabled selects the FIPS endpoint of the current AWSRegion
+	// when APIEndpoint is not set. If this value is not set, the FIPS endpoint
+	// is used in the GovCloud regions
+	FIPSEndpointEnabled Conditional
+	// DualStackEndpointEnabled selects the dual-stack (IPv4 and IPv6) endpoint
+	// of the current AWSRegion when APIEndpoint is not set
+	DualStackEndpointEnabled bool
 	// DockerEndpoint is the address the agent will attempt to connect to the
 	// Docker daemon at. This should have the same value as "DOCKER_HOST"
 	// normally would to interact with the daemon. It defaults to
@@ -51,12 +67,76 @@ type Config struct {
 	// will be fatal.
 	AWSRegion string `missing:"fatal" trim:"true"`
 
+	// CreateClusterAttempts is the number of times the agent tries creating the
+	// default cluster, including the first attempt, when the CreateCluster call
+	// is throttled or fails with a retriable error. It is set from the number
+	// of retries in ECS_CREATE_CLUSTER_RETRIES, so that zero retries is not
+	// mistaken for an unset value.
+	CreateClusterAttempts int
+
+	// ECSClientMaxIdleConns is the number of idle connections to the ECS
+	// endpoint that are kept alive, so that API calls reuse them instead of
+	// establishing a new connection.
+	ECSClientMaxIdleConns int
+
+	// ECSClientIdleConnTimeout is the time an idle connection to the ECS
+	// endpoint is kept alive for reuse.
+	ECSClientIdleConnTimeout time.Duration
+
+	// ECSAPIRetryAttempts is the number of times an ECS API call that fails
+	// with a throttling or server error is attempted.
+	ECSAPIRetryAttempts int
+
+	// ECSAPIRetryMinBackoff and ECSAPIRetryMaxBackoff bound the exponential
+	// backoff, with jitter, between attempts of an ECS API call.
+	ECSAPIRetryMinBackoff time.Duration
+	ECSAPIRetryMaxBackoff time.Duration
+
 	// ReservedPorts is an array of ports which should be registered as
 	// unavailable. If not set, they default to [22,2375,2376,51678].
 	ReservedPorts []uint16
 	// ReservedPortsUDP is an array of UDP ports which should be registered as
 	// unavailable. If not set, it defaults to [].
 	ReservedPortsUDP []uint16
+	// HostPortsRefreshInterval is the interval at which the agent reports the
+	// host ports currently bound on the instance, along with the reserved
+	// ports, as container instance attributes. A zero value disables it.
+	HostPortsRefreshInterval time.Duration
+	// AttributeRefreshInterval is the interval at which the dynamic container
+	// instance attributes, such as the host ports and the instance weight, are
+	// reported together in a single PutAttributes call
+	AttributeRefreshInterval time.Duration
+	// PollEndpointCacheMaxAge is the maximum time for which a discovered poll
+	// endpoint is reused. The endpoint is discovered again sooner if the
+	// cluster or the agent's credentials change
+	PollEndpointCacheMaxAge time.Duration
+	// ACSHeartbeatTimeout is the maximum time without any message from ACS,
+	// heartbeats included, after which the connection is considered stale and
+	// re-established. A jitter of up to the same duration is added to it
+	ACSHeartbeatTimeout time.Duration
+	// ACSConnectionMaxAge is the maximum time a connection to ACS is kept
+	// before the agent reconnects, so that endpoint and DNS changes are picked
+	// up. A zero value keeps the connection until it fails
+	ACSConnectionMaxAge time.Duration
+	// ACSConnectTimeout is the maximum time to establish a connection to ACS,
+	// the websocket handshake included
+	ACSConnectTimeout time.Duration
+	// ACSReadWriteTimeout is the read and write deadline of the connection to
+	// ACS. A zero value derives it from the ACSHeartbeatTimeout
+	ACSReadWriteTimeout time.Duration
+	// PublishMetricsInterval is the interval at which container metrics and
+	// health are published to the telemetry backend
+	PublishMetricsInterval time.Duration
+	// DiskFreeSpaceWarnThreshold is the percentage of free space on the docker
+	// data root below which the agent logs a warning
+	DiskFreeSpaceWarnThreshold int
+	// DiskUsageCheckInterval is the interval at which the disk usage of
+	// docker is checked
+	DiskUsageCheckInterval time.Duration
+	// DockerDataRootPath is the path the docker data root is mounted at in
+	// the agent's container, used to measure its free space. When empty, the
+	// data root reported by docker is used
+	DockerDataRootPath string
 
 	// DataDir is the directory data is saved to in order to preserve state
 	// across agent restarts.
@@ -105,6 +185,10 @@ type Config struct {
 	// other than containers managed by ECS
 	ReservedMemory uint16
 
+	// ReservedCPU specifies the amount of CPU (in CPU units) to reserve for things
+	// other than containers managed by ECS
+	ReservedCPU uint16
+
 	// DockerStopTimeout specifies the amount of time before a SIGKILL is issued to
 	// containers managed by ECS
 	DockerStopTimeout time.Duration
@@ -119,10 +203,48 @@ type Config struct {
 	// with Docker.  If not set, it defaults to ["json-file","none"].
 	AvailableLoggingDrivers []dockerclient.LoggingDriver
 
+	// FireLensCapable specifies whether a FireLens log router (fluentd or
+	// fluent bit) can be run on the instance to route container logs
+	FireLensCapable bool
+
 	// PrivilegedDisabled specified whether the Agent is capable of launching
 	// tasks with privileged containers
 	PrivilegedDisabled bool
 
+	// HostPIDDisabled specifies whether the Agent is configured to disallow
+	// tasks that share the host's PID namespace
+	HostPIDDisabled bool
+
+	// HostIPCDisabled specifies whether the Agent is configured to disallow
+	// tasks that share the host's IPC namespace
+	HostIPCDisabled bool
+
+	// DrainProtection specifies whether the instance runs workloads that must
+	// not be interrupted, so that drain tooling can skip it. The agent refuses
+	// to drain or deregister a protected instance on shutdown unless
+	// ForceShutdownDrain is set, and to drain it on a spot rebalance
+	// recommendation
+	DrainProtection bool
+	// ForceShutdownDrain specifies whether the container instance is drained
+	// and deregistered on shutdown even when it is drain protected
+	ForceShutdownDrain bool
+
+	// DrainOnShutdown specifies whether the container instance is set to
+	// DRAINING when the agent is stopped, the agent then waits for the running
+	// tasks to stop before exiting
+	DrainOnShutdown bool
+	// DeregisterOnShutdown specifies whether the container instance is
+	// deregistered from the cluster when the agent is stopped
+	DeregisterOnShutdown bool
+	// ShutdownDrainTimeout is the maximum time for which the agent waits for
+	// the running tasks to stop when draining on shutdown
+	ShutdownDrainTimeout time.Duration
+	// SpotInstanceDrainingEnabled specifies whether the container instance is
+	// set to DRAINING when EC2 notifies a spot instance interruption or a
+	// rebalance recommendation, so that its tasks are replaced elsewhere. On
+	// an interruption notice, the running tasks are also stopped gracefully
+	SpotInstanceDrainingEnabled bool
+
 	// SELinxuCapable specifies whether the Agent is capable of using SELinux
 	// security options
 	SELinuxCapable bool
@@ -159,6 +281,17 @@ type Config struct {
 	// defined EC2 networks
 	TaskENIEnabled bool
 
+	// ENITrunkingEnabled specifies whether awsvpc tasks may use branch ENIs of
+	// a trunk ENI, so that more of them can be placed on an instance type that
+	// supports ENI trunking
+	ENITrunkingEnabled bool
+
+	// BranchENILimits is the number of branch ENIs a trunk ENI supports, by
+	// instance type. ENI trunking is only reported for the instance types
+	// listed here, as overstating the limit makes the scheduler place more
+	// awsvpc tasks than the instance can attach
+	BranchENILimits map[string]int
+
 	// ImageCleanupDisabled specifies whether the Agent will periodically perform
 	// automated image cleanup
 	ImageCleanupDisabled bool
@@ -183,15 +316,35 @@ type Config struct {
 	// local Docker image cache
 	ImagePullBehavior ImagePullBehaviorType
 
+	// MissingExitCodeBehavior specifies what the agent reports for a stopped
+	// container when Docker does not provide its exit code, for example when
+	// the container was killed before it started.
+	MissingExitCodeBehavior MissingExitCodeBehaviorType
+
 	// InstanceAttributes contains key/value pairs representing
 	// attributes to be associated with this instance within the
 	// ECS service and used to influence behavior such as launch
 	// placement.
 	InstanceAttributes map[string]string
 
+	// InstanceWeight is a placement weight for this instance, reported as the
+	// ecs.instance-weight attribute for use by custom placement strategies. It
+	// is not reported when unset. When it is not set in the environment, it
+	// can be updated at runtime by changing the configuration file and sending
+	// SIGHUP to the agent.
+	InstanceWeight int
+
 	// Set if clients validate ssl certificates. Used mainly for testing
 	AcceptInsecureCert bool `json:"-"`
 
+	// CABundlePath is the path of a PEM encoded bundle of certificate
+	// authorities trusted for the ECS endpoints, in addition to the system ones
+	CABundlePath string
+	// PinnedPublicKeys are the base64 encoded SHA-256 hashes of the subject
+	// public key infos accepted in the certificate chains of the ECS endpoints.
+	// Any certificate chain is accepted when empty
+	PinnedPublicKeys []string
+
 	// CNIPluginsPath is the path for the cni plugins
 	CNIPluginsPath string
 
@@ -213,6 +366,29 @@ type Config struct {
 	// default.
 	PrometheusMetricsEnabled bool
 
+	// OTLPTracesEndpoint is the OTLP/HTTP endpoint, such as
+	// "http://localhost:4318/v1/traces", to which spans of the task lifecycle
+	// and of the ECS API calls are exported. Tracing is disabled when empty.
+	OTLPTracesEndpoint string `trim:"true"`
+
+	// DebugEndpointsEnabled configures whether the introspection server serves
+	// the pprof profiles and the goroutine stacks of the agent. This is
+	// disabled by default.
+	DebugEndpointsEnabled bool
+
+	// IntrospectionBindAddress is the IP address the introspection server
+	// listens on. It listens on all the addresses when empty.
+	IntrospectionBindAddress string `trim:"true"`
+
+	// IntrospectionAllowedCIDRs restricts the requests to the introspection
+	// server to the given source networks. All the sources are allowed when
+	// empty.
+	IntrospectionAllowedCIDRs []cnitypes.IPNet
+
+	// IntrospectionAuthToken, when set, is the bearer token that requests to
+	// the introspection server have to present.
+	IntrospectionAuthToken string
+
 	// AWSVPCBlockInstanceMetdata specifies if InstanceMetadata endpoint should be blocked
 	// for tasks that are launched with network mode "awsvpc" when ECS_AWSVPC_BLOCK_IMDS=true
 	AWSVPCBlockInstanceMetdata bool
@@ -283,4 +459,48 @@ type Config struct {
 
 	// TaskMetadataAZDisabled specifies if availability zone should be disabled in Task Metadata endpoint
 	TaskMetadataAZDisabled bool
+
+	// CredentialSourceOrder specifies the order in which the agent looks for
+	// its own AWS credentials. Valid sources are "env", "file" and
+	// "instance-role". If not set, the default SDK credential chain is used.
+	CredentialSourceOrder []string
+
+	// ComplianceFrameworks specifies the compliance frameworks the instance
+	// is certified for. Each framework is reported as an
+	// "ecs.compliance/<framework>" attribute so that placement constraints can
+	// keep workloads within compliance boundaries. Valid frameworks are
+	// "hipaa", "pci" and "fedramp".
+	ComplianceFrameworks []string
+
+	// MaxConcurrentImagePulls limits the number of image pulls the agent runs
+	// at once. A value of 0 places no limit on concurrent pulls.
+	MaxConcurrentImagePulls int
+
+	// ContainerDefaultUlimits are the ulimits applied to every task container
+	// that does not set a ulimit of the same name in its own host config
+	ContainerDefaultUlimits []*units.Ulimit
+
+	// ContainerDefaultPidsLimit is the pids limit applied to every task
+	// container that does not set one in its own host config. A value of 0
+	// applies no default
+	ContainerDefaultPidsLimit int64
+
+	// DisallowedCapabilities are the kernel capabilities, such as "SYS_ADMIN",
+	// task containers may not add. A container adding any of them, adding
+	// "ALL" or running privileged fails to be created
+	DisallowedCapabilities []string
+
+	// UnsafeSysctlsAllowed lets task containers set sysctls that are not
+	// namespaced, and so change the kernel parameters of the host
+	UnsafeSysctlsAllowed bool
+
+	// CNIPluginTimeout bounds each setup and cleanup of a task network
+	// namespace through the CNI plugins. A value of 0 keeps the built-in
+	// timeouts
+	CNIPluginTimeout time.Duration
+
+	// ImagePullRegistryMirrors maps a registry, such as "docker.io", to the
+	// mirrors an image of that registry is pulled from when pulling it from
+	// the registry itself fails
+	ImagePullRegistryMirrors map[string][]string
 }
@@ -16,9 +16,11 @@ package credentials
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
 )
 
 const (
@@ -131,6 +133,16 @@ func (manager *credentialsManager) SetTaskCredentials(taskCredentials *TaskIAMRo
 		return fmt.Errorf("task ARN is empty")
 	}
 
+	// Keep the stored credentials if they outlive the update, which happens
+	// when an older message is delivered again after a newer one. Missing
+	// credentials have no expiration and are always replaced
+	existing := manager.idToTaskCredentials[credentials.CredentialsID].IAMRoleCredentials
+	if expiresBefore(credentials, existing) {
+		seelog.Warnf("Ignoring credentials update that expires at %s before the current credentials, id: %s",
+			credentials.Expiration, credentials.CredentialsID)
+		return nil
+	}
+
 	manager.idToTaskCredentials[credentials.CredentialsID] = TaskIAMRoleCredentials{
 		ARN:                taskCredentials.ARN,
 		IAMRoleCredentials: taskCredentials.GetIAMRoleCredentials(),
@@ -139,6 +151,20 @@ func (manager *credentialsManager) SetTaskCredentials(taskCredentials *TaskIAMRo
 	return nil
 }
 
+// expiresBefore returns true if both expiration times are valid RFC3339
+// timestamps and the first credentials expire before the other ones
+func expiresBefore(credentials IAMRoleCredentials, other IAMRoleCredentials) bool {
+	expiration, err := time.Parse(time.RFC3339, credentials.Expiration)
+	if err != nil {
+		return false
+	}
+	otherExpiration, err := time.Parse(time.RFC3339, other.Expiration)
+	if err != nil {
+		return false
+	}
+	return expiration.Before(otherExpiration)
+}
+
 // GetTaskCredentials retrieves credentials for a given credentials id
 func (manager *credentialsManager) GetTaskCredentials(id string) (TaskIAMRoleCredentials, bool) {
 	manager.taskCredentialsLock.RLock()
@@ -171,3 +171,31 @@ func TestRemoveExistingCredentials(t *testing.T) {
 		t.Error("Expected GetTaskCredentials to return false for removed credentials")
 	}
 }
+
+// TestSetTaskCredentialsKeepsLaterExpiration tests that credentials are not
+// replaced by credentials which expire earlier
+func TestSetTaskCredentialsKeepsLaterExpiration(t *testing.T) {
+	manager := NewManager()
+	newCredentials := func(accessKeyID string, expiration string) *TaskIAMRoleCredentials {
+		return &TaskIAMRoleCredentials{
+			ARN: "t1",
+			IAMRoleCredentials: IAMRoleCredentials{
+				AccessKeyID:   accessKeyID,
+				Expiration:    expiration,
+				CredentialsID: "cid1",
+			},
+		}
+	}
+
+	assert.NoError(t, manager.SetTaskCredentials(newCredentials("akid1", "2018-01-01T06:00:00Z")))
+	assert.NoError(t, manager.SetTaskCredentials(newCredentials("akid2", "2018-01-01T12:00:00Z")))
+	assert.NoError(t, manager.SetTaskCredentials(newCredentials("akid1", "2018-01-01T06:00:00Z")))
+	credentials, ok := manager.GetTaskCredentials("cid1")
+	assert.True(t, ok)
+	assert.Equal(t, "akid2", credentials.IAMRoleCredentials.AccessKeyID)
+
+	// Expiration times that cannot be compared always update the credentials
+	assert.NoError(t, manager.SetTaskCredentials(newCredentials("akid3", "soon")))
+	credentials, _ = manager.GetTaskCredentials("cid1")
+	assert.Equal(t, "akid3", credentials.IAMRoleCredentials.AccessKeyID)
+}
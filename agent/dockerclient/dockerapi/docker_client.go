@@ -45,6 +45,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
 
 	"github.com/cihub/seelog"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	dockercontainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/events"
@@ -118,8 +119,9 @@ type DockerClient interface {
 	// provided for the request.
 	StartContainer(context.Context, string, time.Duration) DockerContainerMetadata
 
-	// StopContainer stops the container identified by the name provided. A timeout value and a context should be provided
-	// for the request.
+	// StopContainer stops the container identified by the name provided. The timeout is the time docker waits for the
+	// container to exit before killing it; the request itself times out dockerclient.StopContainerTimeout after that.
+	// A context should be provided for the request.
 	StopContainer(context.Context, string, time.Duration) DockerContainerMetadata
 
 	// DescribeContainer returns status information about the specified container. A context should be provided
@@ -161,11 +163,19 @@ type DockerClient interface {
 
 	// Stats returns a channel of stat data for the specified container. A context should be provided so the request can
 	// be canceled.
-	Stats(context.Context, string, time.Duration) (<-chan *types.Stats, error)
+	Stats(context.Context, string, time.Duration) (<-chan *types.StatsJSON, error)
 
 	// Version returns the version of the Docker daemon.
 	Version(context.Context, time.Duration) (string, error)
 
+	// Info returns system-wide information about the Docker daemon, such as the
+	// options it was started with.
+	Info(context.Context, time.Duration) (types.Info, error)
+
+	// DiskUsage returns the space used by images, containers and volumes of
+	// the Docker daemon.
+	DiskUsage(context.Context, time.Duration) (types.DiskUsage, error)
+
 	// APIVersion returns the api version of the client
 	APIVersion() (dockerclient.DockerVersion, error)
 
@@ -178,6 +188,18 @@ type DockerClient interface {
 
 	// LoadImage loads an image from an input stream. A timeout value and a context should be provided for the request.
 	LoadImage(context.Context, io.Reader, time.Duration) error
+
+	// CreateContainerExec creates a process to run in a running container and returns the id of the process. A
+	// timeout value and a context should be provided for the request.
+	CreateContainerExec(context.Context, string, types.ExecConfig, time.Duration) (*types.IDResponse, error)
+
+	// StartContainerExec starts the process created by CreateContainerExec detached from the agent. A timeout value
+	// and a context should be provided for the request.
+	StartContainerExec(context.Context, string, time.Duration) error
+
+	// InspectContainerExec returns information about a process created by CreateContainerExec, such as whether it
+	// is still running. A timeout value and a context should be provided for the request.
+	InspectContainerExec(context.Context, string, time.Duration) (*types.ContainerExecInspect, error)
 }
 
 // DockerGoClient wraps the underlying go-dockerclient and docker/docker library.
@@ -295,10 +317,11 @@ func (dg *dockerGoClient) time() ttime.Time {
 }
 
 func (dg *dockerGoClient) PullImage(ctx context.Context, image string,
-	authData *apicontainer.RegistryAuthenticationData, timeout time.Duration) DockerContainerMetadata {
+	authData *apicontainer.RegistryAuthenticationData, timeout time.Duration) (metadata DockerContainerMetadata) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("PULL_IMAGE")()
+	defer func() { metrics.MetricsEngineGlobal.RecordDockerError("PULL_IMAGE", metadata.Error) }()
 	response := make(chan DockerContainerMetadata, 1)
 	go func() {
 		err := retry.RetryNWithBackoffCtx(ctx, dg.imagePullBackoff, maximumPullRetries,
@@ -309,6 +332,9 @@ func (dg *dockerGoClient) PullImage(ctx context.Context, image string,
 				}
 				return err
 			})
+		if err != nil && len(dg.config.ImagePullRegistryMirrors) > 0 && isRetriablePullError(err) {
+			err = dg.pullImageFromMirrors(ctx, image, err)
+		}
 		response <- DockerContainerMetadata{Error: wrapPullErrorAsNamedError(err)}
 	}()
 
@@ -328,6 +354,64 @@ func (dg *dockerGoClient) PullImage(ctx context.Context, image string,
 	}
 }
 
+// pullImageFromMirrors pulls the image from the mirrors configured for its
+// registry after pulling it from the registry failed, and tags it with the
+// name of the image. It returns the error of the last failed pull otherwise
+func (dg *dockerGoClient) pullImageFromMirrors(ctx context.Context, image string, pullErr error) error {
+	for _, mirrorImage := range mirrorImages(image, dg.config.ImagePullRegistryMirrors) {
+		if ctx.Err() != nil {
+			break
+		}
+		seelog.Infof("DockerGoClient: pulling image %s from mirror image %s", image, mirrorImage)
+		err := dg.pullImage(ctx, mirrorImage, nil)
+		if err != nil {
+			seelog.Warnf("DockerGoClient: failed to pull mirror image %s: %s", mirrorImage, err.Error())
+			pullErr = err
+			continue
+		}
+		return dg.tagImage(ctx, mirrorImage, image)
+	}
+	return pullErr
+}
+
+// isRetriablePullError returns true if pulling the image from the registry
+// failed with a transient error. Mirrors are not tried for images the
+// registry refused or doesn't have, so that a mirror can't supply them
+func isRetriablePullError(err error) bool {
+	pullErr, ok := err.(CannotPullContainerError)
+	return ok && pullErr.Retry()
+}
+
+// mirrorImages returns the references of the image in the mirrors of its
+// registry. Images referenced by digest are not mirrored, as the pulled image
+// can't be tagged with a digest reference
+func mirrorImages(image string, registryMirrors map[string][]string) []string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return nil
+	}
+	domain := reference.Domain(named)
+	var images []string
+	for _, mirror := range registryMirrors[domain] {
+		images = append(images, strings.TrimSuffix(mirror, "/")+strings.TrimPrefix(named.String(), domain))
+	}
+	return images
+}
+
+func (dg *dockerGoClient) tagImage(ctx context.Context, source string, target string) apierrors.NamedError {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return CannotGetDockerClientError{version: dg.version, err: err}
+	}
+	if err := client.ImageTag(ctx, getRepository(source), getRepository(target)); err != nil {
+		return CannotPullContainerError{fmt.Errorf("unable to tag mirror image %s: %v", source, err)}
+	}
+	return nil
+}
+
 func wrapPullErrorAsNamedError(err error) apierrors.NamedError {
 	var retErr apierrors.NamedError
 	if err != nil {
@@ -506,10 +590,11 @@ func (dg *dockerGoClient) CreateContainer(ctx context.Context,
 	config *dockercontainer.Config,
 	hostConfig *dockercontainer.HostConfig,
 	name string,
-	timeout time.Duration) DockerContainerMetadata {
+	timeout time.Duration) (metadata DockerContainerMetadata) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("CREATE_CONTAINER")()
+	defer func() { metrics.MetricsEngineGlobal.RecordDockerError("CREATE_CONTAINER", metadata.Error) }()
 	// Buffered channel so in the case of timeout it takes one write, never gets
 	// read, and can still be GC'd
 	response := make(chan DockerContainerMetadata, 1)
@@ -550,10 +635,11 @@ func (dg *dockerGoClient) createContainer(ctx context.Context,
 	return dg.containerMetadata(ctx, dockerContainer.ID)
 }
 
-func (dg *dockerGoClient) StartContainer(ctx context.Context, id string, timeout time.Duration) DockerContainerMetadata {
+func (dg *dockerGoClient) StartContainer(ctx context.Context, id string, timeout time.Duration) (metadata DockerContainerMetadata) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("START_CONTAINER")()
+	defer func() { metrics.MetricsEngineGlobal.RecordDockerError("START_CONTAINER", metadata.Error) }()
 	// Buffered channel so in the case of timeout it takes one write, never gets
 	// read, and can still be GC'd
 	response := make(chan DockerContainerMetadata, 1)
@@ -653,14 +739,17 @@ func (dg *dockerGoClient) inspectContainer(ctx context.Context, dockerID string)
 	return &containerData, err
 }
 
-func (dg *dockerGoClient) StopContainer(ctx context.Context, dockerID string, timeout time.Duration) DockerContainerMetadata {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+func (dg *dockerGoClient) StopContainer(ctx context.Context, dockerID string, timeout time.Duration) (metadata DockerContainerMetadata) {
+	// Give docker time to kill the container once the stop timeout has passed
+	requestTimeout := timeout + dockerclient.StopContainerTimeout
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("STOP_CONTAINER")()
+	defer func() { metrics.MetricsEngineGlobal.RecordDockerError("STOP_CONTAINER", metadata.Error) }()
 	// Buffered channel so in the case of timeout it takes one write, never gets
 	// read, and can still be GC'd
 	response := make(chan DockerContainerMetadata, 1)
-	go func() { response <- dg.stopContainer(ctx, dockerID) }()
+	go func() { response <- dg.stopContainer(ctx, dockerID, timeout) }()
 	select {
 	case resp := <-response:
 		return resp
@@ -669,19 +758,19 @@ func (dg *dockerGoClient) StopContainer(ctx context.Context, dockerID string, ti
 		// send back the DockerTimeoutError
 		err := ctx.Err()
 		if err == context.DeadlineExceeded {
-			return DockerContainerMetadata{Error: &DockerTimeoutError{timeout, "stopped"}}
+			return DockerContainerMetadata{Error: &DockerTimeoutError{requestTimeout, "stopped"}}
 		}
 		return DockerContainerMetadata{Error: CannotStopContainerError{err}}
 	}
 }
 
-func (dg *dockerGoClient) stopContainer(ctx context.Context, dockerID string) DockerContainerMetadata {
+func (dg *dockerGoClient) stopContainer(ctx context.Context, dockerID string, timeout time.Duration) DockerContainerMetadata {
 	client, err := dg.sdkDockerClient()
 	if err != nil {
 		return DockerContainerMetadata{Error: CannotGetDockerClientError{version: dg.version, err: err}}
 	}
 
-	err = client.ContainerStop(ctx, dockerID, &dg.config.DockerStopTimeout)
+	err = client.ContainerStop(ctx, dockerID, &timeout)
 	metadata := dg.containerMetadata(ctx, dockerID)
 	if err != nil {
 		seelog.Infof("DockerGoClient: error stopping container %s: %v", dockerID, err)
@@ -695,10 +784,11 @@ func (dg *dockerGoClient) stopContainer(ctx context.Context, dockerID string) Do
 	return metadata
 }
 
-func (dg *dockerGoClient) RemoveContainer(ctx context.Context, dockerID string, timeout time.Duration) error {
+func (dg *dockerGoClient) RemoveContainer(ctx context.Context, dockerID string, timeout time.Duration) (removeErr error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("REMOVE_CONTAINER")()
+	defer func() { metrics.MetricsEngineGlobal.RecordDockerError("REMOVE_CONTAINER", removeErr) }()
 	// Buffered channel so in the case of timeout it takes one write, never gets
 	// read, and can still be GC'd
 	response := make(chan error, 1)
@@ -1054,6 +1144,71 @@ func (dg *dockerGoClient) Version(ctx context.Context, timeout time.Duration) (s
 	return version, nil
 }
 
+func (dg *dockerGoClient) Info(ctx context.Context, timeout time.Duration) (types.Info, error) {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return types.Info{}, err
+	}
+	return client.Info(derivedCtx)
+}
+
+func (dg *dockerGoClient) DiskUsage(ctx context.Context, timeout time.Duration) (types.DiskUsage, error) {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return types.DiskUsage{}, err
+	}
+	return client.DiskUsage(derivedCtx)
+}
+
+func (dg *dockerGoClient) CreateContainerExec(ctx context.Context, containerID string, execConfig types.ExecConfig,
+	timeout time.Duration) (*types.IDResponse, error) {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	execIDResponse, err := client.ContainerExecCreate(derivedCtx, containerID, execConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &execIDResponse, nil
+}
+
+func (dg *dockerGoClient) StartContainerExec(ctx context.Context, execID string, timeout time.Duration) error {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return err
+	}
+	return client.ContainerExecStart(derivedCtx, execID, types.ExecStartCheck{Detach: true})
+}
+
+func (dg *dockerGoClient) InspectContainerExec(ctx context.Context, execID string,
+	timeout time.Duration) (*types.ContainerExecInspect, error) {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	execInspect, err := client.ContainerExecInspect(derivedCtx, execID)
+	if err != nil {
+		return nil, err
+	}
+	return &execInspect, nil
+}
+
 func (dg *dockerGoClient) getDaemonVersion() string {
 	dg.lock.Lock()
 	defer dg.lock.Unlock()
@@ -1274,8 +1429,8 @@ func (dg *dockerGoClient) APIVersion() (dockerclient.DockerVersion, error) {
 	return dg.sdkClientFactory.FindClientAPIVersion(client), nil
 }
 
-// Stats returns a channel of *types.Stats entries for the container.
-func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeout time.Duration) (<-chan *types.Stats, error) {
+// Stats returns a channel of *types.StatsJSON entries for the container.
+func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeout time.Duration) (<-chan *types.StatsJSON, error) {
 	subCtx, cancelRequest := context.WithCancel(ctx)
 
 	client, err := dg.sdkDockerClient()
@@ -1285,7 +1440,7 @@ func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeou
 	}
 
 	// Create channel to hold the stats
-	statsChnl := make(chan *types.Stats)
+	statsChnl := make(chan *types.StatsJSON)
 	var resp types.ContainerStats
 
 	if !dg.config.PollMetrics {
@@ -1309,7 +1464,7 @@ func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeou
 
 			// Returns a *Decoder and takes in a readCloser
 			decoder := json.NewDecoder(resp.Body)
-			data := new(types.Stats)
+			data := new(types.StatsJSON)
 			for err := decoder.Decode(data); err != io.EOF; err = decoder.Decode(data) {
 				if err != nil {
 					seelog.Warnf("DockerGoClient: Unable to decode stats for container %s: %v", id, err)
@@ -1321,7 +1476,7 @@ func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeou
 				}
 
 				statsChnl <- data
-				data = new(types.Stats)
+				data = new(types.StatsJSON)
 			}
 		}()
 	} else {
@@ -1346,7 +1501,7 @@ func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeou
 
 				// Returns a *Decoder and takes in a readCloser
 				decoder := json.NewDecoder(resp.Body)
-				data := new(types.Stats)
+				data := new(types.StatsJSON)
 				err := decoder.Decode(data)
 				if err != nil {
 					seelog.Warnf("DockerGoClient: Unable to decode stats for container %s: %v", id, err)
@@ -1354,7 +1509,7 @@ func (dg *dockerGoClient) Stats(ctx context.Context, id string, inactivityTimeou
 				}
 
 				statsChnl <- data
-				data = new(types.Stats)
+				data = new(types.StatsJSON)
 			}
 		}()
 	}
@@ -352,6 +352,75 @@ func TestPullImageError(t *testing.T) {
 	assert.Equal(t, "CannotPullContainerError", metadata.Error.(apierrors.NamedError).ErrorName(), "Wrong error type")
 }
 
+func TestPullImageFromRegistryMirror(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.ImagePullRegistryMirrors = map[string][]string{
+		"docker.io": {"mirror1.example.com", "mirror2.example.com/"},
+	}
+	mockDockerSDK, client, testTime, _, _, _ := dockerClientSetupWithConfig(t, conf)
+
+	testTime.EXPECT().After(gomock.Any()).AnyTimes()
+	gomock.InOrder(
+		mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "image:latest", gomock.Any()).Return(
+			nil, errors.New("toomanyrequests: Rate exceeded")).Times(maximumPullRetries),
+		// A mirror that doesn't have the image is skipped
+		mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "mirror1.example.com/library/image:latest", gomock.Any()).Return(
+			nil, errors.New("manifest for mirror1.example.com/library/image:latest not found")),
+		mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "mirror2.example.com/library/image:latest", gomock.Any()).Return(
+			mockReadCloser{reader: strings.NewReader(`{"status":"pull complete"}`)}, nil),
+		mockDockerSDK.EXPECT().ImageTag(gomock.Any(), "mirror2.example.com/library/image:latest", "image:latest").Return(nil),
+	)
+
+	metadata := client.PullImage(context.TODO(), "image", nil, dockerclient.PullImageTimeout)
+	assert.NoError(t, metadata.Error)
+}
+
+func TestPullImageNotFoundSkipsRegistryMirrors(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.ImagePullRegistryMirrors = map[string][]string{
+		"docker.io": {"mirror.example.com"},
+	}
+	mockDockerSDK, client, testTime, _, _, _ := dockerClientSetupWithConfig(t, conf)
+
+	testTime.EXPECT().After(gomock.Any()).AnyTimes()
+	// The registry refused the image, so no mirror is tried
+	mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "image:latest", gomock.Any()).Return(
+		nil, errors.New("pull access denied for image"))
+
+	metadata := client.PullImage(context.TODO(), "image", nil, dockerclient.PullImageTimeout)
+	assert.Error(t, metadata.Error)
+}
+
+func TestMirrorImages(t *testing.T) {
+	mirrors := map[string][]string{
+		"docker.io":            {"mirror.example.com"},
+		"registry.example.com": {"mirror.example.com:5000/registry"},
+	}
+	testCases := []struct {
+		image    string
+		expected []string
+	}{
+		{"image", []string{"mirror.example.com/library/image"}},
+		{"user/image:tag", []string{"mirror.example.com/user/image:tag"}},
+		{"registry.example.com/image:tag", []string{"mirror.example.com:5000/registry/image:tag"}},
+		{"other.example.com/image", nil},
+		{"image@sha256:" + strings.Repeat("a", 64), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mirrorImages(tc.image, mirrors))
+		})
+	}
+}
+
+func TestCannotPullContainerErrorRetry(t *testing.T) {
+	assert.True(t, CannotPullContainerError{errors.New("toomanyrequests: Rate exceeded")}.Retry())
+	assert.True(t, CannotPullContainerError{errors.New("received unexpected HTTP status: 503 Service Unavailable")}.Retry())
+	assert.False(t, CannotPullContainerError{errors.New("pull access denied for image")}.Retry())
+	assert.False(t, CannotPullContainerError{errors.New("manifest for image:tag not found")}.Retry())
+}
+
 type mockReadCloser struct {
 	reader io.Reader
 	delay  time.Duration
@@ -464,19 +533,19 @@ func TestStartContainer(t *testing.T) {
 }
 
 func TestStopContainerTimeout(t *testing.T) {
-	cfg := config.DefaultConfig()
-	cfg.DockerStopTimeout = xContainerShortTimeout
-	mockDockerSDK, client, _, _, _, done := dockerClientSetupWithConfig(t, cfg)
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
 
 	wait := &sync.WaitGroup{}
 	wait.Add(1)
-	mockDockerSDK.EXPECT().ContainerStop(gomock.Any(), "id", &client.config.DockerStopTimeout).Do(func(x, y, z interface{}) {
+	stopTimeout := xContainerShortTimeout
+	mockDockerSDK.EXPECT().ContainerStop(gomock.Any(), "id", &stopTimeout).Do(func(x, y, z interface{}) {
 		wait.Wait()
 		// Don't return, verify timeout happens
 	}).MaxTimes(1).Return(errors.New("test error"))
 	mockDockerSDK.EXPECT().ContainerInspect(gomock.Any(), gomock.Any()).AnyTimes()
-	ctx, cancel := context.WithCancel(context.TODO())
+	// The request deadline is past the stop timeout, so time the context out instead
+	ctx, cancel := context.WithTimeout(context.TODO(), xContainerShortTimeout)
 	defer cancel()
 	metadata := client.StopContainer(ctx, "id", xContainerShortTimeout)
 	assert.Error(t, metadata.Error, "Expected error for pull timeout")
@@ -488,8 +557,9 @@ func TestStopContainer(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
 
+	stopTimeout := 2 * time.Minute
 	gomock.InOrder(
-		mockDockerSDK.EXPECT().ContainerStop(gomock.Any(), "id", &client.config.DockerStopTimeout).Return(nil),
+		mockDockerSDK.EXPECT().ContainerStop(gomock.Any(), "id", &stopTimeout).Return(nil),
 		mockDockerSDK.EXPECT().ContainerInspect(gomock.Any(), "id").
 			Return(
 				types.ContainerJSON{
@@ -505,7 +575,7 @@ func TestStopContainer(t *testing.T) {
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
-	metadata := client.StopContainer(ctx, "id", dockerclient.StopContainerTimeout)
+	metadata := client.StopContainer(ctx, "id", stopTimeout)
 	assert.NoError(t, metadata.Error)
 	assert.Equal(t, "id", metadata.DockerID)
 }
@@ -1048,7 +1118,7 @@ func (ms mockStream) Read(data []byte) (n int, err error) {
 func (ms mockStream) Close() error {
 	return nil
 }
-func waitForStats(t *testing.T, stat *types.Stats) {
+func waitForStats(t *testing.T, stat *types.StatsJSON) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
 	defer cancel()
 	for {
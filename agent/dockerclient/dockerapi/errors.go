@@ -14,6 +14,7 @@
 package dockerapi
 
 import (
+	"strings"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
@@ -136,6 +137,28 @@ func (err CannotPullContainerError) ErrorName() string {
 	return "CannotPullContainerError"
 }
 
+// permanentPullErrors are parts of the errors docker returns when the image
+// does not exist or access to it is denied, which retrying cannot fix
+var permanentPullErrors = []string{
+	"not found",
+	"manifest unknown",
+	"pull access denied",
+	"unauthorized",
+	"invalid reference format",
+}
+
+// Retry returns false when retrying the pull cannot succeed, so that only
+// transient failures such as timeouts, server and DNS errors are retried
+func (err CannotPullContainerError) Retry() bool {
+	msg := strings.ToLower(err.Error())
+	for _, permanentErr := range permanentPullErrors {
+		if strings.Contains(msg, permanentErr) {
+			return false
+		}
+	}
+	return true
+}
+
 // CannotPullECRContainerError indicates any error when trying to pull
 // a container image from ECR
 type CannotPullECRContainerError struct {
@@ -62,4 +62,20 @@ const (
 
 	// VersionTimeout is the timeout for the Version API
 	VersionTimeout = 10 * time.Second
+
+	// InfoTimeout is the timeout for the Info API
+	InfoTimeout = 10 * time.Second
+
+	// DiskUsageTimeout is the timeout for the DiskUsage API. Computing the
+	// size of layers and volumes can take a while on busy instances.
+	DiskUsageTimeout = 2 * time.Minute
+
+	// CreateContainerExecTimeout is the timeout for the ContainerExecCreate API
+	CreateContainerExecTimeout = 1 * time.Minute
+
+	// StartContainerExecTimeout is the timeout for the ContainerExecStart API
+	StartContainerExecTimeout = 3 * time.Minute
+
+	// InspectContainerExecTimeout is the timeout for the ContainerExecInspect API
+	InspectContainerExecTimeout = 30 * time.Second
 )
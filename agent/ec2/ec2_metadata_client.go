@@ -17,27 +17,63 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cihub/seelog"
 )
 
 const (
 	SecurityCrednetialsResource               = "iam/security-credentials/"
 	InstanceIdentityDocumentResource          = "instance-identity/document"
 	InstanceIdentityDocumentSignatureResource = "instance-identity/signature"
+	InstanceIdentityDocumentRSA2048Resource   = "instance-identity/rsa2048"
+	InstanceIdentityDocumentPKCS7Resource     = "instance-identity/pkcs7"
 	MacResource                               = "mac"
 	VPCIDResourceFormat                       = "network/interfaces/macs/%s/vpc-id"
 	SubnetIDResourceFormat                    = "network/interfaces/macs/%s/subnet-id"
 	InstanceIDResource                        = "instance-id"
 	PublicIPv4Resource                        = "public-ipv4"
+	AvailabilityZoneResource                  = "placement/availability-zone"
+	AvailabilityZoneIDResource                = "placement/availability-zone-id"
+	InstanceTypeResource                      = "instance-type"
+	AMIIDResource                             = "ami-id"
+	SpotInstanceActionResource                = "spot/instance-action"
+	RebalanceRecommendationResource           = "events/recommendations/rebalance"
 )
 
+// regionPattern matches the region at the start of an availability zone name.
+// Besides standard zones (us-west-2a), this covers Local Zones (us-west-2-lax-1a)
+// and Wavelength Zones (us-east-1-wl1-bos-wlz-1), whose names don't end with a
+// single letter after the region
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+`)
+
 const (
 	metadataRetries = 5
+
+	// metadataEndpointEnvVar overrides the endpoint of the instance metadata
+	// service
+	metadataEndpointEnvVar = "ECS_EC2_METADATA_ENDPOINT"
+	// metadataTimeoutEnvVar overrides the timeout of the instance metadata
+	// requests
+	metadataTimeoutEnvVar = "ECS_EC2_METADATA_TIMEOUT"
+	// metadataRetriesEnvVar overrides the number of times the instance
+	// metadata requests are retried
+	metadataRetriesEnvVar = "ECS_EC2_METADATA_RETRIES"
+	// metadataDisabledEnvVar disables the instance metadata requests, for
+	// instances that have no instance metadata service
+	metadataDisabledEnvVar = "ECS_DISABLE_EC2_METADATA"
+	// metadataAPIVersionPath is the path of the metadata API version, which
+	// the endpoint of the metadata client includes
+	metadataAPIVersionPath = "/latest"
 )
 
 // RoleCredentials contains the information associated with an IAM role
@@ -78,18 +114,61 @@ type ec2MetadataClientImpl struct {
 	client HttpClient
 }
 
-// NewEC2MetadataClient creates an ec2metadata client to retrieve metadata
+// NewEC2MetadataClient creates an ec2metadata client to retrieve metadata.
+// The default client uses session tokens (IMDSv2), and falls back to
+// session-less requests only when ECS_EC2_METADATA_V1_FALLBACK is true. Its
+// endpoint, timeout and retries may be overridden with the
+// ECS_EC2_METADATA_ENDPOINT, ECS_EC2_METADATA_TIMEOUT and
+// ECS_EC2_METADATA_RETRIES environment variables
 func NewEC2MetadataClient(client HttpClient) EC2MetadataClient {
 	if client == nil {
-		return &ec2MetadataClientImpl{
-			client: ec2metadata.New(
-				session.New(), aws.NewConfig().WithMaxRetries(metadataRetries)),
-		}
+		return &ec2MetadataClientImpl{client: newDefaultMetadataClient()}
 	} else {
 		return &ec2MetadataClientImpl{client: client}
 	}
 }
 
+// newDefaultMetadataClient returns the SDK metadata client configured to send
+// session tokens, with the overrides of the environment
+func newDefaultMetadataClient() *ec2metadata.EC2Metadata {
+	return withMetadataToken(ec2metadata.New(session.New(), metadataConfig()),
+		utils.ParseBool(os.Getenv(metadataV1FallbackEnvVar), false))
+}
+
+// MetadataDisabled returns true if the instance metadata service is not
+// available, as set with the ECS_DISABLE_EC2_METADATA environment variable
+func MetadataDisabled() bool {
+	return utils.ParseBool(os.Getenv(metadataDisabledEnvVar), false)
+}
+
+// metadataConfig returns the configuration of the default metadata client
+func metadataConfig() *aws.Config {
+	cfg := aws.NewConfig().WithMaxRetries(metadataRetries)
+	if endpoint := strings.TrimSuffix(os.Getenv(metadataEndpointEnvVar), "/"); endpoint != "" {
+		if !strings.HasSuffix(endpoint, metadataAPIVersionPath) {
+			endpoint += metadataAPIVersionPath
+		}
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	if timeoutEnvVal := os.Getenv(metadataTimeoutEnvVar); timeoutEnvVal != "" {
+		timeout, err := time.ParseDuration(timeoutEnvVal)
+		if err != nil || timeout <= 0 {
+			seelog.Warnf("Invalid format for \"%s\", expected a positive duration: %s", metadataTimeoutEnvVar, timeoutEnvVal)
+		} else {
+			cfg = cfg.WithHTTPClient(&http.Client{Timeout: timeout})
+		}
+	}
+	if retriesEnvVal := os.Getenv(metadataRetriesEnvVar); retriesEnvVal != "" {
+		retries, err := strconv.Atoi(retriesEnvVal)
+		if err != nil || retries < 0 {
+			seelog.Warnf("Invalid format for \"%s\", expected a non-negative integer: %s", metadataRetriesEnvVar, retriesEnvVal)
+		} else {
+			cfg = cfg.WithMaxRetries(retries)
+		}
+	}
+	return cfg
+}
+
 // DefaultCredentials returns the credentials associated with the instance iam role
 func (c *ec2MetadataClientImpl) DefaultCredentials() (*RoleCredentials, error) {
 	securityCredential, err := c.client.GetMetadata(SecurityCrednetialsResource)
@@ -160,9 +239,24 @@ func (c *ec2MetadataClientImpl) GetUserData() (string, error) {
 	return c.client.GetUserData()
 }
 
-// Region returns the region the instance is running in.
+// Region returns the region the instance is running in. For Local Zones and
+// Wavelength Zones, this is the parent region of the zone.
 func (c *ec2MetadataClientImpl) Region() (string, error) {
-	return c.client.Region()
+	availabilityZone, err := c.client.GetMetadata(AvailabilityZoneResource)
+	if err != nil {
+		return "", err
+	}
+	return RegionFromAvailabilityZone(availabilityZone)
+}
+
+// RegionFromAvailabilityZone returns the region of the availability zone with
+// the given name
+func RegionFromAvailabilityZone(availabilityZone string) (string, error) {
+	region := regionPattern.FindString(availabilityZone)
+	if region == "" {
+		return "", fmt.Errorf("unable to derive region from availability zone %q", availabilityZone)
+	}
+	return region, nil
 }
 
 func (c *ec2MetadataClientImpl) PublicIPv4Address() (string, error) {
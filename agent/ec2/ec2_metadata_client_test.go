@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -181,6 +183,65 @@ func TestSubnetID(t *testing.T) {
 	assert.Equal(t, subnetID, subnetIDResponse)
 }
 
+func TestRegion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mock_ec2.NewMockHttpClient(ctrl)
+	testClient := ec2.NewEC2MetadataClient(mockGetter)
+
+	mockGetter.EXPECT().GetMetadata(ec2.AvailabilityZoneResource).Return("us-west-2-lax-1a", nil)
+	region, err := testClient.Region()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", region)
+}
+
+func TestRegionError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mock_ec2.NewMockHttpClient(ctrl)
+	testClient := ec2.NewEC2MetadataClient(mockGetter)
+
+	mockGetter.EXPECT().GetMetadata(ec2.AvailabilityZoneResource).Return("", errors.New("error"))
+	_, err := testClient.Region()
+	assert.Error(t, err)
+}
+
+func TestRegionFromAvailabilityZone(t *testing.T) {
+	testCases := []struct {
+		availabilityZone string
+		region           string
+	}{
+		{"us-west-2a", "us-west-2"},
+		{"eu-central-1c", "eu-central-1"},
+		{"ap-southeast-2b", "ap-southeast-2"},
+		{"us-gov-west-1a", "us-gov-west-1"},
+		{"cn-northwest-1a", "cn-northwest-1"},
+		// Local Zones
+		{"us-west-2-lax-1a", "us-west-2"},
+		{"us-east-1-bos-1a", "us-east-1"},
+		// Wavelength Zones
+		{"us-east-1-wl1-bos-wlz-1", "us-east-1"},
+		{"ap-northeast-1-wl1-nrt-wlz-1", "ap-northeast-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.availabilityZone, func(t *testing.T) {
+			region, err := ec2.RegionFromAvailabilityZone(tc.availabilityZone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.region, region)
+		})
+	}
+}
+
+func TestRegionFromInvalidAvailabilityZone(t *testing.T) {
+	for _, availabilityZone := range []string{"", "invalid", "us-west"} {
+		_, err := ec2.RegionFromAvailabilityZone(availabilityZone)
+		assert.Error(t, err, "Expected an error for availability zone %q", availabilityZone)
+	}
+}
+
 func TestPublicIPv4Address(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -194,3 +255,37 @@ func TestPublicIPv4Address(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, publicIP, publicIPResponse)
 }
+
+func TestEC2MetadataClientEndpointOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			fmt.Fprint(w, "token")
+		case "/latest/meta-data/instance-id":
+			assert.Equal(t, "token", r.Header.Get("X-aws-ec2-metadata-token"))
+			fmt.Fprint(w, "i-123")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("ECS_EC2_METADATA_ENDPOINT", server.URL)
+	defer os.Unsetenv("ECS_EC2_METADATA_ENDPOINT")
+	os.Setenv("ECS_EC2_METADATA_TIMEOUT", "1s")
+	defer os.Unsetenv("ECS_EC2_METADATA_TIMEOUT")
+	os.Setenv("ECS_EC2_METADATA_RETRIES", "0")
+	defer os.Unsetenv("ECS_EC2_METADATA_RETRIES")
+
+	instanceID, err := ec2.NewEC2MetadataClient(nil).InstanceID()
+	assert.NoError(t, err)
+	assert.Equal(t, "i-123", instanceID)
+}
+
+func TestMetadataDisabled(t *testing.T) {
+	assert.False(t, ec2.MetadataDisabled())
+
+	os.Setenv("ECS_DISABLE_EC2_METADATA", "true")
+	defer os.Unsetenv("ECS_DISABLE_EC2_METADATA")
+	assert.True(t, ec2.MetadataDisabled())
+}
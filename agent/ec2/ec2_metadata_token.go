@@ -0,0 +1,149 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// metadataTokenResource is the path of the instance metadata session
+	// token, relative to the metadata endpoint
+	metadataTokenResource = "/api/token"
+	// metadataTokenHeader is the header used to send the session token
+	metadataTokenHeader = "X-aws-ec2-metadata-token"
+	// metadataTokenTTLHeader is the header used to request the lifetime of
+	// the session token
+	metadataTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	// metadataTokenTTL is the lifetime requested for the session tokens
+	metadataTokenTTL = 6 * time.Hour
+	// metadataTokenRefreshMargin is how long before it expires the session
+	// token is refreshed
+	metadataTokenRefreshMargin = time.Minute
+	// metadataTokenHandlerName is the name of the request handler that adds
+	// the session token to the metadata requests
+	metadataTokenHandlerName = "ecsagent.ec2metadata.Token"
+	// metadataV1FallbackEnvVar is the environment variable that allows the
+	// client to fall back to session-less requests when no session token can
+	// be fetched
+	metadataV1FallbackEnvVar = "ECS_EC2_METADATA_V1_FALLBACK"
+)
+
+// metadataTokenProvider fetches and caches the session tokens used to call
+// the instance metadata service (IMDSv2)
+type metadataTokenProvider struct {
+	client *ec2metadata.EC2Metadata
+	// fallbackToV1 allows requests without a session token when fetching one
+	// fails
+	fallbackToV1 bool
+	token        string
+	expiresAt    time.Time
+	lock         sync.Mutex
+}
+
+// withMetadataToken configures the metadata client to send a session token
+// with every request, refreshing it before it expires or when it is rejected
+func withMetadataToken(client *ec2metadata.EC2Metadata, fallbackToV1 bool) *ec2metadata.EC2Metadata {
+	provider := &metadataTokenProvider{
+		client:       client,
+		fallbackToV1: fallbackToV1,
+	}
+	client.Handlers.Sign.PushBackNamed(request.NamedHandler{
+		Name: metadataTokenHandlerName,
+		Fn:   provider.addToken,
+	})
+	client.Handlers.UnmarshalError.PushBack(provider.handleUnauthorized)
+	return client
+}
+
+// addToken adds the session token to the request
+func (provider *metadataTokenProvider) addToken(r *request.Request) {
+	token, err := provider.getToken()
+	if err != nil {
+		if provider.fallbackToV1 {
+			seelog.Warnf("Unable to get an instance metadata session token, falling back to IMDSv1: %v", err)
+			return
+		}
+		r.Error = err
+		return
+	}
+	r.HTTPRequest.Header.Set(metadataTokenHeader, token)
+}
+
+// handleUnauthorized discards the session token when it is rejected, so that
+// the request is retried with a new one
+func (provider *metadataTokenProvider) handleUnauthorized(r *request.Request) {
+	if r.HTTPResponse == nil || r.HTTPResponse.StatusCode != http.StatusUnauthorized {
+		return
+	}
+	provider.lock.Lock()
+	provider.token = ""
+	provider.lock.Unlock()
+	r.Retryable = aws.Bool(true)
+}
+
+// getToken returns the cached session token, fetching a new one when it is
+// about to expire
+func (provider *metadataTokenProvider) getToken() (string, error) {
+	provider.lock.Lock()
+	defer provider.lock.Unlock()
+
+	if provider.token != "" && time.Now().Add(metadataTokenRefreshMargin).Before(provider.expiresAt) {
+		return provider.token, nil
+	}
+	token, err := provider.fetchToken()
+	if err != nil {
+		return "", err
+	}
+	provider.token = token
+	provider.expiresAt = time.Now().Add(metadataTokenTTL)
+	return token, nil
+}
+
+// fetchToken requests a new session token from the instance metadata service
+func (provider *metadataTokenProvider) fetchToken() (string, error) {
+	var token string
+	r := provider.client.NewRequest(&request.Operation{
+		Name:       "GetToken",
+		HTTPMethod: http.MethodPut,
+		HTTPPath:   metadataTokenResource,
+	}, nil, nil)
+	r.HTTPRequest.Header.Set(metadataTokenTTLHeader, strconv.Itoa(int(metadataTokenTTL/time.Second)))
+	// The token request is the only one sent without a token
+	r.Handlers.Sign.RemoveByName(metadataTokenHandlerName)
+	r.Handlers.Unmarshal.Clear()
+	r.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		defer r.HTTPResponse.Body.Close()
+		body, err := ioutil.ReadAll(r.HTTPResponse.Body)
+		if err != nil {
+			r.Error = awserr.New("SerializationError", "unable to read the instance metadata session token", err)
+			return
+		}
+		token = string(body)
+	})
+	if err := r.Send(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -0,0 +1,104 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestMetadataServer starts an instance metadata service that requires
+// session tokens when tokenStatus is http.StatusOK, and rejects token
+// requests with tokenStatus otherwise
+func newTestMetadataServer(t *testing.T, tokenStatus int, tokenRequests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, strconv.Itoa(int(metadataTokenTTL.Seconds())), r.Header.Get(metadataTokenTTLHeader))
+			requests := atomic.AddInt32(tokenRequests, 1)
+			if tokenStatus != http.StatusOK {
+				w.WriteHeader(tokenStatus)
+				return
+			}
+			fmt.Fprintf(w, "token-%d", requests)
+			return
+		}
+		if tokenStatus == http.StatusOK &&
+			r.Header.Get(metadataTokenHeader) != fmt.Sprintf("token-%d", atomic.LoadInt32(tokenRequests)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "i-123")
+	}))
+}
+
+func newTestMetadataClient(server *httptest.Server, fallbackToV1 bool) *ec2metadata.EC2Metadata {
+	return withMetadataToken(ec2metadata.New(session.New(), aws.NewConfig().
+		WithEndpoint(server.URL+"/latest").WithMaxRetries(1)), fallbackToV1)
+}
+
+func TestMetadataTokenIsSentAndCached(t *testing.T) {
+	var tokenRequests int32
+	server := newTestMetadataServer(t, http.StatusOK, &tokenRequests)
+	defer server.Close()
+
+	client := newTestMetadataClient(server, false)
+	for i := 0; i < 2; i++ {
+		instanceID, err := client.GetMetadata(InstanceIDResource)
+		assert.NoError(t, err)
+		assert.Equal(t, "i-123", instanceID)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&tokenRequests))
+}
+
+func TestMetadataTokenIsRefreshedWhenRejected(t *testing.T) {
+	var tokenRequests int32
+	server := newTestMetadataServer(t, http.StatusOK, &tokenRequests)
+	defer server.Close()
+
+	client := newTestMetadataClient(server, false)
+	_, err := client.GetMetadata(InstanceIDResource)
+	assert.NoError(t, err)
+
+	// Another token invalidates the cached one
+	atomic.AddInt32(&tokenRequests, 1)
+	instanceID, err := client.GetMetadata(InstanceIDResource)
+	assert.NoError(t, err)
+	assert.Equal(t, "i-123", instanceID)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&tokenRequests))
+}
+
+func TestMetadataTokenUnavailable(t *testing.T) {
+	var tokenRequests int32
+	server := newTestMetadataServer(t, http.StatusForbidden, &tokenRequests)
+	defer server.Close()
+
+	_, err := newTestMetadataClient(server, false).GetMetadata(InstanceIDResource)
+	assert.Error(t, err, "expected no fallback to IMDSv1 unless configured")
+
+	instanceID, err := newTestMetadataClient(server, true).GetMetadata(InstanceIDResource)
+	assert.NoError(t, err)
+	assert.Equal(t, "i-123", instanceID)
+}
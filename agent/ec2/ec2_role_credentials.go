@@ -0,0 +1,36 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+)
+
+// roleCredentialsExpiryWindow is how long before they expire the instance role
+// credentials are refreshed, the same as for the SDK's default provider
+const roleCredentialsExpiryWindow = 5 * time.Minute
+
+// NewRoleCredentialsProvider returns a provider of the instance IAM role
+// credentials. Unlike the SDK's default EC2 role provider, it fetches them
+// with session tokens (IMDSv2), so that it works on instances that require
+// them
+func NewRoleCredentialsProvider() credentials.Provider {
+	return &ec2rolecreds.EC2RoleProvider{
+		Client:       newDefaultMetadataClient(),
+		ExpiryWindow: roleCredentialsExpiryWindow,
+	}
+}
@@ -0,0 +1,67 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ec2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoleCredentials = `{
+  "Code": "Success",
+  "Type": "AWS-HMAC",
+  "AccessKeyId": "roleAccessKey",
+  "SecretAccessKey": "roleSecretKey",
+  "Token": "roleToken",
+  "Expiration": "2100-01-01T00:00:00Z"
+}`
+
+func TestRoleCredentialsProviderSendsMetadataToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			fmt.Fprint(w, "token")
+			return
+		}
+		if r.Header.Get(metadataTokenHeader) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/" + SecurityCrednetialsResource:
+			fmt.Fprint(w, "role")
+		case "/latest/meta-data/" + SecurityCrednetialsResource + "role":
+			fmt.Fprint(w, testRoleCredentials)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv(metadataEndpointEnvVar, server.URL)
+	defer os.Unsetenv(metadataEndpointEnvVar)
+
+	creds, err := NewRoleCredentialsProvider().Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "roleAccessKey", creds.AccessKeyID)
+	assert.Equal(t, "roleSecretKey", creds.SecretAccessKey)
+	assert.Equal(t, "roleToken", creds.SessionToken)
+}
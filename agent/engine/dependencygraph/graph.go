@@ -41,6 +41,16 @@ var (
 	// ErrResourceDependencyNotResolved is when the container's dependencies
 	// on task resources are not resolved
 	ErrResourceDependencyNotResolved = errors.New("dependency graph: dependency on resources not resolved")
+	// ErrContainerOrderingNotResolved is when a container waits for another
+	// container of the task to meet the condition it depends on
+	ErrContainerOrderingNotResolved = errors.New("dependency graph: container ordering dependency not resolved")
+	// ErrContainerOrderingNotResolvable is when a container depends on a
+	// condition that another container of the task can no longer meet, such
+	// as a successful exit of a container that failed
+	ErrContainerOrderingNotResolvable = errors.New("dependency graph: container ordering dependency cannot be resolved")
+	// ErrShutdownOrderNotResolved is when a container waits for the
+	// containers of the task that depend on it to stop before it is stopped
+	ErrShutdownOrderNotResolved = errors.New("dependency graph: containers depending on the container have not stopped")
 )
 
 // Because a container may depend on another container being created
@@ -97,7 +107,8 @@ func dependenciesCanBeResolved(target *apicontainer.Container, by []*apicontaine
 
 	return verifyStatusResolvable(target, nameMap, neededVolumeContainers, volumeCanResolve) &&
 		verifyStatusResolvable(target, nameMap, linksToContainerNames(target.Links), linkCanResolve) &&
-		verifyStatusResolvable(target, nameMap, target.SteadyStateDependencies, onSteadyStateCanResolve)
+		verifyStatusResolvable(target, nameMap, target.SteadyStateDependencies, onSteadyStateCanResolve) &&
+		verifyContainerOrderingCanResolve(target, nameMap)
 }
 
 // DependenciesAreResolved validates that the `target` container can be
@@ -141,9 +152,15 @@ func DependenciesAreResolved(target *apicontainer.Container,
 	if !verifyStatusResolvable(target, nameMap, target.SteadyStateDependencies, onSteadyStateIsResolved) {
 		return DependentContainerNotResolvedErr
 	}
+	if err := verifyContainerOrderingResolved(target, nameMap); err != nil {
+		return err
+	}
 	if err := verifyTransitionDependenciesResolved(target, nameMap, resourcesMap); err != nil {
 		return err
 	}
+	if err := verifyShutdownOrderResolved(target, nameMap); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -236,6 +253,115 @@ func verifyResourceDependenciesResolved(target *apicontainer.Container, existing
 	return true
 }
 
+// verifyContainerOrderingCanResolve validates that the conditions `target`
+// depends on can be met by `existingContainers`, which have already been
+// resolved. Only containers with a docker health check can be depended on to
+// be healthy.
+func verifyContainerOrderingCanResolve(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container) bool {
+	for _, dependsOn := range target.DependsOn {
+		dependency, exists := existingContainers[dependsOn.ContainerName]
+		if !exists {
+			return false
+		}
+		switch dependsOn.Condition {
+		case apicontainer.DependsOnConditionStart, apicontainer.DependsOnConditionComplete, apicontainer.DependsOnConditionSuccess:
+		case apicontainer.DependsOnConditionHealthy:
+			if !dependency.HealthStatusShouldBeReported() {
+				log.Errorf("Container [%s] depends on container [%s] to be healthy, which has no health check",
+					target.Name, dependency.Name)
+				return false
+			}
+		default:
+			log.Errorf("Container [%s] depends on container [%s] with an unsupported condition: %s",
+				target.Name, dependency.Name, dependsOn.Condition)
+			return false
+		}
+	}
+	return true
+}
+
+// verifyContainerOrderingResolved validates that the containers `target`
+// depends on meet their conditions before `target` is created and started.
+// Pulling the image of `target` is not held back.
+func verifyContainerOrderingResolved(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container) error {
+	if target.DesiredTerminal() || target.GetNextKnownStateProgression() < apicontainerstatus.ContainerCreated {
+		return nil
+	}
+	for _, dependsOn := range target.DependsOn {
+		dependency, exists := existingContainers[dependsOn.ContainerName]
+		if !exists {
+			return ErrContainerOrderingNotResolvable
+		}
+		if containerOrderingIsResolved(dependency, dependsOn.Condition) {
+			continue
+		}
+		if dependency.GetKnownStatus() == apicontainerstatus.ContainerStopped {
+			// The condition was not met by the time the dependency stopped
+			return ErrContainerOrderingNotResolvable
+		}
+		return ErrContainerOrderingNotResolved
+	}
+	return nil
+}
+
+// containerOrderingIsResolved returns true if `dependency` meets `condition`
+func containerOrderingIsResolved(dependency *apicontainer.Container, condition string) bool {
+	knownStatus := dependency.GetKnownStatus()
+	switch condition {
+	case apicontainer.DependsOnConditionStart:
+		// A stopped dependency has started only if it has a start time
+		return knownStatus >= apicontainerstatus.ContainerRunning &&
+			(knownStatus != apicontainerstatus.ContainerStopped || !dependency.GetStartedAt().IsZero())
+	case apicontainer.DependsOnConditionComplete:
+		return knownStatus == apicontainerstatus.ContainerStopped
+	case apicontainer.DependsOnConditionSuccess:
+		exitCode := dependency.GetKnownExitCode()
+		return knownStatus == apicontainerstatus.ContainerStopped && exitCode != nil && *exitCode == 0
+	case apicontainer.DependsOnConditionHealthy:
+		return knownStatus < apicontainerstatus.ContainerStopped &&
+			dependency.GetHealthStatus().Status == apicontainerstatus.ContainerHealthy
+	}
+	return false
+}
+
+// verifyShutdownOrderResolved validates that the containers depending on
+// `target`, through container ordering or links, have exited before the
+// running `target` is stopped, so that a task is stopped in the reverse order
+// of its dependencies.
+func verifyShutdownOrderResolved(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container) error {
+	if !target.DesiredTerminal() || !target.IsRunning() {
+		return nil
+	}
+	for _, dependent := range existingContainers {
+		if dependent == target || !containerDependsOn(dependent, target.Name) {
+			continue
+		}
+		if dependent.GetKnownStatus() >= apicontainerstatus.ContainerStopped {
+			continue
+		}
+		if dependent.IsRunning() || dependent.GetAppliedStatus() == apicontainerstatus.ContainerRunning {
+			return ErrShutdownOrderNotResolved
+		}
+	}
+	return nil
+}
+
+// containerDependsOn returns true if the container depends on the named
+// container to start, or links to it
+func containerDependsOn(container *apicontainer.Container, name string) bool {
+	for _, dependsOn := range container.DependsOn {
+		if dependsOn.ContainerName == name {
+			return true
+		}
+	}
+	for _, link := range linksToContainerNames(container.Links) {
+		if link == name {
+			return true
+		}
+	}
+	return false
+}
+
 func linkCanResolve(target *apicontainer.Container, link *apicontainer.Container) bool {
 	targetDesiredStatus := target.GetDesiredStatus()
 	linkDesiredStatus := link.GetDesiredStatus()
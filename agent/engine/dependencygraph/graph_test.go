@@ -18,6 +18,7 @@ package dependencygraph
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
@@ -114,6 +115,162 @@ func TestValidDependenciesWithUnresolvedReference(t *testing.T) {
 	assert.False(t, resolveable, "Nonexistent reference shouldn't resolve")
 }
 
+func dependsOnContainer(name string, dependsOn ...apicontainer.DependsOn) *apicontainer.Container {
+	container := steadyStateContainer(name, []string{}, []string{}, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	container.DependsOn = dependsOn
+	return container
+}
+
+func TestValidDependenciesWithContainerOrdering(t *testing.T) {
+	healthChecked := dependsOnContainer("sidecar")
+	healthChecked.HealthCheckType = apicontainer.DockerHealthCheckType
+
+	testCases := []struct {
+		name       string
+		containers []*apicontainer.Container
+		valid      bool
+	}{
+		{
+			name: "all conditions",
+			containers: []*apicontainer.Container{
+				dependsOnContainer("app",
+					apicontainer.DependsOn{ContainerName: "init", Condition: apicontainer.DependsOnConditionSuccess},
+					apicontainer.DependsOn{ContainerName: "sidecar", Condition: apicontainer.DependsOnConditionHealthy},
+					apicontainer.DependsOn{ContainerName: "log", Condition: apicontainer.DependsOnConditionStart},
+					apicontainer.DependsOn{ContainerName: "migrate", Condition: apicontainer.DependsOnConditionComplete}),
+				dependsOnContainer("init"),
+				healthChecked,
+				dependsOnContainer("log"),
+				dependsOnContainer("migrate"),
+			},
+			valid: true,
+		},
+		{
+			name: "healthy without health check",
+			containers: []*apicontainer.Container{
+				dependsOnContainer("app", apicontainer.DependsOn{ContainerName: "init", Condition: apicontainer.DependsOnConditionHealthy}),
+				dependsOnContainer("init"),
+			},
+			valid: false,
+		},
+		{
+			name: "unsupported condition",
+			containers: []*apicontainer.Container{
+				dependsOnContainer("app", apicontainer.DependsOn{ContainerName: "init", Condition: "READY"}),
+				dependsOnContainer("init"),
+			},
+			valid: false,
+		},
+		{
+			name: "nonexistent container",
+			containers: []*apicontainer.Container{
+				dependsOnContainer("app", apicontainer.DependsOn{ContainerName: "init", Condition: apicontainer.DependsOnConditionStart}),
+			},
+			valid: false,
+		},
+		{
+			name: "cycle",
+			containers: []*apicontainer.Container{
+				dependsOnContainer("a", apicontainer.DependsOn{ContainerName: "b", Condition: apicontainer.DependsOnConditionStart}),
+				dependsOnContainer("b", apicontainer.DependsOn{ContainerName: "a", Condition: apicontainer.DependsOnConditionStart}),
+			},
+			valid: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, ValidDependencies(&apitask.Task{Containers: tc.containers}))
+		})
+	}
+}
+
+func TestContainerOrderingIsResolved(t *testing.T) {
+	zero, one := 0, 1
+	testCases := []struct {
+		name        string
+		condition   string
+		knownStatus apicontainerstatus.ContainerStatus
+		exitCode    *int
+		startedAt   time.Time
+		health      apicontainerstatus.ContainerHealthStatus
+		resolved    bool
+	}{
+		{"start waits for running", apicontainer.DependsOnConditionStart, apicontainerstatus.ContainerCreated, nil, time.Time{}, apicontainerstatus.ContainerHealthUnknown, false},
+		{"start is met when running", apicontainer.DependsOnConditionStart, apicontainerstatus.ContainerRunning, nil, time.Now(), apicontainerstatus.ContainerHealthUnknown, true},
+		{"start is met when stopped after running", apicontainer.DependsOnConditionStart, apicontainerstatus.ContainerStopped, &one, time.Now(), apicontainerstatus.ContainerHealthUnknown, true},
+		{"start is not met when stopped before running", apicontainer.DependsOnConditionStart, apicontainerstatus.ContainerStopped, nil, time.Time{}, apicontainerstatus.ContainerHealthUnknown, false},
+		{"complete waits for exit", apicontainer.DependsOnConditionComplete, apicontainerstatus.ContainerRunning, nil, time.Now(), apicontainerstatus.ContainerHealthUnknown, false},
+		{"complete is met on failure", apicontainer.DependsOnConditionComplete, apicontainerstatus.ContainerStopped, &one, time.Now(), apicontainerstatus.ContainerHealthUnknown, true},
+		{"success is met on zero exit code", apicontainer.DependsOnConditionSuccess, apicontainerstatus.ContainerStopped, &zero, time.Now(), apicontainerstatus.ContainerHealthUnknown, true},
+		{"success is not met on failure", apicontainer.DependsOnConditionSuccess, apicontainerstatus.ContainerStopped, &one, time.Now(), apicontainerstatus.ContainerHealthUnknown, false},
+		{"healthy waits for health check", apicontainer.DependsOnConditionHealthy, apicontainerstatus.ContainerRunning, nil, time.Now(), apicontainerstatus.ContainerHealthUnknown, false},
+		{"healthy is met", apicontainer.DependsOnConditionHealthy, apicontainerstatus.ContainerRunning, nil, time.Now(), apicontainerstatus.ContainerHealthy, true},
+		{"healthy is not met when stopped", apicontainer.DependsOnConditionHealthy, apicontainerstatus.ContainerStopped, &zero, time.Now(), apicontainerstatus.ContainerHealthy, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependency := &apicontainer.Container{
+				Name:                "dependency",
+				KnownStatusUnsafe:   tc.knownStatus,
+				KnownExitCodeUnsafe: tc.exitCode,
+			}
+			dependency.SetStartedAt(tc.startedAt)
+			dependency.SetHealthStatus(apicontainer.HealthStatus{Status: tc.health})
+			assert.Equal(t, tc.resolved, containerOrderingIsResolved(dependency, tc.condition))
+		})
+	}
+}
+
+func TestVerifyContainerOrderingResolved(t *testing.T) {
+	one := 1
+	app := dependsOnContainer("app", apicontainer.DependsOn{ContainerName: "init", Condition: apicontainer.DependsOnConditionSuccess})
+	init := dependsOnContainer("init")
+	containers := map[string]*apicontainer.Container{"app": app, "init": init}
+
+	// Pulling the image is not held back
+	assert.NoError(t, verifyContainerOrderingResolved(app, containers))
+
+	app.SetKnownStatus(apicontainerstatus.ContainerPulled)
+	init.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	assert.Equal(t, ErrContainerOrderingNotResolved, verifyContainerOrderingResolved(app, containers))
+
+	init.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	init.SetKnownExitCode(&one)
+	assert.Equal(t, ErrContainerOrderingNotResolvable, verifyContainerOrderingResolved(app, containers))
+
+	// Stopping is never held back
+	app.SetDesiredStatus(apicontainerstatus.ContainerStopped)
+	assert.NoError(t, verifyContainerOrderingResolved(app, containers))
+}
+
+func TestVerifyShutdownOrderResolved(t *testing.T) {
+	sidecar := dependsOnContainer("sidecar")
+	app := dependsOnContainer("app", apicontainer.DependsOn{ContainerName: "sidecar", Condition: apicontainer.DependsOnConditionStart})
+	linked := dependsOnContainer("linked")
+	linked.Links = []string{"sidecar:alias"}
+	containers := map[string]*apicontainer.Container{"sidecar": sidecar, "app": app, "linked": linked}
+	for _, container := range containers {
+		container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+		container.SetDesiredStatus(apicontainerstatus.ContainerStopped)
+	}
+
+	// Containers nothing depends on are stopped first
+	assert.NoError(t, verifyShutdownOrderResolved(app, containers))
+	assert.Equal(t, ErrShutdownOrderNotResolved, verifyShutdownOrderResolved(sidecar, containers))
+
+	app.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	assert.Equal(t, ErrShutdownOrderNotResolved, verifyShutdownOrderResolved(sidecar, containers))
+
+	// A container that was never started is not waited for
+	linked.SetKnownStatus(apicontainerstatus.ContainerCreated)
+	assert.NoError(t, verifyShutdownOrderResolved(sidecar, containers))
+
+	// Running containers are never held back
+	sidecar.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+	app.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	assert.NoError(t, verifyShutdownOrderResolved(sidecar, containers))
+}
+
 func TestDependenciesAreResolvedWhenSteadyStateIsRunning(t *testing.T) {
 	task := &apitask.Task{
 		Containers: []*apicontainer.Container{
@@ -120,9 +120,10 @@ func (imageManager *dockerImageManager) RecordContainerReference(container *apic
 	}
 
 	// Inspect image for obtaining Container's Image ID
-	imageInspected, err := imageManager.client.InspectImage(container.Image)
+	imageName := containerImageReference(container)
+	imageInspected, err := imageManager.client.InspectImage(imageName)
 	if err != nil {
-		seelog.Errorf("Error inspecting image %v: %v", container.Image, err)
+		seelog.Errorf("Error inspecting image %v: %v", imageName, err)
 		return err
 	}
 
@@ -131,6 +132,11 @@ func (imageManager *dockerImageManager) RecordContainerReference(container *apic
 	if !added {
 		imageManager.addContainerReferenceToNewImageState(container, imageInspected.Size)
 	}
+	// The digest is pinned once the container is tracked by the name it was
+	// pulled by, later containers of the task are tracked by the digest
+	if container.GetImageDigest() == "" {
+		container.SetImageDigest(imageRepoDigest(container.Image, imageInspected.RepoDigests))
+	}
 	return nil
 }
 
@@ -138,10 +144,12 @@ func (imageManager *dockerImageManager) addContainerReferenceToExistingImageStat
 	// this lock is used for reading the image states in the image manager
 	imageManager.updateLock.RLock()
 	defer imageManager.updateLock.RUnlock()
-	imageManager.removeExistingImageNameOfDifferentID(container.Image, container.ImageID)
+	imageName := containerImageReference(container)
+	imageManager.removeExistingImageNameOfDifferentID(imageName, container.ImageID)
 	imageState, ok := imageManager.getImageState(container.ImageID)
 	if ok {
-		imageState.UpdateImageState(container)
+		imageState.AddImageName(imageName)
+		imageState.UpdateContainerReference(container)
 	}
 	return ok
 }
@@ -150,11 +158,13 @@ func (imageManager *dockerImageManager) addContainerReferenceToNewImageState(con
 	// this lock is used while creating and adding new image state to image manager
 	imageManager.updateLock.Lock()
 	defer imageManager.updateLock.Unlock()
-	imageManager.removeExistingImageNameOfDifferentID(container.Image, container.ImageID)
+	imageName := containerImageReference(container)
+	imageManager.removeExistingImageNameOfDifferentID(imageName, container.ImageID)
 	// check to see if a different thread added image state for same image ID
 	imageState, ok := imageManager.getImageState(container.ImageID)
 	if ok {
-		imageState.UpdateImageState(container)
+		imageState.AddImageName(imageName)
+		imageState.UpdateContainerReference(container)
 	} else {
 		sourceImage := &image.Image{
 			ImageID: container.ImageID,
@@ -165,7 +175,8 @@ func (imageManager *dockerImageManager) addContainerReferenceToNewImageState(con
 			PulledAt:   time.Now(),
 			LastUsedAt: time.Now(),
 		}
-		sourceImageState.UpdateImageState(container)
+		sourceImageState.AddImageName(imageName)
+		sourceImageState.UpdateContainerReference(container)
 		imageManager.addImageState(sourceImageState)
 	}
 }
@@ -139,6 +139,57 @@ func TestAddAndRemoveContainerToImageStateReferenceHappyPath(t *testing.T) {
 	}
 }
 
+func TestRecordContainerReferenceImageDigest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := NewImageManager(defaultTestConfig(), client, dockerstate.NewTaskEngineState())
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+
+	container := &apicontainer.Container{
+		Name:  "testContainer",
+		Image: "myregistry.io/app:latest",
+	}
+	imageDigest := "sha256:f8fd3a9bd47d0ae2b4d7bae00ba0c7d1ec1e1dca4bd15e1b34a8e67e1c2c4b4d"
+	imageInspected := &types.ImageInspect{
+		ID: "sha256:qwerty",
+		RepoDigests: []string{
+			"mirror.io/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			"myregistry.io/app@" + imageDigest,
+		},
+	}
+	client.EXPECT().InspectImage(container.Image).Return(imageInspected, nil)
+	require.NoError(t, imageManager.RecordContainerReference(container))
+	assert.Equal(t, imageDigest, container.GetImageDigest())
+}
+
+func TestRecordContainerReferencePinnedImageDigest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := NewImageManager(defaultTestConfig(), client, dockerstate.NewTaskEngineState())
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+
+	container := &apicontainer.Container{
+		Name:  "testContainer",
+		Image: "myregistry.io/app:latest",
+	}
+	imageDigest := "sha256:f8fd3a9bd47d0ae2b4d7bae00ba0c7d1ec1e1dca4bd15e1b34a8e67e1c2c4b4d"
+	digestReference := "myregistry.io/app@" + imageDigest
+	container.SetImageDigest(imageDigest)
+
+	// The container is tracked against the image of its pinned digest,
+	// not the image its tag points to now
+	client.EXPECT().InspectImage(digestReference).Return(&types.ImageInspect{ID: "sha256:pinned"}, nil)
+	require.NoError(t, imageManager.RecordContainerReference(container))
+	assert.Equal(t, "sha256:pinned", container.ImageID)
+	imageState, ok := imageManager.GetImageStateFromImageName(digestReference)
+	require.True(t, ok)
+	assert.Equal(t, "sha256:pinned", imageState.Image.ImageID)
+}
+
 func TestRecordContainerReferenceInspectError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
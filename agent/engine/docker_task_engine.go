@@ -18,6 +18,7 @@ import (
 	"context"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,11 +36,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	utilsync "github.com/aws/amazon-ecs-agent/agent/utils/sync"
@@ -47,6 +50,7 @@ import (
 
 	"github.com/cihub/seelog"
 	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
 	"github.com/pkg/errors"
 )
 
@@ -67,8 +71,39 @@ const (
 	maxEngineConnectRetryDelay         = 2 * time.Second
 	engineConnectRetryJitterMultiplier = 0.20
 	engineConnectRetryDelayMultiplier  = 1.5
+	// execAgentMonitorInterval is how often the execute command agents of
+	// running containers are checked and restarted if they have stopped
+	execAgentMonitorInterval = 15 * time.Minute
+	// allCapabilities adds every kernel capability to a container
+	allCapabilities = "ALL"
+	// ipcSysctlPrefix and networkSysctlPrefix are the prefixes of the sysctls
+	// of the IPC and network namespaces
+	ipcSysctlPrefix     = "fs.mqueue."
+	networkSysctlPrefix = "net."
 )
 
+// ipcSysctls are the sysctls of the IPC namespace without a common prefix
+var ipcSysctls = map[string]bool{
+	"kernel.msgmax":          true,
+	"kernel.msgmnb":          true,
+	"kernel.msgmni":          true,
+	"kernel.sem":             true,
+	"kernel.shmall":          true,
+	"kernel.shmmax":          true,
+	"kernel.shmmni":          true,
+	"kernel.shm_rmid_forced": true,
+}
+
+// containerTransitionSpanNames maps the desired state of a container
+// transition to the name of the span traced for it
+var containerTransitionSpanNames = map[apicontainerstatus.ContainerStatus]string{
+	apicontainerstatus.ContainerPulled:               "PullContainer",
+	apicontainerstatus.ContainerCreated:              "CreateContainer",
+	apicontainerstatus.ContainerRunning:              "StartContainer",
+	apicontainerstatus.ContainerResourcesProvisioned: "ProvisionContainerResources",
+	apicontainerstatus.ContainerStopped:              "StopContainer",
+}
+
 // DockerTaskEngine is a state machine for managing a task and its containers
 // in ECS.
 //
@@ -126,6 +161,25 @@ type DockerTaskEngine struct {
 	taskSteadyStatePollInterval time.Duration
 
 	resourceFields *taskresource.ResourceFields
+
+	// imagePullSemaphore limits the number of images pulled at once. It is
+	// nil when no limit is configured
+	imagePullSemaphore utils.Semaphore
+
+	// gpuAllocations maps the id of every GPU assigned to a container being
+	// created to that container, so that concurrent tasks cannot both use it
+	gpuAllocations     map[string]gpuAllocation
+	gpuAllocationsLock sync.Mutex
+
+	// execCmdMgr sets up and supervises the execute command agent of the
+	// containers that enable it
+	execCmdMgr execcmd.Manager
+}
+
+// gpuAllocation identifies the container a GPU is assigned to
+type gpuAllocation struct {
+	taskArn       string
+	containerName string
 }
 
 // NewDockerTaskEngine returns a created, but uninitialized, DockerTaskEngine.
@@ -163,6 +217,12 @@ func NewDockerTaskEngine(cfg *config.Config,
 		metadataManager:             metadataManager,
 		taskSteadyStatePollInterval: defaultTaskSteadyStatePollInterval,
 		resourceFields:              resourceFields,
+		gpuAllocations:              make(map[string]gpuAllocation),
+		execCmdMgr:                  execcmd.NewManager(),
+	}
+
+	if cfg.MaxConcurrentImagePulls > 0 {
+		dockerTaskEngine.imagePullSemaphore = utils.NewSemaphore(cfg.MaxConcurrentImagePulls)
 	}
 
 	dockerTaskEngine.initializeContainerStatusToTransitionFunction()
@@ -216,6 +276,7 @@ func (engine *DockerTaskEngine) Init(ctx context.Context) error {
 	engine.synchronizeState()
 	// Now catch up and start processing new events per normal
 	go engine.handleDockerEvents(derivedCtx)
+	go engine.monitorExecAgents(derivedCtx)
 	engine.initialized = true
 	return nil
 }
@@ -471,9 +532,28 @@ func (engine *DockerTaskEngine) sweepTask(task *apitask.Task) {
 			seelog.Warnf("Task engine [%s]: clean task metadata failed: %v", task.Arn, err)
 		}
 	}
+
+	// Clean the logs of the execute command agents of the task
+	if taskUsesExecCmd(task) {
+		taskID, _ := task.GetID()
+		if err := engine.execCmdMgr.CleanupTask(taskID); err != nil {
+			seelog.Warnf("Task engine [%s]: clean execute command agent logs failed: %v", task.Arn, err)
+		}
+	}
 	engine.saver.Save()
 }
 
+// taskUsesExecCmd returns true if any container of the task runs the execute
+// command agent
+func taskUsesExecCmd(task *apitask.Task) bool {
+	for _, container := range task.Containers {
+		if execcmd.IsEnabled(container) {
+			return true
+		}
+	}
+	return false
+}
+
 func (engine *DockerTaskEngine) deleteTask(task *apitask.Task) {
 	for _, resource := range task.GetResources() {
 		err := resource.Cleanup()
@@ -653,6 +733,29 @@ func (engine *DockerTaskEngine) AddTask(task *apitask.Task) {
 	engine.updateTaskUnsafe(existingTask, task)
 }
 
+// StopTask sets the desired status of the task to STOPPED, the task manager
+// then stops its containers gracefully. The reason is reported in the task
+// state change
+func (engine *DockerTaskEngine) StopTask(arn string, reason string) {
+	engine.tasksLock.Lock()
+	defer engine.tasksLock.Unlock()
+
+	task, ok := engine.state.TaskByArn(arn)
+	if !ok {
+		seelog.Warnf("Task engine [%s]: unable to stop unknown task", arn)
+		return
+	}
+	if task.GetDesiredStatus().Terminal() {
+		return
+	}
+	seelog.Infof("Task engine [%s]: stopping task: %s", arn, reason)
+	task.SetTerminalReason(reason)
+	engine.updateTaskUnsafe(task, &apitask.Task{
+		Arn:                 arn,
+		DesiredStatusUnsafe: apitaskstatus.TaskStopped,
+	})
+}
+
 // ListTasks returns the tasks currently managed by the DockerTaskEngine
 func (engine *DockerTaskEngine) ListTasks() ([]*apitask.Task, error) {
 	return engine.state.AllTasks(), nil
@@ -723,6 +826,13 @@ func (engine *DockerTaskEngine) imagePullRequired(imagePullBehavior config.Image
 }
 
 func (engine *DockerTaskEngine) concurrentPull(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
+	if engine.imagePullSemaphore != nil {
+		seelog.Debugf("Task engine [%s]: waiting for a free image pull slot to pull image %s for container %s",
+			task.Arn, container.Image, container.Name)
+		engine.imagePullSemaphore.Wait()
+		defer engine.imagePullSemaphore.Post()
+	}
+
 	seelog.Debugf("Task engine [%s]: attempting to obtain ImagePullDeleteLock to pull image %s for container %s",
 		task.Arn, container.Image, container.Name)
 	ImagePullDeleteLock.RLock()
@@ -793,7 +903,23 @@ func (engine *DockerTaskEngine) pullAndUpdateContainerReference(task *apitask.Ta
 		defer container.SetASMDockerAuthConfig(types.AuthConfig{})
 	}
 
-	metadata := engine.client.PullImage(engine.ctx, container.Image, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	// The digest pinned by an earlier pull of the task is pulled directly, so
+	// that the container runs the image the task first resolved the tag to
+	// rather than the image the tag points to now
+	var pinnedDigest string
+	if !container.IsInternal() {
+		pinnedDigest = pinnedImageDigest(task, container)
+	}
+	var metadata dockerapi.DockerContainerMetadata
+	if pinnedDigest != "" {
+		container.SetImageDigest(pinnedDigest)
+		digestReference := imageDigestReference(container.Image, pinnedDigest)
+		seelog.Infof("Task engine [%s]: pulling image %s pinned by the task for container %s",
+			task.Arn, digestReference, container.Name)
+		metadata = engine.client.PullImage(engine.ctx, digestReference, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	} else {
+		metadata = engine.client.PullImage(engine.ctx, container.Image, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	}
 
 	// Don't add internal images(created by ecs-agent) into imagemanger state
 	if container.IsInternal() {
@@ -804,13 +930,33 @@ func (engine *DockerTaskEngine) pullAndUpdateContainerReference(task *apitask.Ta
 	return metadata
 }
 
+// pinnedImageDigest returns the digest the container's image resolved to when
+// it was first pulled for the container or another container of the task
+func pinnedImageDigest(task *apitask.Task, container *apicontainer.Container) string {
+	if imageDigest := container.GetImageDigest(); imageDigest != "" {
+		return imageDigest
+	}
+	for _, taskContainer := range task.Containers {
+		if taskContainer.Image != container.Image {
+			continue
+		}
+		if imageDigest := taskContainer.GetImageDigest(); imageDigest != "" {
+			return imageDigest
+		}
+	}
+	return ""
+}
+
 func (engine *DockerTaskEngine) updateContainerReference(pullSucceeded bool, container *apicontainer.Container, taskArn string) {
+	// Recording the reference may pin the image's digest, the image state is
+	// found by the name the image was pulled by
+	imageName := containerImageReference(container)
 	err := engine.imageManager.RecordContainerReference(container)
 	if err != nil {
 		seelog.Errorf("Task engine [%s]: unable to add container reference to image state: %v",
 			taskArn, err)
 	}
-	imageState, ok := engine.imageManager.GetImageStateFromImageName(container.Image)
+	imageState, ok := engine.imageManager.GetImageStateFromImageName(imageName)
 	if ok && pullSucceeded {
 		imageState.SetPullSucceeded(true)
 	}
@@ -853,6 +999,31 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(hcerr)}
 	}
 
+	if !container.IsInternal() {
+		engine.applyContainerDefaultLimits(hostConfig)
+		if err := engine.checkDisallowedCapabilities(hostConfig); err != nil {
+			return dockerapi.DockerContainerMetadata{Error: err}
+		}
+	}
+
+	if err := engine.checkSysctls(hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: err}
+	}
+
+	if err := engine.allocateGPUs(task, container); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: err}
+	}
+
+	// A container that cannot run the execute command agent still runs,
+	// the failure is reported as the status of the agent
+	if execcmd.IsEnabled(container) {
+		taskID, _ := task.GetID()
+		if err := engine.execCmdMgr.InitializeContainer(taskID, container, hostConfig); err != nil {
+			seelog.Warnf("Task engine [%s]: unable to set up the execute command agent for container %s: %v",
+				task.Arn, container.Name, err)
+		}
+	}
+
 	if container.AWSLogAuthExecutionRole() {
 		err := task.ApplyExecutionRoleLogsAuth(hostConfig, engine.credentialsManager)
 		if err != nil {
@@ -860,6 +1031,15 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	// read the variables of the environment files, secrets and the
+	// container's own variables take precedence over them
+	if container.ShouldCreateWithEnvFiles() {
+		err := task.PopulateEnvFilesAsEnv(container)
+		if err != nil {
+			return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(err)}
+		}
+	}
+
 	// apply secrets to container.Environment
 	if container.HasSecretAsEnv() {
 		err := task.PopulateSecretsAsEnv(container)
@@ -872,6 +1052,9 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	if err != nil {
 		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(err)}
 	}
+	if imageDigest := container.GetImageDigest(); imageDigest != "" && !container.IsInternal() {
+		config.Image = imageDigestReference(container.Image, imageDigest)
+	}
 
 	// Augment labels with some metadata from the agent. Explicitly do this last
 	// such that it will always override duplicates in the provided raw config
@@ -928,6 +1111,140 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	return metadata
 }
 
+// allocateGPUs records the GPUs assigned to the container as in use by it. It
+// fails if one of them is still used by a container of another task that has
+// not stopped, which happens when GPUs are double-booked between tasks
+func (engine *DockerTaskEngine) allocateGPUs(task *apitask.Task, container *apicontainer.Container) apierrors.NamedError {
+	if len(container.GPUIDs) == 0 {
+		return nil
+	}
+	engine.gpuAllocationsLock.Lock()
+	defer engine.gpuAllocationsLock.Unlock()
+
+	for _, gpuID := range container.GPUIDs {
+		if taskArn, ok := engine.gpuInUseByOtherTask(task, gpuID); ok {
+			seelog.Errorf("Task engine [%s]: GPU %s assigned to container %s is still in use by task %s",
+				task.Arn, gpuID, container.Name, taskArn)
+			return GPUAllocationConflictError{gpuID: gpuID, taskArn: taskArn}
+		}
+	}
+	for _, gpuID := range container.GPUIDs {
+		engine.gpuAllocations[gpuID] = gpuAllocation{taskArn: task.Arn, containerName: container.Name}
+	}
+	return nil
+}
+
+// gpuInUseByOtherTask returns the ARN of the task whose container, other than
+// in the given task, uses the GPU and has not stopped. The allocations made by
+// this engine cover containers being created, while the task state covers
+// containers created before an agent restart
+func (engine *DockerTaskEngine) gpuInUseByOtherTask(task *apitask.Task, gpuID string) (string, bool) {
+	if allocation, ok := engine.gpuAllocations[gpuID]; ok && allocation.taskArn != task.Arn {
+		if otherTask, ok := engine.state.TaskByArn(allocation.taskArn); ok {
+			if otherContainer, ok := otherTask.ContainerByName(allocation.containerName); ok &&
+				otherContainer.GetKnownStatus() < apicontainerstatus.ContainerStopped {
+				return allocation.taskArn, true
+			}
+		}
+	}
+
+	for _, otherTask := range engine.state.AllTasks() {
+		if otherTask.Arn == task.Arn {
+			continue
+		}
+		for _, otherContainer := range otherTask.Containers {
+			knownStatus := otherContainer.GetKnownStatus()
+			if knownStatus < apicontainerstatus.ContainerCreated || knownStatus >= apicontainerstatus.ContainerStopped {
+				continue
+			}
+			for _, otherGPUID := range otherContainer.GPUIDs {
+				if otherGPUID == gpuID {
+					return otherTask.Arn, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// applyContainerDefaultLimits sets the default ulimits and pids limit from the
+// config on the host config, unless the container already sets them
+func (engine *DockerTaskEngine) applyContainerDefaultLimits(hostConfig *dockercontainer.HostConfig) {
+	for _, defaultUlimit := range engine.cfg.ContainerDefaultUlimits {
+		set := false
+		for _, ulimit := range hostConfig.Ulimits {
+			if ulimit.Name == defaultUlimit.Name {
+				set = true
+				break
+			}
+		}
+		if !set {
+			ulimit := *defaultUlimit
+			hostConfig.Ulimits = append(hostConfig.Ulimits, &ulimit)
+		}
+	}
+
+	if hostConfig.PidsLimit == 0 {
+		hostConfig.PidsLimit = engine.cfg.ContainerDefaultPidsLimit
+	}
+}
+
+// checkDisallowedCapabilities fails if the host config adds a kernel capability
+// the config disallows. Adding "ALL" or running privileged, which grants every
+// capability, is refused when any capability is disallowed
+func (engine *DockerTaskEngine) checkDisallowedCapabilities(hostConfig *dockercontainer.HostConfig) apierrors.NamedError {
+	if len(engine.cfg.DisallowedCapabilities) == 0 {
+		return nil
+	}
+	if hostConfig.Privileged {
+		return DisallowedCapabilityError{privileged: true}
+	}
+	disallowed := make(map[string]bool)
+	for _, capability := range engine.cfg.DisallowedCapabilities {
+		disallowed[normalizeCapability(capability)] = true
+	}
+	for _, capability := range hostConfig.CapAdd {
+		normalized := normalizeCapability(capability)
+		if normalized == allCapabilities || disallowed[normalized] {
+			return DisallowedCapabilityError{capability: capability}
+		}
+	}
+	return nil
+}
+
+// normalizeCapability returns the capability in upper case without the
+// "CAP_" prefix, docker accepts both "CAP_SYS_ADMIN" and "sys_admin"
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+// checkSysctls fails if the host config sets a sysctl that is not namespaced
+// for the container, unless the config allows unsafe sysctls
+func (engine *DockerTaskEngine) checkSysctls(hostConfig *dockercontainer.HostConfig) apierrors.NamedError {
+	if engine.cfg.UnsafeSysctlsAllowed {
+		return nil
+	}
+	for sysctl := range hostConfig.Sysctls {
+		if !isNamespacedSysctl(sysctl, hostConfig) {
+			return UnsafeSysctlError{sysctl: sysctl}
+		}
+	}
+	return nil
+}
+
+// isNamespacedSysctl returns whether the sysctl only affects the container. IPC
+// and network sysctls are namespaced, unless the container shares the IPC or
+// network namespace of the host
+func isNamespacedSysctl(sysctl string, hostConfig *dockercontainer.HostConfig) bool {
+	if ipcSysctls[sysctl] || strings.HasPrefix(sysctl, ipcSysctlPrefix) {
+		return !hostConfig.IpcMode.IsHost()
+	}
+	if strings.HasPrefix(sysctl, networkSysctlPrefix) {
+		return !hostConfig.NetworkMode.IsHost()
+	}
+	return false
+}
+
 func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	seelog.Infof("Task engine [%s]: starting container: %s", task.Arn, container.Name)
 	client := engine.client
@@ -976,9 +1293,72 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 	}
 	seelog.Infof("Task engine [%s]: started docker container for task: %s -> %s, took %s",
 		task.Arn, container.Name, dockerContainerMD.DockerID, time.Since(startContainerBegin))
+
+	if dockerContainerMD.Error == nil && execcmd.IsEnabled(container) {
+		err := engine.execCmdMgr.StartAgent(engine.ctx, client, task, container, dockerContainer.DockerID)
+		if err != nil {
+			seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+		}
+	}
 	return dockerContainerMD
 }
 
+// monitorExecAgents periodically restarts the execute command agents that
+// have stopped in the running containers of running tasks
+func (engine *DockerTaskEngine) monitorExecAgents(ctx context.Context) {
+	ticker := time.NewTicker(execAgentMonitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			engine.restartStoppedExecAgents(ctx)
+		}
+	}
+}
+
+// restartStoppedExecAgents restarts the execute command agents that have
+// stopped, and reports the changes of their status
+func (engine *DockerTaskEngine) restartStoppedExecAgents(ctx context.Context) {
+	for _, task := range engine.state.AllTasks() {
+		if task.GetKnownStatus() != apitaskstatus.TaskRunning {
+			continue
+		}
+		containerMap, ok := engine.state.ContainerMapByArn(task.Arn)
+		if !ok {
+			continue
+		}
+		for _, dockerContainer := range containerMap {
+			container := dockerContainer.Container
+			if !execcmd.IsEnabled(container) || !container.IsRunning() || container.DesiredTerminal() {
+				continue
+			}
+			restarted, err := engine.execCmdMgr.RestartAgentIfStopped(ctx, engine.client, task, container,
+				dockerContainer.DockerID)
+			if err != nil {
+				seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+			} else if restarted {
+				seelog.Infof("Task engine [%s]: restarted the execute command agent of container %s",
+					task.Arn, container.Name)
+			}
+		}
+		engine.emitManagedAgentEvent(task)
+	}
+}
+
+// emitManagedAgentEvent reports the changes of the status of the managed
+// agents of the task's containers that have not been sent yet
+func (engine *DockerTaskEngine) emitManagedAgentEvent(task *apitask.Task) {
+	event, err := api.NewManagedAgentChangeEvent(task)
+	if err != nil {
+		seelog.Debugf("Task engine [%s]: no managed agent changes to report: %v", task.Arn, err)
+		return
+	}
+	seelog.Infof("Task engine [%s]: sending managed agent change event: %s", task.Arn, event.String())
+	engine.stateChangeEvents <- event
+}
+
 func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	seelog.Infof("Task engine [%s]: setting up container resources for container [%s]",
 		task.Arn, container.Name)
@@ -992,7 +1372,7 @@ func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task,
 		}
 	}
 	// Invoke the libcni to config the network namespace for the container
-	result, err := engine.cniClient.SetupNS(engine.ctx, cniConfig, cniSetupTimeout)
+	result, err := engine.cniClient.SetupNS(engine.ctx, cniConfig, engine.cniPluginTimeout(cniSetupTimeout))
 	if err != nil {
 		seelog.Errorf("Task engine [%s]: unable to configure pause container namespace: %v",
 			task.Arn, err)
@@ -1021,7 +1401,16 @@ func (engine *DockerTaskEngine) cleanupPauseContainerNetwork(task *apitask.Task,
 			"engine: failed cleanup task network namespace, task: %s", task.String())
 	}
 
-	return engine.cniClient.CleanupNS(engine.ctx, cniConfig, cniCleanupTimeout)
+	return engine.cniClient.CleanupNS(engine.ctx, cniConfig, engine.cniPluginTimeout(cniCleanupTimeout))
+}
+
+// cniPluginTimeout returns the configured timeout for invoking the cni plugins,
+// or the given default when none is configured
+func (engine *DockerTaskEngine) cniPluginTimeout(defaultTimeout time.Duration) time.Duration {
+	if engine.cfg.CNIPluginTimeout > 0 {
+		return engine.cfg.CNIPluginTimeout
+	}
+	return defaultTimeout
 }
 
 func (engine *DockerTaskEngine) buildCNIConfigFromTaskContainer(task *apitask.Task, container *apicontainer.Container) (*ecscni.Config, error) {
@@ -1093,8 +1482,11 @@ func (engine *DockerTaskEngine) stopContainer(task *apitask.Task, container *api
 		}
 		seelog.Infof("Task engine [%s]: cleaned pause container network namespace", task.Arn)
 	}
-	// timeout is defined by the const 'stopContainerTimeout' and the 'DockerStopTimeout' in the config
-	timeout := engine.cfg.DockerStopTimeout + dockerclient.StopContainerTimeout
+	// The container's own stop timeout takes precedence over the 'DockerStopTimeout' in the config
+	timeout := container.GetStopTimeout()
+	if timeout <= 0 {
+		timeout = engine.cfg.DockerStopTimeout
+	}
 	return engine.client.StopContainer(engine.ctx, dockerContainer.DockerID, timeout)
 }
 
@@ -1170,11 +1562,18 @@ func (engine *DockerTaskEngine) applyContainerState(task *apitask.Task, containe
 			task.Arn, container.Name, nextState.String())
 		return dockerapi.DockerContainerMetadata{Error: &impossibleTransitionError{nextState}}
 	}
+	span := tracing.StartSpan(task.Arn, containerTransitionSpanNames[nextState])
+	span.SetAttribute("aws.ecs.task.arn", task.Arn)
+	span.SetAttribute("container.name", container.Name)
+	span.SetAttribute("container.image.name", container.Image)
+	span.SetAttribute("aws.ecs.container.next_status", nextState.String())
 	metadata := transitionFunction(task, container)
 	if metadata.Error != nil {
+		span.End(metadata.Error)
 		seelog.Infof("Task engine [%s]: error transitioning container [%s] to [%s]: %v",
 			task.Arn, container.Name, nextState.String(), metadata.Error)
 	} else {
+		span.End(nil)
 		seelog.Debugf("Task engine [%s]: transitioned container [%s] to [%s]",
 			task.Arn, container.Name, nextState.String())
 		engine.saver.Save()
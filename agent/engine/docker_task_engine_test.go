@@ -66,6 +66,7 @@ import (
 	"github.com/containernetworking/cni/pkg/types/current"
 	"github.com/docker/docker/api/types"
 	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/assert"
@@ -1271,13 +1272,42 @@ func TestStopPauseContainerCleanupCalled(t *testing.T) {
 		mockCNIClient.EXPECT().CleanupNS(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
 		dockerClient.EXPECT().StopContainer(gomock.Any(),
 			containerID,
-			defaultConfig.DockerStopTimeout+dockerclient.StopContainerTimeout,
+			defaultConfig.DockerStopTimeout,
 		).Return(dockerapi.DockerContainerMetadata{}),
 	)
 
 	taskEngine.(*DockerTaskEngine).stopContainer(testTask, pauseContainer)
 }
 
+// TestStopContainerWithStopTimeout tests that the stop timeout of a container
+// takes precedence over the one in the config
+func TestStopContainerWithStopTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	container := &apicontainer.Container{
+		Name:        "container",
+		StopTimeout: 120,
+	}
+	testTask := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/stop-timeout",
+		Containers: []*apicontainer.Container{container},
+	}
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: "container",
+		Container:  container,
+	}, testTask)
+
+	client.EXPECT().StopContainer(gomock.Any(), containerID, 120*time.Second).Return(dockerapi.DockerContainerMetadata{})
+
+	metadata := taskEngine.(*DockerTaskEngine).stopContainer(testTask, container)
+	assert.NoError(t, metadata.Error)
+}
+
 // TestTaskWithCircularDependency tests the task with containers of which the
 // dependencies can't be resolved
 func TestTaskWithCircularDependency(t *testing.T) {
@@ -1378,6 +1408,49 @@ func TestPullNormalImage(t *testing.T) {
 	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
 }
 
+func TestPullImagePinnedByTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	taskEngine._time = nil
+	imageName := "image:latest"
+	imageDigest := "sha256:f8fd3a9bd47d0ae2b4d7bae00ba0c7d1ec1e1dca4bd15e1b34a8e67e1c2c4b4d"
+	digestReference := "image@" + imageDigest
+	pulledContainer := &apicontainer.Container{
+		Name:  "pulled",
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	pulledContainer.SetImageDigest(imageDigest)
+	container := &apicontainer.Container{
+		Name:  "container",
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{pulledContainer, container},
+	}
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "id"},
+	}
+
+	// The image of the pinned digest is pulled and tracked instead of the
+	// image the tag points to now
+	gomock.InOrder(
+		client.EXPECT().PullImage(gomock.Any(), digestReference, nil, gomock.Any()),
+		imageManager.EXPECT().RecordContainerReference(container),
+		imageManager.EXPECT().GetImageStateFromImageName(digestReference).Return(imageState, true),
+		saver.EXPECT().Save(),
+	)
+	metadata := taskEngine.pullContainer(task, container)
+	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
+	assert.Equal(t, imageDigest, container.GetImageDigest())
+}
+
 func TestPullImageWithImagePullOnceBehavior(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -1484,6 +1557,246 @@ func TestPullImageWithImagePullPreferCachedBehaviorWithoutCachedImage(t *testing
 	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
 }
 
+func TestPullImageWaitsForImagePullSlot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{MaxConcurrentImagePulls: 1})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	taskEngine._time = nil
+	imageName := "image"
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container},
+	}
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "id"},
+	}
+	pulled := make(chan struct{})
+	client.EXPECT().PullImage(gomock.Any(), imageName, nil, gomock.Any()).Do(
+		func(ctx context.Context, image string, auth *apicontainer.RegistryAuthenticationData, timeout time.Duration) {
+			close(pulled)
+		})
+	imageManager.EXPECT().RecordContainerReference(container)
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true)
+	saver.EXPECT().Save()
+
+	// Occupy the only pull slot so that the pull below has to wait for it
+	taskEngine.imagePullSemaphore.Wait()
+	done := make(chan dockerapi.DockerContainerMetadata)
+	go func() {
+		done <- taskEngine.pullContainer(task, container)
+	}()
+
+	select {
+	case <-pulled:
+		t.Fatal("Expected image pull to wait for a free pull slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	taskEngine.imagePullSemaphore.Post()
+	metadata := <-done
+	<-pulled
+	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
+}
+
+func TestApplyContainerDefaultLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := &config.Config{
+		ContainerDefaultUlimits: []*units.Ulimit{
+			{Name: "nofile", Soft: 1024, Hard: 4096},
+			{Name: "core", Soft: 0, Hard: 0},
+		},
+		ContainerDefaultPidsLimit: 100,
+	}
+	ctrl, _, _, privateTaskEngine, _, _, _ := mocks(t, ctx, cfg)
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+
+	hostConfig := &dockercontainer.HostConfig{}
+	taskEngine.applyContainerDefaultLimits(hostConfig)
+	assert.Equal(t, cfg.ContainerDefaultUlimits, hostConfig.Ulimits)
+	assert.Equal(t, int64(100), hostConfig.PidsLimit)
+
+	// Limits set by the container take precedence over the defaults
+	hostConfig = &dockercontainer.HostConfig{
+		Resources: dockercontainer.Resources{
+			Ulimits:   []*units.Ulimit{{Name: "nofile", Soft: 65536, Hard: 65536}},
+			PidsLimit: 500,
+		},
+	}
+	taskEngine.applyContainerDefaultLimits(hostConfig)
+	assert.Equal(t, []*units.Ulimit{
+		{Name: "nofile", Soft: 65536, Hard: 65536},
+		{Name: "core", Soft: 0, Hard: 0},
+	}, hostConfig.Ulimits)
+	assert.Equal(t, int64(500), hostConfig.PidsLimit)
+}
+
+func TestCheckDisallowedCapabilities(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := &config.Config{DisallowedCapabilities: []string{"SYS_ADMIN"}}
+	ctrl, _, _, privateTaskEngine, _, _, _ := mocks(t, ctx, cfg)
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+
+	testCases := []struct {
+		capAdd  []string
+		allowed bool
+	}{
+		{capAdd: nil, allowed: true},
+		{capAdd: []string{"NET_ADMIN"}, allowed: true},
+		{capAdd: []string{"NET_ADMIN", "SYS_ADMIN"}, allowed: false},
+		{capAdd: []string{"cap_sys_admin"}, allowed: false},
+		{capAdd: []string{"ALL"}, allowed: false},
+	}
+	for _, tc := range testCases {
+		err := taskEngine.checkDisallowedCapabilities(&dockercontainer.HostConfig{CapAdd: tc.capAdd})
+		if tc.allowed {
+			assert.NoError(t, err, "capabilities %v should be allowed", tc.capAdd)
+		} else {
+			assert.IsType(t, DisallowedCapabilityError{}, err, "capabilities %v should be disallowed", tc.capAdd)
+		}
+	}
+
+	// A privileged container gets every capability, including the disallowed ones
+	assert.IsType(t, DisallowedCapabilityError{},
+		taskEngine.checkDisallowedCapabilities(&dockercontainer.HostConfig{Privileged: true}))
+
+	// Any capability may be added when none is disallowed
+	taskEngine.cfg.DisallowedCapabilities = nil
+	assert.NoError(t, taskEngine.checkDisallowedCapabilities(&dockercontainer.HostConfig{CapAdd: []string{"ALL"}}))
+	assert.NoError(t, taskEngine.checkDisallowedCapabilities(&dockercontainer.HostConfig{Privileged: true}))
+}
+
+func TestCheckSysctls(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, privateTaskEngine, _, _, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+
+	testCases := []struct {
+		name       string
+		hostConfig *dockercontainer.HostConfig
+		allowed    bool
+	}{
+		{
+			name:       "network sysctl",
+			hostConfig: &dockercontainer.HostConfig{Sysctls: map[string]string{"net.core.somaxconn": "1024"}},
+			allowed:    true,
+		},
+		{
+			name:       "ipc sysctls",
+			hostConfig: &dockercontainer.HostConfig{Sysctls: map[string]string{"kernel.shmmax": "1", "fs.mqueue.msg_max": "1"}},
+			allowed:    true,
+		},
+		{
+			name: "network sysctl in host network mode",
+			hostConfig: &dockercontainer.HostConfig{
+				NetworkMode: "host",
+				Sysctls:     map[string]string{"net.core.somaxconn": "1024"},
+			},
+			allowed: false,
+		},
+		{
+			name: "ipc sysctl in host ipc mode",
+			hostConfig: &dockercontainer.HostConfig{
+				IpcMode: "host",
+				Sysctls: map[string]string{"kernel.shmmax": "1"},
+			},
+			allowed: false,
+		},
+		{
+			name:       "sysctl that is not namespaced",
+			hostConfig: &dockercontainer.HostConfig{Sysctls: map[string]string{"vm.swappiness": "10"}},
+			allowed:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskEngine.cfg.UnsafeSysctlsAllowed = false
+			err := taskEngine.checkSysctls(tc.hostConfig)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.IsType(t, UnsafeSysctlError{}, err)
+			}
+
+			// Any sysctl may be set when unsafe sysctls are allowed
+			taskEngine.cfg.UnsafeSysctlsAllowed = true
+			assert.NoError(t, taskEngine.checkSysctls(tc.hostConfig))
+		})
+	}
+}
+
+func TestCNIPluginTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, privateTaskEngine, _, _, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+
+	assert.Equal(t, cniSetupTimeout, taskEngine.cniPluginTimeout(cniSetupTimeout))
+	taskEngine.cfg.CNIPluginTimeout = 2 * time.Minute
+	assert.Equal(t, 2*time.Minute, taskEngine.cniPluginTimeout(cniSetupTimeout))
+	assert.Equal(t, 2*time.Minute, taskEngine.cniPluginTimeout(cniCleanupTimeout))
+}
+
+func TestAllocateGPUsConflict(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, privateTaskEngine, _, _, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+
+	runningContainer := &apicontainer.Container{
+		Name:   "gpu",
+		GPUIDs: []string{"gpu0"},
+	}
+	runningContainer.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	runningTask := &apitask.Task{
+		Arn:        "runningTask",
+		Containers: []*apicontainer.Container{runningContainer},
+	}
+	taskEngine.State().AddTask(runningTask)
+
+	container := &apicontainer.Container{
+		Name:   "gpu",
+		GPUIDs: []string{"gpu1", "gpu0"},
+	}
+	task := &apitask.Task{
+		Arn:        "task",
+		Containers: []*apicontainer.Container{container},
+	}
+	err := taskEngine.allocateGPUs(task, container)
+	require.Error(t, err)
+	assert.Equal(t, "GPUAllocationConflictError", err.ErrorName())
+
+	// The GPU can be used once the container holding it has stopped
+	runningContainer.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	assert.NoError(t, taskEngine.allocateGPUs(task, container))
+
+	// GPUs allocated to a container being created cannot be used by another task
+	otherContainer := &apicontainer.Container{
+		Name:   "gpu",
+		GPUIDs: []string{"gpu1"},
+	}
+	otherTask := &apitask.Task{
+		Arn:        "otherTask",
+		Containers: []*apicontainer.Container{otherContainer},
+	}
+	taskEngine.State().AddTask(task)
+	assert.Error(t, taskEngine.allocateGPUs(otherTask, otherContainer))
+}
+
 func TestUpdateContainerReference(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -14,6 +14,8 @@
 package engine
 
 import (
+	"fmt"
+
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 )
@@ -97,3 +99,54 @@ func (err CannotGetDockerClientVersionError) ErrorName() string {
 func (err CannotGetDockerClientVersionError) Error() string {
 	return err.fromError.Error()
 }
+
+// DisallowedCapabilityError indicates that a container adds a kernel capability
+// the agent is configured to disallow, or runs privileged and so gets every
+// capability
+type DisallowedCapabilityError struct {
+	capability string
+	privileged bool
+}
+
+func (err DisallowedCapabilityError) Error() string {
+	if err.privileged {
+		return "privileged containers are not allowed on this container instance as some capabilities are disallowed"
+	}
+	return fmt.Sprintf("capability %s is not allowed on this container instance", err.capability)
+}
+
+// ErrorName returns the name of the error
+func (err DisallowedCapabilityError) ErrorName() string {
+	return "DisallowedCapabilityError"
+}
+
+// UnsafeSysctlError indicates that a container sets a sysctl that is not
+// namespaced, while the agent is configured to disallow such sysctls
+type UnsafeSysctlError struct {
+	sysctl string
+}
+
+func (err UnsafeSysctlError) Error() string {
+	return fmt.Sprintf("sysctl %s is not namespaced for the container and is not allowed on this container instance", err.sysctl)
+}
+
+// ErrorName returns the name of the error
+func (err UnsafeSysctlError) ErrorName() string {
+	return "UnsafeSysctlError"
+}
+
+// GPUAllocationConflictError indicates that a GPU assigned to a container is
+// still in use by a container of another task
+type GPUAllocationConflictError struct {
+	gpuID   string
+	taskArn string
+}
+
+func (err GPUAllocationConflictError) Error() string {
+	return fmt.Sprintf("GPU %s is still in use by task %s", err.gpuID, err.taskArn)
+}
+
+// ErrorName returns the name of the error
+func (err GPUAllocationConflictError) ErrorName() string {
+	return "GPUAllocationConflictError"
+}
@@ -0,0 +1,302 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package execcmd sets up, starts and supervises the agent serving the
+// `aws ecs execute-command` sessions of the containers that enable it
+package execcmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	sdkclient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HostDepsDir is the directory of the host the execute command agent is
+	// installed in, with the binaries of each version under bin/<version>
+	HostDepsDir = "/var/lib/ecs/deps/execute-command"
+	// depsDir is the directory HostDepsDir is mounted at in the ECS agent's
+	// container
+	depsDir = "/managed-agents/execute-command"
+	// hostLogDir is the directory of the host the logs of the execute command
+	// agent of every container are written to
+	hostLogDir = "/var/log/ecs/exec"
+	// logDir is the directory hostLogDir is mounted at in the ECS agent's
+	// container
+	logDir = "/log/exec"
+
+	binDirName   = "bin"
+	certsDirName = "certs"
+
+	// containerDepsDirPrefix is the prefix of the directory the binaries of
+	// the agent are mounted at in a container. A random suffix keeps it from
+	// clashing with the files of the container's image
+	containerDepsDirPrefix = "/ecs-execute-command-"
+	containerLogDir        = "/var/log/amazon/ssm"
+
+	// containerDepsDirKey and versionKey are the keys of the agent's metadata,
+	// kept so that the agent is restarted from the same place
+	containerDepsDirKey = "containerDepsDir"
+	versionKey          = "version"
+
+	// agentUser runs the agent as root in the container, as it manages the
+	// sessions of the container's users
+	agentUser = "0"
+)
+
+// agentBinNames are the binaries the execute command agent is made of. The
+// first one is the agent process started in the container
+var agentBinNames = []string{"amazon-ssm-agent", "ssm-agent-worker", "ssm-session-worker"}
+
+// Manager sets up the execute command agent in the containers that enable it,
+// starts it once they run and restarts it when it stops
+type Manager interface {
+	// InitializeContainer adds the binds of the agent's binaries, certificates
+	// and logs to the host config of the container
+	InitializeContainer(taskID string, container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error
+	// StartAgent starts the agent in the running container
+	StartAgent(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+		container *apicontainer.Container, containerID string) error
+	// RestartAgentIfStopped starts the agent again in the running container if
+	// its process has exited, and returns whether it was restarted
+	RestartAgentIfStopped(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+		container *apicontainer.Container, containerID string) (bool, error)
+	// CleanupTask removes the logs the agent wrote for the containers of the
+	// task once the task is cleaned up
+	CleanupTask(taskID string) error
+}
+
+type manager struct {
+	depsDir      string
+	hostDepsDir  string
+	hostLogDir   string
+	logDir       string
+	uuidProvider utils.UUIDProvider
+}
+
+// NewManager returns a Manager of the execute command agent installed on the
+// host
+func NewManager() Manager {
+	return &manager{
+		depsDir:      depsDir,
+		hostDepsDir:  HostDepsDir,
+		hostLogDir:   hostLogDir,
+		logDir:       logDir,
+		uuidProvider: utils.NewDynamicUUIDProvider(),
+	}
+}
+
+// AgentAvailable returns true if a version of the execute command agent is
+// installed on the host
+func AgentAvailable() bool {
+	_, err := latestAgentVersion(depsDir)
+	return err == nil
+}
+
+// IsEnabled returns true if the container runs the execute command agent
+func IsEnabled(container *apicontainer.Container) bool {
+	_, ok := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	return ok
+}
+
+func (m *manager) InitializeContainer(taskID string, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	managedAgent, ok := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	if !ok {
+		return nil
+	}
+	state := managedAgent.ManagedAgentState
+	version, err := latestAgentVersion(m.depsDir)
+	if err != nil {
+		state.InitFailed = true
+		state.Status = apicontainerstatus.ContainerStopped
+		state.Reason = err.Error()
+		container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, state)
+		return err
+	}
+
+	containerDepsDir := state.Metadata[containerDepsDirKey]
+	if containerDepsDir == "" {
+		containerDepsDir = containerDepsDirPrefix + m.uuidProvider.New()
+	}
+	hostBinDir := filepath.Join(m.hostDepsDir, binDirName, version)
+	for _, binName := range agentBinNames {
+		hostConfig.Binds = append(hostConfig.Binds, readOnlyBind(filepath.Join(hostBinDir, binName),
+			path.Join(containerDepsDir, binName)))
+	}
+	hostConfig.Binds = append(hostConfig.Binds,
+		readOnlyBind(filepath.Join(m.hostDepsDir, certsDirName), path.Join(containerDepsDir, certsDirName)),
+		fmt.Sprintf("%s:%s", filepath.Join(m.hostLogDir, taskID, container.Name), containerLogDir))
+
+	state.InitFailed = false
+	state.Reason = ""
+	state.Metadata = map[string]string{
+		containerDepsDirKey: containerDepsDir,
+		versionKey:          version,
+	}
+	container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, state)
+	return nil
+}
+
+func (m *manager) StartAgent(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+	container *apicontainer.Container, containerID string) error {
+	managedAgent, ok := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	if !ok || managedAgent.InitFailed {
+		return nil
+	}
+	state := managedAgent.ManagedAgentState
+	execID, err := startAgentProcess(ctx, client, containerID, state.Metadata[containerDepsDirKey])
+	if err != nil {
+		state.Status = apicontainerstatus.ContainerStopped
+		state.Reason = err.Error()
+		container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, state)
+		return errors.Wrapf(err, "execute command agent: unable to start the agent in container %s of task %s",
+			container.Name, task.Arn)
+	}
+
+	state.ID = execID
+	state.Status = apicontainerstatus.ContainerRunning
+	state.Reason = ""
+	state.LastStartedAt = time.Now()
+	container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, state)
+	return nil
+}
+
+func (m *manager) RestartAgentIfStopped(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+	container *apicontainer.Container, containerID string) (bool, error) {
+	managedAgent, ok := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	if !ok || managedAgent.InitFailed {
+		return false, nil
+	}
+	if managedAgent.ID != "" {
+		execInspect, err := client.InspectContainerExec(ctx, managedAgent.ID, dockerclient.InspectContainerExecTimeout)
+		// The agent may still be running when docker cannot be reached, starting
+		// it again would then run a second agent in the container
+		if err != nil && !isNoSuchExecError(err) {
+			return false, errors.Wrapf(err, "execute command agent: unable to inspect the agent in container %s of task %s",
+				container.Name, task.Arn)
+		}
+		if err == nil && execInspect.Running {
+			return false, nil
+		}
+	}
+	return true, m.StartAgent(ctx, client, task, container, containerID)
+}
+
+func (m *manager) CleanupTask(taskID string) error {
+	return os.RemoveAll(filepath.Join(m.logDir, taskID))
+}
+
+// isNoSuchExecError returns true if docker does not know the exec process,
+// such as when the daemon restarted since the agent was started
+func isNoSuchExecError(err error) bool {
+	return sdkclient.IsErrNotFound(err) || strings.Contains(err.Error(), "No such exec")
+}
+
+// startAgentProcess runs the agent in the container detached from the ECS
+// agent, and returns the id of its exec process once it is running
+func startAgentProcess(ctx context.Context, client dockerapi.DockerClient, containerID string,
+	containerDepsDir string) (string, error) {
+	execIDResponse, err := client.CreateContainerExec(ctx, containerID, types.ExecConfig{
+		User:   agentUser,
+		Detach: true,
+		Cmd:    []string{path.Join(containerDepsDir, agentBinNames[0])},
+	}, dockerclient.CreateContainerExecTimeout)
+	if err != nil {
+		return "", err
+	}
+	if err := client.StartContainerExec(ctx, execIDResponse.ID, dockerclient.StartContainerExecTimeout); err != nil {
+		return "", err
+	}
+	execInspect, err := client.InspectContainerExec(ctx, execIDResponse.ID, dockerclient.InspectContainerExecTimeout)
+	if err != nil {
+		return "", err
+	}
+	if !execInspect.Running {
+		return "", errors.Errorf("agent exited with code %d", execInspect.ExitCode)
+	}
+	return execIDResponse.ID, nil
+}
+
+// latestAgentVersion returns the latest version of the agent installed in
+// the dependencies directory with all of its binaries
+func latestAgentVersion(depsDir string) (string, error) {
+	binDir := filepath.Join(depsDir, binDirName)
+	versionDirs, err := ioutil.ReadDir(binDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "execute command agent: unable to list the installed versions")
+	}
+	var latest string
+	for _, versionDir := range versionDirs {
+		version := versionDir.Name()
+		if !versionDir.IsDir() || !binariesInstalled(filepath.Join(binDir, version)) {
+			continue
+		}
+		if latest == "" || versionNewer(version, latest) {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", errors.Errorf("execute command agent: no version is installed in %s", binDir)
+	}
+	return latest, nil
+}
+
+// versionNewer returns true if version lhs is newer than version rhs. The
+// agent's versions have four numeric parts, which utils.Version cannot parse
+func versionNewer(lhs, rhs string) bool {
+	lhsParts := strings.Split(lhs, ".")
+	rhsParts := strings.Split(rhs, ".")
+	for i := 0; i < len(lhsParts) && i < len(rhsParts); i++ {
+		lhsPart, lhsErr := strconv.Atoi(lhsParts[i])
+		rhsPart, rhsErr := strconv.Atoi(rhsParts[i])
+		if lhsErr == nil && rhsErr == nil {
+			if lhsPart != rhsPart {
+				return lhsPart > rhsPart
+			}
+		} else if lhsParts[i] != rhsParts[i] {
+			return lhsParts[i] > rhsParts[i]
+		}
+	}
+	return len(lhsParts) > len(rhsParts)
+}
+
+func binariesInstalled(versionDir string) bool {
+	for _, binName := range agentBinNames {
+		if _, err := os.Stat(filepath.Join(versionDir, binName)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func readOnlyBind(source, destination string) string {
+	return fmt.Sprintf("%s:%s:ro", source, destination)
+}
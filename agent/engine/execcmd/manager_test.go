@@ -0,0 +1,263 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package execcmd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN     = "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id"
+	containerID = "container-id"
+	execID      = "exec-id"
+)
+
+// installAgent creates the binaries of the given versions of the agent in the
+// dependencies directory
+func installAgent(t *testing.T, depsDir string, versions ...string) {
+	for _, version := range versions {
+		versionDir := filepath.Join(depsDir, binDirName, version)
+		require.NoError(t, os.MkdirAll(versionDir, 0755))
+		for _, binName := range agentBinNames {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, binName), nil, 0755))
+		}
+	}
+}
+
+func newTestManager(depsDir string) *manager {
+	return &manager{
+		depsDir:      depsDir,
+		hostDepsDir:  HostDepsDir,
+		hostLogDir:   hostLogDir,
+		logDir:       logDir,
+		uuidProvider: utils.NewStaticUUIDProvider("uuid"),
+	}
+}
+
+func newExecContainer() *apicontainer.Container {
+	return &apicontainer.Container{
+		Name: "app",
+		ManagedAgentsUnsafe: []apicontainer.ManagedAgent{
+			{Name: apicontainer.ExecuteCommandAgentName},
+		},
+	}
+}
+
+func TestLatestAgentVersion(t *testing.T) {
+	depsDir, err := ioutil.TempDir("", "execcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(depsDir)
+
+	_, err = latestAgentVersion(depsDir)
+	assert.Error(t, err)
+
+	installAgent(t, depsDir, "3.0.161.0", "3.0.236.0")
+	// a version missing some of the binaries is ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(depsDir, binDirName, "3.0.300.0"), 0755))
+
+	version, err := latestAgentVersion(depsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.236.0", version)
+}
+
+func TestInitializeContainer(t *testing.T) {
+	depsDir, err := ioutil.TempDir("", "execcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(depsDir)
+	installAgent(t, depsDir, "3.0.236.0")
+
+	container := newExecContainer()
+	hostConfig := &dockercontainer.HostConfig{}
+	require.NoError(t, newTestManager(depsDir).InitializeContainer("task-id", container, hostConfig))
+
+	binDir := "/var/lib/ecs/deps/execute-command/bin/3.0.236.0"
+	assert.Equal(t, []string{
+		binDir + "/amazon-ssm-agent:/ecs-execute-command-uuid/amazon-ssm-agent:ro",
+		binDir + "/ssm-agent-worker:/ecs-execute-command-uuid/ssm-agent-worker:ro",
+		binDir + "/ssm-session-worker:/ecs-execute-command-uuid/ssm-session-worker:ro",
+		"/var/lib/ecs/deps/execute-command/certs:/ecs-execute-command-uuid/certs:ro",
+		"/var/log/ecs/exec/task-id/app:/var/log/amazon/ssm",
+	}, hostConfig.Binds)
+	managedAgent, ok := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	require.True(t, ok)
+	assert.False(t, managedAgent.InitFailed)
+	assert.Equal(t, "3.0.236.0", managedAgent.Metadata[versionKey])
+}
+
+func TestInitializeContainerAgentNotInstalled(t *testing.T) {
+	depsDir, err := ioutil.TempDir("", "execcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(depsDir)
+
+	container := newExecContainer()
+	hostConfig := &dockercontainer.HostConfig{}
+	assert.Error(t, newTestManager(depsDir).InitializeContainer("task-id", container, hostConfig))
+
+	assert.Empty(t, hostConfig.Binds)
+	managedAgent, _ := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	assert.True(t, managedAgent.InitFailed)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, managedAgent.Status)
+	assert.NotEmpty(t, managedAgent.Reason)
+}
+
+func TestStartAgent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	container := newExecContainer()
+	container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, apicontainer.ManagedAgentState{
+		Metadata: map[string]string{containerDepsDirKey: "/ecs-execute-command-uuid"},
+	})
+	gomock.InOrder(
+		client.EXPECT().CreateContainerExec(gomock.Any(), containerID, types.ExecConfig{
+			User:   agentUser,
+			Detach: true,
+			Cmd:    []string{"/ecs-execute-command-uuid/amazon-ssm-agent"},
+		}, gomock.Any()).Return(&types.IDResponse{ID: execID}, nil),
+		client.EXPECT().StartContainerExec(gomock.Any(), execID, gomock.Any()).Return(nil),
+		client.EXPECT().InspectContainerExec(gomock.Any(), execID, gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: true}, nil),
+	)
+
+	err := newTestManager("").StartAgent(context.TODO(), client, &apitask.Task{Arn: taskARN}, container, containerID)
+	require.NoError(t, err)
+	managedAgent, _ := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	assert.Equal(t, execID, managedAgent.ID)
+	assert.Equal(t, apicontainerstatus.ContainerRunning, managedAgent.Status)
+	assert.False(t, managedAgent.LastStartedAt.IsZero())
+}
+
+func TestStartAgentFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	container := newExecContainer()
+	client.EXPECT().CreateContainerExec(gomock.Any(), containerID, gomock.Any(), gomock.Any()).Return(
+		nil, errors.New("exec failed"))
+
+	err := newTestManager("").StartAgent(context.TODO(), client, &apitask.Task{Arn: taskARN}, container, containerID)
+	assert.Error(t, err)
+	managedAgent, _ := container.GetManagedAgentByName(apicontainer.ExecuteCommandAgentName)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, managedAgent.Status)
+	assert.Equal(t, "exec failed", managedAgent.Reason)
+}
+
+func TestRestartAgentIfStopped(t *testing.T) {
+	testCases := []struct {
+		name              string
+		running           bool
+		inspectErr        error
+		expectedRestarted bool
+		expectedErr       bool
+	}{
+		{
+			name:              "agent running",
+			running:           true,
+			expectedRestarted: false,
+		},
+		{
+			name:              "agent stopped",
+			running:           false,
+			expectedRestarted: true,
+		},
+		{
+			name:              "agent exec unknown to docker",
+			inspectErr:        errors.New("Error response from daemon: No such exec instance: old-exec-id"),
+			expectedRestarted: true,
+		},
+		{
+			name:              "agent inspect failed",
+			inspectErr:        errors.New("context deadline exceeded"),
+			expectedRestarted: false,
+			expectedErr:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+			container := newExecContainer()
+			container.UpdateManagedAgentByName(apicontainer.ExecuteCommandAgentName, apicontainer.ManagedAgentState{
+				ID:     "old-exec-id",
+				Status: apicontainerstatus.ContainerRunning,
+			})
+			client.EXPECT().InspectContainerExec(gomock.Any(), "old-exec-id", gomock.Any()).Return(
+				&types.ContainerExecInspect{Running: tc.running}, tc.inspectErr)
+			if tc.expectedRestarted {
+				client.EXPECT().CreateContainerExec(gomock.Any(), containerID, gomock.Any(), gomock.Any()).Return(
+					&types.IDResponse{ID: execID}, nil)
+				client.EXPECT().StartContainerExec(gomock.Any(), execID, gomock.Any()).Return(nil)
+				client.EXPECT().InspectContainerExec(gomock.Any(), execID, gomock.Any()).Return(
+					&types.ContainerExecInspect{Running: true}, nil)
+			}
+
+			restarted, err := newTestManager("").RestartAgentIfStopped(context.TODO(), client,
+				&apitask.Task{Arn: taskARN}, container, containerID)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRestarted, restarted)
+		})
+	}
+}
+
+func TestCleanupTask(t *testing.T) {
+	logDir, err := ioutil.TempDir("", "execcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(logDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(logDir, "task-id", "app"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(logDir, "other-task-id", "app"), 0755))
+
+	m := newTestManager("")
+	m.logDir = logDir
+	require.NoError(t, m.CleanupTask("task-id"))
+
+	_, err = os.Stat(filepath.Join(logDir, "task-id"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(logDir, "other-task-id"))
+	assert.NoError(t, err)
+}
+
+func TestVersionNewer(t *testing.T) {
+	assert.True(t, versionNewer("3.0.236.0", "3.0.161.0"))
+	assert.True(t, versionNewer("3.1.0.0", "3.0.999.0"))
+	assert.True(t, versionNewer("3.0.161.1", "3.0.161"))
+	assert.False(t, versionNewer("3.0.161.0", "3.0.161.0"))
+	assert.False(t, versionNewer("2.3.1319.0", "3.0.161.0"))
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/docker/distribution/reference"
+)
+
+// imageRepoDigest returns the digest of the image among the repo digests of
+// the local image, or an empty string if the image was not pulled from its
+// repository, e.g. when it was built or loaded locally
+func imageRepoDigest(image string, repoDigests []string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	for _, repoDigest := range repoDigests {
+		digested, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil || digested.Name() != named.Name() {
+			continue
+		}
+		if canonical, ok := digested.(reference.Canonical); ok {
+			return canonical.Digest().String()
+		}
+	}
+	return ""
+}
+
+// imageDigestReference returns the reference of the image by its digest
+func imageDigestReference(image string, imageDigest string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	return reference.FamiliarName(named) + "@" + imageDigest
+}
+
+// containerImageReference returns the reference the container's image is
+// pulled and tracked by, which is the reference of its digest once the task
+// pinned one
+func containerImageReference(container *apicontainer.Container) string {
+	if imageDigest := container.GetImageDigest(); imageDigest != "" {
+		return imageDigestReference(container.Image, imageDigest)
+	}
+	return container.Image
+}
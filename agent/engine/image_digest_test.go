@@ -0,0 +1,39 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testImageDigest = "sha256:f8fd3a9bd47d0ae2b4d7bae00ba0c7d1ec1e1dca4bd15e1b34a8e67e1c2c4b4d"
+
+func TestImageRepoDigest(t *testing.T) {
+	repoDigests := []string{"busybox@" + testImageDigest}
+
+	assert.Equal(t, testImageDigest, imageRepoDigest("busybox", repoDigests))
+	assert.Equal(t, testImageDigest, imageRepoDigest("docker.io/library/busybox:1.31", repoDigests))
+	assert.Empty(t, imageRepoDigest("alpine", repoDigests))
+	assert.Empty(t, imageRepoDigest("busybox", nil))
+}
+
+func TestImageDigestReference(t *testing.T) {
+	assert.Equal(t, "busybox@"+testImageDigest, imageDigestReference("busybox:latest", testImageDigest))
+	assert.Equal(t, "myregistry.io:5000/app@"+testImageDigest,
+		imageDigestReference("myregistry.io:5000/app:v1", testImageDigest))
+}
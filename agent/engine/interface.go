@@ -42,6 +42,10 @@ type TaskEngine interface {
 	// lifecycle. If it returns an error, the task was not added.
 	AddTask(*apitask.Task)
 
+	// StopTask stops a managed task, given its arn, as if ACS had asked for
+	// it. The reason is reported when the task stops.
+	StopTask(arn string, reason string)
+
 	// ListTasks lists all the tasks being managed by the TaskEngine.
 	ListTasks() ([]*apitask.Task, error)
 
@@ -155,6 +155,16 @@ func (mr *MockTaskEngineMockRecorder) StateChangeEvents() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateChangeEvents", reflect.TypeOf((*MockTaskEngine)(nil).StateChangeEvents))
 }
 
+// StopTask mocks base method
+func (m *MockTaskEngine) StopTask(arg0, arg1 string) {
+	m.ctrl.Call(m, "StopTask", arg0, arg1)
+}
+
+// StopTask indicates an expected call of StopTask
+func (mr *MockTaskEngineMockRecorder) StopTask(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopTask", reflect.TypeOf((*MockTaskEngine)(nil).StopTask), arg0, arg1)
+}
+
 // UnmarshalJSON mocks base method
 func (m *MockTaskEngine) UnmarshalJSON(arg0 []byte) error {
 	ret := m.ctrl.Call(m, "UnmarshalJSON", arg0)
@@ -34,6 +34,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	utilsync "github.com/aws/amazon-ecs-agent/agent/utils/sync"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
 
@@ -50,6 +51,10 @@ const (
 	stoppedSentWaitInterval               = 30 * time.Second
 	maxStoppedWaitTimes                   = 72 * time.Hour / stoppedSentWaitInterval
 	taskUnableToTransitionToStoppedReason = "TaskStateError: Agent could not progress task's state to stopped"
+	// containerOrderingCheckInterval is the interval at which the conditions
+	// of container dependencies are checked again while no event is received,
+	// since health status changes are not sent to the task manager
+	containerOrderingCheckInterval = 5 * time.Second
 )
 
 var (
@@ -148,6 +153,12 @@ type managedTask struct {
 	_time     ttime.Time
 	_timeOnce sync.Once
 
+	// shutdownOrderDeadline is the time after which the containers of the
+	// task are stopped without waiting for the containers depending on them
+	// to stop first. It is set when the task first waits for the shutdown
+	// order.
+	shutdownOrderDeadline time.Time
+
 	// steadyStatePollInterval is the duration that a managed task waits
 	// once the task gets into steady state before polling the state of all of
 	// the task's containers to re-evaluate if the task is still in steady state
@@ -163,21 +174,21 @@ type managedTask struct {
 func (engine *DockerTaskEngine) newManagedTask(task *apitask.Task) *managedTask {
 	ctx, cancel := context.WithCancel(engine.ctx)
 	t := &managedTask{
-		ctx:                      ctx,
-		cancel:                   cancel,
-		Task:                     task,
-		acsMessages:              make(chan acsTransition),
-		dockerMessages:           make(chan dockerContainerChange),
-		resourceStateChangeEvent: make(chan resourceStateChange),
+		ctx:                        ctx,
+		cancel:                     cancel,
+		Task:                       task,
+		acsMessages:                make(chan acsTransition),
+		dockerMessages:             make(chan dockerContainerChange),
+		resourceStateChangeEvent:   make(chan resourceStateChange),
 		engine:                     engine,
 		cfg:                        engine.cfg,
 		stateChangeEvents:          engine.stateChangeEvents,
 		containerChangeEventStream: engine.containerChangeEventStream,
-		saver:                   engine.saver,
-		credentialsManager:      engine.credentialsManager,
-		cniClient:               engine.cniClient,
-		taskStopWG:              engine.taskStopGroup,
-		steadyStatePollInterval: engine.taskSteadyStatePollInterval,
+		saver:                      engine.saver,
+		credentialsManager:         engine.credentialsManager,
+		cniClient:                  engine.cniClient,
+		taskStopWG:                 engine.taskStopGroup,
+		steadyStatePollInterval:    engine.taskSteadyStatePollInterval,
 	}
 	engine.managedTasks[task.Arn] = t
 	return t
@@ -187,6 +198,12 @@ func (engine *DockerTaskEngine) newManagedTask(task *apitask.Task) *managedTask
 // loop of receiving messages and attempting to take action based on those
 // messages.
 func (mtask *managedTask) overseeTask() {
+	// The spans of the containers and of the state changes of the task are
+	// children of this span, which ends once the task has been cleaned up
+	taskSpan := tracing.StartRootSpan(mtask.Arn, "Task")
+	taskSpan.SetAttribute("aws.ecs.task.arn", mtask.Arn)
+	taskSpan.SetAttribute("aws.ecs.task.family", mtask.Family)
+	taskSpan.SetAttribute("aws.ecs.task.revision", mtask.Version)
 	// Do a single updatestatus at the beginning to create the container
 	// `desiredstatus`es which are a construct of the engine used only here,
 	// not present on the backend
@@ -246,6 +263,7 @@ func (mtask *managedTask) overseeTask() {
 	// TODO: make this idempotent on agent restart
 	go mtask.releaseIPInIPAM()
 	mtask.cleanupTask(mtask.cfg.TaskCleanupWaitDuration)
+	taskSpan.End(nil)
 }
 
 // emitCurrentStatus emits a container event for every container and a task
@@ -405,6 +423,9 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	// Update the container to be known
 	currentKnownStatus := containerKnownStatus
 	container.SetKnownStatus(event.Status)
+	if event.Status == apicontainerstatus.ContainerStopped {
+		container.SetManagedAgentsStopped()
+	}
 	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
 
 	if event.Error != nil {
@@ -748,7 +769,7 @@ func (mtask *managedTask) progressTask() {
 		})
 
 	if !anyContainerTransition && !anyResourceTransition {
-		if !mtask.waitForExecutionCredentialsFromACS(reasons) {
+		if !mtask.waitForExecutionCredentialsFromACS(reasons) && !mtask.waitForContainerOrdering(reasons) {
 			mtask.onContainersUnableToTransitionState()
 		}
 		return
@@ -801,6 +822,25 @@ func (mtask *managedTask) waitForExecutionCredentialsFromACS(reasons []error) bo
 	return false
 }
 
+// waitForContainerOrdering checks if the container that can't be transitioned
+// waits for other containers of the task to meet the conditions it depends on,
+// or to stop before it is stopped, and waits for them to change
+func (mtask *managedTask) waitForContainerOrdering(reasons []error) bool {
+	for _, reason := range reasons {
+		if reason == dependencygraph.ErrContainerOrderingNotResolved ||
+			reason == dependencygraph.ErrShutdownOrderNotResolved {
+			seelog.Debugf("Managed task [%s]: waiting for container ordering dependencies", mtask.Arn)
+
+			timeoutCtx, timeoutCancel := context.WithTimeout(mtask.ctx, containerOrderingCheckInterval)
+			defer timeoutCancel()
+
+			mtask.waitEvent(timeoutCtx.Done())
+			return true
+		}
+	}
+	return false
+}
+
 // startContainerTransitions steps through each container in the task and calls
 // the passed transition function when a transition should occur.
 func (mtask *managedTask) startContainerTransitions(transitionFunc containerTransitionFunc) (bool, map[string]apicontainerstatus.ContainerStatus, []error) {
@@ -957,7 +997,8 @@ func (mtask *managedTask) containerNextState(container *apicontainer.Container)
 		}
 	}
 	if err := dependencygraph.DependenciesAreResolved(container, mtask.Containers,
-		mtask.Task.GetExecutionCredentialsID(), mtask.credentialsManager, mtask.GetResources()); err != nil {
+		mtask.Task.GetExecutionCredentialsID(), mtask.credentialsManager, mtask.GetResources()); err != nil &&
+		!mtask.shutdownOrderTimedOut(err) {
 		seelog.Debugf("Managed task [%s]: can't apply state to container [%s] yet due to unresolved dependencies: %v",
 			mtask.Arn, container.Name, err)
 		return &containerTransition{
@@ -1001,6 +1042,39 @@ func (mtask *managedTask) containerNextState(container *apicontainer.Container)
 	}
 }
 
+// shutdownOrderTimedOut returns true if the error is the container waiting
+// for the containers depending on it to stop, and the task has waited for the
+// shutdown order longer than it takes to stop each of its containers in turn
+func (mtask *managedTask) shutdownOrderTimedOut(err error) bool {
+	if err != dependencygraph.ErrShutdownOrderNotResolved {
+		return false
+	}
+	now := mtask.time().Now()
+	if mtask.shutdownOrderDeadline.IsZero() {
+		mtask.shutdownOrderDeadline = now.Add(mtask.taskStopTimeout())
+		return false
+	}
+	if now.Before(mtask.shutdownOrderDeadline) {
+		return false
+	}
+	seelog.Warnf("Managed task [%s]: timed out waiting for containers to stop in dependency order", mtask.Arn)
+	return true
+}
+
+// taskStopTimeout returns the time to stop every container of the task one
+// after another
+func (mtask *managedTask) taskStopTimeout() time.Duration {
+	var timeout time.Duration
+	for _, container := range mtask.Containers {
+		containerTimeout := container.GetStopTimeout()
+		if containerTimeout <= 0 {
+			containerTimeout = mtask.cfg.DockerStopTimeout
+		}
+		timeout += containerTimeout
+	}
+	return timeout
+}
+
 func (mtask *managedTask) resourceNextState(resource taskresource.TaskResource) *resourceTransition {
 	if resource.DesiredTerminal() {
 		nextState := resource.TerminalStatus()
@@ -1319,6 +1319,65 @@ func TestTaskWaitForExecutionCredentials(t *testing.T) {
 	}
 }
 
+func TestTaskWaitForContainerOrdering(t *testing.T) {
+	tcs := []struct {
+		errs   []error
+		result bool
+		msg    string
+	}{
+		{
+			errs: []error{
+				dependencygraph.ErrContainerOrderingNotResolved,
+				dependencygraph.ContainerPastDesiredStatusErr,
+			},
+			result: true,
+			msg:    "managed task should wait while a container waits for its container ordering dependencies",
+		},
+		{
+			errs: []error{
+				dependencygraph.ErrShutdownOrderNotResolved,
+				dependencygraph.ContainerPastDesiredStatusErr,
+			},
+			result: true,
+			msg:    "managed task should wait while a container waits for its dependents to stop",
+		},
+		{
+			errs: []error{
+				dependencygraph.ErrContainerOrderingNotResolvable,
+				dependencygraph.ContainerPastDesiredStatusErr,
+			},
+			result: false,
+			msg:    "managed task should not wait for container ordering dependencies that cannot be resolved",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%v", tc.errs), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockTime := mock_ttime.NewMockTime(ctrl)
+			mockTimer := mock_ttime.NewMockTimer(ctrl)
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			task := &managedTask{
+				ctx: ctx,
+				Task: &apitask.Task{
+					KnownStatusUnsafe:   apitaskstatus.TaskRunning,
+					DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+				},
+				_time:       mockTime,
+				acsMessages: make(chan acsTransition),
+			}
+			if tc.result {
+				mockTime.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(mockTimer)
+				mockTimer.EXPECT().Stop()
+				go func() { task.acsMessages <- acsTransition{desiredStatus: apitaskstatus.TaskRunning} }()
+			}
+
+			assert.Equal(t, tc.result, task.waitForContainerOrdering(tc.errs), tc.msg)
+		})
+	}
+}
+
 func TestCleanupTaskWithInvalidInterval(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockTime := mock_ttime.NewMockTime(ctrl)
@@ -1906,6 +1965,61 @@ func getTestConfig() config.Config {
 // TestContainerNextStateDependsStoppedContainer tests the container that has
 // dependency on other containers' stopped status should wait for other container
 // to be stopped before it can be stopped
+func TestContainerNextStateShutdownOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockTime := mock_ttime.NewMockTime(ctrl)
+
+	sidecar := &apicontainer.Container{
+		Name:                "sidecar",
+		KnownStatusUnsafe:   apicontainerstatus.ContainerRunning,
+		DesiredStatusUnsafe: apicontainerstatus.ContainerStopped,
+		StopTimeout:         10,
+	}
+	app := &apicontainer.Container{
+		Name:                "app",
+		KnownStatusUnsafe:   apicontainerstatus.ContainerRunning,
+		DesiredStatusUnsafe: apicontainerstatus.ContainerStopped,
+		DependsOn:           []apicontainer.DependsOn{{ContainerName: "sidecar", Condition: apicontainer.DependsOnConditionStart}},
+	}
+	mtask := managedTask{
+		Task: &apitask.Task{
+			Arn:        "task1",
+			Containers: []*apicontainer.Container{sidecar, app},
+		},
+		engine: &DockerTaskEngine{},
+		cfg:    &config.Config{DockerStopTimeout: 30 * time.Second},
+		_time:  mockTime,
+	}
+
+	transition := mtask.containerNextState(app)
+	assert.True(t, transition.actionRequired)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, transition.nextState)
+
+	// The sidecar waits for the app to stop, until the task stop deadline
+	// of the stop timeouts of both containers has passed
+	now := time.Now()
+	mockTime.EXPECT().Now().Return(now)
+	transition = mtask.containerNextState(sidecar)
+	assert.Equal(t, dependencygraph.ErrShutdownOrderNotResolved, transition.reason)
+	assert.Equal(t, now.Add(40*time.Second), mtask.shutdownOrderDeadline)
+
+	mockTime.EXPECT().Now().Return(now.Add(39 * time.Second))
+	transition = mtask.containerNextState(sidecar)
+	assert.Equal(t, dependencygraph.ErrShutdownOrderNotResolved, transition.reason)
+
+	mockTime.EXPECT().Now().Return(now.Add(40 * time.Second))
+	transition = mtask.containerNextState(sidecar)
+	assert.NoError(t, transition.reason)
+	assert.True(t, transition.actionRequired)
+
+	app.SetKnownStatus(apicontainerstatus.ContainerStopped)
+	mtask.shutdownOrderDeadline = time.Time{}
+	transition = mtask.containerNextState(sidecar)
+	assert.NoError(t, transition.reason)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, transition.nextState)
+}
+
 func TestContainerNextStateDependsStoppedContainer(t *testing.T) {
 	testCases := []struct {
 		// Known status of the dependent container
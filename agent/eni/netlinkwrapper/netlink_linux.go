@@ -21,6 +21,8 @@ import "github.com/vishvananda/netlink"
 type NetLink interface {
 	LinkByName(name string) (netlink.Link, error)
 	LinkList() ([]netlink.Link, error)
+	LinkByIndex(index int) (netlink.Link, error)
+	RouteList(link netlink.Link, family int) ([]netlink.Route, error)
 }
 
 // NetLinkClient helps invoke the actual netlink methods
@@ -40,3 +42,13 @@ func (NetLinkClient) LinkByName(name string) (netlink.Link, error) {
 func (NetLinkClient) LinkList() ([]netlink.Link, error) {
 	return netlink.LinkList()
 }
+
+// LinkByIndex finds a link by index and returns a pointer to the object
+func (NetLinkClient) LinkByIndex(index int) (netlink.Link, error) {
+	return netlink.LinkByIndex(index)
+}
+
+// RouteList gets a list of routes in the system. Equivalent to: `ip route show`
+func (NetLinkClient) RouteList(link netlink.Link, family int) ([]netlink.Route, error) {
+	return netlink.RouteList(link, family)
+}
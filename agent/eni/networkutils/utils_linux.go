@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/netlinkwrapper"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
 
 	"github.com/cihub/seelog"
 )
@@ -127,6 +128,28 @@ func (retriever *macAddressRetriever) retrieveOnce() error {
 	return nil
 }
 
+// GetPrimaryInterfaceMTU returns the MTU of the primary network interface of
+// the host, which is the interface that the default IPv4 route goes through
+func GetPrimaryInterfaceMTU(netlinkClient netlinkwrapper.NetLink) (int, error) {
+	routes, err := netlinkClient.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return 0, errors.Wrap(err, "primary interface mtu: unable to list routes")
+	}
+	for _, route := range routes {
+		// The default route has no destination
+		if route.Dst != nil || route.LinkIndex <= 0 {
+			continue
+		}
+		link, err := netlinkClient.LinkByIndex(route.LinkIndex)
+		if err != nil {
+			return 0, errors.Wrapf(err, "primary interface mtu: unable to find link with index %d",
+				route.LinkIndex)
+		}
+		return link.Attrs().MTU, nil
+	}
+	return 0, errors.New("primary interface mtu: no default route found")
+}
+
 // IsValidNetworkDevice is used to differentiate virtual and physical devices
 // Returns true only for pci or vif interfaces
 func IsValidNetworkDevice(devicePath string) bool {
@@ -135,3 +135,70 @@ func TestIsValidDevicePathTableTest(t *testing.T) {
 		assert.Equal(t, entry.output, status)
 	}
 }
+
+// TestGetPrimaryInterfaceMTU checks that the MTU of the interface with the
+// default route is returned
+func TestGetPrimaryInterfaceMTU(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockNetlink := mock_netlinkwrapper.NewMockNetLink(mockCtrl)
+	_, subnet, _ := net.ParseCIDR("172.31.0.0/20")
+	gomock.InOrder(
+		mockNetlink.EXPECT().RouteList(nil, netlink.FAMILY_V4).Return([]netlink.Route{
+			{LinkIndex: 3, Dst: subnet},
+			{LinkIndex: 2, Gw: net.ParseIP("172.31.0.1")},
+		}, nil),
+		mockNetlink.EXPECT().LinkByIndex(2).Return(
+			&netlink.Device{
+				LinkAttrs: netlink.LinkAttrs{
+					Index: 2,
+					MTU:   9001,
+					Name:  "eth0",
+				},
+			}, nil),
+	)
+	mtu, err := GetPrimaryInterfaceMTU(mockNetlink)
+	assert.NoError(t, err)
+	assert.Equal(t, 9001, mtu)
+}
+
+// TestGetPrimaryInterfaceMTUNoDefaultRoute checks that an error is returned
+// when there is no default route
+func TestGetPrimaryInterfaceMTUNoDefaultRoute(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockNetlink := mock_netlinkwrapper.NewMockNetLink(mockCtrl)
+	_, subnet, _ := net.ParseCIDR("172.31.0.0/20")
+	mockNetlink.EXPECT().RouteList(nil, netlink.FAMILY_V4).Return([]netlink.Route{
+		{LinkIndex: 3, Dst: subnet},
+	}, nil)
+	_, err := GetPrimaryInterfaceMTU(mockNetlink)
+	assert.Error(t, err)
+}
+
+// TestGetPrimaryInterfaceMTURouteListError checks that errors listing the
+// routes are propagated
+func TestGetPrimaryInterfaceMTURouteListError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockNetlink := mock_netlinkwrapper.NewMockNetLink(mockCtrl)
+	mockNetlink.EXPECT().RouteList(nil, netlink.FAMILY_V4).Return(nil, errors.New("error"))
+	_, err := GetPrimaryInterfaceMTU(mockNetlink)
+	assert.Error(t, err)
+}
+
+// TestGetPrimaryInterfaceMTULinkError checks that errors finding the link of
+// the default route are propagated
+func TestGetPrimaryInterfaceMTULinkError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockNetlink := mock_netlinkwrapper.NewMockNetLink(mockCtrl)
+	gomock.InOrder(
+		mockNetlink.EXPECT().RouteList(nil, netlink.FAMILY_V4).Return([]netlink.Route{
+			{LinkIndex: 2},
+		}, nil),
+		mockNetlink.EXPECT().LinkByIndex(2).Return(nil, errors.New("error")),
+	)
+	_, err := GetPrimaryInterfaceMTU(mockNetlink)
+	assert.Error(t, err)
+}
@@ -132,6 +132,65 @@ func TestSendsEventsInvalidParametersEventsRemoved(t *testing.T) {
 	handler.tasksToEvents[taskARN].lock.Unlock()
 }
 
+func TestSendsEventsDuplicateContainerNameEventsRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_api.NewMockECSClient(ctrl)
+	stateManager := statemanager.NewNoopStateManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, stateManager, nil, client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var taskEvents *taskSendableEvents
+
+	taskEvent := taskEvent(taskARN)
+
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(interface{}) {
+		taskEvents = handler.tasksToEvents[taskARN]
+		assert.Equal(t, 1, taskEvents.events.Len())
+		wg.Done()
+	}).Return(&apierrors.DuplicateContainerNameError{TaskARN: taskARN, ContainerName: "container"})
+
+	handler.AddStateChangeEvent(taskEvent, client)
+
+	wg.Wait()
+	// Require the lock to wait for submitFirstEvent to be finished. The event list
+	// may already be removed from the handler as nothing is left to retry
+	taskEvents.lock.Lock()
+	assert.Equal(t, 0, taskEvents.events.Len())
+	taskEvents.lock.Unlock()
+}
+
+func TestSendsEventsUnretriableErrorEventsRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_api.NewMockECSClient(ctrl)
+	stateManager := statemanager.NewNoopStateManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, stateManager, nil, client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Only the rejections known to be permanent drop the event
+	unretriable := apierrors.NewRetriableError(apierrors.NewRetriable(false), errors.New("test"))
+	taskEvent := taskEvent(taskARN)
+
+	gomock.InOrder(
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Return(unretriable).Do(func(interface{}) { wg.Done() }),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Return(nil).Do(func(interface{}) { wg.Done() }),
+	)
+
+	handler.AddStateChangeEvent(taskEvent, client)
+
+	wg.Wait()
+}
+
 func TestSendsEventsConcurrentLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -341,6 +400,35 @@ func TestCleanupTaskEventAfterSubmit(t *testing.T) {
 	}
 }
 
+func TestSubmitQueueDepth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stateManager := statemanager.NewNoopStateManager()
+	client := mock_api.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, stateManager, nil, client)
+	defer cancel()
+
+	// Hold the submissions until the queue depth has been checked
+	release := make(chan struct{})
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(
+		func(change api.TaskStateChange) {
+			<-release
+		}).Times(3)
+
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+	handler.AddStateChangeEvent(taskEvent("taskarn2"), client)
+	assert.Equal(t, 3, handler.SubmitQueueDepth())
+
+	close(release)
+	for handler.SubmitQueueDepth() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func containerEvent(arn string) statechange.Event {
 	return api.ContainerStateChange{TaskArn: arn, ContainerName: "containerName", Status: apicontainerstatus.ContainerRunning, Container: &apicontainer.Container{}}
 }
@@ -575,3 +663,32 @@ func TestSubmitTaskEventsWhenSubmittingTaskStoppedAfterRunning(t *testing.T) {
 	assert.NoError(t, err)
 	wg.Wait()
 }
+
+func TestBatchContainerEventsDedupe(t *testing.T) {
+	handler := &TaskHandler{
+		tasksToContainerStates: make(map[string][]api.ContainerStateChange),
+	}
+
+	container1 := &apicontainer.Container{Name: "c1"}
+	container2 := &apicontainer.Container{Name: "c2"}
+	newContainerEvent := func(container *apicontainer.Container, status apicontainerstatus.ContainerStatus) api.ContainerStateChange {
+		return api.ContainerStateChange{
+			TaskArn:       taskARN,
+			ContainerName: container.Name,
+			Status:        status,
+			Container:     container,
+		}
+	}
+	handler.batchContainerEventUnsafe(newContainerEvent(container1, apicontainerstatus.ContainerRunning))
+	handler.batchContainerEventUnsafe(newContainerEvent(container2, apicontainerstatus.ContainerRunning))
+	// A duplicate of the same transition is collapsed
+	handler.batchContainerEventUnsafe(newContainerEvent(container1, apicontainerstatus.ContainerRunning))
+	// A later transition is batched as well, so that RUNNING is still reported
+	handler.batchContainerEventUnsafe(newContainerEvent(container1, apicontainerstatus.ContainerStopped))
+
+	assert.Equal(t, []api.ContainerStateChange{
+		newContainerEvent(container1, apicontainerstatus.ContainerRunning),
+		newContainerEvent(container2, apicontainerstatus.ContainerRunning),
+		newContainerEvent(container1, apicontainerstatus.ContainerStopped),
+	}, handler.tasksToContainerStates[taskARN])
+}
@@ -19,9 +19,11 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
@@ -76,6 +78,11 @@ type TaskHandler struct {
 	minDrainEventsFrequency time.Duration
 	maxDrainEventsFrequency time.Duration
 
+	// queueDepth counts the events queued across all tasks that have not
+	// been removed from their task's event list yet. It is updated
+	// atomically so that reading it never waits on an in-flight submission
+	queueDepth int64
+
 	state  dockerstate.TaskEngineState
 	client api.ECSClient
 	ctx    context.Context
@@ -219,10 +226,21 @@ func (handler *TaskHandler) taskStateChangesToSend() []api.TaskStateChange {
 	return events
 }
 
-// batchContainerEventUnsafe collects container state change events for a given task arn
+// batchContainerEventUnsafe collects container state change events for a given task arn.
+// A duplicate of an already batched transition of the same container is collapsed,
+// every distinct transition is still reported
 func (handler *TaskHandler) batchContainerEventUnsafe(event api.ContainerStateChange) {
+	events := handler.tasksToContainerStates[event.TaskArn]
+	for i, batched := range events {
+		if batched.Container == event.Container && batched.ContainerName == event.ContainerName &&
+			batched.Status == event.Status {
+			seelog.Infof("TaskHandler: replacing duplicate batched container event with: %s", event.String())
+			events[i] = event
+			return
+		}
+	}
 	seelog.Infof("TaskHandler: batching container event: %s", event.String())
-	handler.tasksToContainerStates[event.TaskArn] = append(handler.tasksToContainerStates[event.TaskArn], event)
+	handler.tasksToContainerStates[event.TaskArn] = append(events, event)
 }
 
 // flushBatchUnsafe attaches the task arn's container events to TaskStateChange event
@@ -318,6 +336,7 @@ func (taskEvents *taskSendableEvents) sendChange(change *sendableEvent,
 	// Add event to the queue
 	seelog.Infof("TaskHandler: Adding event: %s", change.toString())
 	taskEvents.events.PushBack(change)
+	atomic.AddInt64(&handler.queueDepth, 1)
 
 	if !taskEvents.sending {
 		// If a send event is not already in progress, trigger the
@@ -343,6 +362,13 @@ func (taskEvents *taskSendableEvents) submitFirstEvent(handler *TaskHandler, bac
 
 	seelog.Debugf("TaskHandler: Acquired lock, processing event list: : %s", taskEvents.toStringUnsafe())
 
+	// Events may be removed from the list by several paths below; account
+	// for all of them in the handler's queue depth once we're done
+	queued := taskEvents.events.Len()
+	defer func() {
+		atomic.AddInt64(&handler.queueDepth, int64(taskEvents.events.Len()-queued))
+	}()
+
 	if taskEvents.events.Len() == 0 {
 		seelog.Debug("TaskHandler: No events left; not retrying more")
 		taskEvents.sending = false
@@ -356,18 +382,19 @@ func (taskEvents *taskSendableEvents) submitFirstEvent(handler *TaskHandler, bac
 	if event.containerShouldBeSent() {
 		if err := event.send(sendContainerStatusToECS, setContainerChangeSent, "container",
 			handler.client, eventToSubmit, handler.stateSaver, backoff, taskEvents); err != nil {
+			handleUnsendableEvent(err, taskEvents.events, eventToSubmit)
 			return false, err
 		}
 	} else if event.taskShouldBeSent() {
 		if err := event.send(sendTaskStatusToECS, setTaskChangeSent, "task",
 			handler.client, eventToSubmit, handler.stateSaver, backoff, taskEvents); err != nil {
-			handleInvalidParamException(err, taskEvents.events, eventToSubmit)
+			handleUnsendableEvent(err, taskEvents.events, eventToSubmit)
 			return false, err
 		}
 	} else if event.taskAttachmentShouldBeSent() {
 		if err := event.send(sendTaskStatusToECS, setTaskAttachmentSent, "task attachment",
 			handler.client, eventToSubmit, handler.stateSaver, backoff, taskEvents); err != nil {
-			handleInvalidParamException(err, taskEvents.events, eventToSubmit)
+			handleUnsendableEvent(err, taskEvents.events, eventToSubmit)
 			return false, err
 		}
 	} else {
@@ -390,6 +417,13 @@ func (taskEvents *taskSendableEvents) toStringUnsafe() string {
 		taskEvents.taskARN, taskEvents.sending, taskEvents.createdAt.String())
 }
 
+// SubmitQueueDepth returns the number of task and container state changes
+// waiting to be submitted to the backend. It does not take any lock, so it
+// never blocks behind a submission that is being retried
+func (handler *TaskHandler) SubmitQueueDepth() int {
+	return int(atomic.LoadInt64(&handler.queueDepth))
+}
+
 // getTasksToEventsLen returns the length of the tasksToEvents map. It is
 // used only in the test code to ascertain that map has been cleaned up
 func (handler *TaskHandler) getTasksToEventsLen() int {
@@ -399,12 +433,35 @@ func (handler *TaskHandler) getTasksToEventsLen() int {
 	return len(handler.tasksToEvents)
 }
 
+// handleUnsendableEvent removes the event from event queue when submitting it
+// again can not succeed
+func handleUnsendableEvent(err error, events *list.List, eventToSubmit *list.Element) {
+	if handleInvalidParamException(err, events, eventToSubmit) {
+		return
+	}
+	handleDuplicateContainerNameError(err, events, eventToSubmit)
+}
+
 // handleInvalidParamException removes the event from event queue when its parameters are
-// invalid to reduce redundant API call
-func handleInvalidParamException(err error, events *list.List, eventToSubmit *list.Element) {
-	if utils.IsAWSErrorCodeEqual(err, ecs.ErrCodeInvalidParameterException) {
-		event := eventToSubmit.Value.(*sendableEvent)
-		seelog.Warnf("TaskHandler: Event is sent with invalid parameters; just removing: %s", event.toString())
-		events.Remove(eventToSubmit)
+// invalid to reduce redundant API call. It returns true if the event was removed
+func handleInvalidParamException(err error, events *list.List, eventToSubmit *list.Element) bool {
+	if !utils.IsAWSErrorCodeEqual(err, ecs.ErrCodeInvalidParameterException) {
+		return false
 	}
+	event := eventToSubmit.Value.(*sendableEvent)
+	seelog.Warnf("TaskHandler: Event is sent with invalid parameters; just removing: %s", event.toString())
+	events.Remove(eventToSubmit)
+	return true
+}
+
+// handleDuplicateContainerNameError removes the event from event queue when the
+// task state change was rejected for reporting several containers with the
+// same name, which submitting it again can not fix
+func handleDuplicateContainerNameError(err error, events *list.List, eventToSubmit *list.Element) {
+	if _, ok := err.(*apierrors.DuplicateContainerNameError); !ok {
+		return
+	}
+	event := eventToSubmit.Value.(*sendableEvent)
+	seelog.Errorf("TaskHandler: Dropping event rejected with duplicate container names: %s: %v", event.toString(), err)
+	events.Remove(eventToSubmit)
 }
@@ -97,6 +97,14 @@ func (event *sendableEvent) taskShouldBeSent() bool {
 		}
 	}
 
+	// Managed agent event should be sent
+	for _, managedAgentStateChange := range tevent.ManagedAgents {
+		managedAgent, ok := managedAgentStateChange.Container.GetManagedAgentByName(managedAgentStateChange.Name)
+		if ok && managedAgent.SentStatus != managedAgentStateChange.Status {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -208,6 +216,10 @@ func setTaskChangeSent(event *sendableEvent) {
 			container.SetSentStatus(containerStateChange.Status)
 		}
 	}
+	for _, managedAgentStateChange := range event.taskChange.ManagedAgents {
+		managedAgentStateChange.Container.UpdateManagedAgentSentStatus(managedAgentStateChange.Name,
+			managedAgentStateChange.Status)
+	}
 }
 
 // setTaskAttachmentSent sets the event's task attachment object as sent
@@ -21,6 +21,7 @@ import (
 	reflect "reflect"
 
 	ecs "github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	gpu "github.com/aws/amazon-ecs-agent/agent/gpu"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -83,6 +84,18 @@ func (mr *MockGPUManagerMockRecorder) GetGPUIDsUnsafe() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGPUIDsUnsafe", reflect.TypeOf((*MockGPUManager)(nil).GetGPUIDsUnsafe))
 }
 
+// GetGPUs mocks base method
+func (m *MockGPUManager) GetGPUs() []*gpu.GPUInfo {
+	ret := m.ctrl.Call(m, "GetGPUs")
+	ret0, _ := ret[0].([]*gpu.GPUInfo)
+	return ret0
+}
+
+// GetGPUs indicates an expected call of GetGPUs
+func (mr *MockGPUManagerMockRecorder) GetGPUs() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGPUs", reflect.TypeOf((*MockGPUManager)(nil).GetGPUs))
+}
+
 // GetRuntimeVersion mocks base method
 func (m *MockGPUManager) GetRuntimeVersion() string {
 	ret := m.ctrl.Call(m, "GetRuntimeVersion")
@@ -137,6 +150,16 @@ func (mr *MockGPUManagerMockRecorder) SetGPUIDs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGPUIDs", reflect.TypeOf((*MockGPUManager)(nil).SetGPUIDs), arg0)
 }
 
+// SetGPUs mocks base method
+func (m *MockGPUManager) SetGPUs(arg0 []*gpu.GPUInfo) {
+	m.ctrl.Call(m, "SetGPUs", arg0)
+}
+
+// SetGPUs indicates an expected call of SetGPUs
+func (mr *MockGPUManagerMockRecorder) SetGPUs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGPUs", reflect.TypeOf((*MockGPUManager)(nil).SetGPUs), arg0)
+}
+
 // SetRuntimeVersion mocks base method
 func (m *MockGPUManager) SetRuntimeVersion(arg0 string) {
 	m.ctrl.Call(m, "SetRuntimeVersion", arg0)
@@ -36,6 +36,8 @@ type GPUManager interface {
 	GetDevices() []*ecs.PlatformDevice
 	SetDriverVersion(string)
 	GetDriverVersion() string
+	SetGPUs([]*GPUInfo)
+	GetGPUs() []*GPUInfo
 }
 
 // NvidiaGPUManager is used as a wrapper for NVML APIs and implements GPUManager
@@ -43,6 +45,7 @@ type GPUManager interface {
 type NvidiaGPUManager struct {
 	DriverVersion string                `json:"DriverVersion"`
 	GPUIDs        []string              `json:"GPUIDs"`
+	GPUs          []*GPUInfo            `json:"GPUs,omitempty"`
 	GPUDevices    []*ecs.PlatformDevice `json:"-"`
 	lock          sync.RWMutex
 }
@@ -78,6 +81,7 @@ func (n *NvidiaGPUManager) Initialize() error {
 		nvidiaGPUInfo.lock.RUnlock()
 		n.SetGPUIDs(gpuIDs)
 		n.SetDevices()
+		n.SetGPUs(nvidiaGPUInfo.GetGPUs())
 	} else {
 		seelog.Error("Config for GPU support is enabled, but GPU information is not found; continuing without it")
 	}
@@ -153,3 +157,18 @@ func (n *NvidiaGPUManager) GetDevices() []*ecs.PlatformDevice {
 	defer n.lock.RUnlock()
 	return n.GPUDevices
 }
+
+// SetGPUs sets the per-device GPU information
+func (n *NvidiaGPUManager) SetGPUs(gpus []*GPUInfo) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.GPUs = gpus
+}
+
+// GetGPUs returns the per-device GPU information. It's empty if the GPU info
+// file doesn't contain per-device information
+func (n *NvidiaGPUManager) GetGPUs() []*GPUInfo {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	return n.GPUs
+}
@@ -59,6 +59,46 @@ func TestNvidiaGPUManagerInitialize(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(devices, nvidiaGPUManager.GetDevices()))
 }
 
+func TestNvidiaGPUManagerInitializeWithPerDeviceInfo(t *testing.T) {
+	nvidiaGPUManager := NewNvidiaGPUManager()
+	GPUInfoFileExists = func() bool {
+		return true
+	}
+	GetGPUInfoJSON = func() ([]byte, error) {
+		return []byte(`{"DriverVersion":"418.40.04","GPUIDs":["id1","id2"],"GPUs":[` +
+			`{"ID":"id1","MemoryMB":15109,"ComputeCapability":"7.5"},` +
+			`{"ID":"id2","MemoryMB":32510,"ComputeCapability":"7.0"}]}`), nil
+	}
+	defer func() {
+		GPUInfoFileExists = CheckForGPUInfoFile
+		GetGPUInfoJSON = GetGPUInfo
+	}()
+	err := nvidiaGPUManager.Initialize()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id1", "id2"}, nvidiaGPUManager.GetGPUIDsUnsafe())
+	assert.Equal(t, []*GPUInfo{
+		{ID: "id1", MemoryMB: 15109, ComputeCapability: "7.5"},
+		{ID: "id2", MemoryMB: 32510, ComputeCapability: "7.0"},
+	}, nvidiaGPUManager.GetGPUs())
+}
+
+func TestNvidiaGPUManagerInitializeWithoutPerDeviceInfo(t *testing.T) {
+	nvidiaGPUManager := NewNvidiaGPUManager()
+	GPUInfoFileExists = func() bool {
+		return true
+	}
+	GetGPUInfoJSON = func() ([]byte, error) {
+		return []byte(`{"DriverVersion":"396.44","GPUIDs":["id1"]}`), nil
+	}
+	defer func() {
+		GPUInfoFileExists = CheckForGPUInfoFile
+		GetGPUInfoJSON = GetGPUInfo
+	}()
+	err := nvidiaGPUManager.Initialize()
+	assert.NoError(t, err)
+	assert.Empty(t, nvidiaGPUManager.GetGPUs())
+}
+
 func TestNvidiaGPUManagerError(t *testing.T) {
 	nvidiaGPUManager := NewNvidiaGPUManager()
 	GPUInfoFileExists = func() bool {
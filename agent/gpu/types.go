@@ -0,0 +1,26 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gpu
+
+// GPUInfo contains the per-device information of a GPU used for placement. It
+// is read from the GPUs listed in the GPU info file, which ecs-init writes from
+// NVML; the agent does not query NVML itself
+type GPUInfo struct {
+	// ID is the UUID of the GPU, which is also present in GPUIDs
+	ID string `json:"ID"`
+	// MemoryMB is the total memory of the GPU in MiB
+	MemoryMB int64 `json:"MemoryMB"`
+	// ComputeCapability is the CUDA compute capability of the GPU, e.g. "7.5"
+	ComputeCapability string `json:"ComputeCapability"`
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/cihub/seelog"
+)
+
+const bearerAuthPrefix = "Bearer "
+
+// AccessControlHandler rejects the requests that do not come from an allowed
+// source network or that do not present the expected bearer token, so that the
+// metadata and stats of the instance are only available to trusted clients.
+type AccessControlHandler struct {
+	h            http.Handler
+	allowedCIDRs []*net.IPNet
+	authToken    string
+}
+
+// NewAccessControlHandler creates a new AccessControlHandler object from the
+// introspection server configuration.
+func NewAccessControlHandler(handler http.Handler, cfg *config.Config) AccessControlHandler {
+	allowedCIDRs := make([]*net.IPNet, 0, len(cfg.IntrospectionAllowedCIDRs))
+	for _, cidr := range cfg.IntrospectionAllowedCIDRs {
+		ipNet := net.IPNet(cidr)
+		allowedCIDRs = append(allowedCIDRs, &ipNet)
+	}
+	return AccessControlHandler{
+		h:            handler,
+		allowedCIDRs: allowedCIDRs,
+		authToken:    cfg.IntrospectionAuthToken,
+	}
+}
+
+// ServeHTTP passes the request through to the handler if it is allowed.
+func (ach AccessControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !ach.sourceAllowed(r) {
+		seelog.Warnf("Rejected http request from %s: source not allowed", r.RemoteAddr)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if !ach.authorized(r) {
+		seelog.Warnf("Rejected http request from %s: missing or invalid bearer token", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	ach.h.ServeHTTP(w, r)
+}
+
+func (ach AccessControlHandler) sourceAllowed(r *http.Request) bool {
+	if len(ach.allowedCIDRs) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, allowedCIDR := range ach.allowedCIDRs {
+		if allowedCIDR.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ach AccessControlHandler) authorized(r *http.Request) bool {
+	if ach.authToken == "" {
+		return true
+	}
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, bearerAuthPrefix) {
+		return false
+	}
+	token := strings.TrimPrefix(authorization, bearerAuthPrefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(ach.authToken)) == 1
+}
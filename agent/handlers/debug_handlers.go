@@ -0,0 +1,86 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// debugPprofPath is the path of the pprof profiles of the agent
+	debugPprofPath = "/debug/pprof/"
+	// debugStacksPath is the path of the dump of the goroutine stacks
+	debugStacksPath = "/debug/stacks"
+	// debugWriteTimeout is the write timeout of the CPU profile and execution
+	// trace endpoints, so that they can be collected for up to a minute
+	debugWriteTimeout = 2 * time.Minute
+)
+
+// debugEndpointsAllowed returns true if the debug endpoints are enabled and
+// can't be reached by anyone else than the operator of the instance, that is
+// the introspection server is bound to a loopback address or requires a
+// bearer token
+func debugEndpointsAllowed(cfg *config.Config) bool {
+	if !cfg.DebugEndpointsEnabled {
+		return false
+	}
+	if cfg.IntrospectionAuthToken != "" {
+		return true
+	}
+	if ip := net.ParseIP(cfg.IntrospectionBindAddress); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	seelog.Warn("Not serving the debug endpoints: the introspection server must be bound to a loopback address " +
+		"or require an auth token to serve them")
+	return false
+}
+
+// debugHandlersSetup adds the debug handlers that respond within the write
+// timeout of the introspection server to the server mux
+func debugHandlersSetup(serverMux *http.ServeMux) {
+	serverMux.HandleFunc(debugPprofPath, pprof.Index)
+	serverMux.HandleFunc(debugPprofPath+"cmdline", pprof.Cmdline)
+	serverMux.HandleFunc(debugPprofPath+"symbol", pprof.Symbol)
+	serverMux.HandleFunc(debugStacksPath, stacksHandler)
+}
+
+// debugProfileHandlersSetup adds the handlers of the profiles that are
+// collected over a duration, which need the longer debug write timeout, to
+// the server mux
+func debugProfileHandlersSetup(serverMux *http.ServeMux) {
+	serverMux.HandleFunc(debugPprofPath+"profile", pprof.Profile)
+	serverMux.HandleFunc(debugPprofPath+"trace", pprof.Trace)
+}
+
+// stacksHandler writes the stacks of all the goroutines of the agent
+func stacksHandler(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf)
+}
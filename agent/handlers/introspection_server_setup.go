@@ -16,6 +16,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"strconv"
 	"sync"
@@ -25,16 +26,29 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	handlersutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	"github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/cihub/seelog"
 )
 
+// metricsPath is the path of the Prometheus scrape endpoint
+const metricsPath = "/metrics"
+
 type rootResponse struct {
 	AvailableCommands []string
 }
 
-func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersutils.DockerStateResolver, cfg *config.Config) *http.Server {
-	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.LicensePath}
+func introspectionServerSetup(containerInstanceArn *string,
+	taskEngine handlersutils.DockerStateResolver,
+	statsEngine stats.Engine,
+	healthChecks map[string]v1.HealthCheck,
+	cfg *config.Config) *http.Server {
+	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.ContainerStatsPath, v1.HealthPath, v1.LicensePath}
+	debugEnabled := debugEndpointsAllowed(cfg)
+	if debugEnabled {
+		paths = append(paths, debugPprofPath, debugStacksPath)
+	}
 	availableCommands := &rootResponse{paths}
 	// Autogenerated list of the above serverFunctions paths
 	availableCommandResponse, _ := json.Marshal(&availableCommands)
@@ -46,17 +60,34 @@ func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersu
 	serverMux := http.NewServeMux()
 	serverMux.HandleFunc("/", defaultHandler)
 
-	v1HandlersSetup(serverMux, containerInstanceArn, taskEngine, cfg)
+	v1HandlersSetup(serverMux, containerInstanceArn, taskEngine, statsEngine, healthChecks, cfg)
+	if metrics.MetricsEngineGlobal.Enabled() {
+		// Prometheus metrics are also served on the introspection port, so that
+		// they can be scraped without exposing another port
+		serverMux.Handle(metricsPath, metrics.MetricsEngineGlobal.Handler())
+	}
+	handler := http.Handler(serverMux)
+	serverWriteTimeout := writeTimeout
+	if debugEnabled {
+		debugHandlersSetup(serverMux)
+		// Only the profiles collected over a duration get the longer write
+		// timeout, the other endpoints keep the default one
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/", http.TimeoutHandler(serverMux, writeTimeout, ""))
+		debugProfileHandlersSetup(debugMux)
+		handler = debugMux
+		serverWriteTimeout = debugWriteTimeout
+	}
 
-	// Log all requests and then pass through to serverMux
+	// Log all requests and then pass the allowed ones through to the handler
 	loggingServeMux := http.NewServeMux()
-	loggingServeMux.Handle("/", LoggingHandler{serverMux})
+	loggingServeMux.Handle("/", LoggingHandler{NewAccessControlHandler(handler, cfg)})
 
 	server := &http.Server{
-		Addr:         ":" + strconv.Itoa(config.AgentIntrospectionPort),
+		Addr:         net.JoinHostPort(cfg.IntrospectionBindAddress, strconv.Itoa(config.AgentIntrospectionPort)),
 		Handler:      loggingServeMux,
 		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
+		WriteTimeout: serverWriteTimeout,
 	}
 
 	return server
@@ -66,21 +97,29 @@ func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersu
 func v1HandlersSetup(serverMux *http.ServeMux,
 	containerInstanceArn *string,
 	taskEngine handlersutils.DockerStateResolver,
+	statsEngine stats.Engine,
+	healthChecks map[string]v1.HealthCheck,
 	cfg *config.Config) {
 	serverMux.HandleFunc(v1.AgentMetadataPath, v1.AgentMetadataHandler(containerInstanceArn, cfg))
 	serverMux.HandleFunc(v1.TaskContainerMetadataPath, v1.TaskContainerMetadataHandler(taskEngine))
+	serverMux.HandleFunc(v1.ContainerStatsPath, v1.ContainerStatsHandler(taskEngine, statsEngine))
+	serverMux.HandleFunc(v1.HealthPath, v1.HealthHandler(healthChecks))
 	serverMux.HandleFunc(v1.LicensePath, v1.LicenseHandler)
 }
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks
 // running on it. "V1" here indicates the hostname version of this server instead
 // of the handler versions, i.e. "V1" server can include "V1" and "V2" handlers.
-func ServeIntrospectionHTTPEndpoint(containerInstanceArn *string, taskEngine engine.TaskEngine, cfg *config.Config) {
+func ServeIntrospectionHTTPEndpoint(containerInstanceArn *string,
+	taskEngine engine.TaskEngine,
+	statsEngine stats.Engine,
+	healthChecks map[string]v1.HealthCheck,
+	cfg *config.Config) {
 	// Is this the right level to type assert, assuming we'd abstract multiple taskengines here?
 	// Revisit if we ever add another type..
 	dockerTaskEngine := taskEngine.(*engine.DockerTaskEngine)
 
-	server := introspectionServerSetup(containerInstanceArn, dockerTaskEngine, cfg)
+	server := introspectionServerSetup(containerInstanceArn, dockerTaskEngine, statsEngine, healthChecks, cfg)
 	for {
 		once := sync.Once{}
 		retry.RetryWithBackoff(retry.NewExponentialBackoff(time.Second, time.Minute, 0.2, 2), func() error {
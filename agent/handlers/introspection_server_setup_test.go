@@ -17,6 +17,8 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -30,8 +32,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -408,7 +415,7 @@ func performMockRequest(t *testing.T, path string) *httptest.ResponseRecorder {
 	stateSetupHelper(state, testTasks)
 
 	mockStateResolver.EXPECT().State().Return(state)
-	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, &config.Config{Cluster: testClusterArn})
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, &config.Config{Cluster: testClusterArn})
 
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", path, nil)
@@ -416,3 +423,270 @@ func performMockRequest(t *testing.T, path string) *httptest.ResponseRecorder {
 
 	return recorder
 }
+
+func performMockStatsRequest(t *testing.T, path string, setStatsExpectations func(*mock_stats.MockEngine)) *httptest.ResponseRecorder {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+
+	state := dockerstate.NewTaskEngineState()
+	stateSetupHelper(state, testTasks)
+
+	mockStateResolver.EXPECT().State().Return(state)
+	setStatsExpectations(statsEngine)
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, statsEngine, nil, &config.Config{Cluster: testClusterArn})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestListContainerStats(t *testing.T) {
+	recorder := performMockStatsRequest(t, v1.ContainerStatsPath, func(statsEngine *mock_stats.MockEngine) {
+		statsEngine.EXPECT().ContainerDockerStats(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(taskARN string, dockerID string) (*types.StatsJSON, error) {
+				if dockerID == "dockerid-task2-foo" {
+					return nil, errors.New("no stats yet")
+				}
+				return &types.StatsJSON{Stats: types.Stats{NumProcs: 1}}, nil
+			}).Times(7)
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var statsResponse map[string]*types.StatsJSON
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &statsResponse))
+	assert.Len(t, statsResponse, 6, "Containers without stats should be omitted")
+	assert.NotContains(t, statsResponse, "dockerid-task2-foo")
+	assert.Equal(t, uint32(1), statsResponse["dockerid-task1-one"].NumProcs)
+}
+
+func TestGetContainerStatsByShortDockerID(t *testing.T) {
+	recorder := performMockStatsRequest(t, v1.ContainerStatsPath+"?dockerid=dockerid-by", func(statsEngine *mock_stats.MockEngine) {
+		statsEngine.EXPECT().ContainerDockerStats("byShortId", "dockerid-byShortId-shortId").Return(
+			&types.StatsJSON{Stats: types.Stats{NumProcs: 2}}, nil)
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var dockerStats types.StatsJSON
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &dockerStats))
+	assert.Equal(t, uint32(2), dockerStats.NumProcs)
+}
+
+func TestGetContainerStatsByDockerIDErrors(t *testing.T) {
+	recorder := performMockStatsRequest(t, v1.ContainerStatsPath+"?dockerid=notfound", func(*mock_stats.MockEngine) {})
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Expected http 404 for unknown dockerid")
+
+	recorder = performMockStatsRequest(t, v1.ContainerStatsPath+"?dockerid=dockerid-tas", func(*mock_stats.MockEngine) {})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "Expected http 400 for dockerid with multiple matches")
+
+	recorder = performMockStatsRequest(t, v1.ContainerStatsPath+"?dockerid=dockerid-task2-foo", func(statsEngine *mock_stats.MockEngine) {
+		statsEngine.EXPECT().ContainerDockerStats("task2", "dockerid-task2-foo").Return(nil, errors.New("no stats yet"))
+	})
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Expected http 404 for container without stats")
+}
+
+func TestHealthHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	checks := map[string]v1.HealthCheck{
+		"docker": func() error { return nil },
+		"acs":    func() error { return errors.New("not connected") },
+	}
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, checks, &config.Config{Cluster: testClusterArn})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v1.HealthPath, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	var healthResponse v1.HealthResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &healthResponse))
+	assert.False(t, healthResponse.Healthy)
+	assert.Equal(t, v1.HealthCheckResponse{Healthy: true}, healthResponse.Checks["docker"])
+	assert.Equal(t, v1.HealthCheckResponse{Healthy: false, Error: "not connected"}, healthResponse.Checks["acs"])
+
+	delete(checks, "acs")
+	recorder = httptest.NewRecorder()
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestIntrospectionServerDebugEndpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	cfg := &config.Config{
+		Cluster:                  testClusterArn,
+		DebugEndpointsEnabled:    true,
+		IntrospectionBindAddress: "127.0.0.1",
+	}
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, cfg)
+	assert.Equal(t, debugWriteTimeout, requestHandler.WriteTimeout)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	var rootResp rootResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rootResp))
+	assert.Contains(t, rootResp.AvailableCommands, debugPprofPath)
+	assert.Contains(t, rootResp.AvailableCommands, debugStacksPath)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", debugStacksPath, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "goroutine ")
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", debugPprofPath+"goroutine?debug=1", nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "goroutine profile")
+}
+
+func TestIntrospectionServerDebugEndpointsWriteTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	cfg := &config.Config{
+		Cluster:                testClusterArn,
+		DebugEndpointsEnabled:  true,
+		IntrospectionAuthToken: "token",
+	}
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, cfg)
+	handler := requestHandler.Handler.(*http.ServeMux)
+
+	// Only the profiles collected over a duration are served without the
+	// default write timeout
+	for _, path := range []string{debugPprofPath + "profile", debugPprofPath + "trace"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		h, _ := handler.Handler(req)
+		debugMux := h.(LoggingHandler).h.(AccessControlHandler).h.(*http.ServeMux)
+		_, pattern := debugMux.Handler(req)
+		assert.Equal(t, path, pattern)
+	}
+	req, _ := http.NewRequest("GET", debugStacksPath, nil)
+	h, _ := handler.Handler(req)
+	debugMux := h.(LoggingHandler).h.(AccessControlHandler).h.(*http.ServeMux)
+	_, pattern := debugMux.Handler(req)
+	assert.Equal(t, "/", pattern)
+}
+
+func TestIntrospectionServerDebugEndpointsRequireProtection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	// Bound to all addresses without an auth token
+	cfg := &config.Config{Cluster: testClusterArn, DebugEndpointsEnabled: true}
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, cfg)
+	assert.Equal(t, writeTimeout, requestHandler.WriteTimeout)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", debugPprofPath+"cmdline", nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	var rootResp rootResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rootResp))
+	assert.NotContains(t, rootResp.AvailableCommands, debugPprofPath)
+}
+
+func TestIntrospectionServerDebugEndpointsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, &config.Config{Cluster: testClusterArn})
+	assert.Equal(t, writeTimeout, requestHandler.WriteTimeout)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", debugStacksPath, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+	assert.NotContains(t, recorder.Body.String(), "goroutine ")
+}
+
+func TestIntrospectionServerAccessControl(t *testing.T) {
+	_, allowedCIDR, _ := net.ParseCIDR("127.0.0.0/8")
+	cfg := &config.Config{
+		Cluster:                   testClusterArn,
+		IntrospectionBindAddress:  "127.0.0.1",
+		IntrospectionAllowedCIDRs: []cnitypes.IPNet{{IP: allowedCIDR.IP, Mask: allowedCIDR.Mask}},
+		IntrospectionAuthToken:    "token",
+	}
+
+	testCases := []struct {
+		name          string
+		remoteAddr    string
+		authorization string
+		expectedCode  int
+	}{
+		{"allowed source and token", "127.0.0.1:1234", "Bearer token", http.StatusOK},
+		{"source not allowed", "172.17.0.2:1234", "Bearer token", http.StatusForbidden},
+		{"missing token", "127.0.0.1:1234", "", http.StatusUnauthorized},
+		{"wrong token", "127.0.0.1:1234", "Bearer other", http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+			requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, cfg)
+			assert.Equal(t, "127.0.0.1:51678", requestHandler.Addr)
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", v1.AgentMetadataPath, nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			requestHandler.Handler.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestIntrospectionServerServesMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() {
+		metrics.MetricsEngineGlobal = &metrics.MetricsEngine{}
+	}()
+
+	cfg := &config.Config{Cluster: testClusterArn, PrometheusMetricsEnabled: true}
+	metrics.MustInit(cfg, prometheus.NewRegistry())
+	metrics.MetricsEngineGlobal.RecordDockerError("PULL_IMAGE", errors.New("pull failed"))
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	mockStateResolver.EXPECT().State().AnyTimes()
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, cfg)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", metricsPath, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `AgentMetrics_DockerAPI_error_count{Call="PULL_IMAGE"} 1`)
+}
+
+func TestIntrospectionServerMetricsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	mockStateResolver.EXPECT().State().AnyTimes()
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, &config.Config{Cluster: testClusterArn})
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", metricsPath, nil)
+	requestHandler.Handler.ServeHTTP(recorder, req)
+
+	assert.NotContains(t, recorder.Body.String(), "AgentMetrics")
+}
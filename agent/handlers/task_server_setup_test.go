@@ -43,6 +43,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/handlers/v2"
 	"github.com/aws/amazon-ecs-agent/agent/handlers/v3"
 	mock_audit "github.com/aws/amazon-ecs-agent/agent/logger/audit/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/docker/docker/api/types"
@@ -579,7 +580,7 @@ func TestV2ContainerStats(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_api.NewMockECSClient(ctrl)
 
-	dockerStats := &types.Stats{NumProcs: 2}
+	dockerStats := &types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
 	gomock.InOrder(
 		state.EXPECT().GetTaskByIPAddress(remoteIP).Return(taskARN, true),
 		statsEngine.EXPECT().ContainerDockerStats(taskARN, containerID).Return(dockerStats, nil),
@@ -593,7 +594,7 @@ func TestV2ContainerStats(t *testing.T) {
 	res, err := ioutil.ReadAll(recorder.Body)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, recorder.Code)
-	var statsFromResult *types.Stats
+	var statsFromResult *types.StatsJSON
 	err = json.Unmarshal(res, &statsFromResult)
 	assert.NoError(t, err)
 	assert.Equal(t, dockerStats.NumProcs, statsFromResult.NumProcs)
@@ -621,7 +622,7 @@ func TestV2TaskStats(t *testing.T) {
 			statsEngine := mock_stats.NewMockEngine(ctrl)
 			ecsClient := mock_api.NewMockECSClient(ctrl)
 
-			dockerStats := &types.Stats{NumProcs: 2}
+			dockerStats := &types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
 			containerMap := map[string]*apicontainer.DockerContainer{
 				containerName: {
 					DockerID: containerID,
@@ -641,7 +642,7 @@ func TestV2TaskStats(t *testing.T) {
 			res, err := ioutil.ReadAll(recorder.Body)
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusOK, recorder.Code)
-			var statsFromResult map[string]*types.Stats
+			var statsFromResult map[string]*types.StatsJSON
 			err = json.Unmarshal(res, &statsFromResult)
 			assert.NoError(t, err)
 			containerStats, ok := statsFromResult[containerID]
@@ -786,7 +787,7 @@ func TestV3TaskStats(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_api.NewMockECSClient(ctrl)
 
-	dockerStats := &types.Stats{NumProcs: 2}
+	dockerStats := &types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
 
 	containerMap := map[string]*apicontainer.DockerContainer{
 		containerName: {
@@ -807,7 +808,7 @@ func TestV3TaskStats(t *testing.T) {
 	res, err := ioutil.ReadAll(recorder.Body)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, recorder.Code)
-	var statsFromResult map[string]*types.Stats
+	var statsFromResult map[string]*types.StatsJSON
 	err = json.Unmarshal(res, &statsFromResult)
 	assert.NoError(t, err)
 	containerStats, ok := statsFromResult[containerID]
@@ -815,6 +816,56 @@ func TestV3TaskStats(t *testing.T) {
 	assert.Equal(t, dockerStats.NumProcs, containerStats.NumProcs)
 }
 
+func TestV3TaskNetworkStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	auditLog := mock_audit.NewMockAuditLogger(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+
+	networkStats := &stats.NetworkStats{RxBytes: 100, TxBytes: 200}
+
+	gomock.InOrder(
+		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+		statsEngine.EXPECT().TaskNetworkStats(taskARN).Return(networkStats, nil),
+	)
+	server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/task/stats/network", nil)
+	server.Handler.ServeHTTP(recorder, req)
+	res, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var networkStatsFromResult stats.NetworkStats
+	err = json.Unmarshal(res, &networkStatsFromResult)
+	assert.NoError(t, err)
+	assert.Equal(t, *networkStats, networkStatsFromResult)
+}
+
+func TestV3TaskNetworkStatsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	auditLog := mock_audit.NewMockAuditLogger(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+
+	gomock.InOrder(
+		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+		statsEngine.EXPECT().TaskNetworkStats(taskARN).Return(nil, fmt.Errorf("no network stats")),
+	)
+	server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/task/stats/network", nil)
+	server.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
 func TestV3ContainerStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -824,7 +875,7 @@ func TestV3ContainerStats(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_api.NewMockECSClient(ctrl)
 
-	dockerStats := &types.Stats{NumProcs: 2}
+	dockerStats := &types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
 
 	gomock.InOrder(
 		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
@@ -839,7 +890,7 @@ func TestV3ContainerStats(t *testing.T) {
 	res, err := ioutil.ReadAll(recorder.Body)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, recorder.Code)
-	var statsFromResult *types.Stats
+	var statsFromResult *types.StatsJSON
 	err = json.Unmarshal(res, &statsFromResult)
 	assert.NoError(t, err)
 	assert.Equal(t, dockerStats.NumProcs, statsFromResult.NumProcs)
@@ -41,6 +41,12 @@ const (
 	// RequestTypeContainerStats specifies the container stats request type of StatsHandler.
 	RequestTypeContainerStats = "container stats"
 
+	// RequestTypeTaskNetworkStats specifies the task network stats request type of TaskNetworkStatsHandler.
+	RequestTypeTaskNetworkStats = "task network stats"
+
+	// RequestTypeHealth specifies the health check request type of HealthHandler.
+	RequestTypeHealth = "health"
+
 	// RequestTypeAgentMetadata specifies the Agent metadata request type of AgentMetadataHandler.
 	RequestTypeAgentMetadata = "agent metadata"
 
@@ -0,0 +1,109 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+)
+
+// ContainerStatsPath is the container stats path for v1 handler.
+const ContainerStatsPath = "/v1/stats"
+
+// containerStatsKey identifies a container whose stats are collected
+type containerStatsKey struct {
+	taskARN  string
+	dockerID string
+}
+
+// ContainerStatsHandler creates response for the 'v1/stats' API. Returns the
+// latest docker stats of all the containers, keyed by docker id, if the request
+// doesn't contain any fields. Returns the stats of a single container if
+// 'dockerid' is specified in the request, matching either the full or the short
+// docker id.
+func ContainerStatsHandler(taskEngine utils.DockerStateResolver, statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		containers := listContainers(taskEngine.State())
+		dockerID, dockerIDExists := utils.ValueFromRequest(r, dockerIDQueryField)
+		if !dockerIDExists {
+			responseJSON, _ := json.Marshal(newContainerStatsResponse(containers, statsEngine))
+			utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, utils.RequestTypeContainerStats)
+			return
+		}
+
+		var matches []containerStatsKey
+		for _, container := range containers {
+			if strings.HasPrefix(container.dockerID, dockerID) {
+				matches = append(matches, container)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			seelog.Warn("Could not find requested resource: " + dockerID)
+			responseJSON, _ := json.Marshal("Unable to find container: " + dockerID)
+			utils.WriteJSONToResponse(w, http.StatusNotFound, responseJSON, utils.RequestTypeContainerStats)
+		case 1:
+			dockerStats, err := statsEngine.ContainerDockerStats(matches[0].taskARN, matches[0].dockerID)
+			if err != nil {
+				seelog.Warnf("Unable to get stats for container '%s': %v", matches[0].dockerID, err)
+				responseJSON, _ := json.Marshal("Unable to get stats for container: " + matches[0].dockerID)
+				utils.WriteJSONToResponse(w, http.StatusNotFound, responseJSON, utils.RequestTypeContainerStats)
+				return
+			}
+			responseJSON, _ := json.Marshal(dockerStats)
+			utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, utils.RequestTypeContainerStats)
+		default:
+			seelog.Info("Multiple containers found for requested dockerId: " + dockerID)
+			responseJSON, _ := json.Marshal("Multiple containers found for: " + dockerID)
+			utils.WriteJSONToResponse(w, http.StatusBadRequest, responseJSON, utils.RequestTypeContainerStats)
+		}
+	}
+}
+
+// listContainers returns the containers of all the tasks that have a docker id
+func listContainers(state dockerstate.TaskEngineState) []containerStatsKey {
+	var containers []containerStatsKey
+	for _, task := range state.AllTasks() {
+		containerMap, _ := state.ContainerMapByArn(task.Arn)
+		for _, dockerContainer := range containerMap {
+			if dockerContainer.DockerID == "" {
+				continue
+			}
+			containers = append(containers, containerStatsKey{taskARN: task.Arn, dockerID: dockerContainer.DockerID})
+		}
+	}
+	return containers
+}
+
+// newContainerStatsResponse returns the latest docker stats of the containers.
+// Containers that the stats engine has no stats for yet are omitted
+func newContainerStatsResponse(containers []containerStatsKey, statsEngine stats.Engine) map[string]*types.StatsJSON {
+	resp := make(map[string]*types.StatsJSON)
+	for _, container := range containers {
+		dockerStats, err := statsEngine.ContainerDockerStats(container.taskARN, container.dockerID)
+		if err != nil {
+			seelog.Debugf("Unable to get stats for container '%s': %v", container.dockerID, err)
+			continue
+		}
+		resp[container.dockerID] = dockerStats
+	}
+	return resp
+}
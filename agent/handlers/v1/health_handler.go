@@ -0,0 +1,68 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/cihub/seelog"
+)
+
+// HealthPath is the health check path for v1 handler.
+const HealthPath = "/healthz"
+
+// HealthCheck returns an error when a dependency of the agent is unhealthy.
+type HealthCheck func() error
+
+// HealthResponse is the schema for the health check response JSON object
+type HealthResponse struct {
+	Healthy bool                           `json:"Healthy"`
+	Checks  map[string]HealthCheckResponse `json:"Checks"`
+}
+
+// HealthCheckResponse is the schema for the result of a single health check
+type HealthCheckResponse struct {
+	Healthy bool   `json:"Healthy"`
+	Error   string `json:"Error,omitempty"`
+}
+
+// HealthHandler creates response for the '/healthz' API. It runs all the
+// checks and responds with http 503 if any of them fails, so that it can be
+// used by liveness probes.
+func HealthHandler(checks map[string]HealthCheck) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := HealthResponse{
+			Healthy: true,
+			Checks:  make(map[string]HealthCheckResponse),
+		}
+		for name, check := range checks {
+			if err := check(); err != nil {
+				seelog.Warnf("Health check '%s' failed: %v", name, err)
+				resp.Healthy = false
+				resp.Checks[name] = HealthCheckResponse{Healthy: false, Error: err.Error()}
+				continue
+			}
+			resp.Checks[name] = HealthCheckResponse{Healthy: true}
+		}
+
+		status := http.StatusOK
+		if !resp.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		responseJSON, _ := json.Marshal(resp)
+		utils.WriteJSONToResponse(w, status, responseJSON, utils.RequestTypeHealth)
+	}
+}
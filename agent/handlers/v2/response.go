@@ -54,6 +54,7 @@ type ContainerResponse struct {
 	DockerName    string                      `json:"DockerName"`
 	Image         string                      `json:"Image"`
 	ImageID       string                      `json:"ImageID"`
+	ImageDigest   string                      `json:"ImageDigest,omitempty"`
 	Ports         []v1.PortResponse           `json:"Ports,omitempty"`
 	Labels        map[string]string           `json:"Labels,omitempty"`
 	DesiredStatus string                      `json:"DesiredStatus"`
@@ -191,6 +192,7 @@ func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
 		DockerName:    dockerContainer.DockerName,
 		Image:         container.Image,
 		ImageID:       container.ImageID,
+		ImageDigest:   container.GetImageDigest(),
 		DesiredStatus: container.GetDesiredStatus().String(),
 		KnownStatus:   container.GetKnownStatus().String(),
 		Limits: LimitsResponse{
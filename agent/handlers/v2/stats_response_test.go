@@ -33,7 +33,7 @@ func TestTaskStatsResponseSuccess(t *testing.T) {
 	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 
-	dockerStats := &types.Stats{NumProcs: 2}
+	dockerStats := &types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
 	containerMap := map[string]*apicontainer.DockerContainer{
 		containerName: {
 			DockerID: containerID,
@@ -28,6 +28,9 @@ import (
 // TaskStatsPath specifies the relative URI path for serving task stats.
 var TaskStatsPath = "/v3/" + utils.ConstructMuxVar(v3EndpointIDMuxName, utils.AnythingButSlashRegEx) + "/task/stats"
 
+// TaskNetworkStatsPath specifies the relative URI path for serving task network stats.
+var TaskNetworkStatsPath = TaskStatsPath + "/network"
+
 // TaskStatsHandler returns the handler method for handling task stats requests.
 func TaskStatsHandler(state dockerstate.TaskEngineState, statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -45,3 +48,28 @@ func TaskStatsHandler(state dockerstate.TaskEngineState, statsEngine stats.Engin
 		v2.WriteTaskStatsResponse(w, taskARN, state, statsEngine)
 	}
 }
+
+// TaskNetworkStatsHandler returns the handler method for handling task network stats requests.
+func TaskNetworkStatsHandler(state dockerstate.TaskEngineState, statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, err := getTaskARNByRequest(r, state)
+		if err != nil {
+			errResponseJSON, _ := json.Marshal(
+				fmt.Sprintf("V3 task network stats handler: unable to get task arn from request: %s", err.Error()))
+			utils.WriteJSONToResponse(w, http.StatusBadRequest, errResponseJSON, utils.RequestTypeTaskNetworkStats)
+			return
+		}
+
+		networkStats, err := statsEngine.TaskNetworkStats(taskARN)
+		if err != nil {
+			seelog.Warnf("V3 task network stats handler: unable to get network stats for task '%s': %v", taskARN, err)
+			errResponseJSON, _ := json.Marshal("Unable to get task network stats for: " + taskARN)
+			utils.WriteJSONToResponse(w, http.StatusBadRequest, errResponseJSON, utils.RequestTypeTaskNetworkStats)
+			return
+		}
+
+		seelog.Infof("V3 task network stats handler: writing response for task '%s'", taskARN)
+		responseJSON, _ := json.Marshal(networkStats)
+		utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, utils.RequestTypeTaskNetworkStats)
+	}
+}
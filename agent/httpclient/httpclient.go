@@ -57,6 +57,14 @@ func (client *ecsRoundTripper) CancelRequest(req *http.Request) {
 
 // New returns an ECS httpClient with a roundtrip timeout of the given duration
 func New(timeout time.Duration, insecureSkipVerify bool) *http.Client {
+	return NewWithIdleConnPool(timeout, insecureSkipVerify, 0, 0)
+}
+
+// NewWithIdleConnPool returns an ECS httpClient with a roundtrip timeout of the
+// given duration, which keeps up to maxIdleConns idle connections per host
+// alive for idleConnTimeout so that they are reused by subsequent requests.
+// Zero values keep the defaults of the golang http library
+func NewWithIdleConnPool(timeout time.Duration, insecureSkipVerify bool, maxIdleConns int, idleConnTimeout time.Duration) *http.Client {
 	// Transport is the transport requests will be made over
 	// Note, these defaults are taken from the golang http library. We do not
 	// explicitly do not use theirs to avoid changing their behavior.
@@ -67,6 +75,9 @@ func New(timeout time.Duration, insecureSkipVerify bool) *http.Client {
 			KeepAlive: defaultDialKeepalive,
 		}).Dial,
 		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConns,
+		IdleConnTimeout:     idleConnTimeout,
 	}
 
 	transport.TLSClientConfig = &tls.Config{}
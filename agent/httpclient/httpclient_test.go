@@ -17,6 +17,7 @@ package httpclient
 
 import (
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
@@ -30,3 +31,28 @@ func TestNewHttpClient(t *testing.T) {
 	assert.Equal(t, cipher.SupportedCipherSuites, transport.transport.(*http.Transport).TLSClientConfig.CipherSuites)
 	assert.Equal(t, true, transport.transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
 }
+
+func TestNewHttpClientWithIdleConnPool(t *testing.T) {
+	client := NewWithIdleConnPool(time.Duration(10), false, 5, time.Minute)
+	transport := client.Transport.(*ecsRoundTripper).transport.(*http.Transport)
+	assert.Equal(t, 5, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.Equal(t, cipher.SupportedCipherSuites, transport.TLSClientConfig.CipherSuites)
+}
+
+func TestSetDefaultNoProxy(t *testing.T) {
+	defer os.Setenv("NO_PROXY", os.Getenv("NO_PROXY"))
+
+	os.Unsetenv("NO_PROXY")
+	SetDefaultNoProxy("")
+	assert.Equal(t, "169.254.169.254,169.254.170.2", os.Getenv("NO_PROXY"))
+
+	os.Unsetenv("NO_PROXY")
+	SetDefaultNoProxy("tcp://10.0.0.1:2375")
+	assert.Equal(t, "169.254.169.254,169.254.170.2,//10.0.0.1:2375", os.Getenv("NO_PROXY"))
+
+	os.Setenv("NO_PROXY", "example.com")
+	SetDefaultNoProxy("tcp://10.0.0.1:2375")
+	assert.Equal(t, "example.com", os.Getenv("NO_PROXY"), "NO_PROXY set by the user should not be overridden")
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httpclient
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/cihub/seelog"
+)
+
+// defaultNoProxyIP are the addresses of the EC2 instance metadata service and
+// of the task credentials endpoint, which must never be reached via a proxy
+const defaultNoProxyIP = "169.254.169.254,169.254.170.2"
+
+// SetDefaultNoProxy sets NO_PROXY, unless it is already set, so that the
+// instance metadata, the task credentials endpoint and the docker daemon are
+// reached directly when HTTP_PROXY or HTTPS_PROXY is set. The proxy variables
+// are only read by the first request, so this must be invoked before any
+// request is made
+func SetDefaultNoProxy(dockerEndpoint string) {
+	if os.Getenv("NO_PROXY") != "" {
+		return
+	}
+	if dockerEndpoint == "" {
+		os.Setenv("NO_PROXY", defaultNoProxyIP)
+		seelog.Info("NO_PROXY set:", os.Getenv("NO_PROXY"))
+		return
+	}
+	dockerHost, err := url.Parse(dockerEndpoint)
+	if err != nil {
+		seelog.Errorf("NO_PROXY unable to be set: the configured Docker endpoint is invalid.")
+		return
+	}
+	dockerHost.Scheme = ""
+	os.Setenv("NO_PROXY", defaultNoProxyIP+","+dockerHost.String())
+	seelog.Info("NO_PROXY set:", os.Getenv("NO_PROXY"))
+}
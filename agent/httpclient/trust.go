@@ -0,0 +1,135 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigureTLSTrust makes the TLS config trust the certificate authorities of
+// the PEM encoded CA bundle at caBundlePath, in addition to the system ones,
+// and reject the server certificate chains that contain none of the pinned
+// public keys. A pin is the base64 encoded SHA-256 hash of a DER encoded
+// subject public key info. Empty values leave the TLS config unchanged.
+// If the trust cannot be configured, an error is returned and the TLS config
+// rejects every server, so that the settings are never silently ignored
+func ConfigureTLSTrust(tlsConfig *tls.Config, caBundlePath string, pinnedPublicKeys []string) error {
+	err := configureTLSTrust(tlsConfig, caBundlePath, pinnedPublicKeys)
+	if err != nil {
+		tlsConfig.VerifyPeerCertificate = func([][]byte, [][]*x509.Certificate) error {
+			return err
+		}
+	}
+	return err
+}
+
+// SetTLSTrust configures the TLS trust of an ECS httpClient, see
+// ConfigureTLSTrust
+func SetTLSTrust(client *http.Client, caBundlePath string, pinnedPublicKeys []string) error {
+	roundTripper, ok := client.Transport.(*ecsRoundTripper)
+	if !ok {
+		return errors.New("tls trust: not an ECS http client")
+	}
+	transport, ok := roundTripper.transport.(*http.Transport)
+	if !ok {
+		return errors.New("tls trust: unsupported http transport")
+	}
+	return ConfigureTLSTrust(transport.TLSClientConfig, caBundlePath, pinnedPublicKeys)
+}
+
+func configureTLSTrust(tlsConfig *tls.Config, caBundlePath string, pinnedPublicKeys []string) error {
+	if caBundlePath != "" {
+		roots, err := loadCABundle(caBundlePath)
+		if err != nil {
+			return err
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if len(pinnedPublicKeys) == 0 {
+		return nil
+	}
+	pins, err := parsePublicKeyPins(pinnedPublicKeys)
+	if err != nil {
+		return err
+	}
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		return verifyPublicKeyPins(pins, rawCerts, verifiedChains)
+	}
+	return nil
+}
+
+func loadCABundle(caBundlePath string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "tls trust: unable to read CA bundle %s", caBundlePath)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil || roots == nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("tls trust: no PEM encoded certificate found in CA bundle %s", caBundlePath)
+	}
+	return roots, nil
+}
+
+func parsePublicKeyPins(pinnedPublicKeys []string) ([][]byte, error) {
+	pins := make([][]byte, 0, len(pinnedPublicKeys))
+	for _, pinnedPublicKey := range pinnedPublicKeys {
+		pin, err := base64.StdEncoding.DecodeString(pinnedPublicKey)
+		if err != nil || len(pin) != sha256.Size {
+			return nil, errors.Errorf("tls trust: invalid public key pin %q, expected a base64 encoded SHA-256 hash",
+				pinnedPublicKey)
+		}
+		pins = append(pins, pin)
+	}
+	return pins, nil
+}
+
+// verifyPublicKeyPins checks that a certificate of the verified chains matches
+// one of the pins. The chains are only verified when certificate verification
+// is enabled, otherwise the certificates presented by the server are checked
+func verifyPublicKeyPins(pins [][]byte, rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	var certificates []*x509.Certificate
+	for _, chain := range verifiedChains {
+		certificates = append(certificates, chain...)
+	}
+	if len(verifiedChains) == 0 {
+		for _, rawCert := range rawCerts {
+			certificate, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return errors.Wrap(err, "tls trust: unable to parse server certificate")
+			}
+			certificates = append(certificates, certificate)
+		}
+	}
+
+	for _, certificate := range certificates {
+		hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(hash[:], pin) {
+				return nil
+			}
+		}
+	}
+	return errors.New("tls trust: no pinned public key found in the server certificate chain")
+}
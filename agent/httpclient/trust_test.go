@@ -0,0 +1,121 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTrustTestServer starts a TLS server and writes its certificate as a CA
+// bundle, it returns the server, the path of the bundle and the pin of the
+// server's public key
+func newTrustTestServer(t *testing.T) (*httptest.Server, string, string, func()) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	dir, err := ioutil.TempDir("", "ecs-agent-trust-test")
+	require.NoError(t, err)
+
+	caBundlePath := filepath.Join(dir, "ca-bundle.pem")
+	certificate := server.Certificate()
+	err = ioutil.WriteFile(caBundlePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0600)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return server, caBundlePath, base64.StdEncoding.EncodeToString(hash[:]), func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSetTLSTrust(t *testing.T) {
+	server, caBundlePath, pin, cleanup := newTrustTestServer(t)
+	defer cleanup()
+	otherPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	testCases := []struct {
+		name             string
+		insecure         bool
+		caBundlePath     string
+		pinnedPublicKeys []string
+		expectedSuccess  bool
+	}{
+		{"system roots only", false, "", nil, false},
+		{"ca bundle", false, caBundlePath, nil, true},
+		{"ca bundle and matching pin", false, caBundlePath, []string{otherPin, pin}, true},
+		{"ca bundle and other pin", false, caBundlePath, []string{otherPin}, false},
+		{"insecure and matching pin", true, "", []string{pin}, true},
+		{"insecure and other pin", true, "", []string{otherPin}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(10*time.Second, tc.insecure)
+			require.NoError(t, SetTLSTrust(client, tc.caBundlePath, tc.pinnedPublicKeys))
+
+			resp, err := client.Get(server.URL)
+			if tc.expectedSuccess {
+				require.NoError(t, err)
+				resp.Body.Close()
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigureTLSTrustInvalidSettingsRejectAllServers(t *testing.T) {
+	server, _, _, cleanup := newTrustTestServer(t)
+	defer cleanup()
+
+	testCases := []struct {
+		name             string
+		caBundlePath     string
+		pinnedPublicKeys []string
+	}{
+		{"missing ca bundle", "/does/not/exist.pem", nil},
+		{"invalid pin", "", []string{"not-a-pin"}},
+		{"pin of wrong size", "", []string{base64.StdEncoding.EncodeToString([]byte("short"))}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(10*time.Second, true)
+			assert.Error(t, SetTLSTrust(client, tc.caBundlePath, tc.pinnedPublicKeys))
+
+			_, err := client.Get(server.URL)
+			assert.Error(t, err, "invalid trust settings must not be ignored")
+		})
+	}
+}
+
+func TestConfigureTLSTrustNoSettings(t *testing.T) {
+	tlsConfig := &tls.Config{}
+	assert.NoError(t, ConfigureTLSTrust(tlsConfig, "", nil))
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Nil(t, tlsConfig.VerifyPeerCertificate)
+}
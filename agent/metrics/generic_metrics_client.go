@@ -35,11 +35,13 @@ const (
 // 2) A durations guage vector that updates the last recorded duration for the API call
 // 	  allowing for a time series view in the Prometheus browser
 // 3) A counter vector that increments call counts for each API call
+// 4) A counter vector that increments the counts of failed calls
 // The outstandingCalls map allows Fired CallStarts to be matched with Fired CallEnds
 type GenericMetrics struct {
 	durationVec      *prometheus.SummaryVec
 	durations        *prometheus.GaugeVec
 	counterVec       *prometheus.CounterVec
+	errorCounterVec  *prometheus.CounterVec
 	lock             sync.RWMutex
 	outstandingCalls map[string]time.Time
 }
@@ -134,3 +136,19 @@ func (gm *GenericMetrics) IncrementCallCount(callName string) {
 	defer gm.lock.Unlock()
 	gm.counterVec.WithLabelValues(callName).Inc()
 }
+
+// This function increments the count of failed calls for a specific API call
+func (gm *GenericMetrics) IncrementErrorCount(callName string) {
+	gm.errorCounterVec.WithLabelValues(callName).Inc()
+}
+
+// ObserveCall records the count and the duration of a call that has already
+// completed, and counts it as failed if err is not nil.
+func (gm *GenericMetrics) ObserveCall(callName string, duration time.Duration, err error) {
+	gm.counterVec.WithLabelValues(callName).Inc()
+	gm.durationVec.WithLabelValues(callName).Observe(duration.Seconds())
+	gm.durations.WithLabelValues(callName).Set(duration.Seconds())
+	if err != nil {
+		gm.IncrementErrorCount(callName)
+	}
+}
@@ -52,6 +52,13 @@ type MetricsClient interface {
 	// called. When a FireCallStart(...) is called but its FireCallEnd(...) is not,
 	// we will see a discrepancy between the Summary's count and this Gauge count
 	IncrementCallCount(string)
+
+	// IncrementErrorCount increments the count of failed calls.
+	IncrementErrorCount(string)
+
+	// ObserveCall records a call whose duration is already known, such as
+	// calls timed by the AWS SDK, and counts it as failed if err is not nil.
+	ObserveCall(callName string, duration time.Duration, err error)
 }
 
 // MetricsEngine_ is an interface that drives metric collection over
@@ -32,6 +32,7 @@ type MetricsEngine struct {
 	ctx            context.Context
 	Registry       *prometheus.Registry
 	managedMetrics map[APIType]MetricsClient
+	acsReconnects  *prometheus.CounterVec
 }
 
 const (
@@ -93,6 +94,13 @@ func NewMetricsEngine(cfg *config.Config, registry *prometheus.Registry) *Metric
 		aClient := NewMetricsClient(managedAPI, metricsEngine.Registry)
 		metricsEngine.managedMetrics[managedAPI] = aClient
 	}
+	metricsEngine.acsReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ACSSubsystem,
+		Name:      "reconnect_count",
+		Help:      "Number of reconnections to ACS",
+	}, []string{"Reason"})
+	registry.MustRegister(metricsEngine.acsReconnects)
 	return metricsEngine
 }
 
@@ -138,6 +146,65 @@ func (engine *MetricsEngine) recordGenericMetric(apiType APIType, callName strin
 	}
 }
 
+// Counts a failed Docker API call. Nothing is recorded when err is nil.
+func (engine *MetricsEngine) RecordDockerError(callName string, err error) {
+	if engine == nil || !engine.collection || err == nil {
+		return
+	}
+	engine.managedMetrics[DockerAPI].IncrementErrorCount(callName)
+}
+
+// Records an ECS API call that has completed, with its duration and outcome
+func (engine *MetricsEngine) RecordECSClientCall(callName string, duration time.Duration, err error) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.managedMetrics[ECSClient].ObserveCall(callName, duration, err)
+}
+
+// Registers the function counting the tasks managed by the Agent by their
+// known status. The counts are read every time the metrics are gathered.
+func (engine *MetricsEngine) RegisterTaskStatusCounter(countTasks func() map[string]int) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.Registry.MustRegister(newTaskStatusCollector(countTasks))
+}
+
+// Counts a reconnection to ACS, labeled by the reason the previous
+// connection ended
+func (engine *MetricsEngine) RecordACSReconnect(reason string) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.acsReconnects.WithLabelValues(reason).Inc()
+}
+
+// Registers a gauge whose value is read from valueFunc every time the metrics
+// are gathered, such as the depth of a queue.
+func (engine *MetricsEngine) RegisterGaugeFunc(subsystem, name, help string, valueFunc func() float64) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: AgentNamespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, valueFunc))
+}
+
+// Returns the handler serving the metrics of the engine's registry, so that
+// they can be scraped from other servers of the Agent.
+func (engine *MetricsEngine) Handler() http.Handler {
+	return promhttp.HandlerFor(engine.Registry, promhttp.HandlerOpts{})
+}
+
+// Returns true if metrics are collected
+func (engine *MetricsEngine) Enabled() bool {
+	return engine != nil && engine.collection
+}
+
 func (engine *MetricsEngine) recordMetric(apiType APIType, callName, callID string, callStarted chan bool) string {
 	return engine.managedMetrics[apiType].RecordCall(callID, callName, time.Now(), callStarted)
 }
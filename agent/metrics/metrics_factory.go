@@ -26,6 +26,8 @@ const (
 	TaskEngineSubsystem   = "TaskEngine"
 	StateManagerSubsystem = "StateManager"
 	ECSClientSubsystem    = "ECSClient"
+	ACSSubsystem          = "ACS"
+	DiskSubsystem         = "Disk"
 )
 
 // A factory method that enables various MetricsClients to be created.
@@ -62,6 +64,14 @@ func NewGenericMetricsClient(subsystem string, registry *prometheus.Registry) *G
 	}, []string{"Call"})
 	registry.MustRegister(aCounterVec)
 
+	anErrorCounterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: subsystem,
+		Name:      "error_count",
+		Help:      subsystem + " failed call count",
+	}, []string{"Call"})
+	registry.MustRegister(anErrorCounterVec)
+
 	aGaugeVec := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: AgentNamespace,
@@ -75,6 +85,7 @@ func NewGenericMetricsClient(subsystem string, registry *prometheus.Registry) *G
 	genericMetrics := &GenericMetrics{
 		durationVec:      aDurationVec,
 		counterVec:       aCounterVec,
+		errorCounterVec:  anErrorCounterVec,
 		durations:        aGaugeVec,
 		outstandingCalls: make(map[string]time.Time),
 	}
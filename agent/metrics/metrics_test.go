@@ -223,3 +223,93 @@ func compareDiff(a, b, deltaMin float64) bool {
 	}
 	return diff <= (a * deltaMin)
 }
+
+// Tests that failed calls, completed ECS API calls and task counts are exposed
+func TestRecordErrorsAndTaskStatus(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	MetricsEngineGlobal.RecordDockerError("PULL_IMAGE", fmt.Errorf("pull failed"))
+	MetricsEngineGlobal.RecordDockerError("PULL_IMAGE", nil)
+	MetricsEngineGlobal.RecordECSClientCall("SubmitTaskStateChange", 2*time.Second, nil)
+	MetricsEngineGlobal.RecordECSClientCall("SubmitTaskStateChange", 2*time.Second, fmt.Errorf("throttled"))
+	MetricsEngineGlobal.RegisterTaskStatusCounter(func() map[string]int {
+		return map[string]int{"RUNNING": 3, "STOPPED": 1}
+	})
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+
+	expected := make(metricMap)
+	expected["AgentMetrics_DockerAPI_error_count"] = map[string][]interface{}{
+		"CallPULL_IMAGE": {"COUNTER", 1.0},
+	}
+	expected["AgentMetrics_ECSClient_call_count"] = map[string][]interface{}{
+		"CallSubmitTaskStateChange": {"COUNTER", 2.0},
+	}
+	expected["AgentMetrics_ECSClient_error_count"] = map[string][]interface{}{
+		"CallSubmitTaskStateChange": {"COUNTER", 1.0},
+	}
+	expected["AgentMetrics_ECSClient_duration_seconds"] = map[string][]interface{}{
+		"CallSubmitTaskStateChange": {"SUMMARY", 2.0},
+	}
+	expected["AgentMetrics_ECSClient_call_duration"] = map[string][]interface{}{
+		"CallSubmitTaskStateChange": {"GUAGE", 2.0},
+	}
+	expected["AgentMetrics_TaskEngine_task_count"] = map[string][]interface{}{
+		"StatusRUNNING": {"GUAGE", 3.0},
+		"StatusSTOPPED": {"GUAGE", 1.0},
+	}
+	assert.True(t, verifyStats(metricFamilies, expected), "Metrics are not accurate")
+
+	taskCounts := make(map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "AgentMetrics_TaskEngine_task_count" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			taskCounts[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	assert.Equal(t, map[string]float64{"RUNNING": 3, "STOPPED": 1}, taskCounts)
+}
+
+// Tests the ACS reconnect counter and gauges read from functions
+func TestACSReconnectsAndGaugeFunc(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	MetricsEngineGlobal.RecordACSReconnect("error")
+	MetricsEngineGlobal.RecordACSReconnect("error")
+	MetricsEngineGlobal.RecordACSReconnect("closed")
+	MetricsEngineGlobal.RegisterGaugeFunc(TaskEngineSubsystem, "submit_queue_depth", "test gauge", func() float64 {
+		return 7
+	})
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		for _, metric := range metricFamily.GetMetric() {
+			name := metricFamily.GetName()
+			if len(metric.GetLabel()) > 0 {
+				name += "/" + metric.GetLabel()[0].GetValue()
+			}
+			values[name] = metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		}
+	}
+	assert.Equal(t, 2.0, values["AgentMetrics_ACS_reconnect_count/error"])
+	assert.Equal(t, 1.0, values["AgentMetrics_ACS_reconnect_count/closed"])
+	assert.Equal(t, 7.0, values["AgentMetrics_TaskEngine_submit_queue_depth"])
+}
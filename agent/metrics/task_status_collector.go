@@ -0,0 +1,48 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A taskStatusCollector exposes the number of tasks by known status as a gauge.
+// The counts are computed when the metrics are gathered, so that they can never
+// drift from the state of the TaskEngine.
+type taskStatusCollector struct {
+	desc       *prometheus.Desc
+	countTasks func() map[string]int
+}
+
+func newTaskStatusCollector(countTasks func() map[string]int) *taskStatusCollector {
+	return &taskStatusCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(AgentNamespace, TaskEngineSubsystem, "task_count"),
+			"Number of tasks managed by the Agent by known status",
+			[]string{"Status"}, nil),
+		countTasks: countTasks,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (collector *taskStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.desc
+}
+
+// Collect implements prometheus.Collector
+func (collector *taskStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	for status, count := range collector.countTasks() {
+		ch <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(count), status)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package factory
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	s3client "github.com/aws/amazon-ecs-agent/agent/s3"
+	"github.com/aws/aws-sdk-go/aws"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+const (
+	roundtripTimeout = 30 * time.Second
+	// bucketRegionTimeout is the timeout of the request that finds the region
+	// of a bucket
+	bucketRegionTimeout = 30 * time.Second
+)
+
+type S3ClientCreator interface {
+	NewS3ManagerClient(bucket, region string, creds credentials.IAMRoleCredentials) (s3client.S3ManagerClient, error)
+}
+
+func NewS3ClientCreator() S3ClientCreator {
+	return &s3ClientCreator{}
+}
+
+type s3ClientCreator struct{}
+
+// NewS3ManagerClient returns a client that downloads the objects of the
+// bucket from the region the bucket is in, which may differ from the region
+// of the instance
+func (*s3ClientCreator) NewS3ManagerClient(bucket, region string,
+	creds credentials.IAMRoleCredentials) (s3client.S3ManagerClient, error) {
+	cfg := aws.NewConfig().
+		WithHTTPClient(httpclient.New(roundtripTimeout, false)).
+		WithRegion(region).
+		WithCredentials(
+			awscreds.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey,
+				creds.SessionToken))
+	sess := session.Must(session.NewSession(cfg))
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketRegionTimeout)
+	defer cancel()
+	bucketRegion, err := s3manager.GetBucketRegion(ctx, sess, bucket, region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get the region of bucket %s", bucket)
+	}
+	return s3manager.NewDownloaderWithClient(s3.New(sess, aws.NewConfig().WithRegion(bucketRegion))), nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2015-2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-ecs-agent/agent/s3/factory (interfaces: S3ClientCreator)
+
+// Package mock_factory is a generated GoMock package.
+package mock_factory
+
+import (
+	reflect "reflect"
+
+	credentials "github.com/aws/amazon-ecs-agent/agent/credentials"
+	s3 "github.com/aws/amazon-ecs-agent/agent/s3"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockS3ClientCreator is a mock of S3ClientCreator interface
+type MockS3ClientCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockS3ClientCreatorMockRecorder
+}
+
+// MockS3ClientCreatorMockRecorder is the mock recorder for MockS3ClientCreator
+type MockS3ClientCreatorMockRecorder struct {
+	mock *MockS3ClientCreator
+}
+
+// NewMockS3ClientCreator creates a new mock instance
+func NewMockS3ClientCreator(ctrl *gomock.Controller) *MockS3ClientCreator {
+	mock := &MockS3ClientCreator{ctrl: ctrl}
+	mock.recorder = &MockS3ClientCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockS3ClientCreator) EXPECT() *MockS3ClientCreatorMockRecorder {
+	return m.recorder
+}
+
+// NewS3ManagerClient mocks base method
+func (m *MockS3ClientCreator) NewS3ManagerClient(arg0, arg1 string, arg2 credentials.IAMRoleCredentials) (s3.S3ManagerClient, error) {
+	ret := m.ctrl.Call(m, "NewS3ManagerClient", arg0, arg1, arg2)
+	ret0, _ := ret[0].(s3.S3ManagerClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewS3ManagerClient indicates an expected call of NewS3ManagerClient
+func (mr *MockS3ClientCreatorMockRecorder) NewS3ManagerClient(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewS3ManagerClient", reflect.TypeOf((*MockS3ClientCreator)(nil).NewS3ManagerClient), arg0, arg1, arg2)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const s3ARNService = "s3"
+
+// DownloadFile downloads the object with the given key from the bucket to
+// the writer, failing once the timeout has passed
+func DownloadFile(bucket, key string, timeout time.Duration, w io.WriterAt, client S3ManagerClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := client.DownloadWithContext(ctx, w, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// ParseS3ARN returns the bucket and the key of the object of an S3 object
+// ARN, such as arn:aws:s3:::bucket/path/to/object
+func ParseS3ARN(s3ARN string) (bucket string, key string, err error) {
+	parsedARN, err := arn.Parse(s3ARN)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid s3 arn %s", s3ARN)
+	}
+	if parsedARN.Service != s3ARNService {
+		return "", "", errors.Errorf("arn %s is not an s3 arn", s3ARN)
+	}
+	fields := strings.SplitN(parsedARN.Resource, "/", 2)
+	if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+		return "", "", errors.Errorf("s3 arn %s does not identify an object", s3ARN)
+	}
+	return fields[0], fields[1], nil
+}
@@ -0,0 +1,71 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package s3
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDownloader struct {
+	S3ManagerClient
+	input *s3.GetObjectInput
+}
+
+func (m *mockDownloader) DownloadWithContext(ctx aws.Context, w io.WriterAt, input *s3.GetObjectInput,
+	options ...func(*s3manager.Downloader)) (int64, error) {
+	m.input = input
+	n, err := w.WriteAt([]byte("KEY=value"), 0)
+	return int64(n), err
+}
+
+func TestParseS3ARN(t *testing.T) {
+	bucket, key, err := ParseS3ARN("arn:aws:s3:::bucket/path/to/file.env")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "path/to/file.env", key)
+}
+
+func TestParseS3ARNInvalid(t *testing.T) {
+	for _, s3ARN := range []string{
+		"bucket/file.env",
+		"arn:aws:ssm:us-west-2:123456789012:parameter/file.env",
+		"arn:aws:s3:::bucket",
+		"arn:aws:s3:::bucket/",
+	} {
+		t.Run(s3ARN, func(t *testing.T) {
+			_, _, err := ParseS3ARN(s3ARN)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	client := &mockDownloader{}
+	buf := aws.NewWriteAtBuffer([]byte{})
+
+	require.NoError(t, DownloadFile("bucket", "file.env", time.Second, buf, client))
+	assert.Equal(t, "bucket", aws.StringValue(client.input.Bucket))
+	assert.Equal(t, "file.env", aws.StringValue(client.input.Key))
+	assert.Equal(t, "KEY=value", string(buf.Bytes()))
+}
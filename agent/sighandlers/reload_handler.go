@@ -0,0 +1,44 @@
+// +build !windows
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sighandlers
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cihub/seelog"
+)
+
+// StartReloadHandler invokes the reload function every time the agent
+// receives SIGHUP, until the context is canceled
+func StartReloadHandler(ctx context.Context, reload func()) {
+	signalChannel := make(chan os.Signal, 1)
+	signal.Notify(signalChannel, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signalChannel)
+		for {
+			select {
+			case <-signalChannel:
+				seelog.Info("Reload handler received SIGHUP, reloading configuration")
+				reload()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
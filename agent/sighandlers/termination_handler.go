@@ -13,12 +13,16 @@
 
 // Package sighandlers handle signals and behave appropriately.
 // SIGTERM:
-//   Flush state to disk and exit
+//   Drain and deregister the container instance if configured to, flush state
+//   to disk and exit
 // SIGUSR1:
 //   Print a dump of goroutines to the logger and DON'T exit
+// SIGHUP:
+//   Reload the runtime updatable configuration and DON'T exit
 package sighandlers
 
 import (
+	"context"
 	"errors"
 	"os"
 	"os/signal"
@@ -38,17 +42,33 @@ const (
 	finalSaveTimeout     = 3 * time.Second
 )
 
+// ShutdownFunc gracefully shuts down the agent when it is asked to terminate,
+// before its state is saved for the last time
+type ShutdownFunc func(ctx context.Context) error
+
 // TerminationHandler defines a handler used for terminating the agent
-type TerminationHandler func(saver statemanager.Saver, taskEngine engine.TaskEngine)
+type TerminationHandler func(saver statemanager.Saver, taskEngine engine.TaskEngine, shutdown ShutdownFunc)
 
 // StartDefaultTerminationHandler defines a default termination handler suitable for running in a process
-func StartDefaultTerminationHandler(saver statemanager.Saver, taskEngine engine.TaskEngine) {
+func StartDefaultTerminationHandler(saver statemanager.Saver, taskEngine engine.TaskEngine, shutdown ShutdownFunc) {
 	signalChannel := make(chan os.Signal, 2)
 	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
 
 	sig := <-signalChannel
 	seelog.Debugf("Termination handler received termination signal: %s", sig.String())
 
+	// A second termination signal aborts the graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-signalChannel
+		cancel()
+	}()
+	SaveBeforeShutdown(saver)
+	if err := shutdown(ctx); err != nil {
+		seelog.Errorf("Error shutting down gracefully: %v", err)
+	}
+	cancel()
+
 	err := FinalSave(saver, taskEngine)
 	if err != nil {
 		seelog.Criticalf("Error saving state before final shutdown: %v", err)
@@ -58,6 +78,16 @@ func StartDefaultTerminationHandler(saver statemanager.Saver, taskEngine engine.
 	os.Exit(exitcodes.ExitSuccess)
 }
 
+// SaveBeforeShutdown saves the state before the graceful shutdown, which may
+// wait for the tasks to stop for longer than the agent is given to exit. The
+// agent may then be killed before its final save
+func SaveBeforeShutdown(saver statemanager.Saver) {
+	seelog.Debug("Saving state before shutting down gracefully")
+	if err := saver.ForceSave(); err != nil {
+		seelog.Errorf("Error saving state before shutting down gracefully: %v", err)
+	}
+}
+
 // FinalSave should be called immediately before exiting, and only before
 // exiting, in order to flush tasks to disk. It waits a short timeout for state
 // to settle if necessary. If unable to reach a steady-state and save within
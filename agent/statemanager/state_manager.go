@@ -78,7 +78,22 @@ const (
 	//   a) Add 'Associations' field to 'api.task.task'
 	//   b) Add 'GPUIDs' field to 'apicontainer.Container'
 	//   c) Add 'NvidiaRuntime' field to 'api.task.task'
-	ECSDataVersion = 19
+	// 20)
+	//   a) Add 'FirelensConfig' field to 'apicontainer.Container'
+	//   b) Add 'firelens' field to 'resources'
+	// 21) Add 'efs' field to 'resources'
+	// 22) Add 'ACSAppliedPayloads' to the saved state
+	// 23) Add 'DependsOn' field to 'apicontainer.Container'
+	// 24) Add 'StopTimeout' field to 'apicontainer.Container'
+	// 25) Add 'ImageDigest' field to 'apicontainer.Container'
+	// 26) Add 'ManagedAgents' field to 'apicontainer.Container'
+	// 27)
+	//   a) Add 'EnvironmentFiles' field to 'apicontainer.Container'
+	//   b) Add 'envfile' field to 'resources'
+	// 28) Add 'LinuxParameters' field to 'apicontainer.Container'
+	// 29) Add 'PseudoTerminal' and 'Interactive' fields to 'apicontainer.Container'
+	// 30) Add 'SystemControls' field to 'apicontainer.Container'
+	ECSDataVersion = 30
 
 	// ecsDataFile specifies the filename in the ECS_DATADIR
 	ecsDataFile = "ecs_agent_data.json"
@@ -247,8 +247,8 @@ func validateEmptyTaskHealthMetrics(t *testing.T, engine *DockerStatsEngine) {
 
 func createFakeContainerStats() []*ContainerStats {
 	return []*ContainerStats{
-		{22400432, 1839104, parseNanoTime("2015-02-12T21:22:05.131117533Z")},
-		{116499979, 3649536, parseNanoTime("2015-02-12T21:22:05.232291187Z")},
+		{cpuUsage: 22400432, memoryUsage: 1839104, timestamp: parseNanoTime("2015-02-12T21:22:05.131117533Z")},
+		{cpuUsage: 116499979, memoryUsage: 3649536, timestamp: parseNanoTime("2015-02-12T21:22:05.232291187Z")},
 	}
 }
 
@@ -275,6 +275,9 @@ func (engine *MockTaskEngine) SetSaver(statemanager.Saver) {
 func (engine *MockTaskEngine) AddTask(*apitask.Task) {
 }
 
+func (engine *MockTaskEngine) StopTask(arn string, reason string) {
+}
+
 func (engine *MockTaskEngine) ListTasks() ([]*apitask.Task, error) {
 	return nil, nil
 }
@@ -0,0 +1,225 @@
+// Copyright 2014-2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// DiskUsage holds the space used by docker on the instance.
+type DiskUsage struct {
+	// DataRoot is the docker data root directory
+	DataRoot string `json:"dataRoot"`
+	// DataRootTotalBytes and DataRootFreeBytes describe the filesystem of the
+	// data root. Both are zero when it could not be inspected.
+	DataRootTotalBytes uint64 `json:"dataRootTotalBytes"`
+	DataRootFreeBytes  uint64 `json:"dataRootFreeBytes"`
+	// LayersBytes is the space used by image layers
+	LayersBytes int64 `json:"layersBytes"`
+	// ContainerWritableLayerBytes maps container ids to the size of their
+	// writable layer
+	ContainerWritableLayerBytes map[string]int64 `json:"containerWritableLayerBytes"`
+	// VolumeBytes maps volume names to the space they use, when docker reports it
+	VolumeBytes map[string]int64 `json:"volumeBytes"`
+}
+
+// newDiskUsage builds the disk usage from the docker daemon info and disk usage
+// responses.
+func newDiskUsage(info types.Info, dockerDiskUsage types.DiskUsage) *DiskUsage {
+	diskUsage := &DiskUsage{
+		DataRoot:                    info.DockerRootDir,
+		LayersBytes:                 dockerDiskUsage.LayersSize,
+		ContainerWritableLayerBytes: make(map[string]int64),
+		VolumeBytes:                 make(map[string]int64),
+	}
+	for _, container := range dockerDiskUsage.Containers {
+		if container == nil {
+			continue
+		}
+		diskUsage.ContainerWritableLayerBytes[container.ID] = container.SizeRw
+	}
+	for _, volume := range dockerDiskUsage.Volumes {
+		// Size is -1 when docker cannot compute it, as for volumes of non-local drivers
+		if volume == nil || volume.UsageData == nil || volume.UsageData.Size < 0 {
+			continue
+		}
+		diskUsage.VolumeBytes[volume.Name] = volume.UsageData.Size
+	}
+	return diskUsage
+}
+
+// WritableLayersBytes returns the space used by the writable layers of all the
+// containers.
+func (diskUsage *DiskUsage) WritableLayersBytes() int64 {
+	var total int64
+	for _, size := range diskUsage.ContainerWritableLayerBytes {
+		total += size
+	}
+	return total
+}
+
+// VolumesBytes returns the space used by all the volumes docker reports the
+// usage of.
+func (diskUsage *DiskUsage) VolumesBytes() int64 {
+	var total int64
+	for _, size := range diskUsage.VolumeBytes {
+		total += size
+	}
+	return total
+}
+
+// freeSpaceBelow returns true if the free space on the data root is below the
+// percentage of its total space.
+func (diskUsage *DiskUsage) freeSpaceBelow(thresholdPercent int) bool {
+	if diskUsage.DataRootTotalBytes == 0 {
+		return false
+	}
+	return diskUsage.DataRootFreeBytes*100 < diskUsage.DataRootTotalBytes*uint64(thresholdPercent)
+}
+
+// getDiskUsage queries docker for the space used by layers, containers and
+// volumes, and the filesystem of the data root for its free space.
+func (engine *DockerStatsEngine) getDiskUsage() (*DiskUsage, error) {
+	info, err := engine.client.Info(engine.ctx, dockerclient.InfoTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get docker info")
+	}
+	dockerDiskUsage, err := engine.client.DiskUsage(engine.ctx, dockerclient.DiskUsageTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get docker disk usage")
+	}
+
+	diskUsage := newDiskUsage(info, dockerDiskUsage)
+	dataRootPath := engine.dockerDataRootPath
+	if dataRootPath == "" {
+		dataRootPath = diskUsage.DataRoot
+	}
+	if dataRootPath != "" {
+		total, free, err := diskSpace(dataRootPath)
+		if err != nil {
+			engine.dataRootFreeSpaceUnavailableWarning(dataRootPath, err)
+		} else {
+			diskUsage.DataRootTotalBytes, diskUsage.DataRootFreeBytes = total, free
+		}
+	}
+	return diskUsage, nil
+}
+
+// dataRootFreeSpaceUnavailableWarning warns once that the free space of the
+// data root cannot be measured. The data root is not visible when the agent
+// runs in a container, unless it is mounted in the agent's container
+func (engine *DockerStatsEngine) dataRootFreeSpaceUnavailableWarning(dataRootPath string, err error) {
+	if engine.dataRootFreeSpaceUnavailable {
+		seelog.Debugf("Unable to get free space of docker data root %s: %v", dataRootPath, err)
+		return
+	}
+	engine.dataRootFreeSpaceUnavailable = true
+	seelog.Warnf("Unable to get free space of docker data root %s, the low free space warning is disabled. "+
+		"Mount the data root in the agent's container and set ECS_DOCKER_DATA_ROOT_PATH to enable it: %v",
+		dataRootPath, err)
+}
+
+// checkDiskUsage logs the disk usage of docker, and warns when the free space
+// on the data root is below the configured threshold.
+func (engine *DockerStatsEngine) checkDiskUsage() {
+	diskUsage, err := engine.getDiskUsage()
+	if err != nil {
+		seelog.Warnf("Unable to check disk usage: %v", err)
+		return
+	}
+
+	engine.diskUsageLock.Lock()
+	engine.diskUsage = diskUsage
+	engine.diskUsageLock.Unlock()
+
+	for containerID, size := range diskUsage.ContainerWritableLayerBytes {
+		seelog.Debugf("Disk usage of container writable layer, container: %s, bytes: %d", containerID, size)
+	}
+	for volumeName, size := range diskUsage.VolumeBytes {
+		seelog.Debugf("Disk usage of volume, volume: %s, bytes: %d", volumeName, size)
+	}
+	seelog.Infof("Docker disk usage: layers: %d bytes, container writable layers: %d bytes, volumes: %d bytes, data root free: %d of %d bytes",
+		diskUsage.LayersBytes, diskUsage.WritableLayersBytes(), diskUsage.VolumesBytes(),
+		diskUsage.DataRootFreeBytes, diskUsage.DataRootTotalBytes)
+
+	if diskUsage.freeSpaceBelow(engine.diskFreeSpaceWarnThreshold) {
+		seelog.Warnf("Free space on docker data root %s is below %d%%: %d of %d bytes free",
+			diskUsage.DataRoot, engine.diskFreeSpaceWarnThreshold, diskUsage.DataRootFreeBytes, diskUsage.DataRootTotalBytes)
+	}
+}
+
+// monitorDiskUsage checks the disk usage of docker periodically until the
+// engine is stopped.
+func (engine *DockerStatsEngine) monitorDiskUsage() {
+	interval := engine.diskUsageCheckInterval
+	if interval <= 0 {
+		interval = config.DefaultDiskUsageCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-engine.ctx.Done():
+			return
+		case <-ticker.C:
+			engine.checkDiskUsage()
+		}
+	}
+}
+
+// GetDiskUsage returns the disk usage of docker last checked, or nil if it has
+// not been checked yet.
+func (engine *DockerStatsEngine) GetDiskUsage() *DiskUsage {
+	engine.diskUsageLock.RLock()
+	defer engine.diskUsageLock.RUnlock()
+	return engine.diskUsage
+}
+
+// RegisterDiskUsageMetrics publishes the disk usage of docker last checked as
+// metrics.
+func (engine *DockerStatsEngine) RegisterDiskUsageMetrics() {
+	gauges := []struct {
+		name  string
+		help  string
+		value func(*DiskUsage) float64
+	}{
+		{"data_root_total_bytes", "Size of the filesystem of the docker data root, 0 when unknown",
+			func(diskUsage *DiskUsage) float64 { return float64(diskUsage.DataRootTotalBytes) }},
+		{"data_root_free_bytes", "Free space on the filesystem of the docker data root, 0 when unknown",
+			func(diskUsage *DiskUsage) float64 { return float64(diskUsage.DataRootFreeBytes) }},
+		{"layers_bytes", "Space used by the docker image layers",
+			func(diskUsage *DiskUsage) float64 { return float64(diskUsage.LayersBytes) }},
+		{"container_writable_layers_bytes", "Space used by the writable layers of the containers",
+			func(diskUsage *DiskUsage) float64 { return float64(diskUsage.WritableLayersBytes()) }},
+		{"volumes_bytes", "Space used by the docker volumes",
+			func(diskUsage *DiskUsage) float64 { return float64(diskUsage.VolumesBytes()) }},
+	}
+	for _, gauge := range gauges {
+		value := gauge.value
+		metrics.MetricsEngineGlobal.RegisterGaugeFunc(metrics.DiskSubsystem, gauge.name, gauge.help, func() float64 {
+			diskUsage := engine.GetDiskUsage()
+			if diskUsage == nil {
+				return 0
+			}
+			return value(diskUsage)
+		})
+	}
+}
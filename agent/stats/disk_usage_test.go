@@ -0,0 +1,135 @@
+// +build unit
+
+// Copyright 2014-2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiskUsage(t *testing.T) {
+	info := types.Info{DockerRootDir: "/var/lib/docker"}
+	dockerDiskUsage := types.DiskUsage{
+		LayersSize: 1024,
+		Containers: []*types.Container{
+			{ID: "c1", SizeRw: 10},
+			{ID: "c2", SizeRw: 20},
+		},
+		Volumes: []*types.Volume{
+			{Name: "v1", UsageData: &types.VolumeUsageData{Size: 30}},
+			{Name: "v2", UsageData: &types.VolumeUsageData{Size: -1}},
+			{Name: "v3"},
+		},
+	}
+
+	diskUsage := newDiskUsage(info, dockerDiskUsage)
+	assert.Equal(t, "/var/lib/docker", diskUsage.DataRoot)
+	assert.Equal(t, int64(1024), diskUsage.LayersBytes)
+	assert.Equal(t, map[string]int64{"c1": 10, "c2": 20}, diskUsage.ContainerWritableLayerBytes)
+	assert.Equal(t, map[string]int64{"v1": 30}, diskUsage.VolumeBytes)
+}
+
+func TestDiskUsageFreeSpaceBelow(t *testing.T) {
+	diskUsage := &DiskUsage{DataRootTotalBytes: 1000, DataRootFreeBytes: 50}
+	assert.True(t, diskUsage.freeSpaceBelow(10))
+	assert.False(t, diskUsage.freeSpaceBelow(5))
+
+	unknown := &DiskUsage{}
+	assert.False(t, unknown.freeSpaceBelow(10), "free space must not be reported below the threshold when unknown")
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(mockCtrl)
+
+	engine := NewDockerStatsEngine(&cfg, mockDockerClient, eventStream("TestGetDiskUsage"))
+	engine.ctx = context.TODO()
+
+	gomock.InOrder(
+		mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{DockerRootDir: "/"}, nil),
+		mockDockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(types.DiskUsage{LayersSize: 1024}, nil),
+	)
+	diskUsage, err := engine.getDiskUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), diskUsage.LayersBytes)
+	assert.NotZero(t, diskUsage.DataRootTotalBytes)
+
+	mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
+	mockDockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(types.DiskUsage{}, errors.New("error"))
+	_, err = engine.getDiskUsage()
+	assert.Error(t, err)
+}
+
+func TestGetDiskUsageDataRootPath(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(mockCtrl)
+
+	engine := NewDockerStatsEngine(&cfg, mockDockerClient, eventStream("TestGetDiskUsageDataRootPath"))
+	engine.ctx = context.TODO()
+
+	// The data root reported by docker is not visible to the agent, the path
+	// it is mounted at is measured instead
+	engine.dockerDataRootPath = "/"
+	mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(
+		types.Info{DockerRootDir: "/nonexistent/docker"}, nil)
+	mockDockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(types.DiskUsage{}, nil)
+	diskUsage, err := engine.getDiskUsage()
+	require.NoError(t, err)
+	assert.NotZero(t, diskUsage.DataRootTotalBytes)
+	assert.False(t, engine.dataRootFreeSpaceUnavailable)
+
+	engine.dockerDataRootPath = ""
+	mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(
+		types.Info{DockerRootDir: "/nonexistent/docker"}, nil)
+	mockDockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(types.DiskUsage{}, nil)
+	diskUsage, err = engine.getDiskUsage()
+	require.NoError(t, err)
+	assert.Zero(t, diskUsage.DataRootTotalBytes)
+	assert.True(t, engine.dataRootFreeSpaceUnavailable)
+}
+
+func TestCheckDiskUsageRecordsDiskUsage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(mockCtrl)
+
+	engine := NewDockerStatsEngine(&cfg, mockDockerClient, eventStream("TestCheckDiskUsageRecordsDiskUsage"))
+	engine.ctx = context.TODO()
+	assert.Nil(t, engine.GetDiskUsage())
+
+	mockDockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil)
+	mockDockerClient.EXPECT().DiskUsage(gomock.Any(), gomock.Any()).Return(types.DiskUsage{
+		LayersSize: 1024,
+		Containers: []*types.Container{{ID: "c1", SizeRw: 10}, {ID: "c2", SizeRw: 20}},
+		Volumes:    []*types.Volume{{Name: "v1", UsageData: &types.VolumeUsageData{Size: 30}}},
+	}, nil)
+	engine.checkDiskUsage()
+
+	diskUsage := engine.GetDiskUsage()
+	require.NotNil(t, diskUsage)
+	assert.Equal(t, int64(1024), diskUsage.LayersBytes)
+	assert.Equal(t, int64(30), diskUsage.WritableLayersBytes())
+	assert.Equal(t, int64(30), diskUsage.VolumesBytes())
+}
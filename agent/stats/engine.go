@@ -63,7 +63,8 @@ type DockerContainerMetadataResolver struct {
 // defined to make testing easier.
 type Engine interface {
 	GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs.TaskMetric, error)
-	ContainerDockerStats(taskARN string, containerID string) (*types.Stats, error)
+	ContainerDockerStats(taskARN string, containerID string) (*types.StatsJSON, error)
+	TaskNetworkStats(taskARN string) (*NetworkStats, error)
 	GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error)
 }
 
@@ -77,6 +78,9 @@ type DockerStatsEngine struct {
 	containerInstanceArn       string
 	lock                       sync.RWMutex
 	disableMetrics             bool
+	diskFreeSpaceWarnThreshold int
+	diskUsageCheckInterval     time.Duration
+	dockerDataRootPath         string
 	containerChangeEventStream *eventstream.EventStream
 	resolver                   resolver.ContainerMetadataResolver
 	// tasksToContainers maps task arns to a map of container ids to StatsContainer objects.
@@ -85,6 +89,13 @@ type DockerStatsEngine struct {
 	tasksToHealthCheckContainers map[string]map[string]*StatsContainer
 	// tasksToDefinitions maps task arns to task definition name and family metadata objects.
 	tasksToDefinitions map[string]*taskDefinition
+	// diskUsage is the disk usage of docker last checked, read by the metrics
+	// of the disk usage
+	diskUsage     *DiskUsage
+	diskUsageLock sync.RWMutex
+	// dataRootFreeSpaceUnavailable is set once the agent warned that the free
+	// space of the data root cannot be measured
+	dataRootFreeSpaceUnavailable bool
 }
 
 // ResolveTask resolves the api task object, given container id.
@@ -120,6 +131,9 @@ func NewDockerStatsEngine(cfg *config.Config, client dockerapi.DockerClient, con
 		client:                       client,
 		resolver:                     nil,
 		disableMetrics:               cfg.DisableMetrics,
+		diskFreeSpaceWarnThreshold:   cfg.DiskFreeSpaceWarnThreshold,
+		diskUsageCheckInterval:       cfg.DiskUsageCheckInterval,
+		dockerDataRootPath:           cfg.DockerDataRootPath,
 		tasksToContainers:            make(map[string]map[string]*StatsContainer),
 		tasksToHealthCheckContainers: make(map[string]map[string]*StatsContainer),
 		tasksToDefinitions:           make(map[string]*taskDefinition),
@@ -186,6 +200,7 @@ func (engine *DockerStatsEngine) MustInit(ctx context.Context, taskEngine ecseng
 	}
 
 	go engine.waitToStop()
+	go engine.monitorDiskUsage()
 	return nil
 }
 
@@ -648,7 +663,7 @@ func (engine *DockerStatsEngine) resetStatsUnsafe() {
 }
 
 // ContainerDockerStats returns the last stored raw docker stats object for a container
-func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerID string) (*types.Stats, error) {
+func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerID string) (*types.StatsJSON, error) {
 	engine.lock.RLock()
 	defer engine.lock.RUnlock()
 
@@ -666,6 +681,54 @@ func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerI
 
 }
 
+// TaskNetworkStats returns the network counters of a task from the last stored
+// docker stats of its containers. Containers of awsvpc tasks share the network
+// namespace of the pause container, whose counters alone cover the task, while
+// the counters of containers in bridge mode are summed. No counters are
+// reported by docker for containers in host mode.
+func (engine *DockerStatsEngine) TaskNetworkStats(taskARN string) (*NetworkStats, error) {
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	containerIDToStatsContainer, ok := engine.tasksToContainers[taskARN]
+	if !ok {
+		return nil, errors.Errorf("stats engine: task '%s' not found", taskARN)
+	}
+
+	containers := make([]*StatsContainer, 0, len(containerIDToStatsContainer))
+	for containerID, container := range containerIDToStatsContainer {
+		dockerContainer, err := engine.resolver.ResolveContainer(containerID)
+		if err == nil && dockerContainer.Container.Type == apicontainer.ContainerCNIPause {
+			containers = []*StatsContainer{container}
+			break
+		}
+		containers = append(containers, container)
+	}
+
+	var taskNetworkStats NetworkStats
+	reported := false
+	for _, container := range containers {
+		dockerStats := container.statsQueue.GetLastStat()
+		if dockerStats == nil || len(dockerStats.Networks) == 0 {
+			continue
+		}
+		reported = true
+		networkStats := dockerNetworkStats(dockerStats)
+		taskNetworkStats.RxBytes += networkStats.RxBytes
+		taskNetworkStats.RxPackets += networkStats.RxPackets
+		taskNetworkStats.RxErrors += networkStats.RxErrors
+		taskNetworkStats.RxDropped += networkStats.RxDropped
+		taskNetworkStats.TxBytes += networkStats.TxBytes
+		taskNetworkStats.TxPackets += networkStats.TxPackets
+		taskNetworkStats.TxErrors += networkStats.TxErrors
+		taskNetworkStats.TxDropped += networkStats.TxDropped
+	}
+	if !reported {
+		return nil, errors.Errorf("stats engine: no network stats reported for task '%s'", taskARN)
+	}
+	return &taskNetworkStats, nil
+}
+
 // newMetricsMetadata creates the singleton metadata object.
 func newMetricsMetadata(cluster *string, containerInstance *string) *ecstcs.MetricsMetadata {
 	return &ecstcs.MetricsMetadata{
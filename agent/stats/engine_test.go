@@ -52,7 +52,7 @@ func TestStatsEngineAddRemoveContainers(t *testing.T) {
 	resolver.EXPECT().ResolveContainer(gomock.Any()).AnyTimes().Return(&apicontainer.DockerContainer{
 		Container: &apicontainer.Container{},
 	}, nil)
-	mockStatsChannel := make(chan *types.Stats)
+	mockStatsChannel := make(chan *types.StatsJSON)
 	defer close(mockStatsChannel)
 	mockDockerClient.EXPECT().Stats(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockStatsChannel, nil).AnyTimes()
 
@@ -191,15 +191,15 @@ func TestStatsEngineMetadataInStatsSets(t *testing.T) {
 	ts1 := parseNanoTime("2015-02-12T21:22:05.131117533Z")
 	ts2 := parseNanoTime("2015-02-12T21:22:05.232291187Z")
 	containerStats := []*ContainerStats{
-		{22400432, 1839104, ts1},
-		{116499979, 3649536, ts2},
+		{cpuUsage: 22400432, memoryUsage: 1839104, timestamp: ts1},
+		{cpuUsage: 116499979, memoryUsage: 3649536, timestamp: ts2},
 	}
-	dockerStats := []*types.Stats{
+	dockerStats := []*types.StatsJSON{
 		{
-			Read: ts1,
+			Stats: types.Stats{Read: ts1},
 		},
 		{
-			Read: ts2,
+			Stats: types.Stats{Read: ts2},
 		},
 	}
 	containers, _ := engine.tasksToContainers["t1"]
@@ -398,7 +398,7 @@ func TestSynchronizeOnRestart(t *testing.T) {
 	defer ctrl.Finish()
 
 	containerID := "containerID"
-	statsChan := make(chan *types.Stats)
+	statsChan := make(chan *types.StatsJSON)
 	statsStarted := make(chan struct{})
 	client := mock_dockerapi.NewMockDockerClient(ctrl)
 	resolver := mock_resolver.NewMockContainerMetadataResolver(ctrl)
@@ -438,3 +438,70 @@ func TestSynchronizeOnRestart(t *testing.T) {
 	<-statsStarted
 	statsContainer.StopStatsCollection()
 }
+
+func TestStatsEngineTaskNetworkStats(t *testing.T) {
+	testCases := []struct {
+		name            string
+		pauseContainer  bool
+		expectedRxBytes uint64
+	}{
+		{
+			name:            "bridge mode task sums its containers",
+			pauseContainer:  false,
+			expectedRxBytes: 300,
+		},
+		{
+			name:            "awsvpc task uses the pause container",
+			pauseContainer:  true,
+			expectedRxBytes: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			resolver := mock_resolver.NewMockContainerMetadataResolver(mockCtrl)
+			mockDockerClient := mock_dockerapi.NewMockDockerClient(mockCtrl)
+			t1 := &apitask.Task{Arn: "t1", Family: "f1"}
+			resolver.EXPECT().ResolveTask(gomock.Any()).AnyTimes().Return(t1, nil)
+			firstContainerType := apicontainer.ContainerNormal
+			if tc.pauseContainer {
+				firstContainerType = apicontainer.ContainerCNIPause
+			}
+			resolver.EXPECT().ResolveContainer("c1").AnyTimes().Return(&apicontainer.DockerContainer{
+				Container: &apicontainer.Container{Type: firstContainerType},
+			}, nil)
+			resolver.EXPECT().ResolveContainer("c2").AnyTimes().Return(&apicontainer.DockerContainer{
+				Container: &apicontainer.Container{},
+			}, nil)
+			mockDockerClient.EXPECT().Stats(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+			engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestStatsEngineTaskNetworkStats"))
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			engine.ctx = ctx
+			engine.resolver = resolver
+			engine.client = mockDockerClient
+			engine.addAndStartStatsContainer("c1")
+			engine.addAndStartStatsContainer("c2")
+
+			_, err := engine.TaskNetworkStats("t1")
+			assert.Error(t, err, "expected an error before any network stats are reported")
+
+			engine.tasksToContainers["t1"]["c1"].statsQueue.setLastStat(&types.StatsJSON{
+				Networks: map[string]types.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 10}},
+			})
+			engine.tasksToContainers["t1"]["c2"].statsQueue.setLastStat(&types.StatsJSON{
+				Networks: map[string]types.NetworkStats{"eth0": {RxBytes: 200, TxBytes: 20}},
+			})
+
+			networkStats, err := engine.TaskNetworkStats("t1")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRxBytes, networkStats.RxBytes)
+
+			_, err = engine.TaskNetworkStats("t2")
+			assert.Error(t, err)
+		})
+	}
+}
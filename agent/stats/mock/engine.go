@@ -20,6 +20,7 @@ package mock_stats
 import (
 	reflect "reflect"
 
+	stats "github.com/aws/amazon-ecs-agent/agent/stats"
 	ecstcs "github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
 	types "github.com/docker/docker/api/types"
 	gomock "github.com/golang/mock/gomock"
@@ -49,9 +50,9 @@ func (m *MockEngine) EXPECT() *MockEngineMockRecorder {
 }
 
 // ContainerDockerStats mocks base method
-func (m *MockEngine) ContainerDockerStats(arg0, arg1 string) (*types.Stats, error) {
+func (m *MockEngine) ContainerDockerStats(arg0, arg1 string) (*types.StatsJSON, error) {
 	ret := m.ctrl.Call(m, "ContainerDockerStats", arg0, arg1)
-	ret0, _ := ret[0].(*types.Stats)
+	ret0, _ := ret[0].(*types.StatsJSON)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -88,3 +89,16 @@ func (m *MockEngine) GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.T
 func (mr *MockEngineMockRecorder) GetTaskHealthMetrics() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskHealthMetrics", reflect.TypeOf((*MockEngine)(nil).GetTaskHealthMetrics))
 }
+
+// TaskNetworkStats mocks base method
+func (m *MockEngine) TaskNetworkStats(arg0 string) (*stats.NetworkStats, error) {
+	ret := m.ctrl.Call(m, "TaskNetworkStats", arg0)
+	ret0, _ := ret[0].(*stats.NetworkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskNetworkStats indicates an expected call of TaskNetworkStats
+func (mr *MockEngineMockRecorder) TaskNetworkStats(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskNetworkStats", reflect.TypeOf((*MockEngine)(nil).TaskNetworkStats), arg0)
+}
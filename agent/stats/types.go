@@ -29,6 +29,19 @@ type ContainerStats struct {
 	timestamp   time.Time
 }
 
+// NetworkStats holds the cumulative network counters of a container, summed
+// over its network interfaces.
+type NetworkStats struct {
+	RxBytes   uint64 `json:"rxBytes"`
+	RxPackets uint64 `json:"rxPackets"`
+	RxErrors  uint64 `json:"rxErrors"`
+	RxDropped uint64 `json:"rxDropped"`
+	TxBytes   uint64 `json:"txBytes"`
+	TxPackets uint64 `json:"txPackets"`
+	TxErrors  uint64 `json:"txErrors"`
+	TxDropped uint64 `json:"txDropped"`
+}
+
 // UsageStats abstracts the format in which the queue stores data.
 type UsageStats struct {
 	CPUUsagePerc      float32   `json:"cpuUsagePerc"`
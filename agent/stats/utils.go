@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
 )
 
 // networkStatsErrorPattern defines the pattern that is used to evaluate
@@ -50,3 +51,20 @@ func isNetworkStatsError(err error) bool {
 
 	return matched
 }
+
+// dockerNetworkStats returns the network counters of the container, summed
+// over its network interfaces.
+func dockerNetworkStats(dockerStats *types.StatsJSON) NetworkStats {
+	var networkStats NetworkStats
+	for _, network := range dockerStats.Networks {
+		networkStats.RxBytes += network.RxBytes
+		networkStats.RxPackets += network.RxPackets
+		networkStats.RxErrors += network.RxErrors
+		networkStats.RxDropped += network.RxDropped
+		networkStats.TxBytes += network.TxBytes
+		networkStats.TxPackets += network.TxPackets
+		networkStats.TxErrors += network.TxErrors
+		networkStats.TxDropped += network.TxDropped
+	}
+	return networkStats
+}
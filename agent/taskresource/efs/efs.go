@@ -0,0 +1,479 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ResourceName is the name of the efs resources in the resources map
+	ResourceName = "efs"
+
+	// EnabledValue is the value of the transit encryption and IAM settings of
+	// an EFS volume that turns them on
+	EnabledValue = "ENABLED"
+
+	resourceDirName = "efs"
+	// mountType selects the amazon-efs-utils mount helper, which resolves the
+	// file system DNS name and sets up TLS and IAM authorization
+	mountType            = "efs"
+	defaultRootDirectory = "/"
+
+	// mountTimeout bounds the mount helper, which may have to start the TLS
+	// tunnel and fetch the task credentials before the file system is mounted
+	mountTimeout = 2 * time.Minute
+	// unmountTimeout bounds the unmount, which hangs when the file system is
+	// unreachable
+	unmountTimeout = time.Minute
+)
+
+// execCommandContext is used to run the mount helper, it is replaced in tests
+var execCommandContext = exec.CommandContext
+
+// EFSResource represents an EFS file system mounted on the host for a volume
+// of a task. The mount directory is bind mounted into the containers that use
+// the volume. Mounting requires amazon-efs-utils where the agent runs, and when
+// the agent runs in a container, its data directory has to be mounted with
+// shared propagation so that the mount is visible on the host.
+type EFSResource struct {
+	// volumeName is the name of the task volume the file system backs
+	volumeName          string
+	taskARN             string
+	createdAt           time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatus is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatus                      resourcestatus.ResourceStatus
+	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
+
+	fileSystemID          string
+	rootDirectory         string
+	transitEncryption     bool
+	transitEncryptionPort int64
+	accessPointID         string
+	iamAuth               bool
+	// credentialsRelativeURI is the credentials endpoint path of the task
+	// role, which the mount helper uses when IAM authorization is enabled
+	credentialsRelativeURI string
+	// mountDir is the directory the file system is mounted at
+	mountDir string
+	// mountDirOnHost is the path of mountDir on the host, from which it is
+	// bind mounted to the containers
+	mountDirOnHost string
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisioning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewEFSResource creates a new EFSResource object
+func NewEFSResource(taskARN, volumeName string,
+	volumeConfig *taskresourcevolume.EFSVolumeConfig,
+	credentialsRelativeURI string,
+	dataDir, dataDirOnHost string) (*EFSResource, error) {
+	if volumeConfig.FileSystemID == "" {
+		return nil, errors.Errorf("efs resource: file system id is required for volume %s", volumeName)
+	}
+	taskID, err := taskIDFromARN(taskARN)
+	if err != nil {
+		return nil, err
+	}
+
+	efs := &EFSResource{
+		volumeName:            volumeName,
+		taskARN:               taskARN,
+		fileSystemID:          volumeConfig.FileSystemID,
+		rootDirectory:         volumeConfig.RootDirectory,
+		transitEncryption:     volumeConfig.TransitEncryption == EnabledValue,
+		transitEncryptionPort: volumeConfig.TransitEncryptionPort,
+		accessPointID:         volumeConfig.AuthorizationConfig.AccessPointID,
+		iamAuth:               volumeConfig.AuthorizationConfig.IAM == EnabledValue,
+		mountDir:              filepath.Join(dataDir, resourceDirName, taskID, volumeName),
+		mountDirOnHost:        filepath.Join(dataDirOnHost, dataDir, resourceDirName, taskID, volumeName),
+	}
+	if efs.rootDirectory == "" {
+		efs.rootDirectory = defaultRootDirectory
+	}
+	if (efs.iamAuth || efs.accessPointID != "") && !efs.transitEncryption {
+		return nil, errors.Errorf("efs resource: transit encryption is required for IAM authorization and access points of volume %s",
+			volumeName)
+	}
+	if efs.iamAuth {
+		if credentialsRelativeURI == "" {
+			return nil, errors.Errorf("efs resource: IAM authorization of volume %s requires a task role", volumeName)
+		}
+		efs.credentialsRelativeURI = credentialsRelativeURI
+	}
+
+	efs.initStatusToTransition()
+	return efs, nil
+}
+
+func taskIDFromARN(taskARN string) (string, error) {
+	fields := strings.Split(taskARN, "/")
+	if len(fields) < 2 || fields[len(fields)-1] == "" {
+		return "", errors.Errorf("efs resource: invalid task arn: %s", taskARN)
+	}
+	return fields[len(fields)-1], nil
+}
+
+func (efs *EFSResource) initStatusToTransition() {
+	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(EFSCreated): efs.Create,
+	}
+	efs.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
+}
+
+func (efs *EFSResource) setTerminalReason(reason string) {
+	efs.terminalReasonOnce.Do(func() {
+		seelog.Infof("efs resource: setting terminal reason for efs resource %s in task: [%s]", efs.volumeName, efs.taskARN)
+		efs.terminalReason = reason
+	})
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (efs *EFSResource) GetTerminalReason() string {
+	return efs.terminalReason
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (efs *EFSResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (efs *EFSResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.desiredStatusUnsafe
+}
+
+// GetName returns the name of the volume, which containers mounting the volume
+// depend on
+func (efs *EFSResource) GetName() string {
+	return efs.volumeName
+}
+
+// DesiredTerminal returns true if the resource's desired status is REMOVED
+func (efs *EFSResource) DesiredTerminal() bool {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.desiredStatusUnsafe == resourcestatus.ResourceStatus(EFSRemoved)
+}
+
+// KnownCreated returns true if the resource's known status is CREATED
+func (efs *EFSResource) KnownCreated() bool {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.knownStatusUnsafe == resourcestatus.ResourceStatus(EFSCreated)
+}
+
+// TerminalStatus returns the last transition state of the resource
+func (efs *EFSResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EFSRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (efs *EFSResource) NextKnownState() resourcestatus.ResourceStatus {
+	return efs.GetKnownStatus() + 1
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (efs *EFSResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := efs.resourceStatusToTransitionFunction[nextState]
+	if !ok {
+		return errors.Errorf("resource [%s]: transition to %s impossible", efs.GetName(),
+			efs.StatusString(nextState))
+	}
+	return transitionFunc()
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (efs *EFSResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EFSCreated)
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (efs *EFSResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.knownStatusUnsafe = status
+	efs.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (efs *EFSResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if efs.appliedStatus == resourcestatus.ResourceStatus(EFSStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if efs.appliedStatus <= knownStatus {
+		efs.appliedStatus = resourcestatus.ResourceStatus(EFSStatusNone)
+	}
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (efs *EFSResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	if efs.appliedStatus != resourcestatus.ResourceStatus(EFSStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	efs.appliedStatus = status
+	return true
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (efs *EFSResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.knownStatusUnsafe
+}
+
+// StatusString returns the string of the efs resource status
+func (efs *EFSResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return EFSStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (efs *EFSResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.createdAt = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (efs *EFSResource) GetCreatedAt() time.Time {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.createdAt
+}
+
+// Create mounts the file system at the mount directory of the volume
+func (efs *EFSResource) Create() error {
+	seelog.Infof("efs resource: mounting file system %s for volume %s of task: [%s]",
+		efs.fileSystemID, efs.volumeName, efs.taskARN)
+	err := efs.create()
+	if err != nil {
+		efs.setTerminalReason(err.Error())
+	}
+	return err
+}
+
+func (efs *EFSResource) create() error {
+	if err := os.MkdirAll(efs.mountDir, 0755); err != nil {
+		return errors.Wrap(err, "efs resource: unable to create mount directory")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mountTimeout)
+	defer cancel()
+	output, err := execCommandContext(ctx, "mount", efs.mountArgs()...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("efs resource: timed out mounting file system %s after %s",
+			efs.fileSystemID, mountTimeout)
+	}
+	if err != nil {
+		return errors.Errorf("efs resource: unable to mount file system %s: %v: %s",
+			efs.fileSystemID, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// mountArgs returns the arguments of the mount command
+func (efs *EFSResource) mountArgs() []string {
+	var options []string
+	if efs.transitEncryption {
+		options = append(options, "tls")
+		if efs.transitEncryptionPort > 0 {
+			options = append(options, "tlsport="+strconv.FormatInt(efs.transitEncryptionPort, 10))
+		}
+	}
+	if efs.accessPointID != "" {
+		options = append(options, "accesspoint="+efs.accessPointID)
+	}
+	if efs.iamAuth {
+		options = append(options, "iam", "awscredsuri="+efs.credentialsRelativeURI)
+	}
+
+	args := []string{"-t", mountType}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	return append(args, efs.fileSystemID+":"+efs.rootDirectory, efs.mountDir)
+}
+
+// Cleanup unmounts the file system and removes the mount directory. The
+// directory is only removed once empty, so that the contents of the file
+// system are never deleted when unmounting fails
+func (efs *EFSResource) Cleanup() error {
+	if _, err := os.Stat(efs.mountDir); os.IsNotExist(err) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), unmountTimeout)
+	defer cancel()
+	output, err := execCommandContext(ctx, "umount", efs.mountDir).CombinedOutput()
+	if err != nil {
+		seelog.Warnf("efs resource: unable to unmount %s, it may not be mounted: %v: %s",
+			efs.mountDir, err, strings.TrimSpace(string(output)))
+	}
+	if err := os.Remove(efs.mountDir); err != nil {
+		return errors.Wrapf(err, "efs resource: unable to remove mount directory %s", efs.mountDir)
+	}
+	// The task directory is shared by the efs volumes of the task and is
+	// removed with the last of them
+	os.Remove(filepath.Dir(efs.mountDir))
+	return nil
+}
+
+// HostPath returns the path on the host the file system is mounted at
+func (efs *EFSResource) HostPath() string {
+	return efs.mountDirOnHost
+}
+
+func (efs *EFSResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	efs.initStatusToTransition()
+
+	// if task hasn't turn to 'created' status, and it's desire status is 'running'
+	// the resource status needs to be reset to 'NONE' status so the file system
+	// will be mounted again
+	if taskKnownStatus < status.TaskCreated &&
+		taskDesiredStatus <= status.TaskRunning {
+		efs.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+type EFSResourceJSON struct {
+	VolumeName             string     `json:"volumeName"`
+	TaskARN                string     `json:"taskARN"`
+	CreatedAt              *time.Time `json:"createdAt,omitempty"`
+	DesiredStatus          *EFSStatus `json:"desiredStatus"`
+	KnownStatus            *EFSStatus `json:"knownStatus"`
+	FileSystemID           string     `json:"fileSystemId"`
+	RootDirectory          string     `json:"rootDirectory"`
+	TransitEncryption      bool       `json:"transitEncryption"`
+	TransitEncryptionPort  int64      `json:"transitEncryptionPort,omitempty"`
+	AccessPointID          string     `json:"accessPointId,omitempty"`
+	IAMAuth                bool       `json:"iamAuth"`
+	CredentialsRelativeURI string     `json:"credentialsRelativeURI,omitempty"`
+	MountDir               string     `json:"mountDir"`
+	MountDirOnHost         string     `json:"mountDirOnHost"`
+}
+
+// MarshalJSON serialises the EFSResource struct to JSON
+func (efs *EFSResource) MarshalJSON() ([]byte, error) {
+	if efs == nil {
+		return nil, errors.New("efs resource is nil")
+	}
+	createdAt := efs.GetCreatedAt()
+	return json.Marshal(EFSResourceJSON{
+		VolumeName: efs.volumeName,
+		TaskARN:    efs.taskARN,
+		CreatedAt:  &createdAt,
+		DesiredStatus: func() *EFSStatus {
+			desiredState := efs.GetDesiredStatus()
+			s := EFSStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *EFSStatus {
+			knownState := efs.GetKnownStatus()
+			s := EFSStatus(knownState)
+			return &s
+		}(),
+		FileSystemID:           efs.fileSystemID,
+		RootDirectory:          efs.rootDirectory,
+		TransitEncryption:      efs.transitEncryption,
+		TransitEncryptionPort:  efs.transitEncryptionPort,
+		AccessPointID:          efs.accessPointID,
+		IAMAuth:                efs.iamAuth,
+		CredentialsRelativeURI: efs.credentialsRelativeURI,
+		MountDir:               efs.mountDir,
+		MountDirOnHost:         efs.mountDirOnHost,
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a EFSResource struct
+func (efs *EFSResource) UnmarshalJSON(b []byte) error {
+	temp := EFSResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	if temp.DesiredStatus != nil {
+		efs.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		efs.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		efs.SetCreatedAt(*temp.CreatedAt)
+	}
+	efs.volumeName = temp.VolumeName
+	efs.taskARN = temp.TaskARN
+	efs.fileSystemID = temp.FileSystemID
+	efs.rootDirectory = temp.RootDirectory
+	efs.transitEncryption = temp.TransitEncryption
+	efs.transitEncryptionPort = temp.TransitEncryptionPort
+	efs.accessPointID = temp.AccessPointID
+	efs.iamAuth = temp.IAMAuth
+	efs.credentialsRelativeURI = temp.CredentialsRelativeURI
+	efs.mountDir = temp.MountDir
+	efs.mountDirOnHost = temp.MountDirOnHost
+
+	return nil
+}
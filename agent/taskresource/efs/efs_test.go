@@ -0,0 +1,194 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN                = "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id"
+	volumeName             = "data"
+	dataDirOnHost          = "/var/lib/ecs"
+	credentialsRelativeURI = "/v2/credentials/credentials-id"
+)
+
+// fakeExecCommand replaces the commands run by the resource with the given
+// command and records the arguments they were run with
+func fakeExecCommand(command string, calls *[][]string) func(context.Context, string, ...string) *exec.Cmd {
+	return func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, append([]string{name}, args...))
+		return exec.CommandContext(ctx, command)
+	}
+}
+
+func TestMountArgs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		volumeConfig *taskresourcevolume.EFSVolumeConfig
+		expectedArgs []string
+	}{
+		{
+			name:         "nfs",
+			volumeConfig: &taskresourcevolume.EFSVolumeConfig{FileSystemID: "fs-12345678"},
+			expectedArgs: []string{"-t", "efs", "fs-12345678:/", "/data/efs/task-id/data"},
+		},
+		{
+			name: "tls with access point and iam",
+			volumeConfig: &taskresourcevolume.EFSVolumeConfig{
+				FileSystemID:          "fs-12345678",
+				RootDirectory:         "/export",
+				TransitEncryption:     EnabledValue,
+				TransitEncryptionPort: 20049,
+				AuthorizationConfig: taskresourcevolume.EFSAuthorizationConfig{
+					AccessPointID: "fsap-12345678",
+					IAM:           EnabledValue,
+				},
+			},
+			expectedArgs: []string{"-t", "efs",
+				"-o", "tls,tlsport=20049,accesspoint=fsap-12345678,iam,awscredsuri=/v2/credentials/credentials-id",
+				"fs-12345678:/export", "/data/efs/task-id/data"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			efs, err := NewEFSResource(taskARN, volumeName, tc.volumeConfig, credentialsRelativeURI, "/data", dataDirOnHost)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedArgs, efs.mountArgs())
+			assert.Equal(t, "/var/lib/ecs/data/efs/task-id/data", efs.HostPath())
+		})
+	}
+}
+
+func TestNewEFSResourceInvalid(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		volumeConfig           *taskresourcevolume.EFSVolumeConfig
+		credentialsRelativeURI string
+	}{
+		{
+			name:         "missing file system id",
+			volumeConfig: &taskresourcevolume.EFSVolumeConfig{},
+		},
+		{
+			name: "access point without transit encryption",
+			volumeConfig: &taskresourcevolume.EFSVolumeConfig{
+				FileSystemID:        "fs-12345678",
+				AuthorizationConfig: taskresourcevolume.EFSAuthorizationConfig{AccessPointID: "fsap-12345678"},
+			},
+			credentialsRelativeURI: credentialsRelativeURI,
+		},
+		{
+			name: "iam without task role",
+			volumeConfig: &taskresourcevolume.EFSVolumeConfig{
+				FileSystemID:        "fs-12345678",
+				TransitEncryption:   EnabledValue,
+				AuthorizationConfig: taskresourcevolume.EFSAuthorizationConfig{IAM: EnabledValue},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEFSResource(taskARN, volumeName, tc.volumeConfig, tc.credentialsRelativeURI, "/data", dataDirOnHost)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCreateAndCleanup(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "efs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+	var calls [][]string
+	execCommandContext = fakeExecCommand("true", &calls)
+	defer func() { execCommandContext = exec.CommandContext }()
+
+	efs, err := NewEFSResource(taskARN, volumeName,
+		&taskresourcevolume.EFSVolumeConfig{FileSystemID: "fs-12345678"}, "", dataDir, dataDirOnHost)
+	require.NoError(t, err)
+	mountDir := filepath.Join(dataDir, "efs", "task-id", volumeName)
+
+	require.NoError(t, efs.Create())
+	_, err = os.Stat(mountDir)
+	assert.NoError(t, err)
+
+	require.NoError(t, efs.Cleanup())
+	_, err = os.Stat(filepath.Join(dataDir, "efs", "task-id"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, [][]string{
+		{"mount", "-t", "efs", "fs-12345678:/", mountDir},
+		{"umount", mountDir},
+	}, calls)
+}
+
+func TestCreateMountFailure(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "efs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+	var calls [][]string
+	execCommandContext = fakeExecCommand("false", &calls)
+	defer func() { execCommandContext = exec.CommandContext }()
+
+	efs, err := NewEFSResource(taskARN, volumeName,
+		&taskresourcevolume.EFSVolumeConfig{FileSystemID: "fs-12345678"}, "", dataDir, dataDirOnHost)
+	require.NoError(t, err)
+
+	assert.Error(t, efs.Create())
+	assert.NotEmpty(t, efs.GetTerminalReason())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	efsResIn, err := NewEFSResource(taskARN, volumeName, &taskresourcevolume.EFSVolumeConfig{
+		FileSystemID:      "fs-12345678",
+		TransitEncryption: EnabledValue,
+		AuthorizationConfig: taskresourcevolume.EFSAuthorizationConfig{
+			AccessPointID: "fsap-12345678",
+			IAM:           EnabledValue,
+		},
+	}, credentialsRelativeURI, "/data", dataDirOnHost)
+	require.NoError(t, err)
+	efsResIn.SetCreatedAt(time.Now())
+	efsResIn.SetKnownStatus(resourcestatus.ResourceCreated)
+	efsResIn.SetDesiredStatus(resourcestatus.ResourceCreated)
+
+	bytes, err := json.Marshal(efsResIn)
+	require.NoError(t, err)
+
+	efsResOut := &EFSResource{}
+	err = json.Unmarshal(bytes, efsResOut)
+	require.NoError(t, err)
+	assert.Equal(t, efsResIn.volumeName, efsResOut.volumeName)
+	assert.Equal(t, efsResIn.taskARN, efsResOut.taskARN)
+	assert.WithinDuration(t, efsResIn.createdAt, efsResOut.createdAt, time.Microsecond)
+	assert.Equal(t, efsResIn.desiredStatusUnsafe, efsResOut.desiredStatusUnsafe)
+	assert.Equal(t, efsResIn.knownStatusUnsafe, efsResOut.knownStatusUnsafe)
+	assert.Equal(t, efsResIn.mountArgs(), efsResOut.mountArgs())
+	assert.Equal(t, efsResIn.mountDirOnHost, efsResOut.mountDirOnHost)
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"errors"
+	"strings"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+type EFSStatus resourcestatus.ResourceStatus
+
+const (
+	// is the zero state of a task resource
+	EFSStatusNone EFSStatus = iota
+	// represents a task resource which has been created
+	EFSCreated
+	// represents a task resource which has been cleaned up
+	EFSRemoved
+)
+
+var efsStatusMap = map[string]EFSStatus{
+	"NONE":    EFSStatusNone,
+	"CREATED": EFSCreated,
+	"REMOVED": EFSRemoved,
+}
+
+// StatusString returns a human readable string representation of this object
+func (fs EFSStatus) String() string {
+	for k, v := range efsStatusMap {
+		if v == fs {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (fs *EFSStatus) MarshalJSON() ([]byte, error) {
+	if fs == nil {
+		return nil, errors.New("efs resource status is nil")
+	}
+	return []byte(`"` + fs.String() + `"`), nil
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (fs *EFSStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*fs = EFSStatusNone
+		return nil
+	}
+
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		*fs = EFSStatusNone
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+
+	strStatus := string(b[1 : len(b)-1])
+	stat, ok := efsStatusMap[strStatus]
+	if !ok {
+		*fs = EFSStatusNone
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*fs = stat
+	return nil
+}
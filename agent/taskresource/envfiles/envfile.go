@@ -0,0 +1,507 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envfiles
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	"github.com/aws/amazon-ecs-agent/agent/s3"
+	"github.com/aws/amazon-ecs-agent/agent/s3/factory"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ResourceName is the name of the environment files resource
+	ResourceName = "envfile"
+
+	resourceDirName = "envfiles"
+	envFileSuffix   = ".env"
+	// downloadTimeout is the timeout of the download of an environment file
+	downloadTimeout = 5 * time.Minute
+	// commentPrefix starts the lines of an environment file that are ignored
+	commentPrefix = "#"
+)
+
+// EnvironmentFileResource downloads the environment files of the containers
+// of a task from S3 with the task role before the containers are created. The
+// files are kept in the agent's data directory until the task is cleaned up,
+// and their variables are added to the environment of each container when it
+// is created.
+type EnvironmentFileResource struct {
+	taskARN             string
+	createdAt           time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatus is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatus                      resourcestatus.ResourceStatus
+	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
+	credentialsManager                 credentials.Manager
+	// credentialsID is the id of the credentials of the task role
+	credentialsID string
+	// region is the region of the instance, in which the region of each
+	// bucket is looked up
+	region string
+
+	// environmentFiles are the environment files of each container, keyed by
+	// the name of the container
+	environmentFiles map[string][]apicontainer.EnvironmentFile
+	// resourceDir is the directory the files are downloaded to
+	resourceDir string
+
+	// s3ClientCreator is a factory interface that creates new S3 clients. This is
+	// needed mostly for testing.
+	s3ClientCreator factory.S3ClientCreator
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisioning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewEnvironmentFileResource creates a new EnvironmentFileResource object
+func NewEnvironmentFileResource(taskARN, region string,
+	environmentFiles map[string][]apicontainer.EnvironmentFile,
+	credentialsID string,
+	credentialsManager credentials.Manager,
+	s3ClientCreator factory.S3ClientCreator,
+	dataDir string) (*EnvironmentFileResource, error) {
+	for containerName, envFiles := range environmentFiles {
+		for _, envFile := range envFiles {
+			if envFile.Type != apicontainer.EnvironmentFileTypeS3 {
+				return nil, errors.Errorf("environment file resource: unsupported type %s of environment file %s of container %s",
+					envFile.Type, envFile.Value, containerName)
+			}
+			if _, _, err := s3.ParseS3ARN(envFile.Value); err != nil {
+				return nil, errors.Wrapf(err, "environment file resource: container %s", containerName)
+			}
+		}
+	}
+	taskID, err := taskIDFromARN(taskARN)
+	if err != nil {
+		return nil, err
+	}
+
+	envfile := &EnvironmentFileResource{
+		taskARN:            taskARN,
+		region:             region,
+		environmentFiles:   environmentFiles,
+		credentialsID:      credentialsID,
+		credentialsManager: credentialsManager,
+		s3ClientCreator:    s3ClientCreator,
+		resourceDir:        filepath.Join(dataDir, resourceDirName, taskID),
+	}
+	envfile.initStatusToTransition()
+	return envfile, nil
+}
+
+func taskIDFromARN(taskARN string) (string, error) {
+	fields := strings.Split(taskARN, "/")
+	if len(fields) < 2 || fields[len(fields)-1] == "" {
+		return "", errors.Errorf("environment file resource: invalid task arn: %s", taskARN)
+	}
+	return fields[len(fields)-1], nil
+}
+
+func (envfile *EnvironmentFileResource) initStatusToTransition() {
+	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(EnvFileCreated): envfile.Create,
+	}
+	envfile.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
+}
+
+func (envfile *EnvironmentFileResource) setTerminalReason(reason string) {
+	envfile.terminalReasonOnce.Do(func() {
+		seelog.Infof("environment file resource: setting terminal reason for environment file resource in task: [%s]",
+			envfile.taskARN)
+		envfile.terminalReason = reason
+	})
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (envfile *EnvironmentFileResource) GetTerminalReason() string {
+	return envfile.terminalReason
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (envfile *EnvironmentFileResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	envfile.lock.Lock()
+	defer envfile.lock.Unlock()
+
+	envfile.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (envfile *EnvironmentFileResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	return envfile.desiredStatusUnsafe
+}
+
+// GetName returns the name of the resource
+func (envfile *EnvironmentFileResource) GetName() string {
+	return ResourceName
+}
+
+// DesiredTerminal returns true if the resource's desired status is REMOVED
+func (envfile *EnvironmentFileResource) DesiredTerminal() bool {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	return envfile.desiredStatusUnsafe == resourcestatus.ResourceStatus(EnvFileRemoved)
+}
+
+// KnownCreated returns true if the resource's known status is CREATED
+func (envfile *EnvironmentFileResource) KnownCreated() bool {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	return envfile.knownStatusUnsafe == resourcestatus.ResourceStatus(EnvFileCreated)
+}
+
+// TerminalStatus returns the last transition state of the resource
+func (envfile *EnvironmentFileResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EnvFileRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (envfile *EnvironmentFileResource) NextKnownState() resourcestatus.ResourceStatus {
+	return envfile.GetKnownStatus() + 1
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (envfile *EnvironmentFileResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := envfile.resourceStatusToTransitionFunction[nextState]
+	if !ok {
+		return errors.Errorf("resource [%s]: transition to %s impossible", envfile.GetName(),
+			envfile.StatusString(nextState))
+	}
+	return transitionFunc()
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (envfile *EnvironmentFileResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EnvFileCreated)
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (envfile *EnvironmentFileResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	envfile.lock.Lock()
+	defer envfile.lock.Unlock()
+
+	envfile.knownStatusUnsafe = status
+	envfile.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (envfile *EnvironmentFileResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if envfile.appliedStatus == resourcestatus.ResourceStatus(EnvFileStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if envfile.appliedStatus <= knownStatus {
+		envfile.appliedStatus = resourcestatus.ResourceStatus(EnvFileStatusNone)
+	}
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (envfile *EnvironmentFileResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	envfile.lock.Lock()
+	defer envfile.lock.Unlock()
+
+	if envfile.appliedStatus != resourcestatus.ResourceStatus(EnvFileStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	envfile.appliedStatus = status
+	return true
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (envfile *EnvironmentFileResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	return envfile.knownStatusUnsafe
+}
+
+// StatusString returns the string of the environment file resource status
+func (envfile *EnvironmentFileResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return EnvFileStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (envfile *EnvironmentFileResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	envfile.lock.Lock()
+	defer envfile.lock.Unlock()
+
+	envfile.createdAt = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (envfile *EnvironmentFileResource) GetCreatedAt() time.Time {
+	envfile.lock.RLock()
+	defer envfile.lock.RUnlock()
+
+	return envfile.createdAt
+}
+
+// Create downloads the environment files of the task's containers
+func (envfile *EnvironmentFileResource) Create() error {
+	seelog.Infof("environment file resource: downloading environment files for containers in task: [%s]",
+		envfile.taskARN)
+	err := envfile.create()
+	if err != nil {
+		envfile.setTerminalReason(err.Error())
+	}
+	return err
+}
+
+func (envfile *EnvironmentFileResource) create() error {
+	// To fail fast, check the task role first
+	taskCredentials, ok := envfile.credentialsManager.GetTaskCredentials(envfile.credentialsID)
+	if !ok {
+		// No need to log here. managedTask.applyResourceState already does that
+		return errors.New("environment file resource: unable to find task role credentials")
+	}
+	iamCredentials := taskCredentials.GetIAMRoleCredentials()
+
+	if err := os.MkdirAll(envfile.resourceDir, 0700); err != nil {
+		return errors.Wrap(err, "environment file resource: unable to create resource directory")
+	}
+	for _, envFileARN := range envfile.envFileARNs() {
+		if err := envfile.downloadEnvFile(envFileARN, iamCredentials); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadEnvFile downloads the environment file to a temporary file that is
+// renamed once complete, so that a partial download is never read
+func (envfile *EnvironmentFileResource) downloadEnvFile(envFileARN string,
+	iamCredentials credentials.IAMRoleCredentials) error {
+	bucket, key, err := s3.ParseS3ARN(envFileARN)
+	if err != nil {
+		return errors.Wrap(err, "environment file resource")
+	}
+	client, err := envfile.s3ClientCreator.NewS3ManagerClient(bucket, envfile.region, iamCredentials)
+	if err != nil {
+		return errors.Wrapf(err, "environment file resource: unable to download environment file %s", envFileARN)
+	}
+
+	tmpFile, err := ioutil.TempFile(envfile.resourceDir, "download")
+	if err != nil {
+		return errors.Wrap(err, "environment file resource: unable to create download file")
+	}
+	defer os.Remove(tmpFile.Name())
+	err = s3.DownloadFile(bucket, key, downloadTimeout, tmpFile, client)
+	tmpFile.Close()
+	if err != nil {
+		return errors.Wrapf(err, "environment file resource: unable to download environment file %s", envFileARN)
+	}
+	if err := os.Rename(tmpFile.Name(), envfile.envFilePath(envFileARN)); err != nil {
+		return errors.Wrapf(err, "environment file resource: unable to save environment file %s", envFileARN)
+	}
+	return nil
+}
+
+// envFileARNs returns the sorted ARNs of the environment files of all the
+// containers, each file being downloaded once
+func (envfile *EnvironmentFileResource) envFileARNs() []string {
+	arns := make(map[string]struct{})
+	for _, envFiles := range envfile.environmentFiles {
+		for _, envFile := range envFiles {
+			arns[envFile.Value] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(arns))
+	for arn := range arns {
+		sorted = append(sorted, arn)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// envFilePath returns the path the environment file is downloaded to. It is
+// named after the hash of the ARN, as object keys may contain any character
+func (envfile *EnvironmentFileResource) envFilePath(envFileARN string) string {
+	hash := sha256.Sum256([]byte(envFileARN))
+	return filepath.Join(envfile.resourceDir, hex.EncodeToString(hash[:])+envFileSuffix)
+}
+
+// ReadEnvVarsFromEnvFiles returns the variables of each environment file of
+// the container, in the order the files are listed in the container
+func (envfile *EnvironmentFileResource) ReadEnvVarsFromEnvFiles(containerName string) ([]map[string]string, error) {
+	var envVarsList []map[string]string
+	for _, envFile := range envfile.environmentFiles[containerName] {
+		file, err := os.Open(envfile.envFilePath(envFile.Value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "environment file resource: unable to open environment file %s", envFile.Value)
+		}
+		envVars, err := parseEnvFile(file, envFile.Value)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		envVarsList = append(envVarsList, envVars)
+	}
+	return envVarsList, nil
+}
+
+// parseEnvFile parses the VARIABLE=VALUE lines of an environment file. Blank
+// lines and lines starting with # are ignored, as are lines that are not a
+// variable assignment. Values are taken as is, without removing quotes
+func parseEnvFile(r io.Reader, envFileARN string) (map[string]string, error) {
+	envVars := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, commentPrefix) {
+			continue
+		}
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) == "" {
+			seelog.Warnf("environment file resource: ignoring line %d of environment file %s, it is not of the form VARIABLE=VALUE",
+				lineNumber, envFileARN)
+			continue
+		}
+		envVars[strings.TrimSpace(fields[0])] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "environment file resource: unable to read environment file %s", envFileARN)
+	}
+	return envVars, nil
+}
+
+// Cleanup removes the downloaded environment files of the task
+func (envfile *EnvironmentFileResource) Cleanup() error {
+	if err := os.RemoveAll(envfile.resourceDir); err != nil {
+		return errors.Wrapf(err, "environment file resource: unable to remove directory %s", envfile.resourceDir)
+	}
+	return nil
+}
+
+func (envfile *EnvironmentFileResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	envfile.initStatusToTransition()
+	envfile.credentialsManager = resourceFields.CredentialsManager
+	envfile.s3ClientCreator = resourceFields.S3ClientCreator
+
+	// if task hasn't turn to 'created' status, and it's desire status is 'running'
+	// the resource status needs to be reset to 'NONE' status so the environment
+	// files will be downloaded again
+	if taskKnownStatus < status.TaskCreated &&
+		taskDesiredStatus <= status.TaskRunning {
+		envfile.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+type EnvironmentFileResourceJSON struct {
+	TaskARN          string                                    `json:"taskARN"`
+	CreatedAt        *time.Time                                `json:"createdAt,omitempty"`
+	DesiredStatus    *EnvFileStatus                            `json:"desiredStatus"`
+	KnownStatus      *EnvFileStatus                            `json:"knownStatus"`
+	CredentialsID    string                                    `json:"credentialsID"`
+	Region           string                                    `json:"region"`
+	EnvironmentFiles map[string][]apicontainer.EnvironmentFile `json:"environmentFiles"`
+	ResourceDir      string                                    `json:"resourceDir"`
+}
+
+// MarshalJSON serialises the EnvironmentFileResource struct to JSON
+func (envfile *EnvironmentFileResource) MarshalJSON() ([]byte, error) {
+	if envfile == nil {
+		return nil, errors.New("environment file resource is nil")
+	}
+	createdAt := envfile.GetCreatedAt()
+	return json.Marshal(EnvironmentFileResourceJSON{
+		TaskARN:   envfile.taskARN,
+		CreatedAt: &createdAt,
+		DesiredStatus: func() *EnvFileStatus {
+			desiredState := envfile.GetDesiredStatus()
+			s := EnvFileStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *EnvFileStatus {
+			knownState := envfile.GetKnownStatus()
+			s := EnvFileStatus(knownState)
+			return &s
+		}(),
+		CredentialsID:    envfile.credentialsID,
+		Region:           envfile.region,
+		EnvironmentFiles: envfile.environmentFiles,
+		ResourceDir:      envfile.resourceDir,
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a EnvironmentFileResource struct
+func (envfile *EnvironmentFileResource) UnmarshalJSON(b []byte) error {
+	temp := EnvironmentFileResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	if temp.DesiredStatus != nil {
+		envfile.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		envfile.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		envfile.SetCreatedAt(*temp.CreatedAt)
+	}
+	envfile.taskARN = temp.TaskARN
+	envfile.credentialsID = temp.CredentialsID
+	envfile.region = temp.Region
+	envfile.environmentFiles = temp.EnvironmentFiles
+	envfile.resourceDir = temp.ResourceDir
+
+	return nil
+}
@@ -0,0 +1,206 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envfiles
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/agent/credentials/mocks"
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/s3/factory/mocks"
+	mock_s3 "github.com/aws/amazon-ecs-agent/agent/s3/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN       = "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id"
+	region        = "us-west-2"
+	credentialsID = "credentials-id"
+	commonEnvFile = "arn:aws:s3:::bucket/common.env"
+	appEnvFile    = "arn:aws:s3:::bucket/app.env"
+)
+
+var testEnvFiles = map[string][]apicontainer.EnvironmentFile{
+	"app": {
+		{Value: appEnvFile, Type: apicontainer.EnvironmentFileTypeS3},
+		{Value: commonEnvFile, Type: apicontainer.EnvironmentFileTypeS3},
+	},
+	"sidecar": {
+		{Value: commonEnvFile, Type: apicontainer.EnvironmentFileTypeS3},
+	},
+}
+
+var testEnvFileContents = map[string]string{
+	"app.env":    "# app settings\nLOG_LEVEL=debug\n\nGREETING=hello=world\n",
+	"common.env": "LOG_LEVEL=info\nREGION=us-west-2\nnot a variable\n",
+}
+
+// downloadEnvFile writes the contents of the test environment file with the
+// key of the input
+func downloadEnvFile(ctx aws.Context, w io.WriterAt, input *s3.GetObjectInput) (int64, error) {
+	n, err := w.WriteAt([]byte(testEnvFileContents[aws.StringValue(input.Key)]), 0)
+	return int64(n), err
+}
+
+func newTestEnvFileResource(t *testing.T, ctrl *gomock.Controller) (*EnvironmentFileResource,
+	*mock_credentials.MockManager, *mock_factory.MockS3ClientCreator, string) {
+	dataDir, err := ioutil.TempDir("", "envfiles")
+	require.NoError(t, err)
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	s3ClientCreator := mock_factory.NewMockS3ClientCreator(ctrl)
+	envfile, err := NewEnvironmentFileResource(taskARN, region, testEnvFiles, credentialsID,
+		credentialsManager, s3ClientCreator, dataDir)
+	require.NoError(t, err)
+	return envfile, credentialsManager, s3ClientCreator, dataDir
+}
+
+func TestNewEnvironmentFileResourceInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		envFiles map[string][]apicontainer.EnvironmentFile
+	}{
+		{
+			name: "unsupported type",
+			envFiles: map[string][]apicontainer.EnvironmentFile{
+				"app": {{Value: appEnvFile, Type: "ssm"}},
+			},
+		},
+		{
+			name: "invalid arn",
+			envFiles: map[string][]apicontainer.EnvironmentFile{
+				"app": {{Value: "bucket/app.env", Type: apicontainer.EnvironmentFileTypeS3}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEnvironmentFileResource(taskARN, region, tc.envFiles, credentialsID, nil, nil, "/data")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCreateAndReadEnvVars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	envfile, credentialsManager, s3ClientCreator, dataDir := newTestEnvFileResource(t, ctrl)
+	defer os.RemoveAll(dataDir)
+
+	iamCredentials := credentials.IAMRoleCredentials{CredentialsID: credentialsID}
+	credentialsManager.EXPECT().GetTaskCredentials(credentialsID).Return(
+		credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamCredentials}, true)
+	s3Client := mock_s3.NewMockS3ManagerClient(ctrl)
+	// each file is downloaded once, even when used by several containers
+	s3ClientCreator.EXPECT().NewS3ManagerClient("bucket", region, iamCredentials).Return(s3Client, nil).Times(2)
+	s3Client.EXPECT().DownloadWithContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		downloadEnvFile).Times(2)
+
+	require.NoError(t, envfile.Create())
+
+	envVarsList, err := envfile.ReadEnvVarsFromEnvFiles("app")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"LOG_LEVEL": "debug", "GREETING": "hello=world"},
+		{"LOG_LEVEL": "info", "REGION": "us-west-2"},
+	}, envVarsList)
+
+	require.NoError(t, envfile.Cleanup())
+	_, err = os.Stat(filepath.Join(dataDir, resourceDirName, "task-id"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCreateMissingCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	envfile, credentialsManager, _, dataDir := newTestEnvFileResource(t, ctrl)
+	defer os.RemoveAll(dataDir)
+
+	credentialsManager.EXPECT().GetTaskCredentials(credentialsID).Return(credentials.TaskIAMRoleCredentials{}, false)
+
+	assert.Error(t, envfile.Create())
+	assert.NotEmpty(t, envfile.GetTerminalReason())
+}
+
+func TestCreateDownloadFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	envfile, credentialsManager, s3ClientCreator, dataDir := newTestEnvFileResource(t, ctrl)
+	defer os.RemoveAll(dataDir)
+
+	credentialsManager.EXPECT().GetTaskCredentials(credentialsID).Return(credentials.TaskIAMRoleCredentials{}, true)
+	s3Client := mock_s3.NewMockS3ManagerClient(ctrl)
+	s3ClientCreator.EXPECT().NewS3ManagerClient("bucket", region, gomock.Any()).Return(s3Client, nil)
+	s3Client.EXPECT().DownloadWithContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		int64(0), errors.New("access denied"))
+
+	assert.Error(t, envfile.Create())
+	assert.Contains(t, envfile.GetTerminalReason(), appEnvFile)
+	assert.Contains(t, envfile.GetTerminalReason(), "access denied")
+	// the partial download is removed
+	files, err := ioutil.ReadDir(filepath.Join(dataDir, resourceDirName, "task-id"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestParseEnvFile(t *testing.T) {
+	envVars, err := parseEnvFile(strings.NewReader(
+		"A=1\n  # comment\nB = two words \nC=\n=nokey\nD=\"quoted\"\n"), appEnvFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"A": "1",
+		"B": " two words ",
+		"C": "",
+		"D": `"quoted"`,
+	}, envVars)
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	envFileResIn, err := NewEnvironmentFileResource(taskARN, region, testEnvFiles, credentialsID, nil, nil, "/data")
+	require.NoError(t, err)
+	envFileResIn.SetCreatedAt(time.Now())
+	envFileResIn.SetKnownStatus(resourcestatus.ResourceCreated)
+	envFileResIn.SetDesiredStatus(resourcestatus.ResourceCreated)
+
+	bytes, err := json.Marshal(envFileResIn)
+	require.NoError(t, err)
+
+	envFileResOut := &EnvironmentFileResource{}
+	err = json.Unmarshal(bytes, envFileResOut)
+	require.NoError(t, err)
+	assert.Equal(t, envFileResIn.taskARN, envFileResOut.taskARN)
+	assert.WithinDuration(t, envFileResIn.createdAt, envFileResOut.createdAt, time.Microsecond)
+	assert.Equal(t, envFileResIn.desiredStatusUnsafe, envFileResOut.desiredStatusUnsafe)
+	assert.Equal(t, envFileResIn.knownStatusUnsafe, envFileResOut.knownStatusUnsafe)
+	assert.Equal(t, envFileResIn.credentialsID, envFileResOut.credentialsID)
+	assert.Equal(t, envFileResIn.region, envFileResOut.region)
+	assert.Equal(t, envFileResIn.environmentFiles, envFileResOut.environmentFiles)
+	assert.Equal(t, envFileResIn.resourceDir, envFileResOut.resourceDir)
+}
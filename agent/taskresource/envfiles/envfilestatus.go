@@ -0,0 +1,78 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package envfiles
+
+import (
+	"errors"
+	"strings"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+type EnvFileStatus resourcestatus.ResourceStatus
+
+const (
+	// is the zero state of a task resource
+	EnvFileStatusNone EnvFileStatus = iota
+	// represents a task resource which has been created
+	EnvFileCreated
+	// represents a task resource which has been cleaned up
+	EnvFileRemoved
+)
+
+var envFileStatusMap = map[string]EnvFileStatus{
+	"NONE":    EnvFileStatusNone,
+	"CREATED": EnvFileCreated,
+	"REMOVED": EnvFileRemoved,
+}
+
+// StatusString returns a human readable string representation of this object
+func (envFileStatus EnvFileStatus) String() string {
+	for k, v := range envFileStatusMap {
+		if v == envFileStatus {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (envFileStatus *EnvFileStatus) MarshalJSON() ([]byte, error) {
+	if envFileStatus == nil {
+		return nil, errors.New("envfile resource status is nil")
+	}
+	return []byte(`"` + envFileStatus.String() + `"`), nil
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (envFileStatus *EnvFileStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*envFileStatus = EnvFileStatusNone
+		return nil
+	}
+
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		*envFileStatus = EnvFileStatusNone
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+
+	strStatus := string(b[1 : len(b)-1])
+	stat, ok := envFileStatusMap[strStatus]
+	if !ok {
+		*envFileStatus = EnvFileStatusNone
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*envFileStatus = stat
+	return nil
+}
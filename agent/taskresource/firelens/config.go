@@ -0,0 +1,145 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/pkg/errors"
+)
+
+const (
+	// fluentdOutputTypeOption is the log option that selects the output plugin
+	// of a container's logs with a fluentd log router
+	fluentdOutputTypeOption = "@type"
+	// fluentbitOutputNameOption is the log option that selects the output
+	// plugin of a container's logs with a fluent bit log router
+	fluentbitOutputNameOption = "Name"
+)
+
+// generateConfig returns the contents of the log router config file
+func (firelens *FirelensResource) generateConfig() ([]byte, error) {
+	if firelens.firelensConfigType == apicontainer.FirelensConfigTypeFluentd {
+		return firelens.generateFluentdConfig()
+	}
+	return firelens.generateFluentbitConfig()
+}
+
+// generateFluentdConfig generates a config that reads the log records from the
+// unix socket, optionally adds the ECS metadata to them and sends the records
+// of every container to the output set in its log options
+func (firelens *FirelensResource) generateFluentdConfig() ([]byte, error) {
+	var config bytes.Buffer
+	fmt.Fprintf(&config, "<source>\n    @type unix\n    path %s\n</source>\n",
+		filepath.Join(SocketDirPath, socketFileName))
+
+	if firelens.externalConfigPath != "" {
+		fmt.Fprintf(&config, "\n@include %s\n", firelens.externalConfigPath)
+	}
+
+	if firelens.ecsMetadataEnabled {
+		fmt.Fprintf(&config, "\n<filter **>\n    @type record_transformer\n    <record>\n")
+		for _, record := range firelens.ecsMetadataRecords() {
+			fmt.Fprintf(&config, "        %s %s\n", record[0], record[1])
+		}
+		fmt.Fprintf(&config, "    </record>\n</filter>\n")
+	}
+
+	for _, containerName := range firelens.loggingContainerNames() {
+		options := firelens.containerToLogOptions[containerName]
+		if options[fluentdOutputTypeOption] == "" {
+			return nil, errors.Errorf("firelens resource: missing %s log option for container %s",
+				fluentdOutputTypeOption, containerName)
+		}
+		fmt.Fprintf(&config, "\n<match %s*>\n    @type %s\n", containerName+"-firelens", options[fluentdOutputTypeOption])
+		for _, key := range sortedKeys(options) {
+			if key == fluentdOutputTypeOption {
+				continue
+			}
+			fmt.Fprintf(&config, "    %s %s\n", key, options[key])
+		}
+		fmt.Fprintf(&config, "</match>\n")
+	}
+	return config.Bytes(), nil
+}
+
+// generateFluentbitConfig generates a config that reads the log records from
+// the unix socket, optionally adds the ECS metadata to them and sends the
+// records of every container to the output set in its log options
+func (firelens *FirelensResource) generateFluentbitConfig() ([]byte, error) {
+	var config bytes.Buffer
+	fmt.Fprintf(&config, "[INPUT]\n    Name forward\n    unix_path %s\n",
+		filepath.Join(SocketDirPath, socketFileName))
+
+	if firelens.externalConfigPath != "" {
+		fmt.Fprintf(&config, "\n@INCLUDE %s\n", firelens.externalConfigPath)
+	}
+
+	if firelens.ecsMetadataEnabled {
+		fmt.Fprintf(&config, "\n[FILTER]\n    Name record_modifier\n    Match *\n")
+		for _, record := range firelens.ecsMetadataRecords() {
+			fmt.Fprintf(&config, "    Record %s %s\n", record[0], record[1])
+		}
+	}
+
+	for _, containerName := range firelens.loggingContainerNames() {
+		options := firelens.containerToLogOptions[containerName]
+		if options[fluentbitOutputNameOption] == "" {
+			return nil, errors.Errorf("firelens resource: missing %s log option for container %s",
+				fluentbitOutputNameOption, containerName)
+		}
+		fmt.Fprintf(&config, "\n[OUTPUT]\n    Name %s\n    Match %s*\n", options[fluentbitOutputNameOption], containerName+"-firelens")
+		for _, key := range sortedKeys(options) {
+			if key == fluentbitOutputNameOption {
+				continue
+			}
+			fmt.Fprintf(&config, "    %s %s\n", key, options[key])
+		}
+	}
+	return config.Bytes(), nil
+}
+
+// ecsMetadataRecords returns the fields added to every log record when ECS
+// metadata is enabled
+func (firelens *FirelensResource) ecsMetadataRecords() [][2]string {
+	return [][2]string{
+		{"ecs_cluster", firelens.cluster},
+		{"ecs_task_arn", firelens.taskARN},
+		{"ecs_task_definition", firelens.taskDefinition},
+	}
+}
+
+// loggingContainerNames returns the sorted names of the containers whose logs
+// are routed, so that the generated config is stable
+func (firelens *FirelensResource) loggingContainerNames() []string {
+	names := make([]string, 0, len(firelens.containerToLogOptions))
+	for name := range firelens.containerToLogOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
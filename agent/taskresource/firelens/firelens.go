@@ -0,0 +1,483 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/cihub/seelog"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ResourceName is the name of the firelens resource
+	ResourceName = "firelens"
+
+	// FluentdConfigPath is the path of the generated config file in a fluentd
+	// log router container
+	FluentdConfigPath = "/fluentd/etc/fluent.conf"
+	// FluentbitConfigPath is the path of the generated config file in a fluent
+	// bit log router container
+	FluentbitConfigPath = "/fluent-bit/etc/fluent-bit.conf"
+	// SocketDirPath is the directory of the log router container in which the
+	// log router listens on its unix socket
+	SocketDirPath = "/var/run"
+
+	// enableECSLogMetadataOption is the log router option that controls whether
+	// the cluster, task ARN and task definition are added to every log record
+	enableECSLogMetadataOption = "enable-ecs-log-metadata"
+	// configFileTypeOption is the log router option that specifies where a
+	// custom config file to include comes from. Only "file" is supported
+	configFileTypeOption = "config-file-type"
+	// configFileValueOption is the log router option that specifies the path of
+	// the custom config file in the log router container
+	configFileValueOption = "config-file-value"
+	configFileTypeFile    = "file"
+
+	resourceDirName       = "firelens"
+	configDirName         = "config"
+	socketDirName         = "socket"
+	socketFileName        = "fluent.sock"
+	fluentdConfigFileName = "fluent.conf"
+	fluentbitConfigFile   = "fluent-bit.conf"
+
+	// fluentdLogDriver is the docker log driver containers use to send their
+	// logs to the log router
+	fluentdLogDriver = "fluentd"
+)
+
+// FirelensResource represents the config file and unix socket directory of the
+// FireLens log router of a task. The config routes the logs of every container
+// of the task using the awsfirelens log driver to the outputs set in its log
+// options.
+type FirelensResource struct {
+	cluster             string
+	taskARN             string
+	taskDefinition      string
+	createdAt           time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatus is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatus                      resourcestatus.ResourceStatus
+	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
+
+	// firelensConfigType is the log router type, fluentd or fluentbit
+	firelensConfigType string
+	// ecsMetadataEnabled specifies whether the cluster, task ARN and task
+	// definition are added to every log record
+	ecsMetadataEnabled bool
+	// externalConfigPath is the path of a custom config file in the log router
+	// container that the generated config includes
+	externalConfigPath string
+	// containerToLogOptions maps the name of every container using the
+	// awsfirelens log driver to its log options
+	containerToLogOptions map[string]map[string]string
+	// resourceDir is the directory the config file and socket are created in
+	resourceDir string
+	// resourceDirOnHost is the path of resourceDir on the host, from which it
+	// is mounted to the log router container
+	resourceDirOnHost string
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisioning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewFirelensResource creates a new FirelensResource object
+func NewFirelensResource(cluster, taskARN, taskDefinition, firelensConfigType string,
+	firelensOptions map[string]string,
+	containerToLogOptions map[string]map[string]string,
+	dataDir, dataDirOnHost string) (*FirelensResource, error) {
+	if firelensConfigType != apicontainer.FirelensConfigTypeFluentd &&
+		firelensConfigType != apicontainer.FirelensConfigTypeFluentbit {
+		return nil, errors.Errorf("firelens resource: unsupported log router type: %s", firelensConfigType)
+	}
+	taskID, err := taskIDFromARN(taskARN)
+	if err != nil {
+		return nil, err
+	}
+
+	firelens := &FirelensResource{
+		cluster:               cluster,
+		taskARN:               taskARN,
+		taskDefinition:        taskDefinition,
+		firelensConfigType:    firelensConfigType,
+		ecsMetadataEnabled:    utils.ParseBool(firelensOptions[enableECSLogMetadataOption], true),
+		containerToLogOptions: containerToLogOptions,
+		resourceDir:           filepath.Join(dataDir, resourceDirName, taskID),
+		resourceDirOnHost:     filepath.Join(dataDirOnHost, dataDir, resourceDirName, taskID),
+	}
+	if configFileType, ok := firelensOptions[configFileTypeOption]; ok {
+		if configFileType != configFileTypeFile {
+			return nil, errors.Errorf("firelens resource: unsupported config file type: %s", configFileType)
+		}
+		firelens.externalConfigPath = firelensOptions[configFileValueOption]
+		if firelens.externalConfigPath == "" {
+			return nil, errors.Errorf("firelens resource: %s is required when %s is set",
+				configFileValueOption, configFileTypeOption)
+		}
+	}
+	// Fail the task early rather than when the config is generated
+	if _, err := firelens.generateConfig(); err != nil {
+		return nil, err
+	}
+
+	firelens.initStatusToTransition()
+	return firelens, nil
+}
+
+func taskIDFromARN(taskARN string) (string, error) {
+	fields := strings.Split(taskARN, "/")
+	if len(fields) < 2 || fields[len(fields)-1] == "" {
+		return "", errors.Errorf("firelens resource: invalid task arn: %s", taskARN)
+	}
+	return fields[len(fields)-1], nil
+}
+
+func (firelens *FirelensResource) initStatusToTransition() {
+	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(FirelensCreated): firelens.Create,
+	}
+	firelens.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
+}
+
+func (firelens *FirelensResource) setTerminalReason(reason string) {
+	firelens.terminalReasonOnce.Do(func() {
+		seelog.Infof("firelens resource: setting terminal reason for firelens resource in task: [%s]", firelens.taskARN)
+		firelens.terminalReason = reason
+	})
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (firelens *FirelensResource) GetTerminalReason() string {
+	return firelens.terminalReason
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (firelens *FirelensResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	firelens.lock.Lock()
+	defer firelens.lock.Unlock()
+
+	firelens.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (firelens *FirelensResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
+	return firelens.desiredStatusUnsafe
+}
+
+// GetName safely returns the name of the resource
+func (firelens *FirelensResource) GetName() string {
+	return ResourceName
+}
+
+// DesiredTerminal returns true if the resource's desired status is REMOVED
+func (firelens *FirelensResource) DesiredTerminal() bool {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
+	return firelens.desiredStatusUnsafe == resourcestatus.ResourceStatus(FirelensRemoved)
+}
+
+// KnownCreated returns true if the resource's known status is CREATED
+func (firelens *FirelensResource) KnownCreated() bool {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
+	return firelens.knownStatusUnsafe == resourcestatus.ResourceStatus(FirelensCreated)
+}
+
+// TerminalStatus returns the last transition state of the resource
+func (firelens *FirelensResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(FirelensRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (firelens *FirelensResource) NextKnownState() resourcestatus.ResourceStatus {
+	return firelens.GetKnownStatus() + 1
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (firelens *FirelensResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := firelens.resourceStatusToTransitionFunction[nextState]
+	if !ok {
+		return errors.Errorf("resource [%s]: transition to %s impossible", firelens.GetName(),
+			firelens.StatusString(nextState))
+	}
+	return transitionFunc()
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (firelens *FirelensResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(FirelensCreated)
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (firelens *FirelensResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	firelens.lock.Lock()
+	defer firelens.lock.Unlock()
+
+	firelens.knownStatusUnsafe = status
+	firelens.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (firelens *FirelensResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if firelens.appliedStatus == resourcestatus.ResourceStatus(FirelensStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if firelens.appliedStatus <= knownStatus {
+		firelens.appliedStatus = resourcestatus.ResourceStatus(FirelensStatusNone)
+	}
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (firelens *FirelensResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	firelens.lock.Lock()
+	defer firelens.lock.Unlock()
+
+	if firelens.appliedStatus != resourcestatus.ResourceStatus(FirelensStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	firelens.appliedStatus = status
+	return true
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (firelens *FirelensResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
+	return firelens.knownStatusUnsafe
+}
+
+// StatusString returns the string of the firelens resource status
+func (firelens *FirelensResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return FirelensStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (firelens *FirelensResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	firelens.lock.Lock()
+	defer firelens.lock.Unlock()
+
+	firelens.createdAt = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (firelens *FirelensResource) GetCreatedAt() time.Time {
+	firelens.lock.RLock()
+	defer firelens.lock.RUnlock()
+
+	return firelens.createdAt
+}
+
+// Create writes the log router config file and creates the directory of its
+// unix socket
+func (firelens *FirelensResource) Create() error {
+	seelog.Infof("firelens resource: generating %s config for task: [%s]", firelens.firelensConfigType, firelens.taskARN)
+	err := firelens.create()
+	if err != nil {
+		firelens.setTerminalReason(err.Error())
+	}
+	return err
+}
+
+func (firelens *FirelensResource) create() error {
+	configDir := filepath.Join(firelens.resourceDir, configDirName)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return errors.Wrap(err, "firelens resource: unable to create config directory")
+	}
+	if err := os.MkdirAll(filepath.Join(firelens.resourceDir, socketDirName), 0755); err != nil {
+		return errors.Wrap(err, "firelens resource: unable to create socket directory")
+	}
+
+	config, err := firelens.generateConfig()
+	if err != nil {
+		return err
+	}
+	configFile := filepath.Join(configDir, firelens.configFileName())
+	if err := ioutil.WriteFile(configFile, config, 0644); err != nil {
+		return errors.Wrap(err, "firelens resource: unable to write config file")
+	}
+	return nil
+}
+
+// Cleanup removes the config file and socket directory of the task
+func (firelens *FirelensResource) Cleanup() error {
+	if err := os.RemoveAll(firelens.resourceDir); err != nil {
+		return fmt.Errorf("firelens resource: unable to remove directory %s: %v", firelens.resourceDir, err)
+	}
+	return nil
+}
+
+func (firelens *FirelensResource) configFileName() string {
+	if firelens.firelensConfigType == apicontainer.FirelensConfigTypeFluentd {
+		return fluentdConfigFileName
+	}
+	return fluentbitConfigFile
+}
+
+// LogRouterBinds returns the binds that mount the config file and the socket
+// directory to the log router container
+func (firelens *FirelensResource) LogRouterBinds() []string {
+	configPath := FluentbitConfigPath
+	if firelens.firelensConfigType == apicontainer.FirelensConfigTypeFluentd {
+		configPath = FluentdConfigPath
+	}
+	return []string{
+		filepath.Join(firelens.resourceDirOnHost, configDirName, firelens.configFileName()) + ":" + configPath,
+		filepath.Join(firelens.resourceDirOnHost, socketDirName) + ":" + SocketDirPath,
+	}
+}
+
+// LogConfig returns the docker log configuration that sends the logs of a
+// container to the log router
+func (firelens *FirelensResource) LogConfig(containerName string) dockercontainer.LogConfig {
+	return dockercontainer.LogConfig{
+		Type: fluentdLogDriver,
+		Config: map[string]string{
+			"fluentd-address":       "unix://" + filepath.Join(firelens.resourceDirOnHost, socketDirName, socketFileName),
+			"fluentd-async-connect": "true",
+			"tag":                   firelens.logTag(containerName),
+		},
+	}
+}
+
+// logTag returns the tag of the log records of a container
+func (firelens *FirelensResource) logTag(containerName string) string {
+	return containerName + "-firelens-" + filepath.Base(firelens.resourceDir)
+}
+
+func (firelens *FirelensResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	firelens.initStatusToTransition()
+
+	// if task hasn't turn to 'created' status, and it's desire status is 'running'
+	// the resource status needs to be reset to 'NONE' status so the config file
+	// will be generated again
+	if taskKnownStatus < status.TaskCreated &&
+		taskDesiredStatus <= status.TaskRunning {
+		firelens.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+type FirelensResourceJSON struct {
+	Cluster               string                       `json:"cluster"`
+	TaskARN               string                       `json:"taskARN"`
+	TaskDefinition        string                       `json:"taskDefinition"`
+	CreatedAt             *time.Time                   `json:"createdAt,omitempty"`
+	DesiredStatus         *FirelensStatus              `json:"desiredStatus"`
+	KnownStatus           *FirelensStatus              `json:"knownStatus"`
+	FirelensConfigType    string                       `json:"firelensConfigType"`
+	ECSMetadataEnabled    bool                         `json:"ecsMetadataEnabled"`
+	ExternalConfigPath    string                       `json:"externalConfigPath,omitempty"`
+	ContainerToLogOptions map[string]map[string]string `json:"containerToLogOptions"`
+	ResourceDir           string                       `json:"resourceDir"`
+	ResourceDirOnHost     string                       `json:"resourceDirOnHost"`
+}
+
+// MarshalJSON serialises the FirelensResource struct to JSON
+func (firelens *FirelensResource) MarshalJSON() ([]byte, error) {
+	if firelens == nil {
+		return nil, errors.New("firelens resource is nil")
+	}
+	createdAt := firelens.GetCreatedAt()
+	return json.Marshal(FirelensResourceJSON{
+		Cluster:        firelens.cluster,
+		TaskARN:        firelens.taskARN,
+		TaskDefinition: firelens.taskDefinition,
+		CreatedAt:      &createdAt,
+		DesiredStatus: func() *FirelensStatus {
+			desiredState := firelens.GetDesiredStatus()
+			s := FirelensStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *FirelensStatus {
+			knownState := firelens.GetKnownStatus()
+			s := FirelensStatus(knownState)
+			return &s
+		}(),
+		FirelensConfigType:    firelens.firelensConfigType,
+		ECSMetadataEnabled:    firelens.ecsMetadataEnabled,
+		ExternalConfigPath:    firelens.externalConfigPath,
+		ContainerToLogOptions: firelens.containerToLogOptions,
+		ResourceDir:           firelens.resourceDir,
+		ResourceDirOnHost:     firelens.resourceDirOnHost,
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a FirelensResource struct
+func (firelens *FirelensResource) UnmarshalJSON(b []byte) error {
+	temp := FirelensResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	if temp.DesiredStatus != nil {
+		firelens.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		firelens.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		firelens.SetCreatedAt(*temp.CreatedAt)
+	}
+	firelens.cluster = temp.Cluster
+	firelens.taskARN = temp.TaskARN
+	firelens.taskDefinition = temp.TaskDefinition
+	firelens.firelensConfigType = temp.FirelensConfigType
+	firelens.ecsMetadataEnabled = temp.ECSMetadataEnabled
+	firelens.externalConfigPath = temp.ExternalConfigPath
+	firelens.containerToLogOptions = temp.ContainerToLogOptions
+	firelens.resourceDir = temp.ResourceDir
+	firelens.resourceDirOnHost = temp.ResourceDirOnHost
+
+	return nil
+}
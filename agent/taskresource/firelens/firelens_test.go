@@ -0,0 +1,216 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cluster        = "mycluster"
+	taskARN        = "arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id"
+	taskDefinition = "myfamily:1"
+	dataDirOnHost  = "/var/lib/ecs"
+)
+
+var testLogOptions = map[string]map[string]string{
+	"app": {
+		"Name":              "cloudwatch",
+		"region":            "us-west-2",
+		"log_group_name":    "app",
+		"auto_create_group": "true",
+	},
+}
+
+var testFluentdLogOptions = map[string]map[string]string{
+	"app": {
+		"@type":          "cloudwatch_logs",
+		"region":         "us-west-2",
+		"log_group_name": "app",
+	},
+}
+
+func newTestFirelensResource(t *testing.T, firelensConfigType string, options map[string]string,
+	containerToLogOptions map[string]map[string]string) (*FirelensResource, string) {
+	dataDir, err := ioutil.TempDir("", "firelens")
+	require.NoError(t, err)
+	firelens, err := NewFirelensResource(cluster, taskARN, taskDefinition, firelensConfigType, options,
+		containerToLogOptions, dataDir, dataDirOnHost)
+	require.NoError(t, err)
+	return firelens, dataDir
+}
+
+func TestCreateFluentbit(t *testing.T) {
+	firelens, dataDir := newTestFirelensResource(t, apicontainer.FirelensConfigTypeFluentbit, nil, testLogOptions)
+	defer os.RemoveAll(dataDir)
+
+	require.NoError(t, firelens.Create())
+
+	config, err := ioutil.ReadFile(filepath.Join(dataDir, "firelens", "task-id", "config", "fluent-bit.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, `[INPUT]
+    Name forward
+    unix_path /var/run/fluent.sock
+
+[FILTER]
+    Name record_modifier
+    Match *
+    Record ecs_cluster mycluster
+    Record ecs_task_arn arn:aws:ecs:us-west-2:123456789012:task/mycluster/task-id
+    Record ecs_task_definition myfamily:1
+
+[OUTPUT]
+    Name cloudwatch
+    Match app-firelens*
+    auto_create_group true
+    log_group_name app
+    region us-west-2
+`, string(config))
+	_, err = os.Stat(filepath.Join(dataDir, "firelens", "task-id", "socket"))
+	assert.NoError(t, err)
+}
+
+func TestCreateFluentd(t *testing.T) {
+	options := map[string]string{
+		"enable-ecs-log-metadata": "false",
+		"config-file-type":        "file",
+		"config-file-value":       "/extra.conf",
+	}
+	firelens, dataDir := newTestFirelensResource(t, apicontainer.FirelensConfigTypeFluentd, options, testFluentdLogOptions)
+	defer os.RemoveAll(dataDir)
+
+	require.NoError(t, firelens.Create())
+
+	config, err := ioutil.ReadFile(filepath.Join(dataDir, "firelens", "task-id", "config", "fluent.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, `<source>
+    @type unix
+    path /var/run/fluent.sock
+</source>
+
+@include /extra.conf
+
+<match app-firelens*>
+    @type cloudwatch_logs
+    log_group_name app
+    region us-west-2
+</match>
+`, string(config))
+}
+
+func TestNewFirelensResourceInvalid(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		firelensConfigType    string
+		options               map[string]string
+		containerToLogOptions map[string]map[string]string
+	}{
+		{
+			name:               "unsupported log router type",
+			firelensConfigType: "logstash",
+		},
+		{
+			name:               "unsupported config file type",
+			firelensConfigType: apicontainer.FirelensConfigTypeFluentbit,
+			options:            map[string]string{"config-file-type": "s3"},
+		},
+		{
+			name:               "missing config file value",
+			firelensConfigType: apicontainer.FirelensConfigTypeFluentbit,
+			options:            map[string]string{"config-file-type": "file"},
+		},
+		{
+			name:                  "missing output plugin",
+			firelensConfigType:    apicontainer.FirelensConfigTypeFluentd,
+			containerToLogOptions: testLogOptions,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewFirelensResource(cluster, taskARN, taskDefinition, tc.firelensConfigType,
+				tc.options, tc.containerToLogOptions, "/data", dataDirOnHost)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLogRouterBindsAndLogConfig(t *testing.T) {
+	firelens, err := NewFirelensResource(cluster, taskARN, taskDefinition, apicontainer.FirelensConfigTypeFluentbit,
+		nil, testLogOptions, "/data", dataDirOnHost)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/var/lib/ecs/data/firelens/task-id/config/fluent-bit.conf:/fluent-bit/etc/fluent-bit.conf",
+		"/var/lib/ecs/data/firelens/task-id/socket:/var/run",
+	}, firelens.LogRouterBinds())
+
+	logConfig := firelens.LogConfig("app")
+	assert.Equal(t, "fluentd", logConfig.Type)
+	assert.Equal(t, map[string]string{
+		"fluentd-address":       "unix:///var/lib/ecs/data/firelens/task-id/socket/fluent.sock",
+		"fluentd-async-connect": "true",
+		"tag":                   "app-firelens-task-id",
+	}, logConfig.Config)
+}
+
+func TestCleanup(t *testing.T) {
+	firelens, dataDir := newTestFirelensResource(t, apicontainer.FirelensConfigTypeFluentbit, nil, testLogOptions)
+	defer os.RemoveAll(dataDir)
+
+	require.NoError(t, firelens.Create())
+	require.NoError(t, firelens.Cleanup())
+
+	_, err := os.Stat(filepath.Join(dataDir, "firelens", "task-id"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	firelensResIn, err := NewFirelensResource(cluster, taskARN, taskDefinition, apicontainer.FirelensConfigTypeFluentbit,
+		nil, testLogOptions, "/data", dataDirOnHost)
+	require.NoError(t, err)
+	firelensResIn.SetCreatedAt(time.Now())
+	firelensResIn.SetKnownStatus(resourcestatus.ResourceCreated)
+	firelensResIn.SetDesiredStatus(resourcestatus.ResourceCreated)
+
+	bytes, err := json.Marshal(firelensResIn)
+	require.NoError(t, err)
+
+	firelensResOut := &FirelensResource{}
+	err = json.Unmarshal(bytes, firelensResOut)
+	require.NoError(t, err)
+	assert.Equal(t, firelensResIn.cluster, firelensResOut.cluster)
+	assert.Equal(t, firelensResIn.taskARN, firelensResOut.taskARN)
+	assert.Equal(t, firelensResIn.taskDefinition, firelensResOut.taskDefinition)
+	assert.WithinDuration(t, firelensResIn.createdAt, firelensResOut.createdAt, time.Microsecond)
+	assert.Equal(t, firelensResIn.desiredStatusUnsafe, firelensResOut.desiredStatusUnsafe)
+	assert.Equal(t, firelensResIn.knownStatusUnsafe, firelensResOut.knownStatusUnsafe)
+	assert.Equal(t, firelensResIn.firelensConfigType, firelensResOut.firelensConfigType)
+	assert.Equal(t, firelensResIn.ecsMetadataEnabled, firelensResOut.ecsMetadataEnabled)
+	assert.Equal(t, firelensResIn.containerToLogOptions, firelensResOut.containerToLogOptions)
+	assert.Equal(t, firelensResIn.resourceDir, firelensResOut.resourceDir)
+	assert.Equal(t, firelensResIn.resourceDirOnHost, firelensResOut.resourceDirOnHost)
+}
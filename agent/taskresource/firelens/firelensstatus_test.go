@@ -0,0 +1,43 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package firelens
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "NONE", FirelensStatusNone.String())
+	assert.Equal(t, "CREATED", FirelensCreated.String())
+	assert.Equal(t, "REMOVED", FirelensRemoved.String())
+}
+
+func TestMarshalUnmarshalFirelensStatus(t *testing.T) {
+	status := FirelensCreated
+	bytes, err := json.Marshal(&status)
+	assert.NoError(t, err)
+	assert.Equal(t, `"CREATED"`, string(bytes))
+
+	var unmarshalled FirelensStatus
+	assert.NoError(t, json.Unmarshal(bytes, &unmarshalled))
+	assert.Equal(t, FirelensCreated, unmarshalled)
+
+	assert.Error(t, json.Unmarshal([]byte(`"UNKNOWN"`), &unmarshalled))
+	assert.Equal(t, FirelensStatusNone, unmarshalled)
+}
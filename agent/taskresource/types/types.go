@@ -21,6 +21,9 @@ import (
 	asmauthres "github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
 	asmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
 	cgroupres "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup"
+	efsres "github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
+	envfileres "github.com/aws/amazon-ecs-agent/agent/taskresource/envfiles"
+	firelensres "github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	ssmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 )
@@ -36,6 +39,12 @@ const (
 	SSMSecretKey = ssmsecretres.ResourceName
 	// ASMSecretKey is the string used in resources map to represent asm secret
 	ASMSecretKey = asmsecretres.ResourceName
+	// FirelensKey is the string used in resources map to represent firelens resource
+	FirelensKey = firelensres.ResourceName
+	// EFSKey is the string used in resources map to represent efs volumes
+	EFSKey = efsres.ResourceName
+	// EnvFileKey is the string used in resources map to represent environment files
+	EnvFileKey = envfileres.ResourceName
 )
 
 // ResourcesMap represents the map of resource type to the corresponding resource
@@ -72,6 +81,18 @@ func (rm *ResourcesMap) UnmarshalJSON(data []byte) error {
 			if unmarshalASMSecretKey(key, value, result) != nil {
 				return err
 			}
+		case FirelensKey:
+			if unmarshalFirelensKey(key, value, result) != nil {
+				return err
+			}
+		case EFSKey:
+			if unmarshalEFSKey(key, value, result) != nil {
+				return err
+			}
+		case EnvFileKey:
+			if unmarshalEnvFileKey(key, value, result) != nil {
+				return err
+			}
 		default:
 			return errors.New("Unsupported resource type")
 		}
@@ -167,3 +188,57 @@ func unmarshalASMSecretKey(key string, value json.RawMessage, result map[string]
 	}
 	return nil
 }
+
+func unmarshalFirelensKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var firelensResources []json.RawMessage
+	err := json.Unmarshal(value, &firelensResources)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range firelensResources {
+		res := &firelensres.FirelensResource{}
+		err := res.UnmarshalJSON(f)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
+
+func unmarshalEFSKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var efsResources []json.RawMessage
+	err := json.Unmarshal(value, &efsResources)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range efsResources {
+		res := &efsres.EFSResource{}
+		err := res.UnmarshalJSON(e)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
+
+func unmarshalEnvFileKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var envFileResources []json.RawMessage
+	err := json.Unmarshal(value, &envFileResources)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range envFileResources {
+		res := &envfileres.EnvironmentFileResource{}
+		err := res.UnmarshalJSON(e)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
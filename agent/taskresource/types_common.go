@@ -16,6 +16,7 @@ package taskresource
 import (
 	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 )
@@ -24,5 +25,6 @@ type ResourceFieldsCommon struct {
 	IOUtil             ioutilwrapper.IOUtil
 	ASMClientCreator   asmfactory.ClientCreator
 	SSMClientCreator   ssmfactory.SSMClientCreator
+	S3ClientCreator    s3factory.S3ClientCreator
 	CredentialsManager credentials.Manager
 }
@@ -41,3 +41,30 @@ type LocalDockerVolume struct {
 func (e *LocalDockerVolume) Source() string {
 	return e.HostPath
 }
+
+// EFSVolumeConfig represents an EFS file system that the agent mounts on the
+// host for a task
+type EFSVolumeConfig struct {
+	FileSystemID  string `json:"fileSystemId"`
+	RootDirectory string `json:"rootDirectory"`
+	// TransitEncryption is "ENABLED" if the file system is mounted over TLS
+	TransitEncryption     string                 `json:"transitEncryption"`
+	TransitEncryptionPort int64                  `json:"transitEncryptionPort"`
+	AuthorizationConfig   EFSAuthorizationConfig `json:"authorizationConfig"`
+	// HostPath is the path on the host the file system is mounted at. It is
+	// set by the agent when the task is initialized
+	HostPath string `json:"hostPath"`
+}
+
+// EFSAuthorizationConfig represents how the agent is authorized to mount an
+// EFS file system
+type EFSAuthorizationConfig struct {
+	AccessPointID string `json:"accessPointId"`
+	// IAM is "ENABLED" if the mount is authorized with the task's IAM role
+	IAM string `json:"iam"`
+}
+
+// Source returns the path on the host the file system is mounted at
+func (efs *EFSVolumeConfig) Source() string {
+	return efs.HostPath
+}
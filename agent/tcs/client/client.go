@@ -48,24 +48,31 @@ type clientServer struct {
 	cancel                 context.CancelFunc
 	disableResourceMetrics bool
 	publishMetricsInterval time.Duration
+	metricsBuffer          *MetricsBuffer
 	wsclient.ClientServerImpl
 }
 
 // New returns a client/server to bidirectionally communicate with the backend.
 // The returned struct should have both 'Connect' and 'Serve' called upon it
-// before being used.
+// before being used. Metrics that cannot be published are kept in the
+// metricsBuffer, which may be nil, and published first the next time.
 func New(url string,
 	cfg *config.Config,
 	credentialProvider *credentials.Credentials,
 	statsEngine stats.Engine,
 	publishMetricsInterval time.Duration,
+	metricsBuffer *MetricsBuffer,
 	rwTimeout time.Duration,
 	disableResourceMetrics bool) wsclient.ClientServer {
+	if metricsBuffer == nil {
+		metricsBuffer = NewMetricsBuffer()
+	}
 	cs := &clientServer{
 		statsEngine:            statsEngine,
 		publishTicker:          nil,
 		publishHealthTicker:    nil,
 		publishMetricsInterval: publishMetricsInterval,
+		metricsBuffer:          metricsBuffer,
 	}
 	cs.URL = url
 	cs.AgentConfig = cfg
@@ -175,21 +182,21 @@ func (cs *clientServer) publishMetrics() {
 }
 
 // publishMetricsOnce is invoked by the ticker to periodically publish metrics to backend.
+// Requests that could not be sent before are sent first, and the requests that
+// cannot be sent are buffered for the next time.
 func (cs *clientServer) publishMetricsOnce() error {
 	// Get the list of objects to send to backend.
 	requests, err := cs.metricsToPublishMetricRequests()
-	if err != nil {
-		return err
-	}
+	requests = append(cs.metricsBuffer.take(), requests...)
 
 	// Make the publish metrics request to the backend.
-	for _, request := range requests {
-		err = cs.MakeRequest(request)
-		if err != nil {
-			return err
+	for i, request := range requests {
+		if sendErr := cs.MakeRequest(request); sendErr != nil {
+			cs.metricsBuffer.add(requests[i:]...)
+			return sendErr
 		}
 	}
-	return nil
+	return err
 }
 
 // metricsToPublishMetricRequests gets task metrics and converts them to a list of PublishMetricRequest
@@ -22,6 +22,7 @@
 package tcsclient
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
@@ -56,7 +57,11 @@ func (*mockStatsEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs
 	return nil, nil, fmt.Errorf("uninitialized")
 }
 
-func (*mockStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.Stats, error) {
+func (*mockStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*mockStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
@@ -70,7 +75,11 @@ func (*emptyStatsEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstc
 	return nil, nil, fmt.Errorf("empty stats")
 }
 
-func (*emptyStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.Stats, error) {
+func (*emptyStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*emptyStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
@@ -90,7 +99,11 @@ func (*idleStatsEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs
 	return metadata, []*ecstcs.TaskMetric{}, nil
 }
 
-func (*idleStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.Stats, error) {
+func (*idleStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*idleStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
@@ -118,7 +131,11 @@ func (engine *nonIdleStatsEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata,
 	return metadata, taskMetrics, nil
 }
 
-func (*nonIdleStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.Stats, error) {
+func (*nonIdleStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*nonIdleStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
@@ -179,13 +196,49 @@ func TestPublishMetricsRequest(t *testing.T) {
 
 func TestPublishMetricsOnceEmptyStatsError(t *testing.T) {
 	cs := clientServer{
-		statsEngine: &emptyStatsEngine{},
+		statsEngine:   &emptyStatsEngine{},
+		metricsBuffer: NewMetricsBuffer(),
 	}
 	err := cs.publishMetricsOnce()
 
 	assert.Error(t, err, "Failed: expecting publishMerticOnce return err ")
 }
 
+// TestPublishMetricsOnceBuffersUnsentRequests tests that the requests which
+// could not be sent are sent first the next time metrics are published
+func TestPublishMetricsOnceBuffersUnsentRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	conn := mock_wsconn.NewMockWebsocketConn(ctrl)
+	conn.EXPECT().SetWriteDeadline(gomock.Any()).Return(nil).AnyTimes()
+	gomock.InOrder(
+		conn.EXPECT().WriteMessage(gomock.Any(), gomock.Any()).Return(errors.New("error")),
+		conn.EXPECT().WriteMessage(gomock.Any(), gomock.Any()).Return(nil).Times(2),
+	)
+
+	metricsBuffer := NewMetricsBuffer()
+	cs := New("https://aws.amazon.com/ecs", &config.Config{AWSRegion: "us-east-1"}, testCreds, &idleStatsEngine{},
+		testPublishMetricsInterval, metricsBuffer, rwTimeout, false).(*clientServer)
+	cs.SetConnection(conn)
+
+	assert.Error(t, cs.publishMetricsOnce())
+	assert.Len(t, metricsBuffer.requests, 1)
+	assert.NoError(t, cs.publishMetricsOnce())
+	assert.Empty(t, metricsBuffer.requests)
+}
+
+func TestMetricsBufferDropsOldestRequests(t *testing.T) {
+	metricsBuffer := NewMetricsBuffer()
+	for i := 0; i < maxBufferedMetricsRequests+1; i++ {
+		metricsBuffer.add(&ecstcs.PublishMetricsRequest{Timestamp: aws.Time(time.Unix(int64(i), 0))})
+	}
+	requests := metricsBuffer.take()
+	assert.Len(t, requests, maxBufferedMetricsRequests)
+	assert.Equal(t, time.Unix(1, 0), aws.TimeValue(requests[0].Timestamp))
+	assert.Empty(t, metricsBuffer.take())
+}
+
 func TestPublishOnceIdleStatsEngine(t *testing.T) {
 	cs := clientServer{
 		statsEngine: &idleStatsEngine{},
@@ -246,7 +299,7 @@ func testCS(conn *mock_wsconn.MockWebsocketConn) wsclient.ClientServer {
 		AcceptInsecureCert: true,
 	}
 	cs := New("https://aws.amazon.com/ecs", cfg, testCreds, &mockStatsEngine{},
-		testPublishMetricsInterval, rwTimeout, false).(*clientServer)
+		testPublishMetricsInterval, nil, rwTimeout, false).(*clientServer)
 	cs.SetConnection(conn)
 	return cs
 }
@@ -313,7 +366,7 @@ func TestMetricsDisabled(t *testing.T) {
 
 	cfg := config.DefaultConfig()
 
-	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, rwTimeout, true)
+	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, nil, rwTimeout, true)
 	cs.SetConnection(conn)
 
 	published := make(chan struct{})
@@ -348,7 +401,7 @@ func TestCreatePublishHealthRequestsEmpty(t *testing.T) {
 	mockStatsEngine := mock_stats.NewMockEngine(ctrl)
 	cfg := config.DefaultConfig()
 
-	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, rwTimeout, true)
+	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, nil, rwTimeout, true)
 	cs.SetConnection(conn)
 
 	mockStatsEngine.EXPECT().GetTaskHealthMetrics().Return(nil, nil, stats.EmptyHealthMetricsError)
@@ -368,7 +421,7 @@ func TestCreatePublishHealthRequests(t *testing.T) {
 	mockStatsEngine := mock_stats.NewMockEngine(ctrl)
 	cfg := config.DefaultConfig()
 
-	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, rwTimeout, true)
+	cs := New("", &cfg, testCreds, mockStatsEngine, testPublishMetricsInterval, nil, rwTimeout, true)
 	cs.SetConnection(conn)
 
 	testMetadata := &ecstcs.HealthMetadata{
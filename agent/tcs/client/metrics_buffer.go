@@ -0,0 +1,60 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tcsclient
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
+	"github.com/cihub/seelog"
+)
+
+// maxBufferedMetricsRequests is the maximum number of publish metrics requests
+// kept while they cannot be sent. The oldest requests are dropped first
+const maxBufferedMetricsRequests = 100
+
+// MetricsBuffer holds the publish metrics requests that could not be sent to
+// the backend, so that they are sent once connected again. It is shared by the
+// clients of successive telemetry sessions
+type MetricsBuffer struct {
+	requests []*ecstcs.PublishMetricsRequest
+	lock     sync.Mutex
+}
+
+// NewMetricsBuffer returns an empty MetricsBuffer
+func NewMetricsBuffer() *MetricsBuffer {
+	return &MetricsBuffer{}
+}
+
+// add buffers the requests, dropping the oldest ones past the maximum size
+func (buffer *MetricsBuffer) add(requests ...*ecstcs.PublishMetricsRequest) {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	buffer.requests = append(buffer.requests, requests...)
+	if dropped := len(buffer.requests) - maxBufferedMetricsRequests; dropped > 0 {
+		seelog.Warnf("Dropping %d buffered publish metrics requests", dropped)
+		buffer.requests = buffer.requests[dropped:]
+	}
+}
+
+// take removes and returns the buffered requests, oldest first
+func (buffer *MetricsBuffer) take() []*ecstcs.PublishMetricsRequest {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	requests := buffer.requests
+	buffer.requests = nil
+	return requests
+}
@@ -76,8 +76,11 @@ func StartMetricsSession(params *TelemetrySessionParams) {
 // the time the websocket client starts using it.
 func StartSession(params *TelemetrySessionParams, statsEngine stats.Engine) error {
 	backoff := retry.NewExponentialBackoff(time.Second, 1*time.Minute, 0.2, 2)
+	// The metrics buffer outlives the sessions, so that metrics that could not
+	// be published are published once reconnected
+	metricsBuffer := tcsclient.NewMetricsBuffer()
 	for {
-		tcsError := startTelemetrySession(params, statsEngine)
+		tcsError := startTelemetrySession(params, statsEngine, metricsBuffer)
 		if tcsError == nil || tcsError == io.EOF {
 			seelog.Info("TCS Websocket connection closed for a valid reason")
 			backoff.Reset()
@@ -88,7 +91,7 @@ func StartSession(params *TelemetrySessionParams, statsEngine stats.Engine) erro
 	}
 }
 
-func startTelemetrySession(params *TelemetrySessionParams, statsEngine stats.Engine) error {
+func startTelemetrySession(params *TelemetrySessionParams, statsEngine stats.Engine, metricsBuffer *tcsclient.MetricsBuffer) error {
 	tcsEndpoint, err := params.ECSClient.DiscoverTelemetryEndpoint(params.ContainerInstanceArn)
 	if err != nil {
 		seelog.Errorf("tcs: unable to discover poll endpoint: %v", err)
@@ -96,8 +99,8 @@ func startTelemetrySession(params *TelemetrySessionParams, statsEngine stats.Eng
 	}
 	url := formatURL(tcsEndpoint, params.Cfg.Cluster, params.ContainerInstanceArn)
 	return startSession(url, params.Cfg, params.CredentialProvider, statsEngine,
-		defaultHeartbeatTimeout, defaultHeartbeatJitter, defaultPublishMetricsInterval,
-		params.DeregisterInstanceEventStream)
+		defaultHeartbeatTimeout, defaultHeartbeatJitter, publishMetricsInterval(params.Cfg),
+		metricsBuffer, params.DeregisterInstanceEventStream)
 }
 
 func startSession(url string,
@@ -106,9 +109,10 @@ func startSession(url string,
 	statsEngine stats.Engine,
 	heartbeatTimeout, heartbeatJitter,
 	publishMetricsInterval time.Duration,
+	metricsBuffer *tcsclient.MetricsBuffer,
 	deregisterInstanceEventStream *eventstream.EventStream) error {
 	client := tcsclient.New(url, cfg, credentialProvider, statsEngine,
-		publishMetricsInterval, wsRWTimeout, cfg.DisableMetrics)
+		publishMetricsInterval, metricsBuffer, wsRWTimeout, cfg.DisableMetrics)
 	defer client.Close()
 
 	err := deregisterInstanceEventStream.Subscribe(deregisterContainerInstanceHandler, client.Disconnect)
@@ -141,6 +145,15 @@ func startSession(url string,
 }
 
 // heartbeatHandler resets the heartbeat timer when HeartbeatMessage message is received from tcs.
+// publishMetricsInterval returns the configured interval at which metrics are
+// published, or the default one when it is not set
+func publishMetricsInterval(cfg *config.Config) time.Duration {
+	if cfg.PublishMetricsInterval > 0 {
+		return cfg.PublishMetricsInterval
+	}
+	return defaultPublishMetricsInterval
+}
+
 func heartbeatHandler(timer *time.Timer) func(*ecstcs.HeartbeatMessage) {
 	return func(*ecstcs.HeartbeatMessage) {
 		seelog.Debug("Received HeartbeatMessage from tcs")
@@ -31,6 +31,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/api/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/tcs/client"
 	"github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
 	"github.com/aws/amazon-ecs-agent/agent/wsclient"
@@ -65,7 +66,11 @@ func (*mockStatsEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs
 	return req.Metadata, req.TaskMetrics, nil
 }
 
-func (*mockStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.Stats, error) {
+func (*mockStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.StatsJSON, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*mockStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
@@ -136,7 +141,7 @@ func TestStartSession(t *testing.T) {
 	// Start a session with the test server.
 	go startSession(server.URL, testCfg, testCreds, &mockStatsEngine{},
 		defaultHeartbeatTimeout, defaultHeartbeatJitter,
-		testPublishMetricsInterval, deregisterInstanceEventStream)
+		testPublishMetricsInterval, nil, deregisterInstanceEventStream)
 
 	// startSession internally starts publishing metrics from the mockStatsEngine object.
 	time.Sleep(testPublishMetricsInterval)
@@ -200,7 +205,7 @@ func TestSessionConnectionClosedByRemote(t *testing.T) {
 	// Start a session with the test server.
 	err = startSession(server.URL, testCfg, testCreds, &mockStatsEngine{},
 		defaultHeartbeatTimeout, defaultHeartbeatJitter,
-		testPublishMetricsInterval, deregisterInstanceEventStream)
+		testPublishMetricsInterval, nil, deregisterInstanceEventStream)
 
 	if err == nil {
 		t.Error("Expected io.EOF on closed connection")
@@ -237,7 +242,7 @@ func TestConnectionInactiveTimeout(t *testing.T) {
 	// Start a session with the test server.
 	err = startSession(server.URL, testCfg, testCreds, &mockStatsEngine{},
 		50*time.Millisecond, 100*time.Millisecond,
-		testPublishMetricsInterval, deregisterInstanceEventStream)
+		testPublishMetricsInterval, nil, deregisterInstanceEventStream)
 	// if we are not blocked here, then the test pass as it will reconnect in StartSession
 	assert.Error(t, err, "Close the connection should cause the tcs client return error")
 
@@ -254,7 +259,7 @@ func TestDiscoverEndpointAndStartSession(t *testing.T) {
 	mockEcs := mock_api.NewMockECSClient(ctrl)
 	mockEcs.EXPECT().DiscoverTelemetryEndpoint(gomock.Any()).Return("", errors.New("error"))
 
-	err := startTelemetrySession(&TelemetrySessionParams{ECSClient: mockEcs}, nil)
+	err := startTelemetrySession(&TelemetrySessionParams{ECSClient: mockEcs}, nil, nil)
 	if err == nil {
 		t.Error("Expected error from startTelemetrySession when DiscoverTelemetryEndpoint returns error")
 	}
@@ -320,3 +325,8 @@ func createPublishMetricsRequest() *ecstcs.PublishMetricsRequest {
 		Timestamp: &ts,
 	}
 }
+
+func TestPublishMetricsInterval(t *testing.T) {
+	assert.Equal(t, defaultPublishMetricsInterval, publishMetricsInterval(&config.Config{}))
+	assert.Equal(t, time.Minute, publishMetricsInterval(&config.Config{PublishMetricsInterval: time.Minute}))
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// exportInterval is the interval at which queued spans are exported
+	exportInterval = 5 * time.Second
+	// exportTimeout is the timeout of a request to the OTLP endpoint
+	exportTimeout = 10 * time.Second
+	// maxExportBatchSize is the maximum number of spans in a request
+	maxExportBatchSize = 512
+	// maxQueuedSpans bounds the memory used by spans that could not be exported
+	// yet. The oldest spans are dropped first
+	maxQueuedSpans = 2048
+
+	serviceName         = "amazon-ecs-agent"
+	instrumentationName = "github.com/aws/amazon-ecs-agent/agent/tracing"
+
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// finishedSpan is a span that has ended and waits to be exported
+type finishedSpan struct {
+	traceID      [16]byte
+	spanID       [8]byte
+	parentSpanID [8]byte
+	name         string
+	kind         int
+	start        time.Time
+	end          time.Time
+	attributes   map[string]string
+	errMessage   string
+}
+
+// Tracer queues finished spans and exports them in batches to an OTLP/HTTP
+// traces endpoint, using the JSON encoding of the protocol.
+type Tracer struct {
+	endpoint string
+	client   *http.Client
+	lock     sync.Mutex
+	spans    []finishedSpan
+}
+
+// NewTracer creates a tracer exporting to the OTLP/HTTP traces endpoint, such
+// as http://localhost:4318/v1/traces.
+func NewTracer(endpoint string) *Tracer {
+	return &Tracer{
+		endpoint: endpoint,
+		client:   httpclient.New(exportTimeout, false),
+	}
+}
+
+// Run exports the queued spans periodically until the context is cancelled.
+func (tracer *Tracer) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Flush what was recorded during the shutdown of the agent
+			tracer.export(context.Background())
+			return
+		case <-ticker.C:
+			tracer.export(ctx)
+		}
+	}
+}
+
+func (tracer *Tracer) enqueue(span finishedSpan) {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+
+	if len(tracer.spans) >= maxQueuedSpans {
+		tracer.spans = tracer.spans[1:]
+	}
+	tracer.spans = append(tracer.spans, span)
+}
+
+func (tracer *Tracer) takeBatch() []finishedSpan {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+
+	size := len(tracer.spans)
+	if size > maxExportBatchSize {
+		size = maxExportBatchSize
+	}
+	batch := tracer.spans[:size]
+	tracer.spans = tracer.spans[size:]
+	return batch
+}
+
+// export sends all the queued spans. Spans of a batch that cannot be sent are
+// dropped, since the collector is not expected to be down for long.
+func (tracer *Tracer) export(ctx context.Context) {
+	for {
+		batch := tracer.takeBatch()
+		if len(batch) == 0 {
+			return
+		}
+		if err := tracer.send(ctx, batch); err != nil {
+			seelog.Warnf("Unable to export %d spans to %s: %v", len(batch), tracer.endpoint, err)
+			return
+		}
+	}
+}
+
+func (tracer *Tracer) send(ctx context.Context, batch []finishedSpan) error {
+	body, err := json.Marshal(newExportRequest(batch))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", tracer.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := tracer.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newExportRequest(batch []finishedSpan) *otlpExportRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		status := otlpStatus{Code: statusCodeOK}
+		if span.errMessage != "" {
+			status = otlpStatus{Code: statusCodeError, Message: span.errMessage}
+		}
+		parentSpanID := ""
+		if span.parentSpanID != [8]byte{} {
+			parentSpanID = hex.EncodeToString(span.parentSpanID[:])
+		}
+		spans = append(spans, otlpSpan{
+			TraceID:           hex.EncodeToString(span.traceID[:]),
+			SpanID:            hex.EncodeToString(span.spanID[:]),
+			ParentSpanID:      parentSpanID,
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        keyValues(span.attributes),
+			Status:            status,
+		})
+	}
+	return &otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: keyValues(map[string]string{
+				"service.name":    serviceName,
+				"service.version": version.Version,
+			})},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationName, Version: version.Version},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+// keyValues converts attributes to OTLP key values, sorted by key
+func keyValues(attributes map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keyValues := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		keyValues = append(keyValues, otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: attributes[key]}})
+	}
+	return keyValues
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing records spans of task lifecycle operations and of the calls
+// to the ECS backend, and exports them to an OpenTelemetry collector with the
+// OTLP/HTTP protocol. Spans of the same task share a trace id derived from the
+// task arn and are children of a root span covering the life of the task, so
+// that a slow task start can be broken down into the time spent pulling from
+// the registry, in Docker and in the backend.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+const (
+	// spanKindInternal and spanKindClient are the OTLP kinds of spans for
+	// operations of the agent and for calls to remote services
+	spanKindInternal = 1
+	spanKindClient   = 3
+)
+
+var (
+	globalTracer     *Tracer
+	globalTracerLock sync.RWMutex
+)
+
+// Span is an operation whose duration and outcome are exported once it ends.
+// A nil Span, as returned while tracing is disabled, ignores all calls.
+type Span struct {
+	tracer  *Tracer
+	traceID [16]byte
+	spanID  [8]byte
+	// parentSpanID is zero for a root span
+	parentSpanID [8]byte
+	name         string
+	kind         int
+	start        time.Time
+	attributes   map[string]string
+}
+
+// Init enables tracing when an OTLP traces endpoint is configured. Spans are
+// exported until the context is cancelled.
+func Init(ctx context.Context, endpoint string) {
+	if endpoint == "" {
+		return
+	}
+	tracer := NewTracer(endpoint)
+	globalTracerLock.Lock()
+	globalTracer = tracer
+	globalTracerLock.Unlock()
+	go tracer.Run(ctx)
+}
+
+func getGlobalTracer() *Tracer {
+	globalTracerLock.RLock()
+	defer globalTracerLock.RUnlock()
+	return globalTracer
+}
+
+// StartRootSpan starts the root span of the trace of the given key, such as a
+// task arn. Its span id is derived from the key, so that the spans started
+// with the same key are its children even when they end before it starts, as
+// happens when the agent restarts.
+func StartRootSpan(traceKey string, name string) *Span {
+	return getGlobalTracer().startRootSpan(traceKey, name, time.Now())
+}
+
+// StartSpan starts a span of an operation of the agent. Spans started with the
+// same trace key, such as a task arn, belong to the same trace and are children
+// of its root span.
+func StartSpan(traceKey string, name string) *Span {
+	return getGlobalTracer().startSpan(traceKey, name, spanKindInternal, time.Now())
+}
+
+// StartClientSpanAt starts a span of a call to a remote service that started
+// at the given time.
+func StartClientSpanAt(traceKey string, name string, start time.Time) *Span {
+	return getGlobalTracer().startSpan(traceKey, name, spanKindClient, start)
+}
+
+func (tracer *Tracer) startRootSpan(traceKey string, name string, start time.Time) *Span {
+	if tracer == nil {
+		return nil
+	}
+	span := newSpan(tracer, name, spanKindInternal, start)
+	hash := sha256.Sum256([]byte(traceKey))
+	copy(span.traceID[:], hash[:16])
+	copy(span.spanID[:], hash[16:])
+	return span
+}
+
+func (tracer *Tracer) startSpan(traceKey string, name string, kind int, start time.Time) *Span {
+	if tracer == nil {
+		return nil
+	}
+	span := newSpan(tracer, name, kind, start)
+	if traceKey != "" {
+		hash := sha256.Sum256([]byte(traceKey))
+		copy(span.traceID[:], hash[:16])
+		copy(span.parentSpanID[:], hash[16:])
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	return span
+}
+
+func newSpan(tracer *Tracer, name string, kind int, start time.Time) *Span {
+	return &Span{
+		tracer:     tracer,
+		name:       name,
+		kind:       kind,
+		start:      start,
+		attributes: make(map[string]string),
+	}
+}
+
+// SetAttribute sets an attribute of the span.
+func (span *Span) SetAttribute(key string, value string) {
+	if span == nil {
+		return
+	}
+	span.attributes[key] = value
+}
+
+// End ends the span now, recording err as its status.
+func (span *Span) End(err error) {
+	span.EndAt(time.Now(), err)
+}
+
+// EndAt ends the span at the given time, recording err as its status.
+func (span *Span) EndAt(end time.Time, err error) {
+	if span == nil {
+		return
+	}
+	errMessage := ""
+	if err != nil {
+		errMessage = err.Error()
+		if errMessage == "" {
+			errMessage = "error"
+		}
+	}
+	span.tracer.enqueue(finishedSpan{
+		traceID:      span.traceID,
+		spanID:       span.spanID,
+		parentSpanID: span.parentSpanID,
+		name:         span.name,
+		kind:         span.kind,
+		start:        span.start,
+		end:          end,
+		attributes:   span.attributes,
+		errMessage:   errMessage,
+	})
+}
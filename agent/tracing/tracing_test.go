@@ -0,0 +1,123 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const taskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/abc"
+
+func TestNilSpanIsNoop(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.startSpan(taskARN, "PullContainer", spanKindInternal, time.Now())
+	assert.Nil(t, span)
+	span.SetAttribute("container.name", "web")
+	span.End(errors.New("error"))
+}
+
+func TestExportSpans(t *testing.T) {
+	requests := make(chan otlpExportRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var request otlpExportRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests <- request
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(server.URL)
+	start := time.Unix(100, 0)
+	task := tracer.startRootSpan(taskARN, "Task", start)
+	pull := tracer.startSpan(taskARN, "PullContainer", spanKindInternal, start)
+	pull.SetAttribute("container.name", "web")
+	pull.EndAt(start.Add(time.Second), nil)
+	submit := tracer.startSpan(taskARN, "SubmitTaskStateChange", spanKindClient, start)
+	submit.EndAt(start.Add(2*time.Second), errors.New("throttled"))
+	task.EndAt(start.Add(3*time.Second), nil)
+	tracer.export(context.Background())
+
+	var request otlpExportRequest
+	select {
+	case request = <-requests:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the export request")
+	}
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Contains(t, request.ResourceSpans[0].Resource.Attributes,
+		otlpKeyValue{Key: "service.name", Value: otlpAnyValue{StringValue: serviceName}})
+	require.Len(t, request.ResourceSpans[0].ScopeSpans, 1)
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "PullContainer", spans[0].Name)
+	assert.Equal(t, spanKindInternal, spans[0].Kind)
+	assert.Len(t, spans[0].TraceID, 32)
+	assert.Len(t, spans[0].SpanID, 16)
+	assert.Equal(t, "100000000000", spans[0].StartTimeUnixNano)
+	assert.Equal(t, "101000000000", spans[0].EndTimeUnixNano)
+	assert.Equal(t, []otlpKeyValue{{Key: "container.name", Value: otlpAnyValue{StringValue: "web"}}}, spans[0].Attributes)
+	assert.Equal(t, otlpStatus{Code: statusCodeOK}, spans[0].Status)
+
+	assert.Equal(t, "SubmitTaskStateChange", spans[1].Name)
+	assert.Equal(t, spanKindClient, spans[1].Kind)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID, "Spans of a task should share the trace id")
+	assert.NotEqual(t, spans[0].SpanID, spans[1].SpanID)
+	assert.Equal(t, otlpStatus{Code: statusCodeError, Message: "throttled"}, spans[1].Status)
+
+	assert.Equal(t, "Task", spans[2].Name)
+	assert.Equal(t, spans[0].TraceID, spans[2].TraceID)
+	assert.Empty(t, spans[2].ParentSpanID, "The task span should be the root of the trace")
+	assert.Equal(t, spans[2].SpanID, spans[0].ParentSpanID, "Spans of a task should be children of the task span")
+	assert.Equal(t, spans[2].SpanID, spans[1].ParentSpanID, "Spans of a task should be children of the task span")
+
+	assert.Empty(t, tracer.takeBatch(), "Exported spans should be dequeued")
+}
+
+func TestSpansWithoutTraceKeyAreRoots(t *testing.T) {
+	tracer := NewTracer("http://localhost:4318/v1/traces")
+	tracer.startSpan("", "DiscoverPollEndpoint", spanKindClient, time.Now()).End(nil)
+	tracer.startSpan("", "DiscoverPollEndpoint", spanKindClient, time.Now()).End(nil)
+
+	request := newExportRequest(tracer.takeBatch())
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[0].ParentSpanID)
+	assert.NotEqual(t, spans[0].TraceID, spans[1].TraceID)
+}
+
+func TestEnqueueDropsOldestSpans(t *testing.T) {
+	tracer := NewTracer("http://localhost:4318/v1/traces")
+	for i := 0; i < maxQueuedSpans+1; i++ {
+		tracer.enqueue(finishedSpan{name: "span", kind: i})
+	}
+	assert.Len(t, tracer.spans, maxQueuedSpans)
+	assert.Equal(t, 1, tracer.spans[0].kind)
+
+	batch := tracer.takeBatch()
+	assert.Len(t, batch, maxExportBatchSize)
+	assert.Len(t, tracer.spans, maxQueuedSpans-maxExportBatchSize)
+}
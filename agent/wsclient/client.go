@@ -27,7 +27,6 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
 	"reflect"
 	"strings"
 	"sync"
@@ -36,6 +35,7 @@ import (
 	"crypto/tls"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/cipher"
 	"github.com/aws/amazon-ecs-agent/agent/wsclient/wsconn"
@@ -63,8 +63,9 @@ const (
 	// writeBufSize is the size of the write buffer for the ws connection.
 	writeBufSize = 32768
 
-	// Default NO_PROXY env var IP addresses
-	defaultNoProxyIP = "169.254.169.254,169.254.170.2"
+	// wsMaxMessageSize is the default maximum size of a message read from the
+	// ws connection. The connection is closed when a larger message is received
+	wsMaxMessageSize = 4 * 1024 * 1024
 
 	errClosed = "use of closed network connection"
 )
@@ -129,6 +130,12 @@ type ClientServerImpl struct {
 	// RWTimeout is the duration used for setting read and write deadlines
 	// for the websocket connection
 	RWTimeout time.Duration
+	// ConnectTimeout is the duration used for establishing the websocket
+	// connection. The default connection timeout is used when it is zero
+	ConnectTimeout time.Duration
+	// MaxMessageSize is the maximum size in bytes of a message read from the
+	// websocket connection. The default maximum size is used when it is zero
+	MaxMessageSize int64
 	// writeLock needed to ensure that only one routine is writing to the socket
 	writeLock sync.RWMutex
 	ClientServer
@@ -167,22 +174,15 @@ func (cs *ClientServerImpl) Connect() error {
 		return err
 	}
 
-	timeoutDialer := &net.Dialer{Timeout: wsConnectTimeout}
+	timeoutDialer := &net.Dialer{Timeout: cs.connectTimeout()}
 	tlsConfig := &tls.Config{ServerName: parsedURL.Host, InsecureSkipVerify: cs.AgentConfig.AcceptInsecureCert}
 	cipher.WithSupportedCipherSuites(tlsConfig)
+	if err := httpclient.ConfigureTLSTrust(tlsConfig, cs.AgentConfig.CABundlePath, cs.AgentConfig.PinnedPublicKeys); err != nil {
+		seelog.Criticalf("Unable to configure the trusted certificates of the websocket client: %v", err)
+	}
 
 	// Ensure that NO_PROXY gets set
-	noProxy := os.Getenv("NO_PROXY")
-	if noProxy == "" {
-		dockerHost, err := url.Parse(cs.AgentConfig.DockerEndpoint)
-		if err == nil {
-			dockerHost.Scheme = ""
-			os.Setenv("NO_PROXY", fmt.Sprintf("%s,%s", defaultNoProxyIP, dockerHost.String()))
-			seelog.Info("NO_PROXY set:", os.Getenv("NO_PROXY"))
-		} else {
-			seelog.Errorf("NO_PROXY unable to be set: the configured Docker endpoint is invalid.")
-		}
-	}
+	httpclient.SetDefaultNoProxy(cs.AgentConfig.DockerEndpoint)
 
 	dialer := websocket.Dialer{
 		ReadBufferSize:   readBufSize,
@@ -190,7 +190,7 @@ func (cs *ClientServerImpl) Connect() error {
 		TLSClientConfig:  tlsConfig,
 		Proxy:            http.ProxyFromEnvironment,
 		NetDial:          timeoutDialer.Dial,
-		HandshakeTimeout: wsHandshakeTimeout,
+		HandshakeTimeout: cs.handshakeTimeout(),
 	}
 
 	websocketConn, httpResponse, err := dialer.Dial(parsedURL.String(), request.Header)
@@ -218,6 +218,8 @@ func (cs *ClientServerImpl) Connect() error {
 			parsedURL.Host, string(resp))
 	}
 
+	websocketConn.SetReadLimit(cs.maxMessageSize())
+
 	cs.writeLock.Lock()
 	defer cs.writeLock.Unlock()
 
@@ -264,12 +266,36 @@ func (cs *ClientServerImpl) SetReadDeadline(t time.Time) error {
 	return err
 }
 
+// connectTimeout returns the timeout for establishing the connection
+func (cs *ClientServerImpl) connectTimeout() time.Duration {
+	if cs.ConnectTimeout > 0 {
+		return cs.ConnectTimeout
+	}
+	return wsConnectTimeout
+}
+
+// handshakeTimeout returns the timeout for the websocket handshake
+func (cs *ClientServerImpl) handshakeTimeout() time.Duration {
+	if cs.ConnectTimeout > 0 {
+		return cs.ConnectTimeout
+	}
+	return wsHandshakeTimeout
+}
+
+// maxMessageSize returns the maximum size of a message read from the connection
+func (cs *ClientServerImpl) maxMessageSize() int64 {
+	if cs.MaxMessageSize > 0 {
+		return cs.MaxMessageSize
+	}
+	return wsMaxMessageSize
+}
+
 func (cs *ClientServerImpl) forceCloseConnection() {
 	closeChan := make(chan error)
 	go func() {
 		closeChan <- cs.Close()
 	}()
-	ctx, cancel := context.WithTimeout(context.TODO(), wsConnectTimeout)
+	ctx, cancel := context.WithTimeout(context.TODO(), cs.connectTimeout())
 	defer cancel()
 	select {
 	case closeErr := <-closeChan:
@@ -385,6 +411,10 @@ func (cs *ClientServerImpl) ConsumeMessages() error {
 			seelog.Debugf("Connection closed for a valid reason: %s", err)
 			return io.EOF
 
+		case err == websocket.ErrReadLimit:
+			seelog.Errorf("Received a message larger than %d bytes from ws backend, closing the connection", cs.maxMessageSize())
+			return err
+
 		default:
 			// Unexpected error occurred
 			seelog.Errorf("Error getting message from ws backend: error: [%v], messageType: [%v] ",
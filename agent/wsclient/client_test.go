@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -267,3 +268,54 @@ func TestSetReadDeadlineError(t *testing.T) {
 	)
 	assert.Error(t, cs.ConsumeMessages())
 }
+
+// TestConsumeMessagesReadLimit checks that messages larger than the maximum
+// message size close the connection
+func TestConsumeMessagesReadLimit(t *testing.T) {
+	closeWS := make(chan []byte)
+	defer close(closeWS)
+
+	messageError := make(chan error)
+	mockServer, serverChan, _, _, _ := utils.GetMockServer(closeWS)
+	mockServer.StartTLS()
+	cs := getClientServer(mockServer.URL)
+	cs.MaxMessageSize = 16
+	require.NoError(t, cs.Connect())
+
+	go func() {
+		messageError <- cs.ConsumeMessages()
+	}()
+
+	serverChan <- `{"type":"AckRequest","message":{"messageId":"a message id longer than the limit"}}`
+	assert.Equal(t, websocket.ErrReadLimit, <-messageError)
+}
+
+func TestDecodeDataValidatesMessageShape(t *testing.T) {
+	decoder := BuildTypeDecoder([]interface{}{ecsacs.AckRequest{}})
+
+	message, typeStr, err := DecodeData([]byte(`{"type":"AckRequest","message":{"messageId":"mid","newField":1}}`), decoder)
+	assert.NoError(t, err, "unknown fields are expected to be ignored")
+	assert.Equal(t, "AckRequest", typeStr)
+	assert.Equal(t, "mid", aws.StringValue(message.(*ecsacs.AckRequest).MessageId))
+
+	for _, data := range []string{
+		`{"type":"AckRequest"}`,
+		`{"type":"AckRequest","message":null}`,
+		`{"type":"AckRequest","message":["mid"]}`,
+		`{"type":"AckRequest","message":{"messageId":1}}`,
+	} {
+		_, _, err := DecodeData([]byte(data), decoder)
+		assert.Error(t, err, "expected an error decoding %s", data)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	body := map[string]interface{}{
+		"messageId": "mid",
+		"tasks": []interface{}{
+			map[string]interface{}{"arn": "t1", "family": "f", "newTaskField": true},
+		},
+		"newField": 1,
+	}
+	assert.Equal(t, []string{"newField", "tasks.newTaskField"}, unknownFields(reflect.TypeOf(&ecsacs.PayloadMessage{}), body, ""))
+}
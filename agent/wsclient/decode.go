@@ -21,9 +21,13 @@ package wsclient
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/cihub/seelog"
 )
 
 // DecodeData decodes a raw message into its type. E.g. An ACS message of the
@@ -48,10 +52,95 @@ func DecodeData(data []byte, dec TypeDecoder) (interface{}, string, error) {
 	if !ok {
 		return nil, raw.Type, &UnrecognizedWSRequestType{raw.Type}
 	}
-	err = jsonutil.UnmarshalJSON(reqMessage, bytes.NewReader(raw.Message))
+	if err := validateMessageShape(reqMessage, raw); err != nil {
+		return nil, raw.Type, err
+	}
+	err = unmarshalMessage(reqMessage, raw.Message)
 	return reqMessage, raw.Type, err
 }
 
+// validateMessageShape checks that the message body is a JSON object, and logs
+// the fields of the body that the message type does not have. Unknown fields
+// are not rejected so that the backend can add fields to its messages
+func validateMessageShape(reqMessage interface{}, raw *ReceivedMessage) error {
+	var body interface{}
+	if err := json.Unmarshal(raw.Message, &body); err != nil {
+		return &UndecodableMessage{raw.Type + ": " + err.Error()}
+	}
+	if _, ok := body.(map[string]interface{}); !ok {
+		return &UndecodableMessage{raw.Type + ": message is not an object: " + string(raw.Message)}
+	}
+	if fields := unknownFields(reflect.TypeOf(reqMessage), body, ""); len(fields) > 0 {
+		seelog.Warnf("Ignoring unknown fields in message of type %s: %s", raw.Type, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// unknownFields returns the paths of the fields in the JSON value that have
+// no matching field in the type
+func unknownFields(t reflect.Type, value interface{}, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var fields []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			for name, fieldValue := range v {
+				field, ok := structFieldByLocationName(t, name)
+				if !ok {
+					fields = append(fields, path+name)
+					continue
+				}
+				fields = append(fields, unknownFields(field.Type, fieldValue, path+name+".")...)
+			}
+		case reflect.Map:
+			for key, fieldValue := range v {
+				fields = append(fields, unknownFields(t.Elem(), fieldValue, path+key+".")...)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice {
+			for _, element := range v {
+				fields = append(fields, unknownFields(t.Elem(), element, path)...)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// structFieldByLocationName returns the exported field of the struct type that
+// is named name in JSON
+func structFieldByLocationName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		locationName := field.Tag.Get("locationName")
+		if locationName == "" {
+			locationName = field.Name
+		}
+		if locationName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// unmarshalMessage unmarshals the message body into the message type,
+// returning an error rather than panicking if the body cannot be assigned to it
+func unmarshalMessage(reqMessage interface{}, message json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &UndecodableMessage{fmt.Sprintf("%v", r)}
+		}
+	}()
+	return jsonutil.UnmarshalJSON(reqMessage, bytes.NewReader(message))
+}
+
 // DecodeConnectionError decodes some of the connection errors returned by the
 // backend. Some differ from the usual ones in that they do not have a 'type'
 // and 'message' field, but rather are of the form {"ErrorType":"ErrorMessage"}